@@ -10,3 +10,78 @@ CREATE TABLE IF NOT EXISTS admins (
     email VARCHAR(100) UNIQUE NOT NULL,
     name VARCHAR(100) NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(100) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_action_created_at_idx ON audit_logs (action, created_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL DEFAULT 'web',
+    username VARCHAR(100) NOT NULL,
+    access_token TEXT NOT NULL,
+    id_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    access_token_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS sessions_username_idx ON sessions (username, purpose, created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS outbox_messages_pending_idx ON outbox_messages (id) WHERE sent_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS token_revocations (
+    sub VARCHAR(64) PRIMARY KEY,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS email_changes (
+    user_id VARCHAR(64) PRIMARY KEY,
+    previous_email VARCHAR(100) NOT NULL,
+    new_email VARCHAR(100) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    requested_at TIMESTAMP WITH TIME ZONE,
+    verified_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS login_failures (
+    username VARCHAR(100) PRIMARY KEY,
+    failures INT NOT NULL,
+    first_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_failures_last_failure_at_idx ON login_failures (last_failure_at);
+
+CREATE TABLE IF NOT EXISTS reset_passwords_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    actor_id VARCHAR(36) NOT NULL,
+    group_name VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    processed INT NOT NULL DEFAULT 0,
+    succeeded INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- At most one running job across all instances.
+CREATE UNIQUE INDEX IF NOT EXISTS reset_passwords_jobs_running_idx ON reset_passwords_jobs (status) WHERE status = 'RUNNING';
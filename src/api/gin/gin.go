@@ -3,34 +3,94 @@ package gin
 import (
 	"auth-api/src/api/gin/middleware"
 	"auth-api/src/api/gin/routes"
+	"auth-api/src/config"
 	"auth-api/src/factory"
 	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/metrics"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	TrailingSlashRedirect = "redirect" // gin answers with a 301/307 to the registered variant
+	TrailingSlashHandle   = "handle"   // the trailing slash is dropped and the request served directly
+)
+
 type Gin struct {
 	log     logger.Logger
 	Gin     *gin.Engine
+	config  *config.Config
 	factory *factory.Factory
+	metrics *metrics.Registry
 }
 
-func New(logger logger.Logger, factory *factory.Factory) *Gin {
-	gin := gin.Default()
+func New(logger logger.Logger, config *config.Config, factory *factory.Factory) *Gin {
+	gin := gin.New() // recovery and request logging are set up in SetupMiddlewares
+	gin.RedirectTrailingSlash = config.Api.TrailingSlash != TrailingSlashHandle
 	return &Gin{
 		log:     logger,
 		Gin:     gin,
+		config:  config,
 		factory: factory,
 	}
 }
 
-func (s *Gin) SetupMiddlewares() {
-	cors := middleware.NewCors("*", "GET, POST, PUT, DELETE, OPTIONS", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token, X-Auth-Token, X-Requested-With", false)
+// Handler returns the engine to be served. Routes are registered without a
+// trailing slash, so in handle mode the slash is trimmed before routing.
+func (s *Gin) Handler() http.Handler {
+	if s.config.Api.TrailingSlash != TrailingSlashHandle {
+		return s.Gin
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			r.URL.Path = strings.TrimSuffix(r.URL.Path, "/")
+			r.URL.RawPath = strings.TrimSuffix(r.URL.RawPath, "/")
+		}
+		s.Gin.ServeHTTP(w, r)
+	})
+}
+
+func (s *Gin) SetupMiddlewares() error {
+	// The rate limits and the access log key on ClientIP, so a forwarded
+	// address is only taken from the proxies in front of the service.
+	if err := s.Gin.SetTrustedProxies(s.config.Api.TrustedProxies); err != nil {
+		return err
+	}
+	s.Gin.Use(middleware.RequestID())
+	// Only production is held to HTTPS so local and dev setups keep working
+	// over plain HTTP. Disable it when TLS is terminated somewhere that
+	// doesn't forward the original protocol.
+	if s.config.Env == "production" && s.config.Api.Https.Enforce {
+		https, err := middleware.NewHttps(s.config.Api.Https.Mode, s.config.Api.Https.TrustForwardedProto, s.config.Api.TrustedProxies, s.config.Api.ErrorFormat, "/health")
+		if err != nil {
+			return err
+		}
+		s.Gin.Use(https.HttpsMiddleware())
+	}
+	cors := middleware.NewCors("*", "GET, POST, PUT, DELETE, OPTIONS", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-CSRF-Token, X-Auth-Token, X-Requested-With, X-Client-Type", false)
 	s.Gin.Use(cors.CorsMiddleware())
-	s.Gin.Use(gin.CustomRecovery(middleware.RecoveryHandler(s.log)))
-	s.Gin.Use(gin.Logger())
-	s.Gin.Use(middleware.ErrorHandler(s.log))
+	if s.config.Api.Metrics.Enabled {
+		// Ahead of recovery so a panic is counted with the 500 it becomes.
+		s.metrics = metrics.NewRegistry()
+		s.Gin.Use(middleware.NewHTTPMetrics(s.metrics).MetricsMiddleware())
+	}
+	if s.config.Api.AccessLog.Enabled {
+		// Bodies carry credentials and tokens, so production never logs them
+		// whatever the config says.
+		logBodies := s.config.Api.AccessLog.LogBodies && s.config.Env != "production"
+		accessLog := middleware.NewAccessLog(s.log, s.config.Api.AccessLog.Level, logBodies, s.config.Api.AccessLog.MaxBodyBytes, s.config.Api.AccessLog.RedactFields...)
+		s.Gin.Use(accessLog.AccessLogMiddleware())
+	} else {
+		s.Gin.Use(gin.Logger())
+	}
+	// Inside the access log so a panicked request is still logged, as the 500
+	// it becomes.
+	s.Gin.Use(middleware.Recovery(s.log, s.config.Api.ErrorFormat))
+	s.Gin.Use(middleware.ErrorHandler(s.log, s.config.Api.ErrorFormat))
+	s.Gin.Use(middleware.MaxAuthHeaderSize(s.config.Api.MaxAuthHeaderBytes))
+	return nil
 }
 
 func (s *Gin) SetupApi() error {
@@ -38,16 +98,19 @@ func (s *Gin) SetupApi() error {
 	s.Gin.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	if s.metrics != nil {
+		s.Gin.GET("/metrics", gin.WrapH(s.metrics.Handler()))
+	}
 
 	apiRoutes := s.Gin.Group("/api/v1")
 
 	// Middlewares
-	authMiddleware := middleware.NewAuthMiddleware(s.factory.Service.UserManager.Auth)
+	authMiddleware := middleware.NewAuthMiddleware(s.factory.Service.UserManager.Auth, s.factory.Service.UserManager.ClaimsResolver)
 
 	//Static files
 	s.Gin.StaticFS("/web", http.Dir("static"))
 
 	//Routes
-	routes.NewRoutes(apiRoutes, s.factory, authMiddleware).ConfigRoutes()
+	routes.NewRoutes(apiRoutes, s.factory, authMiddleware, s.config).ConfigRoutes()
 	return nil
 }
@@ -0,0 +1,195 @@
+package gin
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/factory"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/metrics"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type publicRoute struct {
+	Method string
+	Path   string
+}
+
+// publicRoutes are the routes reachable without the auth middleware. Some of
+// them still take a token, in the body or in the session cookie, and check it
+// themselves. A new route served without AuthMiddleware has to be added here.
+var publicRoutes = map[publicRoute]bool{
+	{http.MethodGet, "/health"}:         true,
+	{http.MethodGet, "/metrics"}:        true,
+	{http.MethodGet, "/web/*filepath"}:  true,
+	{http.MethodHead, "/web/*filepath"}: true,
+
+	{http.MethodPost, "/api/v1/auth/login"}:                  true,
+	{http.MethodPost, "/api/v1/auth/logout"}:                 true,
+	{http.MethodPost, "/api/v1/auth/refresh"}:                true,
+	{http.MethodPost, "/api/v1/auth/refresh/id-token"}:       true,
+	{http.MethodPost, "/api/v1/auth/authorize"}:              true,
+	{http.MethodGet, "/api/v1/auth/validate-password"}:       true,
+	{http.MethodPost, "/api/v1/auth/validate-password"}:      true,
+	{http.MethodPost, "/api/v1/auth/confirm"}:                true,
+	{http.MethodPost, "/api/v1/auth/send-confirmation-code"}: true,
+	{http.MethodPost, "/api/v1/auth/password/forget"}:        true,
+	{http.MethodPost, "/api/v1/auth/password/reset"}:         true,
+	{http.MethodPost, "/api/v1/auth/password/change"}:        true,
+	{http.MethodPost, "/api/v1/auth/password/set"}:           true,
+	{http.MethodPost, "/api/v1/auth/devices/confirm"}:        true,
+	{http.MethodGet, "/api/v1/auth/devices"}:                 true,
+	{http.MethodPost, "/api/v1/auth/session"}:                true,
+	{http.MethodGet, "/api/v1/auth/session"}:                 true,
+	{http.MethodDelete, "/api/v1/auth/session"}:              true,
+	{http.MethodPost, "/api/v1/auth/mfa"}:                    true,
+	{http.MethodGet, "/api/v1/auth/mfa/setup"}:               true,
+	{http.MethodPost, "/api/v1/auth/mfa/setup"}:              true,
+	{http.MethodPost, "/api/v1/auth/mfa/verify"}:             true,
+	{http.MethodPost, "/api/v1/auth/mfa/remove"}:             true,
+	{http.MethodPost, "/api/v1/auth/mfa/enroll"}:             true,
+
+	{http.MethodPost, "/api/v1/user/register"}:      true,
+	{http.MethodPost, "/api/v1/user/invite/verify"}: true,
+}
+
+var routeParam = regexp.MustCompile(`[:*][^/]+`)
+
+// TestRoutesAreProtected sends one request to each route. A middleware in
+// front of everything records whether the auth middleware is in the route's
+// chain and aborts, so no handler runs.
+func TestRoutesAreProtected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := New(logger.Nop{}, &config.Config{}, &factory.Factory{})
+	s.metrics = metrics.NewRegistry()
+
+	var chain []string
+	s.Gin.Use(func(c *gin.Context) {
+		chain = c.HandlerNames()
+		c.AbortWithStatus(http.StatusNoContent)
+	})
+	if err := s.SetupApi(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range s.Gin.Routes() {
+		if publicRoutes[publicRoute{route.Method, route.Path}] {
+			continue
+		}
+		path := routeParam.ReplaceAllString(route.Path, "probe")
+		s.Gin.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(route.Method, path, nil))
+		if !hasAuthMiddleware(chain) {
+			t.Errorf("%s %s is served without the auth middleware and isn't listed as public", route.Method, route.Path)
+		}
+	}
+}
+
+func hasAuthMiddleware(chain []string) bool {
+	for _, name := range chain {
+		if strings.Contains(name, "(*AuthMiddlewareImpl).AuthMiddleware") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTrailingSlash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		mode         string
+		method       string
+		path         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{mode: TrailingSlashRedirect, method: http.MethodGet, path: "/api/v1/auth/user", wantStatus: http.StatusOK},
+		{mode: TrailingSlashRedirect, method: http.MethodGet, path: "/api/v1/auth/user/", wantStatus: http.StatusMovedPermanently, wantLocation: "/api/v1/auth/user"},
+		{mode: TrailingSlashRedirect, method: http.MethodPost, path: "/api/v1/auth/user/", wantStatus: http.StatusTemporaryRedirect, wantLocation: "/api/v1/auth/user"},
+		{mode: "", method: http.MethodGet, path: "/api/v1/auth/user/", wantStatus: http.StatusMovedPermanently, wantLocation: "/api/v1/auth/user"},
+		{mode: TrailingSlashHandle, method: http.MethodGet, path: "/api/v1/auth/user", wantStatus: http.StatusOK},
+		{mode: TrailingSlashHandle, method: http.MethodGet, path: "/api/v1/auth/user/", wantStatus: http.StatusOK},
+		{mode: TrailingSlashHandle, method: http.MethodPost, path: "/api/v1/auth/user/", wantStatus: http.StatusOK},
+		{mode: TrailingSlashHandle, method: http.MethodGet, path: "/", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Api.TrailingSlash = tt.mode
+			s := New(logger.Nop{}, cfg, &factory.Factory{})
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			s.Gin.GET("/", ok)
+			s.Gin.GET("/api/v1/auth/user", ok)
+			s.Gin.POST("/api/v1/auth/user", ok)
+
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+// infoLines keeps what was logged at info level.
+type infoLines struct {
+	logger.Nop
+	lines []string
+}
+
+func (l *infoLines) Info(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestAccessLogBodiesOffInProduction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		env        string
+		logBodies  bool
+		wantBodies bool
+	}{
+		{env: "development", wantBodies: false},
+		{env: "development", logBodies: true, wantBodies: true},
+		{env: "production", logBodies: true, wantBodies: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s log_bodies=%v", tt.env, tt.logBodies), func(t *testing.T) {
+			cfg := &config.Config{Env: tt.env}
+			cfg.Api.AccessLog = config.AccessLogConfig{Enabled: true, Level: "info", LogBodies: tt.logBodies, MaxBodyBytes: 1024}
+			cfg.Api.MaxAuthHeaderBytes = 8192
+			log := &infoLines{}
+			s := New(log, cfg, &factory.Factory{})
+			if err := s.SetupMiddlewares(); err != nil {
+				t.Fatalf("SetupMiddlewares: %v", err)
+			}
+			s.Gin.POST("/api/v1/auth/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"someone@example.com"}`))
+			req.Header.Set("Content-Type", "application/json")
+			s.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(log.lines) != 1 {
+				t.Fatalf("logged %q, want one access line", log.lines)
+			}
+			line := log.lines[0]
+			if !strings.Contains(line, "path=/api/v1/auth/login") || !strings.Contains(line, "status=200") {
+				t.Errorf("access line %q is missing the path or status", line)
+			}
+			if got := strings.Contains(line, "someone@example.com"); got != tt.wantBodies {
+				t.Errorf("body logged = %v, want %v: %q", got, tt.wantBodies, line)
+			}
+		})
+	}
+}
@@ -5,18 +5,27 @@ import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	admin_usecases "auth-api/src/internal/modules/user-manager/usecases/admin"
 	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/validator"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AdminHandler struct {
-	useCases *admin_usecases.UseCases
+	useCases               *admin_usecases.UseCases
+	exportDelivery         string
+	caseSensitiveUsernames bool
 }
 
-func NewAdminHandler(useCases *admin_usecases.UseCases) *AdminHandler {
+func NewAdminHandler(useCases *admin_usecases.UseCases, exportDelivery string, caseSensitiveUsernames bool) *AdminHandler {
 	return &AdminHandler{
-		useCases: useCases,
+		useCases:               useCases,
+		exportDelivery:         exportDelivery,
+		caseSensitiveUsernames: caseSensitiveUsernames,
 	}
 }
 
@@ -24,16 +33,33 @@ type registerAdminInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
+	// ClientMetadata is passed to the user pool triggers, e.g. a tenant.
+	ClientMetadata map[string]string `json:"clientMetadata"`
 }
 
 func (h *AdminHandler) Register() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
 		processRequestNoOutput(c, registerAdminInput{}, func(ctx context.Context, input registerAdminInput) error {
 			err := h.useCases.Register.Execute(ctx, admin_usecases.RegisterAdminInput{
+				ActorID: adminClaims.Id,
 				SignupAdmin: auth.CreateAdminInput{
-					Username: input.Email,
-					Password: input.Password,
-					Name:     input.Name,
+					Username:       validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+					Password:       input.Password,
+					Name:           input.Name,
+					ClientMetadata: input.ClientMetadata,
 				},
 				CreateAdminInput: admin.CreateAdminInput{
 					Name:  input.Name,
@@ -46,7 +72,8 @@ func (h *AdminHandler) Register() gin.HandlerFunc {
 }
 
 type updateAdminInput struct {
-	Name *string `json:"name"`
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
 }
 
 func (h *AdminHandler) Update() gin.HandlerFunc {
@@ -70,10 +97,252 @@ func (h *AdminHandler) Update() gin.HandlerFunc {
 			if err != nil {
 				return err
 			}
+			if input.Email != nil {
+				email := validator.NormalizeEmail(*input.Email, h.caseSensitiveUsernames)
+				input.Email = &email
+			}
 			err = h.useCases.Update.Execute(ctx, admin_usecases.UpdateAdminInput{
 				UpdateAdminInput: admin.UpdateAdminInput{
-					ID:   adminId,
-					Name: input.Name,
+					ID:    adminId,
+					Name:  input.Name,
+					Email: input.Email,
+				},
+			})
+			return err
+		})
+	}
+}
+
+type resetPasswordsInput struct {
+	Group            *auth.UserGroup `json:"group"`
+	ConfirmationCode string          `json:"confirmationCode"`
+}
+
+func (h *AdminHandler) ResetPasswords() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminId := adminClaims.Id
+
+		var input resetPasswordsInput
+		if err := bindJSON(c, &input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		actorId, err := admin.ParseAdminID(adminId)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		output, err := h.useCases.ResetPasswords.Execute(c.Request.Context(), admin_usecases.ResetPasswordsInput{
+			ActorID:          actorId,
+			Group:            input.Group,
+			ConfirmationCode: input.ConfirmationCode,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if output.ConfirmationRequired {
+			c.JSON(http.StatusOK, output)
+			return
+		}
+
+		c.Header("Location", c.Request.URL.Path+"/"+output.Job.Id)
+		c.JSON(http.StatusAccepted, output.Job)
+	}
+}
+
+func (h *AdminHandler) ResetPasswordsJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := h.useCases.ResetPasswordsJob.Execute(c.Request.Context(), admin_usecases.GetResetPasswordsJobInput{
+			Id: c.Param("id"),
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+type exportUsersInput struct {
+	Format string          `form:"format"`
+	Group  *auth.UserGroup `form:"group"`
+}
+
+// ExportUsers streams the pool as CSV or JSON while paging through it. Once
+// the first row is out the status is sent, so a later failure can only cut
+// the body short. With the "url" delivery it answers with a download link
+// instead, see exportUsersToURL.
+func (h *AdminHandler) ExportUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		var input exportUsersInput
+		if err := bindQuery(c, &input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if h.exportDelivery == ExportDeliveryURL {
+			h.exportUsersToURL(c, adminClaims.Id, input)
+			return
+		}
+
+		writer, contentType, err := newUserExportWriter(input.Format, c.Writer)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		started := false
+		start := func() error {
+			started = true
+			extension := input.Format
+			if extension == "" {
+				extension = ExportFormatCSV
+			}
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=users.%s", extension))
+			c.Status(http.StatusOK)
+			return writer.begin()
+		}
+
+		err = h.useCases.ExportUsers.Execute(c.Request.Context(), admin_usecases.ExportUsersInput{
+			ActorID: adminClaims.Id,
+			Group:   input.Group,
+		}, func(user auth.User) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			return writer.write(user)
+		})
+		if err != nil {
+			if !started {
+				c.Error(err)
+			}
+			return
+		}
+
+		if !started {
+			if err := start(); err != nil {
+				return
+			}
+		}
+		writer.end()
+	}
+}
+
+// exportUsersToURL writes the whole export to a temporary file, uploads it
+// and returns a presigned link to it.
+func (h *AdminHandler) exportUsersToURL(c *gin.Context, actorID string, input exportUsersInput) {
+	if !h.useCases.StoreExport.Enabled() {
+		c.Error(admin_usecases.ErrExportStorageDisabled)
+		return
+	}
+
+	file, err := os.CreateTemp("", "users-export-*")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer, contentType, err := newUserExportWriter(input.Format, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writer.begin(); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.useCases.ExportUsers.Execute(c.Request.Context(), admin_usecases.ExportUsersInput{
+		ActorID: actorID,
+		Group:   input.Group,
+	}, writer.write); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writer.end(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.Error(err)
+		return
+	}
+
+	extension := input.Format
+	if extension == "" {
+		extension = ExportFormatCSV
+	}
+	output, err := h.useCases.StoreExport.Execute(c.Request.Context(), admin_usecases.StoreExportInput{
+		Extension:   extension,
+		ContentType: contentType,
+		Body:        file,
+		Size:        size,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+func (h *AdminHandler) UpdateByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminId := c.Param("id")
+
+		processRequestNoOutput(c, updateAdminInput{}, func(ctx context.Context, input updateAdminInput) error {
+			adminId, err := admin.ParseAdminID(adminId)
+			if err != nil {
+				return err
+			}
+			if input.Email != nil {
+				email := validator.NormalizeEmail(*input.Email, h.caseSensitiveUsernames)
+				input.Email = &email
+			}
+			err = h.useCases.Update.Execute(ctx, admin_usecases.UpdateAdminInput{
+				UpdateAdminInput: admin.UpdateAdminInput{
+					ID:    adminId,
+					Name:  input.Name,
+					Email: input.Email,
 				},
 			})
 			return err
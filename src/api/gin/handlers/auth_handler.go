@@ -1,57 +1,109 @@
 package handlers
 
 import (
+	"auth-api/src/config"
 	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/internal/modules/user-manager/domain/user"
 	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/validator"
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
-	useCases *auth_usecases.UseCases
+	useCases      *auth_usecases.UseCases
+	sessionCookie config.SessionCookieConfig
+	refreshToken  config.RefreshTokenConfig
+	tokens        *tokenDelivery
+	// caseSensitiveUsernames keeps emails as entered instead of folding them.
+	caseSensitiveUsernames bool
 }
 
-func NewAuthHandler(useCases *auth_usecases.UseCases) *AuthHandler {
+func NewAuthHandler(useCases *auth_usecases.UseCases, sessionCookie config.SessionCookieConfig, refreshToken config.RefreshTokenConfig, delivery config.TokenDeliveryConfig, caseSensitiveUsernames bool) *AuthHandler {
 	return &AuthHandler{
-		useCases: useCases,
+		useCases:               useCases,
+		sessionCookie:          sessionCookie,
+		refreshToken:           refreshToken,
+		caseSensitiveUsernames: caseSensitiveUsernames,
+		tokens: &tokenDelivery{
+			cfg:           delivery,
+			cookieName:    refreshToken.CookieName,
+			sessionCookie: sessionCookie,
+		},
 	}
 }
 
 type loginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email          string `json:"email" form:"email"`
+	Password       string `json:"password" form:"password"`
+	ChallengeToken string `json:"challengeToken" form:"challengeToken"`
 }
 
 func (h *AuthHandler) Login() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		processRequest(c, loginInput{}, func(ctx context.Context, input loginInput) (*auth.LoginOutput, error) {
-			return h.useCases.Login.Execute(ctx, auth_usecases.LoginInput{
+		processBodyRequest(c, loginInput{}, func(ctx context.Context, input loginInput) (*auth.LoginOutput, error) {
+			out, err := h.useCases.Login.Execute(ctx, auth_usecases.LoginInput{
 				LoginInput: auth.LoginInput{
-					Username: input.Email,
+					Username: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					Password: input.Password,
 				},
+				IP:             c.ClientIP(),
+				UserAgent:      c.Request.UserAgent(),
+				ChallengeToken: challengeToken(c, input.ChallengeToken),
 			})
+			if err == nil {
+				h.tokens.deliver(c, out)
+			}
+			return out, err
 		})
 	}
 }
 
 type confirmSignUpInput struct {
-	Email string `json:"email"`
-	Code  string `json:"code"`
+	Email       string  `json:"email" form:"email"`
+	Code        string  `json:"code" form:"code"`
+	Password    *string `json:"password" form:"password"`
+	InviteToken string  `json:"inviteToken" form:"inviteToken"`
 }
 
 func (h *AuthHandler) ConfirmSignUp() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		processRequestNoOutput(c, confirmSignUpInput{}, func(ctx context.Context, input confirmSignUpInput) error {
-			_, err := h.useCases.ConfirmSignUp.Execute(ctx, auth_usecases.ConfirmSignUpInput{
-				Username: input.Email,
-				Code:     input.Code,
-			})
-			return err
+		var input confirmSignUpInput
+		if err := bindBody(c, &input); err != nil {
+			if err == ErrUnsupportedMediaType {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, err := h.useCases.ConfirmSignUp.Execute(c.Request.Context(), auth_usecases.ConfirmSignUpInput{
+			Username:    validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+			Code:        input.Code,
+			Password:    input.Password,
+			InviteToken: input.InviteToken,
+			IP:          c.ClientIP(),
+			UserAgent:   c.Request.UserAgent(),
 		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if output.Tokens == nil {
+			c.JSON(http.StatusNoContent, gin.H{})
+			return
+		}
+		h.tokens.deliver(c, output.Tokens)
+		c.JSON(http.StatusOK, output)
 	}
 }
 
@@ -71,22 +123,161 @@ func (h *AuthHandler) GetMe() gin.HandlerFunc {
 	}
 }
 
-type refreshTokenInput struct {
-	RefreshToken string `json:"refreshToken"`
+// GetUserGroups answers from the claims AuthMiddleware already validated, so
+// it always matches the token and never calls Cognito.
+func (h *AuthHandler) GetUserGroups() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		groups := userClaims.UserGroups
+		if groups == nil {
+			groups = []string{}
+		}
+		c.JSON(http.StatusOK, auth.UserGroupsOutput{Groups: groups})
+	}
 }
 
-func (h *AuthHandler) RefreshToken() gin.HandlerFunc {
+type confirmDeviceInput struct {
+	AccessToken    string  `json:"accessToken"`
+	DeviceKey      string  `json:"deviceKey"`
+	DeviceGroupKey string  `json:"deviceGroupKey"`
+	DeviceName     *string `json:"deviceName"`
+	// NewDeviceMetadata lets clients echo back the object from the login
+	// response instead of copying out the keys.
+	NewDeviceMetadata *auth.DeviceMetadata `json:"newDeviceMetadata"`
+}
+
+func (h *AuthHandler) ConfirmDevice() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		processRequest(c, refreshTokenInput{}, func(ctx context.Context, input refreshTokenInput) (*auth.RefreshTokenOutput, error) {
-			return h.useCases.RefreshToken.Execute(ctx, auth_usecases.RefreshTokenInput{
-				RefreshTokenInput: auth.RefreshTokenInput{
-					RefreshToken: input.RefreshToken,
+		processRequest(c, confirmDeviceInput{}, func(ctx context.Context, input confirmDeviceInput) (*auth.ConfirmDeviceOutput, error) {
+			if input.NewDeviceMetadata != nil && input.DeviceKey == "" && input.DeviceGroupKey == "" {
+				input.DeviceKey = input.NewDeviceMetadata.DeviceKey
+				input.DeviceGroupKey = input.NewDeviceMetadata.DeviceGroupKey
+			}
+			return h.useCases.ConfirmDevice.Execute(ctx, auth_usecases.ConfirmDeviceInput{
+				ConfirmDeviceInput: auth.ConfirmDeviceInput{
+					AccessToken:    input.AccessToken,
+					DeviceKey:      input.DeviceKey,
+					DeviceGroupKey: input.DeviceGroupKey,
+					DeviceName:     input.DeviceName,
 				},
 			})
 		})
 	}
 }
 
+type listDevicesInput struct {
+	AccessToken string `form:"accessToken"`
+}
+
+func (h *AuthHandler) ListDevices() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		processRequestQuery(c, listDevicesInput{}, func(ctx context.Context, input listDevicesInput) (*auth.ListDevicesOutput, error) {
+			return h.useCases.ListDevices.Execute(ctx, auth_usecases.ListDevicesInput{
+				ListDevicesInput: auth.ListDevicesInput{
+					AccessToken: input.AccessToken,
+				},
+			})
+		})
+	}
+}
+
+func (h *AuthHandler) RefreshToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		refreshToken, err := resolveRefreshToken(c, h.refreshToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		output, err := h.useCases.RefreshToken.Execute(c.Request.Context(), auth_usecases.RefreshTokenInput{
+			RefreshTokenInput: auth.RefreshTokenInput{
+				RefreshToken: refreshToken,
+			},
+			IncludeClaims: c.Query("includeClaims") == "true",
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		h.tokens.deliverRefreshed(c, output)
+		c.JSON(http.StatusOK, output)
+	}
+}
+
+// RefreshIdToken runs a regular refresh but only hands back the id token, so
+// profile changes show up without replacing the client's access token.
+func (h *AuthHandler) RefreshIdToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		refreshToken, err := resolveRefreshToken(c, h.refreshToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		output, err := h.useCases.RefreshToken.Execute(c.Request.Context(), auth_usecases.RefreshTokenInput{
+			RefreshTokenInput: auth.RefreshTokenInput{
+				RefreshToken: refreshToken,
+			},
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		// Only the expiry in seconds is guaranteed; the timestamp follows it.
+		idTokenExpiresAt := time.Now().Add(time.Duration(output.IdTokenExpiresIn) * time.Second)
+		if output.IdTokenExpiresAt != nil {
+			idTokenExpiresAt = *output.IdTokenExpiresAt
+		}
+		c.JSON(http.StatusOK, auth.IdTokenOutput{
+			IdToken:          output.IdToken,
+			IdTokenExpiresIn: output.IdTokenExpiresIn,
+			IdTokenExpiresAt: idTokenExpiresAt,
+		})
+	}
+}
+
+type refreshTokenBatchInput struct {
+	RefreshTokens []string `json:"refreshTokens"`
+}
+
+// RefreshTokenBatch answers with a 207 whose items follow the order of the
+// tokens sent. Items don't carry an id, so the tokens aren't echoed back.
+func (h *AuthHandler) RefreshTokenBatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input refreshTokenBatchInput
+		if err := bindJSON(c, &input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, err := h.useCases.RefreshTokenBatch.Execute(c.Request.Context(), auth_usecases.RefreshTokenBatchInput{
+			RefreshTokens: input.RefreshTokens,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		response := NewMultiStatusResponse()
+		for _, result := range output.Results {
+			response.AddResult("", result.Tokens, result.Err)
+		}
+		c.JSON(http.StatusMultiStatus, response)
+	}
+}
+
 type addMfaInput struct {
 	AccessToken string `json:"accessToken"`
 }
@@ -103,21 +294,321 @@ func (h *AuthHandler) AddMfa() gin.HandlerFunc {
 	}
 }
 
+type setupMfaInput struct {
+	AccessToken string `json:"accessToken" form:"accessToken"`
+}
+
+func (h *AuthHandler) SetupMfa() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		execute := func(ctx context.Context, input setupMfaInput) (*auth.SetupMFAOutput, error) {
+			return h.useCases.SetupMFA.Execute(ctx, auth_usecases.SetupMFAInput{
+				AddMFAInput: auth.AddMFAInput{
+					AccessToken: input.AccessToken,
+				},
+			})
+		}
+
+		if c.Request.Method == http.MethodGet {
+			processRequestQuery(c, setupMfaInput{}, execute)
+			return
+		}
+		processRequest(c, setupMfaInput{}, execute)
+	}
+}
+
 type verifyMfaInput struct {
-	Email   string `json:"email"`
-	Code    string `json:"code"`
-	Session string `json:"session"`
+	Email         string `json:"email"`
+	Code          string `json:"code"`
+	Session       string `json:"session"`
+	CorrelationId string `json:"correlationId"`
 }
 
 func (h *AuthHandler) VerifyMfa() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		processRequest(c, verifyMfaInput{}, func(ctx context.Context, input verifyMfaInput) (*auth.LoginOutput, error) {
-			return h.useCases.VerifyMFA.Execute(ctx, auth_usecases.VerifyMFAInput{
+			out, err := h.useCases.VerifyMFA.Execute(ctx, auth_usecases.VerifyMFAInput{
 				VerifyMFAInput: auth.VerifyMFAInput{
 					Code:     input.Code,
-					Username: input.Email,
+					Username: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					Session:  input.Session,
 				},
+				CorrelationId: input.CorrelationId,
+			})
+			if err == nil {
+				h.tokens.deliver(c, out)
+			}
+			return out, err
+		})
+	}
+}
+
+type adminResetTotpInput struct {
+	Reason string `json:"reason"`
+}
+
+func (h *AuthHandler) AdminResetTotp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		username := validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames)
+
+		processRequestNoOutput(c, adminResetTotpInput{}, func(ctx context.Context, input adminResetTotpInput) error {
+			return h.useCases.AdminResetTOTP.Execute(ctx, auth_usecases.AdminResetTOTPInput{
+				ActorID: adminClaims.Id,
+				AdminRemoveMFAInput: auth.AdminRemoveMFAInput{
+					Username: username,
+				},
+				Reason: input.Reason,
+			})
+		})
+	}
+}
+
+type adminFinalizeUserInput struct {
+	NewPassword string `json:"newPassword"`
+}
+
+func (h *AuthHandler) AdminFinalizeUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		username := validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames)
+
+		processRequestNoOutput(c, adminFinalizeUserInput{}, func(ctx context.Context, input adminFinalizeUserInput) error {
+			return h.useCases.AdminFinalizeUser.Execute(ctx, auth_usecases.AdminFinalizeUserInput{
+				ActorID: adminClaims.Id,
+				AdminSetPermanentPasswordInput: auth.AdminSetPermanentPasswordInput{
+					Username:    username,
+					NewPassword: input.NewPassword,
+				},
+			})
+		})
+	}
+}
+
+func (h *AuthHandler) AdminDisableUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		err := h.useCases.AdminDisableUser.Execute(c.Request.Context(), auth_usecases.AdminDisableUserInput{
+			ActorID: adminClaims.Id,
+			DisableUserInput: auth.DisableUserInput{
+				Username: validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames),
+			},
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+// AdminGetUser tags the user with its last modification, so a dashboard
+// polling it gets a 304 until the user changes.
+func (h *AuthHandler) AdminGetUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		output, err := h.useCases.AdminGetUser.Execute(c.Request.Context(), auth.GetUserInput{
+			Username: validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames),
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		etag := ""
+		if !output.LastModifiedAt.IsZero() {
+			etag = fmt.Sprintf(`"%s.%d"`, output.Id, output.LastModifiedAt.UnixNano())
+		}
+		respondWithETag(c, etag, output)
+	}
+}
+
+type adminListUsersInput struct {
+	Group  *auth.UserGroup `form:"group"`
+	Limit  int32           `form:"limit"`
+	Cursor string          `form:"cursor"`
+}
+
+// AdminListUsers tags each page with a hash of its content.
+func (h *AuthHandler) AdminListUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input adminListUsersInput
+		if err := bindQuery(c, &input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, err := h.useCases.AdminListUsers.Execute(c.Request.Context(), auth_usecases.AdminListUsersInput{
+			Group:  input.Group,
+			Limit:  input.Limit,
+			Cursor: input.Cursor,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		respondWithETag(c, "", output)
+	}
+}
+
+func (h *AuthHandler) AdminGetLockout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		output, err := h.useCases.AdminGetLockout.Execute(c.Request.Context(), auth.LockoutInput{
+			Username: validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames),
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, output)
+	}
+}
+
+func (h *AuthHandler) AdminUnlockUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		err := h.useCases.AdminUnlockUser.Execute(c.Request.Context(), auth_usecases.AdminUnlockUserInput{
+			ActorID: adminClaims.Id,
+			LockoutInput: auth.LockoutInput{
+				Username: validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames),
+			},
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+type adminIssueInviteInput struct {
+	Email     string         `json:"email"`
+	Role      auth.UserGroup `json:"role"`
+	SendEmail bool           `json:"sendEmail"`
+}
+
+func (h *AuthHandler) AdminIssueInvite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		processRequest(c, adminIssueInviteInput{}, func(ctx context.Context, input adminIssueInviteInput) (*auth_usecases.AdminIssueInviteOutput, error) {
+			return h.useCases.AdminIssueInvite.Execute(ctx, auth_usecases.AdminIssueInviteInput{
+				ActorID: adminClaims.Id,
+				IssueInviteInput: auth.IssueInviteInput{
+					Email: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+					Role:  input.Role,
+				},
+				SendEmail: input.SendEmail,
+			})
+		})
+	}
+}
+
+type moveGroupInput struct {
+	From auth.UserGroup `json:"from"`
+	To   auth.UserGroup `json:"to"`
+}
+
+func (h *AuthHandler) MoveGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		username := validator.NormalizeEmail(c.Param("username"), h.caseSensitiveUsernames)
+
+		processRequestNoOutput(c, moveGroupInput{}, func(ctx context.Context, input moveGroupInput) error {
+			return h.useCases.ChangeUserGroup.Execute(ctx, auth_usecases.ChangeUserGroupInput{
+				ActorID: adminClaims.Id,
+				ChangeUserGroupInput: auth.ChangeUserGroupInput{
+					Username: username,
+					From:     input.From,
+					To:       input.To,
+				},
+			})
+		})
+	}
+}
+
+type statsInput struct {
+	Window string `form:"window"`
+}
+
+func (h *AuthHandler) Stats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		processRequestQuery(c, statsInput{}, func(ctx context.Context, input statsInput) (*auth.StatsOutput, error) {
+			var window time.Duration
+			if input.Window != "" {
+				parsed, err := time.ParseDuration(input.Window)
+				if err != nil {
+					return nil, app_error.NewApiError(http.StatusBadRequest, "Invalid window", fmt.Sprintf("Field: %s", "Window"))
+				}
+				window = parsed
+			}
+			return h.useCases.GetStats.Execute(ctx, auth_usecases.GetStatsInput{
+				Window: window,
 			})
 		})
 	}
@@ -129,10 +620,24 @@ type adminRemoveMfaInput struct {
 
 func (h *AuthHandler) AdminRemoveMfa() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		adminClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
 		processRequestNoOutput(c, adminRemoveMfaInput{}, func(ctx context.Context, input adminRemoveMfaInput) error {
 			return h.useCases.AdminRemoveMFA.Execute(ctx, auth_usecases.AdminRemoveMFAInput{
+				ActorID: adminClaims.Id,
 				AdminRemoveMFAInput: auth.AdminRemoveMFAInput{
-					Username: input.Username,
+					Username: validator.NormalizeEmail(input.Username, h.caseSensitiveUsernames),
 				},
 			})
 		})
@@ -174,6 +679,67 @@ func (h *AuthHandler) ActivateMfa() gin.HandlerFunc {
 	}
 }
 
+type completeMfaEnrollmentInput struct {
+	Session string `json:"session"`
+	Code    string `json:"code"`
+}
+
+func (h *AuthHandler) CompleteMfaEnrollment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		processRequest(c, completeMfaEnrollmentInput{}, func(ctx context.Context, input completeMfaEnrollmentInput) (*auth.LoginOutput, error) {
+			out, err := h.useCases.CompleteMFAEnrollment.Execute(ctx, auth_usecases.CompleteMFAEnrollmentInput{
+				Session: input.Session,
+				Code:    input.Code,
+			})
+			if err == nil {
+				h.tokens.deliver(c, out)
+			}
+			return out, err
+		})
+	}
+}
+
+type validatePasswordInput struct {
+	Password string `json:"password" form:"password"`
+}
+
+// ValidatePassword accepts the password as a query parameter for GET, but
+// POST keeps it out of URLs and access logs and should be preferred.
+func (h *AuthHandler) ValidatePassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		execute := func(ctx context.Context, input validatePasswordInput) (*auth_usecases.ValidatePasswordOutput, error) {
+			return h.useCases.ValidatePassword.Execute(ctx, auth_usecases.ValidatePasswordInput{
+				Password: input.Password,
+			})
+		}
+		if c.Request.Method == http.MethodGet {
+			processRequestQuery(c, validatePasswordInput{}, execute)
+			return
+		}
+		processRequest(c, validatePasswordInput{}, execute)
+	}
+}
+
+type authorizeInput struct {
+	Token  string   `json:"token"`
+	Groups []string `json:"groups"`
+	Scopes []string `json:"scopes"`
+}
+
+// Authorize lets gateways ask whether a token is allowed to do something
+// without encoding the rules themselves; a denial is a 200 with the reason.
+func (h *AuthHandler) Authorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		processRequest(c, authorizeInput{}, func(ctx context.Context, input authorizeInput) (*auth_usecases.AuthorizeOutput, error) {
+			return h.useCases.Authorize.Execute(ctx, auth_usecases.AuthorizeInput{
+				Token:  input.Token,
+				Groups: input.Groups,
+				Scopes: input.Scopes,
+			})
+		})
+	}
+}
+
 type logoutInput struct {
 	AccessToken string `json:"accessToken"`
 }
@@ -186,15 +752,19 @@ func (h *AuthHandler) Logout() gin.HandlerFunc {
 					AccessToken: input.AccessToken,
 				},
 			})
+			if err == nil {
+				h.tokens.clearRefreshCookie(c)
+			}
 			return err
 		})
 	}
 }
 
 type setPasswordInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Session  string `json:"session"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	Session       string `json:"session"`
+	CorrelationId string `json:"correlationId"`
 }
 
 func (h *AuthHandler) SetPassword() gin.HandlerFunc {
@@ -202,11 +772,15 @@ func (h *AuthHandler) SetPassword() gin.HandlerFunc {
 		processRequest(c, setPasswordInput{}, func(ctx context.Context, input setPasswordInput) (*auth.LoginOutput, error) {
 			out, err := h.useCases.SetPassword.Execute(ctx, auth_usecases.SetPasswordInput{
 				SetPasswordInput: auth.SetPasswordInput{
-					Username: input.Email,
+					Username: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					Password: input.Password,
 					Session:  input.Session,
 				},
+				CorrelationId: input.CorrelationId,
 			})
+			if err == nil {
+				h.tokens.deliver(c, out)
+			}
 			return out, err
 		})
 	}
@@ -233,15 +807,15 @@ func (h *AuthHandler) AddGroup() gin.HandlerFunc {
 
 			err := h.useCases.AddGroup.Execute(ctx, auth_usecases.AddGroupInput{
 				AddGroupInput: auth.AddGroupInput{
-					Username:  input.Email,
+					Username:  validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					GroupName: input.Group,
 				},
 				CreateAdminInput: &admin.CreateAdminInput{
-					Email: input.Email,
+					Email: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					Name:  adminName,
 				},
 				CreateUserInput: &user.CreateUserInput{
-					Email: input.Email,
+					Email: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					Name:  userName,
 					Phone: &userPhone,
 				},
@@ -261,7 +835,7 @@ func (h *AuthHandler) RemoveGroup() gin.HandlerFunc {
 		processRequestNoOutput(c, removeGroupInput{}, func(ctx context.Context, input removeGroupInput) error {
 			err := h.useCases.RemoveGroup.Execute(ctx, auth_usecases.RemoveGroupInput{
 				RemoveGroupInput: auth.RemoveGroupInput{
-					Username:  input.Email,
+					Username:  validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 					GroupName: input.Group,
 				},
 			})
@@ -290,18 +864,20 @@ func (h *AuthHandler) ChangePassword() gin.HandlerFunc {
 }
 
 type resetPasswordInput struct {
-	Email       string `json:"email"`
-	Code        string `json:"code"`
-	NewPassword string `json:"newPassword"`
+	Email           string  `json:"email"`
+	Code            string  `json:"code"`
+	NewPassword     string  `json:"newPassword"`
+	ConfirmPassword *string `json:"confirmPassword"`
 }
 
 func (h *AuthHandler) ResetPassword() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		processRequestNoOutput(c, resetPasswordInput{}, func(ctx context.Context, input resetPasswordInput) error {
 			err := h.useCases.ResetPassword.Execute(ctx, auth_usecases.ResetPasswordInput{
-				Username:    input.Email,
-				Code:        input.Code,
-				NewPassword: input.NewPassword,
+				Username:             validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+				Code:                 input.Code,
+				NewPassword:          input.NewPassword,
+				PasswordConfirmation: input.ConfirmPassword,
 			})
 			return err
 		})
@@ -316,7 +892,7 @@ func (h *AuthHandler) SendForgotPasswordCode() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		processRequestNoOutput(c, sendForgotPasswordCodeInput{}, func(ctx context.Context, input sendForgotPasswordCodeInput) error {
 			err := h.useCases.SendForgotPasswordCode.Execute(ctx, auth_usecases.SendForgotPasswordCodeInput{
-				Username: input.Email,
+				Username: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 			})
 			return err
 		})
@@ -329,11 +905,173 @@ type sendConfirmationCodeInput struct {
 
 func (h *AuthHandler) SendConfirmationCode() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		processRequestNoOutput(c, sendConfirmationCodeInput{}, func(ctx context.Context, input sendConfirmationCodeInput) error {
-			err := h.useCases.SendConfirmationCode.Execute(ctx, auth_usecases.SendConfirmationCodeInput{
-				Username: input.Email,
+		processRequest(c, sendConfirmationCodeInput{}, func(ctx context.Context, input sendConfirmationCodeInput) (*auth_usecases.SendConfirmationCodeOutput, error) {
+			return h.useCases.SendConfirmationCode.Execute(ctx, auth_usecases.SendConfirmationCodeInput{
+				Username: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 			})
-			return err
 		})
 	}
 }
+
+type createSessionInput struct {
+	Email          string `json:"email"`
+	Password       string `json:"password"`
+	Session        string `json:"session"`
+	Code           string `json:"code"`
+	ChallengeToken string `json:"challengeToken"`
+}
+
+func (h *AuthHandler) CreateSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input createSessionInput
+		if err := bindJSON(c, &input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, err := h.useCases.CreateSession.Execute(c.Request.Context(), auth_usecases.CreateSessionInput{
+			Username:         validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+			Password:         input.Password,
+			ChallengeSession: input.Session,
+			Code:             input.Code,
+			IP:               c.ClientIP(),
+			UserAgent:        c.Request.UserAgent(),
+			ChallengeToken:   challengeToken(c, input.ChallengeToken),
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		if output.Token == "" {
+			c.JSON(http.StatusOK, output)
+			return
+		}
+
+		h.setSessionCookie(c, output.Token, int(time.Until(*output.ExpiresAt).Seconds()))
+		c.JSON(http.StatusOK, output)
+	}
+}
+
+func (h *AuthHandler) GetSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(h.sessionCookie.Name)
+
+		output, err := h.useCases.GetSession.Execute(c.Request.Context(), auth_usecases.GetSessionInput{
+			Token: token,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, output)
+	}
+}
+
+func (h *AuthHandler) DeleteSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(h.sessionCookie.Name)
+
+		// The cookie is cleared whatever happens server side.
+		h.setSessionCookie(c, "", -1)
+
+		if err := h.useCases.DeleteSession.Execute(c.Request.Context(), auth_usecases.DeleteSessionInput{
+			Token: token,
+		}); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+func (h *AuthHandler) setSessionCookie(c *gin.Context, value string, maxAge int) {
+	sameSite := http.SameSiteLaxMode
+	if strings.EqualFold(h.sessionCookie.SameSite, "strict") {
+		sameSite = http.SameSiteStrictMode
+	}
+	c.SetSameSite(sameSite)
+	c.SetCookie(h.sessionCookie.Name, value, maxAge, "/", h.sessionCookie.Domain, h.sessionCookie.Secure, true)
+}
+
+type requestEmailChangeInput struct {
+	Email string `json:"email"`
+}
+
+func (h *AuthHandler) RequestEmailChange() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		processRequest(c, requestEmailChangeInput{}, func(ctx context.Context, input requestEmailChangeInput) (*auth_usecases.RequestEmailChangeOutput, error) {
+			return h.useCases.RequestEmailChange.Execute(ctx, auth.RequestEmailChangeInput{
+				UserId:       userClaims.Id,
+				CurrentEmail: userClaims.Email,
+				NewEmail:     validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+			})
+		})
+	}
+}
+
+type verifyEmailChangeInput struct {
+	Code string `json:"code"`
+}
+
+func (h *AuthHandler) VerifyEmailChange() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		processRequest(c, verifyEmailChangeInput{}, func(ctx context.Context, input verifyEmailChangeInput) (*auth.EmailChange, error) {
+			return h.useCases.VerifyEmailChange.Execute(ctx, auth.VerifyEmailChangeInput{
+				UserId: userClaims.Id,
+				Code:   input.Code,
+			})
+		})
+	}
+}
+
+// GetEmailChange lets the UI show that a new email still awaits verification.
+func (h *AuthHandler) GetEmailChange() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+		userClaims, ok := claims.(*auth.Claims)
+		if !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		output, err := h.useCases.GetEmailChange.Execute(c.Request.Context(), userClaims.Id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, output)
+	}
+}
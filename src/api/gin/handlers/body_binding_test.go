@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"auth-api/src/api/gin/middleware"
+	"auth-api/src/config"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginAcceptsJSONAndForms(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+		Auth:       &cookieAuth{},
+		Dispatcher: nopDispatcher{},
+		Logger:     logger.Nop{},
+		Challenge:  passChallenge{},
+	}, auth_usecases.Options{})
+	handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler(logger.Nop{}, ""))
+	engine.POST("/auth/login", handler.Login())
+
+	login := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	jsonLogin := login("application/json", `{"email":"member@example.com","password":"Password1!"}`)
+	if jsonLogin.Code != http.StatusOK {
+		t.Fatalf("JSON login status = %d, want %d: %s", jsonLogin.Code, http.StatusOK, jsonLogin.Body)
+	}
+
+	formLogin := login("application/x-www-form-urlencoded", "email=member%40example.com&password=Password1%21")
+	if formLogin.Code != http.StatusOK {
+		t.Fatalf("form login status = %d, want %d: %s", formLogin.Code, http.StatusOK, formLogin.Body)
+	}
+	if formLogin.Body.String() != jsonLogin.Body.String() {
+		t.Errorf("form login body = %s, want the JSON login's %s", formLogin.Body, jsonLogin.Body)
+	}
+
+	unsupported := login("text/plain", "member@example.com Password1!")
+	if unsupported.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain login status = %d, want %d", unsupported.Code, http.StatusUnsupportedMediaType)
+	}
+	if !strings.Contains(unsupported.Body.String(), `"Unsupported media type"`) {
+		t.Errorf("text/plain login body = %s, want it to say the media type is unsupported", unsupported.Body)
+	}
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+const challengeTokenHeader = "X-Challenge-Token"
+
+// challengeToken takes the bot challenge token from the body field, falling
+// back to the header for clients that can't change the body shape.
+func challengeToken(c *gin.Context, bodyToken string) string {
+	if bodyToken != "" {
+		return bodyToken
+	}
+	return c.GetHeader(challengeTokenHeader)
+}
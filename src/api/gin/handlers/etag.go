@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyETag is a strong ETag over the exact bytes sent, so any change a client
+// could see changes the tag.
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for: a W/
+// prefix on either side is ignored, and "*" matches anything.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithETag sends output as JSON tagged with etag, or with a hash of the
+// body when etag is empty. A request whose If-None-Match already holds the tag
+// gets an empty 304 instead. no-cache makes caches revalidate every time
+// rather than serve a copy that may be stale.
+func respondWithETag(c *gin.Context, etag string, output interface{}) {
+	body, err := json.Marshal(output)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if etag == "" {
+		etag = bodyETag(body)
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
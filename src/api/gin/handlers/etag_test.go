@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/cursor"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// polledAuth serves one user, which the test modifies between polls.
+type polledAuth struct {
+	auth.AuthService
+	user auth.User
+}
+
+func (a *polledAuth) GetUser(ctx context.Context, input auth.GetUserInput) (*auth.User, error) {
+	user := a.user
+	return &user, nil
+}
+
+func (a *polledAuth) ListUsers(ctx context.Context, input auth.ListUsersInput) (*auth.ListUsersOutput, error) {
+	return &auth.ListUsersOutput{Users: []auth.User{a.user}}, nil
+}
+
+func TestAdminUserReadsHonorIfNoneMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, target := range []string{"/auth/admin/users/member@example.com", "/auth/admin/users"} {
+		t.Run(target, func(t *testing.T) {
+			authService := &polledAuth{user: auth.User{
+				Id:             "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e",
+				Email:          "member@example.com",
+				Name:           "Member",
+				Status:         auth.Confirmed,
+				LastModifiedAt: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC),
+			}}
+			useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+				Auth:       authService,
+				Dispatcher: nopDispatcher{},
+				Logger:     logger.Nop{},
+				Challenge:  passChallenge{},
+				Cursors:    cursor.NewSigner([]byte("test-key")),
+			}, auth_usecases.Options{})
+			handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+			engine := gin.New()
+			engine.GET("/auth/admin/users/:username", handler.AdminGetUser())
+			engine.GET("/auth/admin/users", handler.AdminListUsers())
+
+			poll := func(ifNoneMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				if ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", ifNoneMatch)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+				return w
+			}
+
+			first := poll("")
+			etag := first.Header().Get("ETag")
+			if first.Code != http.StatusOK || etag == "" {
+				t.Fatalf("status = %d ETag = %q, want 200 with a tag: %s", first.Code, etag, first.Body)
+			}
+			if got := first.Header().Get("Cache-Control"); got != "private, no-cache" {
+				t.Errorf("Cache-Control = %q, want private, no-cache", got)
+			}
+
+			for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+				unchanged := poll(ifNoneMatch)
+				if unchanged.Code != http.StatusNotModified || unchanged.Body.Len() != 0 {
+					t.Errorf("If-None-Match %s: status = %d body = %q, want an empty 304", ifNoneMatch, unchanged.Code, unchanged.Body)
+				}
+				if got := unchanged.Header().Get("ETag"); got != etag {
+					t.Errorf("If-None-Match %s: ETag = %q on the 304, want %q", ifNoneMatch, got, etag)
+				}
+			}
+
+			authService.user.Name = "Renamed Member"
+			authService.user.LastModifiedAt = authService.user.LastModifiedAt.Add(time.Minute)
+			modified := poll(etag)
+			if modified.Code != http.StatusOK {
+				t.Fatalf("status = %d after a change, want 200", modified.Code)
+			}
+			if newETag := modified.Header().Get("ETag"); newETag == "" || newETag == etag {
+				t.Errorf("ETag = %q after a change, want a new one", newETag)
+			}
+		})
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+var ErrUnsupportedMediaType = app_error.NewApiError(http.StatusUnsupportedMediaType, "Unsupported media type", "Send the body as application/json or application/x-www-form-urlencoded")
+
 func bindJSON(c *gin.Context, obj interface{}) error {
 	if err := c.ShouldBindJSON(obj); err != nil {
 		if err == io.EOF {
@@ -19,6 +22,36 @@ func bindJSON(c *gin.Context, obj interface{}) error {
 	return nil
 }
 
+// bindBody is bindJSON for the endpoints legacy clients post forms to. The
+// content type picks the binding, so the DTO needs form tags matching its
+// json ones.
+func bindBody(c *gin.Context, obj interface{}) error {
+	if err := shouldBindBody(c, obj); err != nil {
+		if err == io.EOF {
+			return app_error.NewApiError(400, "Invalid request")
+		}
+		return err
+	}
+	return nil
+}
+
+// shouldBindBody returns the binding errors as they are, io.EOF included for
+// an empty JSON body. An empty body is read as JSON whatever its content
+// type says.
+func shouldBindBody(c *gin.Context, obj interface{}) error {
+	if c.Request.ContentLength == 0 {
+		return c.ShouldBindJSON(obj)
+	}
+	switch c.ContentType() {
+	case "", binding.MIMEJSON:
+		return c.ShouldBindJSON(obj)
+	case binding.MIMEPOSTForm:
+		return c.ShouldBindWith(obj, binding.Form)
+	default:
+		return ErrUnsupportedMediaType
+	}
+}
+
 func bindQuery(c *gin.Context, obj interface{}) error {
 	if err := c.ShouldBindQuery(obj); err != nil {
 		return app_error.NewApiError(400, "Invalid request")
@@ -40,6 +73,26 @@ func processRequest[T any, U any](c *gin.Context, input T, executeFunc func(cont
 	c.JSON(http.StatusOK, output)
 }
 
+// processBodyRequest is processRequest through bindBody. An unsupported
+// content type goes to the error handler so it keeps its 415.
+func processBodyRequest[T any, U any](c *gin.Context, input T, executeFunc func(context.Context, T) (U, error)) {
+	if err := bindBody(c, &input); err != nil {
+		if err == ErrUnsupportedMediaType {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	output, err := executeFunc(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
 func processRequestNoOutput[T any](c *gin.Context, input T, executeFunc func(context.Context, T) error) {
 	if err := bindJSON(c, &input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
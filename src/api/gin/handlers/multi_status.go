@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"auth-api/src/pkg/app_error"
+	"errors"
+	"net/http"
+)
+
+// MultiStatusItem is the outcome of a single item in a bulk operation.
+type MultiStatusItem struct {
+	Index  int    `json:"index"`
+	Id     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Data   any    `json:"data,omitempty"`
+}
+
+type MultiStatusSummary struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// MultiStatusResponse is the body returned with a 207 by every bulk endpoint.
+type MultiStatusResponse struct {
+	Items   []MultiStatusItem  `json:"items"`
+	Summary MultiStatusSummary `json:"summary"`
+}
+
+func NewMultiStatusResponse() *MultiStatusResponse {
+	return &MultiStatusResponse{
+		Items: []MultiStatusItem{},
+	}
+}
+
+// Add records the outcome of the next item. A nil err counts as a success.
+func (r *MultiStatusResponse) Add(id string, err error) {
+	r.AddResult(id, nil, err)
+}
+
+// AddResult is Add for bulk operations that hand something back per item.
+// data is only kept when the item succeeded.
+func (r *MultiStatusResponse) AddResult(id string, data any, err error) {
+	item := MultiStatusItem{
+		Index:  len(r.Items),
+		Id:     id,
+		Status: http.StatusOK,
+	}
+
+	if err != nil {
+		var apiErr *app_error.ApiError
+		if errors.As(err, &apiErr) {
+			item.Status = apiErr.StatusCode
+			item.Code = apiErr.Code()
+			item.Error = apiErr.Message
+		} else {
+			item.Status = http.StatusInternalServerError
+			item.Code = "INTERNAL_ERROR"
+			item.Error = err.Error()
+		}
+		r.Summary.Failed++
+	} else {
+		item.Data = data
+		r.Summary.Succeeded++
+	}
+
+	r.Items = append(r.Items, item)
+}
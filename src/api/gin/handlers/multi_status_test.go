@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMultiStatusResponseSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want MultiStatusSummary
+	}{
+		{name: "empty", want: MultiStatusSummary{}},
+		{name: "all succeed", errs: []error{nil, nil, nil}, want: MultiStatusSummary{Succeeded: 3}},
+		{name: "all fail", errs: []error{auth.ErrUserNotFound, errors.New("boom")}, want: MultiStatusSummary{Failed: 2}},
+		{name: "mixed", errs: []error{nil, auth.ErrUserNotFound, nil, errors.New("boom"), nil}, want: MultiStatusSummary{Succeeded: 3, Failed: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := NewMultiStatusResponse()
+			for _, err := range tt.errs {
+				response.AddResult("", "data", err)
+			}
+
+			if response.Summary != tt.want {
+				t.Errorf("summary = %+v, want %+v", response.Summary, tt.want)
+			}
+			if got := response.Summary.Succeeded + response.Summary.Failed; got != len(response.Items) {
+				t.Errorf("summary counts %d items, response has %d", got, len(response.Items))
+			}
+			for i, item := range response.Items {
+				failed := item.Status != http.StatusOK
+				if failed != (tt.errs[i] != nil) || failed != (item.Data == nil) {
+					t.Errorf("item %d = %+v for error %v", i, item, tt.errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMultiStatusResponseItemStatus(t *testing.T) {
+	response := NewMultiStatusResponse()
+	response.Add("found", nil)
+	response.Add("missing", auth.ErrUserNotFound)
+	response.Add("broken", errors.New("boom"))
+
+	want := []MultiStatusItem{
+		{Index: 0, Id: "found", Status: http.StatusOK},
+		{Index: 1, Id: "missing", Status: http.StatusNotFound, Code: "USER_NOT_FOUND", Error: "User not found"},
+		{Index: 2, Id: "broken", Status: http.StatusInternalServerError, Code: "INTERNAL_ERROR", Error: "boom"},
+	}
+	if len(response.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d of them", response.Items, len(want))
+	}
+	for i, item := range response.Items {
+		if item != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, item, want[i])
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// revokedAuth refreshes every token except the revoked ones, which Cognito
+// turns down.
+type revokedAuth struct {
+	auth.AuthService
+	revoked map[string]bool
+}
+
+func (a *revokedAuth) RefreshToken(ctx context.Context, input auth.RefreshTokenInput) (*auth.RefreshTokenOutput, error) {
+	if a.revoked[input.RefreshToken] {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	return &auth.RefreshTokenOutput{AccessToken: "access-" + input.RefreshToken}, nil
+}
+
+func TestRefreshTokenBatchMixesValidAndRevokedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authService := &revokedAuth{revoked: map[string]bool{"revoked-1": true, "revoked-2": true}}
+	useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+		Auth:       authService,
+		Dispatcher: nopDispatcher{},
+		Logger:     logger.Nop{},
+		Challenge:  passChallenge{},
+	}, auth_usecases.Options{})
+	handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+	engine := gin.New()
+	engine.POST("/auth/refresh/batch", handler.RefreshTokenBatch())
+
+	body := strings.NewReader(`{"refreshTokens":["first","revoked-1","second","revoked-2",""]}`)
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/batch", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusMultiStatus, w.Body)
+	}
+	for _, token := range []string{`"first"`, `"revoked-1"`} {
+		if strings.Contains(w.Body.String(), token) {
+			t.Errorf("body = %s, echoes the token %s", w.Body, token)
+		}
+	}
+
+	var response struct {
+		Items []struct {
+			Index  int    `json:"index"`
+			Status int    `json:"status"`
+			Code   string `json:"code"`
+			Data   *struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"data"`
+		} `json:"items"`
+		Summary MultiStatusSummary `json:"summary"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("body %s: %v", w.Body, err)
+	}
+
+	want := []struct {
+		status      int
+		code        string
+		accessToken string
+	}{
+		{status: http.StatusOK, accessToken: "access-first"},
+		{status: http.StatusUnauthorized, code: "INVALID_REFRESH_TOKEN"},
+		{status: http.StatusOK, accessToken: "access-second"},
+		{status: http.StatusUnauthorized, code: "INVALID_REFRESH_TOKEN"},
+		{status: http.StatusBadRequest, code: "REFRESH_TOKEN_IS_REQUIRED"},
+	}
+	if len(response.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d of them", response.Items, len(want))
+	}
+	for i, item := range response.Items {
+		if item.Index != i || item.Status != want[i].status || item.Code != want[i].code {
+			t.Errorf("item %d = index %d status %d code %q, want index %d status %d code %q", i, item.Index, item.Status, item.Code, i, want[i].status, want[i].code)
+		}
+		gotAccessToken := ""
+		if item.Data != nil {
+			gotAccessToken = item.Data.AccessToken
+		}
+		if gotAccessToken != want[i].accessToken {
+			t.Errorf("item %d access token = %q, want %q", i, gotAccessToken, want[i].accessToken)
+		}
+	}
+	if response.Summary != (MultiStatusSummary{Succeeded: 2, Failed: 3}) {
+		t.Errorf("summary = %+v, want 2 succeeded and 3 failed", response.Summary)
+	}
+}
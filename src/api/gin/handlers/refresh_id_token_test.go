@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// profileAuth issues id tokens that carry the user's current name, the way
+// Cognito puts the latest attributes in every refreshed id token.
+type profileAuth struct {
+	auth.AuthService
+	name      string
+	expiresAt time.Time
+}
+
+// A zero expiresAt leaves the timestamps out, as only the seconds are
+// guaranteed.
+func (a *profileAuth) RefreshToken(ctx context.Context, input auth.RefreshTokenInput) (*auth.RefreshTokenOutput, error) {
+	out := &auth.RefreshTokenOutput{
+		AccessToken:          "access-token",
+		AccessTokenExpiresIn: 3600,
+		IdToken:              "id-token:" + a.name,
+		IdTokenExpiresIn:     3600,
+	}
+	if !a.expiresAt.IsZero() {
+		out.AccessTokenExpiresAt = &a.expiresAt
+		out.IdTokenExpiresAt = &a.expiresAt
+	}
+	return out, nil
+}
+
+func TestRefreshIdTokenReflectsUpdatedAttributes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authService := &profileAuth{name: "Ada", expiresAt: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)}
+	useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+		Auth:       authService,
+		Dispatcher: nopDispatcher{},
+		Logger:     logger.Nop{},
+		Challenge:  passChallenge{},
+	}, auth_usecases.Options{})
+	handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{Sources: []string{RefreshTokenSourceBody}}, config.TokenDeliveryConfig{}, false)
+	engine := gin.New()
+	engine.POST("/auth/refresh/id-token", handler.RefreshIdToken())
+
+	refresh := func() (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh/id-token", strings.NewReader(`{"refreshToken":"refresh-token"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("body %s: %v", w.Body, err)
+		}
+		return w, body
+	}
+
+	if w, body := refresh(); w.Code != http.StatusOK || string(body["idToken"]) != `"id-token:Ada"` {
+		t.Fatalf("status = %d body = %s, want the id token for Ada", w.Code, w.Body)
+	}
+
+	authService.name = "Ada Lovelace"
+	w, body := refresh()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if got := string(body["idToken"]); got != `"id-token:Ada Lovelace"` {
+		t.Errorf("idToken = %s, want the one with the updated name", got)
+	}
+	if got := string(body["idTokenExpiresIn"]); got != "3600" {
+		t.Errorf("idTokenExpiresIn = %s, want 3600", got)
+	}
+	if got := string(body["idTokenExpiresAt"]); got != `"2030-01-01T12:00:00Z"` {
+		t.Errorf("idTokenExpiresAt = %s, want 2030-01-01T12:00:00Z", got)
+	}
+	for _, field := range []string{"accessToken", "accessTokenExpiresIn", "accessTokenExpiresAt", "refreshToken"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("body = %s, want no %s", w.Body, field)
+		}
+	}
+}
+
+func TestRefreshIdTokenWithoutExpiryTimestamp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+		Auth:       &profileAuth{name: "Ada"},
+		Dispatcher: nopDispatcher{},
+		Logger:     logger.Nop{},
+		Challenge:  passChallenge{},
+	}, auth_usecases.Options{})
+	handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{Sources: []string{RefreshTokenSourceBody}}, config.TokenDeliveryConfig{}, false)
+	engine := gin.New()
+	engine.POST("/auth/refresh/id-token", handler.RefreshIdToken())
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh/id-token", strings.NewReader(`{"refreshToken":"refresh-token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	before := time.Now()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var body auth.IdTokenOutput
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %s: %v", w.Body, err)
+	}
+	if want := before.Add(time.Hour); body.IdTokenExpiresAt.Before(want.Add(-time.Second)) || body.IdTokenExpiresAt.After(want.Add(time.Second)) {
+		t.Errorf("idTokenExpiresAt = %s, want about %s from idTokenExpiresIn", body.IdTokenExpiresAt, want)
+	}
+}
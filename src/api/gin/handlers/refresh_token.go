@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RefreshTokenSourceBody   = "body"
+	RefreshTokenSourceCookie = "cookie"
+)
+
+type refreshTokenInput struct {
+	RefreshToken string `json:"refreshToken" form:"refreshToken"`
+}
+
+// resolveRefreshToken returns the first refresh token found in the configured
+// sources. A body is optional so cookie clients can send an empty request.
+func resolveRefreshToken(c *gin.Context, cfg config.RefreshTokenConfig) (string, error) {
+	for _, source := range cfg.Sources {
+		switch strings.ToLower(source) {
+		case RefreshTokenSourceBody:
+			var input refreshTokenInput
+			if err := shouldBindBody(c, &input); err != nil && err != io.EOF {
+				if err == ErrUnsupportedMediaType {
+					return "", err
+				}
+				return "", app_error.NewApiError(400, "Invalid request")
+			}
+			if input.RefreshToken != "" {
+				return input.RefreshToken, nil
+			}
+		case RefreshTokenSourceCookie:
+			if token, err := c.Cookie(cfg.CookieName); err == nil && token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", auth.ErrMissingRefreshToken
+}
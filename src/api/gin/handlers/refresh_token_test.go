@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResolveRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	both := []string{RefreshTokenSourceBody, RefreshTokenSourceCookie}
+
+	tests := []struct {
+		name      string
+		sources   []string
+		body      string
+		cookie    string
+		wantToken string
+		wantErr   error
+	}{
+		{name: "body only", sources: both, body: `{"refreshToken":"from-body"}`, wantToken: "from-body"},
+		{name: "cookie only", sources: both, cookie: "from-cookie", wantToken: "from-cookie"},
+		{name: "cookie with an empty body", sources: both, body: `{}`, cookie: "from-cookie", wantToken: "from-cookie"},
+		{name: "neither", sources: both, wantErr: auth.ErrMissingRefreshToken},
+		{name: "first configured source wins", sources: []string{RefreshTokenSourceCookie, RefreshTokenSourceBody}, body: `{"refreshToken":"from-body"}`, cookie: "from-cookie", wantToken: "from-cookie"},
+		{name: "cookie ignored when only the body is configured", sources: []string{RefreshTokenSourceBody}, cookie: "from-cookie", wantErr: auth.ErrMissingRefreshToken},
+		{name: "body ignored when only the cookie is configured", sources: []string{RefreshTokenSourceCookie}, body: `{"refreshToken":"from-body"}`, wantErr: auth.ErrMissingRefreshToken},
+		{name: "source names are case insensitive", sources: []string{"Cookie"}, cookie: "from-cookie", wantToken: "from-cookie"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.RefreshTokenConfig{Sources: tt.sources, CookieName: "refresh_token"}
+			var token string
+			var err error
+			engine := gin.New()
+			engine.POST("/auth/refresh", func(c *gin.Context) {
+				token, err = resolveRefreshToken(c, cfg)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "refresh_token", Value: tt.cookie})
+			}
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+		})
+	}
+
+	if code := auth.ErrMissingRefreshToken.Code(); code != "MISSING_REFRESH_TOKEN" || auth.ErrMissingRefreshToken.StatusCode != http.StatusBadRequest {
+		t.Errorf("ErrMissingRefreshToken = %d %s, want 400 MISSING_REFRESH_TOKEN", auth.ErrMissingRefreshToken.StatusCode, code)
+	}
+}
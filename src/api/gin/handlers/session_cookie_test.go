@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type nopDispatcher struct{}
+
+func (nopDispatcher) Register(events.EventType, events.EventHandler) {}
+func (nopDispatcher) Dispatch(events.Event) error                    { return nil }
+
+type passChallenge struct{}
+
+func (passChallenge) Verify(context.Context, auth.PreAuthChallengeInput) error { return nil }
+
+// cookieAuth signs everyone in and records the refresh tokens revoked.
+type cookieAuth struct {
+	auth.AuthService
+	revoked []string
+}
+
+func (a *cookieAuth) Login(context.Context, auth.LoginInput) (*auth.LoginOutput, error) {
+	accessToken, idToken, refreshToken := "access", "id", "refresh"
+	return &auth.LoginOutput{AccessToken: &accessToken, IdToken: &idToken, RefreshToken: &refreshToken}, nil
+}
+
+func (a *cookieAuth) GetMe(context.Context, auth.GetMeInput) (*auth.GetMeOutput, error) {
+	return &auth.GetMeOutput{Username: "member@example.com"}, nil
+}
+
+func (a *cookieAuth) RevokeToken(ctx context.Context, input auth.RevokeTokenInput) error {
+	a.revoked = append(a.revoked, input.RefreshToken)
+	return nil
+}
+
+// cookieSessions keys sessions by their cookie token.
+type cookieSessions struct {
+	session.SessionService
+	byToken map[string]*session.Session
+}
+
+func (s *cookieSessions) CreateWithinLimit(ctx context.Context, input session.CreateWithinLimitInput) (*session.CreateWithinLimitOutput, error) {
+	sess := &session.Session{
+		Username:             input.Username,
+		AccessToken:          input.AccessToken,
+		RefreshToken:         input.RefreshToken,
+		AccessTokenExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}
+	s.byToken["session-token"] = sess
+	return &session.CreateWithinLimitOutput{Token: "session-token", Session: sess}, nil
+}
+
+func (s *cookieSessions) Get(ctx context.Context, input session.GetInput) (*session.Session, error) {
+	sess, ok := s.byToken[input.Token]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func (s *cookieSessions) Delete(ctx context.Context, input session.DeleteInput) error {
+	delete(s.byToken, input.Token)
+	return nil
+}
+
+func TestSessionCookieLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		cookie       config.SessionCookieConfig
+		wantSecure   bool
+		wantSameSite http.SameSite
+	}{
+		{
+			name:         "secure and lax",
+			cookie:       config.SessionCookieConfig{Name: "sid", Secure: true, SameSite: "lax"},
+			wantSecure:   true,
+			wantSameSite: http.SameSiteLaxMode,
+		},
+		{
+			name:         "strict",
+			cookie:       config.SessionCookieConfig{Name: "sid", SameSite: "strict"},
+			wantSameSite: http.SameSiteStrictMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &cookieAuth{}
+			sessions := &cookieSessions{byToken: map[string]*session.Session{}}
+			useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+				Auth:       authService,
+				Dispatcher: nopDispatcher{},
+				Sessions:   sessions,
+				Logger:     logger.Nop{},
+				Challenge:  passChallenge{},
+			}, auth_usecases.Options{})
+			handler := NewAuthHandler(useCases, tt.cookie, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+
+			engine := gin.New()
+			engine.POST("/auth/session", handler.CreateSession())
+			engine.GET("/auth/session", handler.GetSession())
+			engine.DELETE("/auth/session", handler.DeleteSession())
+
+			// Sign in.
+			w := httptest.NewRecorder()
+			body := strings.NewReader(`{"email":"member@example.com","password":"Password1!"}`)
+			req := httptest.NewRequest(http.MethodPost, "/auth/session", body)
+			req.Header.Set("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("POST status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			cookie := sessionCookie(t, w, tt.cookie.Name)
+			if cookie.Value != "session-token" {
+				t.Errorf("cookie value = %q, want session-token", cookie.Value)
+			}
+			if !cookie.HttpOnly {
+				t.Error("cookie is not HttpOnly")
+			}
+			if cookie.Secure != tt.wantSecure {
+				t.Errorf("cookie Secure = %v, want %v", cookie.Secure, tt.wantSecure)
+			}
+			if cookie.SameSite != tt.wantSameSite {
+				t.Errorf("cookie SameSite = %v, want %v", cookie.SameSite, tt.wantSameSite)
+			}
+			if cookie.MaxAge <= 0 {
+				t.Errorf("cookie MaxAge = %d, want it to outlive the request", cookie.MaxAge)
+			}
+
+			// Read the session back.
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/auth/session", nil)
+			req.AddCookie(&http.Cookie{Name: tt.cookie.Name, Value: cookie.Value})
+			engine.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "member@example.com") {
+				t.Fatalf("GET = %d %s, want 200 with the user", w.Code, w.Body)
+			}
+
+			// Sign out.
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodDelete, "/auth/session", nil)
+			req.AddCookie(&http.Cookie{Name: tt.cookie.Name, Value: cookie.Value})
+			engine.ServeHTTP(w, req)
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("DELETE status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			cleared := sessionCookie(t, w, tt.cookie.Name)
+			if cleared.Value != "" || cleared.MaxAge >= 0 {
+				t.Errorf("cookie after DELETE = %q max-age %d, want it cleared", cleared.Value, cleared.MaxAge)
+			}
+			if !cleared.HttpOnly || cleared.Secure != tt.wantSecure {
+				t.Errorf("cleared cookie HttpOnly = %v Secure = %v, want the same flags as when set", cleared.HttpOnly, cleared.Secure)
+			}
+			if len(authService.revoked) != 1 || authService.revoked[0] != "refresh" {
+				t.Errorf("revoked = %v, want [refresh]", authService.revoked)
+			}
+			if len(sessions.byToken) != 0 {
+				t.Errorf("%d sessions left, want 0", len(sessions.byToken))
+			}
+		})
+	}
+}
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	t.Fatalf("no %s cookie in %v", name, w.Header().Values("Set-Cookie"))
+	return nil
+}
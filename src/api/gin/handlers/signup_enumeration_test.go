@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"auth-api/src/api/gin/middleware"
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	user_usecases "auth-api/src/internal/modules/user-manager/usecases/user"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// takenAuth is signupAuth for a pool where signing up fails with err.
+type takenAuth struct {
+	signupAuth
+	err error
+}
+
+func (a *takenAuth) SignUp(ctx context.Context, input auth.SignUpInput) (*auth.SignUpOutput, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.signupAuth.SignUp(ctx, input)
+}
+
+// registeredUsers is signupUsers with a row for every email.
+type registeredUsers struct {
+	signupUsers
+}
+
+func (registeredUsers) GetByEmail(input *user.GetUserByEmailInput) (*user.User, error) {
+	return &user.User{Email: input.Email}, nil
+}
+
+type countingDispatcher struct {
+	nopDispatcher
+	dispatched int
+}
+
+func (d *countingDispatcher) Dispatch(events.Event) error {
+	d.dispatched++
+	return nil
+}
+
+func TestRegisterHidesExistingAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const minDuration = 20 * time.Millisecond
+
+	register := func(users user.UserService, authErr error) (*httptest.ResponseRecorder, time.Duration, int) {
+		dispatcher := &countingDispatcher{}
+		useCases := user_usecases.NewUseCases(user_usecases.Dependencies{
+			User:       users,
+			Auth:       &takenAuth{err: authErr},
+			Logger:     logger.Nop{},
+			Dispatcher: dispatcher,
+			Challenge:  passChallenge{},
+		}, user_usecases.Options{
+			AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail},
+			Enumeration:    user.SignupEnumerationProtection{Enabled: true, MinDuration: minDuration},
+		})
+		engine := gin.New()
+		engine.Use(middleware.ErrorHandler(logger.Nop{}, ""))
+		engine.POST("/user/register", NewUserHandler(useCases, false).Register())
+
+		body := strings.NewReader(`{"email":"member@example.com","password":"Str0ng!Passw0rd","name":"Member"}`)
+		req := httptest.NewRequest(http.MethodPost, "/user/register", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		started := time.Now()
+		engine.ServeHTTP(w, req)
+		return w, time.Since(started), dispatcher.dispatched
+	}
+
+	fresh, freshTook, freshEvents := register(signupUsers{}, nil)
+	if fresh.Code != http.StatusNoContent {
+		t.Fatalf("new account: status = %d, want %d: %s", fresh.Code, http.StatusNoContent, fresh.Body)
+	}
+	if freshEvents != 1 {
+		t.Errorf("new account: dispatched %d events, want 1", freshEvents)
+	}
+	if freshTook < minDuration {
+		t.Errorf("new account answered in %s, want at least %s", freshTook, minDuration)
+	}
+
+	tests := []struct {
+		name    string
+		users   user.UserService
+		authErr error
+	}{
+		{name: "email already has a users row", users: registeredUsers{}},
+		{name: "username taken in the pool", users: signupUsers{}, authErr: auth.ErrUserAlreadyExists},
+		{name: "email is another account's alias", users: signupUsers{}, authErr: auth.ErrAliasAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, took, dispatched := register(tt.users, tt.authErr)
+
+			if w.Code != fresh.Code || w.Body.String() != fresh.Body.String() {
+				t.Errorf("response = %d %q, want the new account's %d %q", w.Code, w.Body, fresh.Code, fresh.Body)
+			}
+			if len(w.Header()) != len(fresh.Header()) {
+				t.Errorf("headers = %v, want the new account's %v", w.Header(), fresh.Header())
+			}
+			if dispatched != 0 {
+				t.Errorf("dispatched %d events for an existing account, want none", dispatched)
+			}
+			if took < minDuration {
+				t.Errorf("answered in %s, want at least %s", took, minDuration)
+			}
+		})
+	}
+}
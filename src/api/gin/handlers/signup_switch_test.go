@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"auth-api/src/api/gin/middleware"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	user_usecases "auth-api/src/internal/modules/user-manager/usecases/user"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signupAuth counts the accounts created in Cognito.
+type signupAuth struct {
+	auth.AuthService
+	signUps int
+}
+
+func (a *signupAuth) SignUp(ctx context.Context, input auth.SignUpInput) (*auth.SignUpOutput, error) {
+	a.signUps++
+	return auth.NewSignUpOutput("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", input.Username, false, a), nil
+}
+
+type signupUsers struct {
+	user.UserService
+}
+
+func (signupUsers) GetByEmail(*user.GetUserByEmailInput) (*user.User, error) {
+	return nil, user.ErrUserNotFound
+}
+
+func (s signupUsers) Create(input *user.CreateUserInput) (*user.CreateUserOutput, error) {
+	return user.NewCreateUserOutput(&input.ID, s), nil
+}
+
+func TestRegisterFollowsDisableSignup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		wantStatus  int
+		wantMessage string
+		wantSignUps int
+	}{
+		{name: "enabled", wantStatus: http.StatusNoContent, wantSignUps: 1},
+		{name: "disabled", disabled: true, wantStatus: http.StatusForbidden, wantMessage: `"Signup disabled"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &signupAuth{}
+			useCases := user_usecases.NewUseCases(user_usecases.Dependencies{
+				User:       signupUsers{},
+				Auth:       authService,
+				Logger:     logger.Nop{},
+				Dispatcher: nopDispatcher{},
+				Challenge:  passChallenge{},
+			}, user_usecases.Options{
+				SignupDisabled: tt.disabled,
+				AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail},
+			})
+			engine := gin.New()
+			engine.Use(middleware.ErrorHandler(logger.Nop{}, ""))
+			engine.POST("/user/register", NewUserHandler(useCases, false).Register())
+
+			body := strings.NewReader(`{"email":"new@example.com","password":"Str0ng!Passw0rd","name":"New User"}`)
+			req := httptest.NewRequest(http.MethodPost, "/user/register", body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantMessage) {
+				t.Errorf("body = %s, want message %s", w.Body, tt.wantMessage)
+			}
+			if authService.signUps != tt.wantSignUps {
+				t.Errorf("sign ups = %d, want %d", authService.signUps, tt.wantSignUps)
+			}
+		})
+	}
+}
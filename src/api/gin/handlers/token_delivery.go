@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/jwt_verify"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TokenPlacementBody   = "body"
+	TokenPlacementCookie = "cookie"
+	TokenPlacementNone   = "none"
+
+	clientTypeHeader = "X-Client-Type"
+)
+
+// tokenDelivery shapes token responses per client type, e.g. a SPA getting
+// its refresh token as an HttpOnly cookie while a mobile app gets all of
+// them in the body.
+type tokenDelivery struct {
+	cfg           config.TokenDeliveryConfig
+	cookieName    string
+	sessionCookie config.SessionCookieConfig
+}
+
+// strategy returns nil when the client gets every token in the body. viper
+// lowercases map keys, so lookups are lowercased too.
+func (d *tokenDelivery) strategy(c *gin.Context, accessToken string) *config.TokenDeliveryStrategy {
+	if len(d.cfg.Types) == 0 {
+		return nil
+	}
+	if clientType := c.GetHeader(clientTypeHeader); clientType != "" {
+		if strategy, ok := d.cfg.Types[strings.ToLower(clientType)]; ok {
+			return &strategy
+		}
+	}
+	if accessToken != "" {
+		if claims, err := jwt_verify.ParseUnverifiedClaims(accessToken); err == nil {
+			if clientType, ok := d.cfg.ClientIDs[strings.ToLower(claims.ClientId)]; ok {
+				if strategy, ok := d.cfg.Types[strings.ToLower(clientType)]; ok {
+					return &strategy
+				}
+			}
+		}
+	}
+	if strategy, ok := d.cfg.Types[strings.ToLower(d.cfg.Default)]; ok {
+		return &strategy
+	}
+	return nil
+}
+
+// deliver removes from out the tokens the client doesn't get in the body,
+// setting the refresh token cookie when that is where it goes. Challenge
+// responses carry no tokens and are left alone.
+func (d *tokenDelivery) deliver(c *gin.Context, out *auth.LoginOutput) {
+	if out == nil || out.AccessToken == nil {
+		return
+	}
+	strategy := d.strategy(c, *out.AccessToken)
+	if strategy == nil {
+		return
+	}
+	if placement(strategy.RefreshToken) == TokenPlacementCookie && out.RefreshToken != nil {
+		d.setRefreshCookie(c, *out.RefreshToken, int(d.cfg.CookieMaxAge.Seconds()))
+	}
+	if placement(strategy.RefreshToken) != TokenPlacementBody {
+		out.RefreshToken = nil
+	}
+	if placement(strategy.IdToken) != TokenPlacementBody {
+		out.IdToken = nil
+	}
+	if placement(strategy.AccessToken) != TokenPlacementBody {
+		out.AccessToken = nil
+	}
+}
+
+// deliverRefreshed applies the same choice to a refresh, which never carries
+// a new refresh token. The claims are read from the id token, so they go
+// wherever it goes.
+func (d *tokenDelivery) deliverRefreshed(c *gin.Context, out *auth.RefreshTokenOutput) {
+	strategy := d.strategy(c, out.AccessToken)
+	if strategy == nil {
+		return
+	}
+	if placement(strategy.IdToken) != TokenPlacementBody {
+		out.IdToken = ""
+		out.IdTokenExpiresIn = 0
+		out.IdTokenExpiresAt = nil
+		out.Claims = nil
+	}
+	if placement(strategy.AccessToken) != TokenPlacementBody {
+		out.AccessToken = ""
+		out.AccessTokenExpiresIn = 0
+		out.AccessTokenExpiresAt = nil
+	}
+}
+
+// clearRefreshCookie expires a refresh token cookie the client still holds.
+func (d *tokenDelivery) clearRefreshCookie(c *gin.Context) {
+	if _, err := c.Cookie(d.cookieName); err == nil {
+		d.setRefreshCookie(c, "", -1)
+	}
+}
+
+func (d *tokenDelivery) setRefreshCookie(c *gin.Context, value string, maxAge int) {
+	sameSite := http.SameSiteLaxMode
+	if strings.EqualFold(d.sessionCookie.SameSite, "strict") {
+		sameSite = http.SameSiteStrictMode
+	}
+	c.SetSameSite(sameSite)
+	c.SetCookie(d.cookieName, value, maxAge, "/", d.sessionCookie.Domain, d.sessionCookie.Secure, true)
+}
+
+// placement only recognizes "cookie" and "none"; anything else is the body,
+// so a typo never swallows a token.
+func placement(value string) string {
+	switch strings.ToLower(value) {
+	case TokenPlacementCookie:
+		return TokenPlacementCookie
+	case TokenPlacementNone:
+		return TokenPlacementNone
+	}
+	return TokenPlacementBody
+}
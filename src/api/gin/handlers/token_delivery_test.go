@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestDeliverRefreshedDropsWithheldTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		strategy config.TokenDeliveryStrategy
+		wantKeys []string
+		wantGone []string
+	}{
+		{
+			name:     "everything in the body",
+			strategy: config.TokenDeliveryStrategy{},
+			wantKeys: []string{"accessToken", "accessTokenExpiresIn", "accessTokenExpiresAt", "idToken", "idTokenExpiresIn", "idTokenExpiresAt", "claims"},
+		},
+		{
+			name:     "id token withheld",
+			strategy: config.TokenDeliveryStrategy{IdToken: TokenPlacementNone},
+			wantKeys: []string{"accessToken", "accessTokenExpiresIn", "accessTokenExpiresAt"},
+			wantGone: []string{"idToken", "idTokenExpiresIn", "idTokenExpiresAt", "claims"},
+		},
+		{
+			name:     "access token withheld",
+			strategy: config.TokenDeliveryStrategy{AccessToken: TokenPlacementNone},
+			wantKeys: []string{"idToken", "idTokenExpiresIn", "idTokenExpiresAt", "claims"},
+			wantGone: []string{"accessToken", "accessTokenExpiresIn", "accessTokenExpiresAt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := &tokenDelivery{cfg: config.TokenDeliveryConfig{
+				Types: map[string]config.TokenDeliveryStrategy{"spa": tt.strategy},
+			}}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+			c.Request.Header.Set(clientTypeHeader, "spa")
+
+			expiresAt := time.Now().Add(time.Hour)
+			out := &auth.RefreshTokenOutput{
+				AccessToken:          "access",
+				IdToken:              "id",
+				AccessTokenExpiresIn: 3600,
+				AccessTokenExpiresAt: &expiresAt,
+				IdTokenExpiresIn:     3600,
+				IdTokenExpiresAt:     &expiresAt,
+				Claims:               &auth.RefreshedClaims{Id: "sub-1"},
+			}
+			delivery.deliverRefreshed(c, out)
+
+			body, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.wantKeys {
+				if !strings.Contains(string(body), `"`+key+`"`) {
+					t.Errorf("body = %s, want %s", body, key)
+				}
+			}
+			for _, key := range tt.wantGone {
+				if strings.Contains(string(body), `"`+key+`"`) {
+					t.Errorf("body = %s, want no %s", body, key)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverPlacesLoginTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.TokenDeliveryConfig{
+		Default: "mobile",
+		Types: map[string]config.TokenDeliveryStrategy{
+			"mobile": {},
+			"spa":    {RefreshToken: TokenPlacementCookie, IdToken: TokenPlacementNone},
+			"server": {RefreshToken: TokenPlacementNone, AccessToken: "typo"},
+		},
+		ClientIDs:    map[string]string{"spa-client": "spa"},
+		CookieMaxAge: time.Hour,
+	}
+	spaAccessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"client_id": "spa-client"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		clientType string
+		// accessToken defaults to one issued to no configured app client.
+		accessToken string
+		challenge   bool
+		wantBody    []string
+		wantGone    []string
+		wantCookie  string
+	}{
+		{name: "default client gets everything in the body", wantBody: []string{"accessToken", "idToken", "refreshToken"}},
+		{name: "unknown client type falls back to the default", clientType: "desktop", wantBody: []string{"accessToken", "idToken", "refreshToken"}},
+		{name: "refresh token in a cookie, id token withheld", clientType: "SPA", wantBody: []string{"accessToken"}, wantGone: []string{"idToken", "refreshToken"}, wantCookie: "refresh"},
+		{name: "app client picks the type without a header", accessToken: spaAccessToken, wantBody: []string{"accessToken"}, wantGone: []string{"idToken", "refreshToken"}, wantCookie: "refresh"},
+		{name: "header wins over the app client", clientType: "mobile", accessToken: spaAccessToken, wantBody: []string{"accessToken", "idToken", "refreshToken"}},
+		{name: "refresh token withheld, unknown placement kept in the body", clientType: "server", wantBody: []string{"accessToken", "idToken"}, wantGone: []string{"refreshToken"}},
+		{name: "challenge left alone", clientType: "spa", challenge: true, wantBody: []string{"session"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := &tokenDelivery{cfg: cfg, cookieName: "refresh_token", sessionCookie: config.SessionCookieConfig{SameSite: "strict", Secure: true}}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+			if tt.clientType != "" {
+				c.Request.Header.Set(clientTypeHeader, tt.clientType)
+			}
+
+			accessToken, idToken, refreshToken, session := "access", "id", "refresh", "session"
+			if tt.accessToken != "" {
+				accessToken = tt.accessToken
+			}
+			out := &auth.LoginOutput{AccessToken: &accessToken, IdToken: &idToken, RefreshToken: &refreshToken}
+			if tt.challenge {
+				out = &auth.LoginOutput{Session: &session}
+			}
+			delivery.deliver(c, out)
+
+			body, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.wantBody {
+				if !strings.Contains(string(body), `"`+key+`"`) {
+					t.Errorf("body = %s, want %s", body, key)
+				}
+			}
+			for _, key := range tt.wantGone {
+				if strings.Contains(string(body), `"`+key+`"`) {
+					t.Errorf("body = %s, want no %s", body, key)
+				}
+			}
+
+			cookies := w.Result().Cookies()
+			if tt.wantCookie == "" {
+				if len(cookies) != 0 {
+					t.Errorf("cookies = %v, want none", cookies)
+				}
+				return
+			}
+			if len(cookies) != 1 {
+				t.Fatalf("cookies = %v, want the refresh token cookie", cookies)
+			}
+			cookie := cookies[0]
+			if cookie.Name != "refresh_token" || cookie.Value != tt.wantCookie || cookie.MaxAge != 3600 {
+				t.Errorf("cookie = %s=%s max-age %d, want refresh_token=%s max-age 3600", cookie.Name, cookie.Value, cookie.MaxAge, tt.wantCookie)
+			}
+			if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode {
+				t.Errorf("cookie = %+v, want HttpOnly, Secure and SameSite=Strict", cookie)
+			}
+		})
+	}
+}
+
+func TestClearRefreshCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, held := range []bool{true, false} {
+		delivery := &tokenDelivery{cookieName: "refresh_token"}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+		if held {
+			c.Request.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh"})
+		}
+
+		delivery.clearRefreshCookie(c)
+
+		cookies := w.Result().Cookies()
+		switch {
+		case held && (len(cookies) != 1 || cookies[0].Value != "" || cookies[0].MaxAge >= 0):
+			t.Errorf("cookies = %v, want the refresh token cookie expired", cookies)
+		case !held && len(cookies) != 0:
+			t.Errorf("cookies = %v, want none for a client without the cookie", cookies)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	ExportDeliveryInline = "inline"
+	ExportDeliveryURL    = "url"
+
+	// exportFlushEvery bounds how much is buffered before it is pushed to
+	// the client.
+	exportFlushEvery = 100
+)
+
+var ErrInvalidExportFormat = app_error.NewApiError(http.StatusBadRequest, "Invalid export format", fmt.Sprintf("Field: %s", "Format"))
+
+// userExportWriter writes users to the response one at a time. Writes block
+// while the client isn't reading, which in turn holds off the next page.
+type userExportWriter interface {
+	begin() error
+	write(user auth.User) error
+	end() error
+}
+
+func newUserExportWriter(format string, w io.Writer) (userExportWriter, string, error) {
+	switch format {
+	case "", ExportFormatCSV:
+		return &csvUserExportWriter{w: w, csv: csv.NewWriter(w)}, "text/csv; charset=utf-8", nil
+	case ExportFormatJSON:
+		return &jsonUserExportWriter{w: w, enc: json.NewEncoder(w)}, "application/json", nil
+	default:
+		return nil, "", ErrInvalidExportFormat
+	}
+}
+
+func flush(w io.Writer) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type csvUserExportWriter struct {
+	w       io.Writer
+	csv     *csv.Writer
+	written int
+}
+
+func (e *csvUserExportWriter) begin() error {
+	return e.csv.Write([]string{"id", "email", "name", "status"})
+}
+
+func (e *csvUserExportWriter) write(user auth.User) error {
+	if err := e.csv.Write([]string{user.Id, user.Email, user.Name, string(user.Status)}); err != nil {
+		return err
+	}
+	e.written++
+	if e.written%exportFlushEvery == 0 {
+		e.csv.Flush()
+		flush(e.w)
+		return e.csv.Error()
+	}
+	return nil
+}
+
+func (e *csvUserExportWriter) end() error {
+	e.csv.Flush()
+	flush(e.w)
+	return e.csv.Error()
+}
+
+// jsonUserExportWriter streams a JSON array, one element per user.
+type jsonUserExportWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	written int
+}
+
+func (e *jsonUserExportWriter) begin() error {
+	_, err := e.w.Write([]byte("["))
+	return err
+}
+
+func (e *jsonUserExportWriter) write(user auth.User) error {
+	if e.written > 0 {
+		if _, err := e.w.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	if err := e.enc.Encode(user); err != nil {
+		return err
+	}
+	e.written++
+	if e.written%exportFlushEvery == 0 {
+		flush(e.w)
+	}
+	return nil
+}
+
+func (e *jsonUserExportWriter) end() error {
+	_, err := e.w.Write([]byte("]"))
+	flush(e.w)
+	return err
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	admin_usecases "auth-api/src/internal/modules/user-manager/usecases/admin"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportPages serves pages of exportFlushEvery users. onFetch runs before a
+// page is handed out, while the previous one is being written.
+type exportPages struct {
+	auth.AuthService
+	pages   int
+	fetches int
+	onFetch func(page int)
+}
+
+func exportEmail(page, i int) string {
+	return fmt.Sprintf("user-%d-%d@example.com", page, i)
+}
+
+func (a *exportPages) ListUsers(ctx context.Context, input auth.ListUsersInput) (*auth.ListUsersOutput, error) {
+	page := 0
+	if input.NextToken != nil {
+		page, _ = strconv.Atoi(*input.NextToken)
+	}
+	a.fetches++
+	if a.onFetch != nil {
+		a.onFetch(page)
+	}
+
+	out := &auth.ListUsersOutput{}
+	for i := 0; i < exportFlushEvery; i++ {
+		out.Users = append(out.Users, auth.User{Id: fmt.Sprintf("%d-%d", page, i), Email: exportEmail(page, i)})
+	}
+	if page+1 < a.pages {
+		next := strconv.Itoa(page + 1)
+		out.NextToken = &next
+	}
+	return out, nil
+}
+
+type nopAudit struct {
+	audit.AuditService
+}
+
+func (nopAudit) Record(context.Context, audit.RecordInput) error { return nil }
+
+func newExportEngine(authService auth.AuthService) *gin.Engine {
+	handler := NewAdminHandler(&admin_usecases.UseCases{
+		ExportUsers: admin_usecases.NewExportUsersUseCase(authService, nopAudit{}, logger.Nop{}),
+	}, ExportDeliveryInline, false)
+	engine := gin.New()
+	engine.GET("/admin/users/export", func(c *gin.Context) {
+		c.Set("claims", &auth.Claims{Id: "admin"})
+	}, handler.ExportUsers())
+	return engine
+}
+
+func TestExportUsersStreamsPageByPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, format := range []string{ExportFormatCSV, ExportFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			authService := &exportPages{pages: 4}
+			// Every page has reached the client before the next one is
+			// fetched, so the export never holds more than about a page.
+			authService.onFetch = func(page int) {
+				if page == 0 {
+					return
+				}
+				if last := exportEmail(page-1, exportFlushEvery-1); !strings.Contains(w.Body.String(), last) {
+					t.Errorf("fetching page %d before %s was written", page, last)
+				}
+				if next := exportEmail(page, 0); strings.Contains(w.Body.String(), next) {
+					t.Errorf("%s written before its page was fetched", next)
+				}
+			}
+
+			newExportEngine(authService).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users/export?format="+format, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if authService.fetches != 4 {
+				t.Errorf("fetched %d pages, want 4", authService.fetches)
+			}
+			if last := exportEmail(3, exportFlushEvery-1); !strings.Contains(w.Body.String(), last) {
+				t.Errorf("body is missing the last user %s", last)
+			}
+		})
+	}
+}
+
+func TestExportUsersStopsWhenTheClientGoesAway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, format := range []string{ExportFormatCSV, ExportFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			authService := &exportPages{pages: 4}
+			authService.onFetch = func(page int) {
+				if page == 1 {
+					cancel()
+				}
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/admin/users/export?format="+format, nil).WithContext(ctx)
+			newExportEngine(authService).ServeHTTP(w, req)
+
+			if authService.fetches != 2 {
+				t.Errorf("fetched %d pages, want paging to stop at the one in flight", authService.fetches)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want the %d already sent", w.Code, http.StatusOK)
+			}
+			if !strings.Contains(w.Body.String(), exportEmail(0, exportFlushEvery-1)) {
+				t.Errorf("body is missing the first page")
+			}
+			if strings.Contains(w.Body.String(), exportEmail(1, 0)) {
+				t.Errorf("body has users fetched after the client went away")
+			}
+			if format == ExportFormatJSON && strings.HasSuffix(w.Body.String(), "]") {
+				t.Errorf("body was closed as if the export completed")
+			}
+		})
+	}
+}
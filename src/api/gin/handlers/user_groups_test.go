@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"auth-api/src/api/gin/middleware"
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenGroupsAuth validates any token as carrying groups, and fails every
+// Cognito lookup so the endpoint is seen not to make one.
+type tokenGroupsAuth struct {
+	auth.AuthService
+	groups []string
+}
+
+func (a tokenGroupsAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	return &auth.Claims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", UserGroups: a.groups}, nil
+}
+
+func (a tokenGroupsAuth) ListUserGroups(context.Context, auth.ListUserGroupsInput) (*auth.UserGroupsOutput, error) {
+	panic("GET /auth/user/groups called Cognito")
+}
+
+func TestGetUserGroupsMatchesTheToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		groups []string
+	}{
+		{name: "user", groups: []string{string(auth.GroupUser)}},
+		{name: "several groups in token order", groups: []string{string(auth.GroupAdmin), string(auth.GroupUser), "Beta"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := tokenGroupsAuth{groups: tt.groups}
+			handler := NewAuthHandler(&auth_usecases.UseCases{}, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+			engine := gin.New()
+			engine.GET("/auth/user/groups", middleware.NewAuthMiddleware(authService, nil).AuthMiddleware(auth.GroupAdmin, auth.GroupUser), handler.GetUserGroups())
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/user/groups", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			var body auth.UserGroupsOutput
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if !reflect.DeepEqual(body.Groups, tt.groups) {
+				t.Errorf("groups = %v, want the token's %v", body.Groups, tt.groups)
+			}
+		})
+	}
+}
+
+func TestGetUserGroupsWithoutClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewAuthHandler(&auth_usecases.UseCases{}, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, false)
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler(logger.Nop{}, ""))
+	engine.GET("/auth/user/groups", handler.GetUserGroups())
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/user/groups", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -5,26 +5,35 @@ import (
 	"auth-api/src/internal/modules/user-manager/domain/user"
 	user_usecases "auth-api/src/internal/modules/user-manager/usecases/user"
 	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/validator"
 	"context"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	useCases *user_usecases.UseCases
+	useCases               *user_usecases.UseCases
+	caseSensitiveUsernames bool
 }
 
-func NewUserHandler(useCases *user_usecases.UseCases) *UserHandler {
+func NewUserHandler(useCases *user_usecases.UseCases, caseSensitiveUsernames bool) *UserHandler {
 	return &UserHandler{
-		useCases: useCases,
+		useCases:               useCases,
+		caseSensitiveUsernames: caseSensitiveUsernames,
 	}
 }
 
 type registerUserInput struct {
-	Email    string  `json:"email"`
-	Password string  `json:"password"`
-	Name     string  `json:"name"`
-	Phone    *string `json:"phone"`
+	Email           string              `json:"email"`
+	Password        string              `json:"password"`
+	Name            string              `json:"name"`
+	Phone           *string             `json:"phone"`
+	DeliveryMedium  auth.DeliveryMedium `json:"deliveryMedium"`
+	ChallengeToken  string              `json:"challengeToken"`
+	ConfirmPassword *string             `json:"confirmPassword"`
+	InviteToken     string              `json:"inviteToken"`
+	// Attributes are the extra user attributes the deployment exposes.
+	Attributes map[string]string `json:"attributes"`
 }
 
 func (h *UserHandler) Register() gin.HandlerFunc {
@@ -32,21 +41,42 @@ func (h *UserHandler) Register() gin.HandlerFunc {
 		processRequestNoOutput(c, registerUserInput{}, func(ctx context.Context, input registerUserInput) error {
 			err := h.useCases.Register.Execute(ctx, user_usecases.RegisterUserInput{
 				SignUpInput: auth.SignUpInput{
-					Username: input.Email,
-					Password: input.Password,
-					Name:     input.Name,
+					Username:             validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
+					Password:             input.Password,
+					Name:                 input.Name,
+					Phone:                input.Phone,
+					DeliveryMedium:       input.DeliveryMedium,
+					PasswordConfirmation: input.ConfirmPassword,
+					Attributes:           input.Attributes,
 				},
 				CreateUserInput: user.CreateUserInput{
 					Phone: input.Phone,
 					Name:  input.Name,
-					Email: input.Email,
+					Email: validator.NormalizeEmail(input.Email, h.caseSensitiveUsernames),
 				},
+				IP:             c.ClientIP(),
+				ChallengeToken: challengeToken(c, input.ChallengeToken),
+				InviteToken:    input.InviteToken,
 			})
 			return err
 		})
 	}
 }
 
+type verifyInviteInput struct {
+	Token string `json:"token"`
+}
+
+func (h *UserHandler) VerifyInvite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		processRequest(c, verifyInviteInput{}, func(ctx context.Context, input verifyInviteInput) (*user_usecases.VerifyInviteOutput, error) {
+			return h.useCases.VerifyInvite.Execute(ctx, user_usecases.VerifyInviteInput{
+				Token: input.Token,
+			})
+		})
+	}
+}
+
 type updateUserInput struct {
 	Name  *string `json:"name"`
 	Phone *string `json:"phone"`
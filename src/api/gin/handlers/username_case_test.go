@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"auth-api/src/config"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usernameAuth records the usernames sign ins reach Cognito with.
+type usernameAuth struct {
+	cookieAuth
+	usernames []string
+}
+
+func (a *usernameAuth) Login(ctx context.Context, input auth.LoginInput) (*auth.LoginOutput, error) {
+	a.usernames = append(a.usernames, input.Username)
+	return a.cookieAuth.Login(ctx, input)
+}
+
+func TestLoginFollowsUsernameCaseSensitivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		want          string
+	}{
+		{name: "folded by default", want: "member@example.com"},
+		{name: "case preserved when flag set", caseSensitive: true, want: "Member@Example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &usernameAuth{}
+			useCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+				Auth:       authService,
+				Dispatcher: nopDispatcher{},
+				Logger:     logger.Nop{},
+				Challenge:  passChallenge{},
+			}, auth_usecases.Options{CaseSensitiveUsernames: tt.caseSensitive})
+			handler := NewAuthHandler(useCases, config.SessionCookieConfig{}, config.RefreshTokenConfig{}, config.TokenDeliveryConfig{}, tt.caseSensitive)
+			engine := gin.New()
+			engine.POST("/auth/login", handler.Login())
+
+			body := strings.NewReader(`{"email":"Member@Example.com","password":"Password1!"}`)
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			if len(authService.usernames) != 1 || authService.usernames[0] != tt.want {
+				t.Errorf("Cognito sign ins = %v, want [%s]", authService.usernames, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"auth-api/src/api/gin/middleware"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	user_usecases "auth-api/src/internal/modules/user-manager/usecases/user"
+	"auth-api/src/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterReportsEveryInvalidField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, format := range []string{"", middleware.ErrorFormatProblem} {
+		t.Run("format="+format, func(t *testing.T) {
+			authService := &signupAuth{}
+			useCases := user_usecases.NewUseCases(user_usecases.Dependencies{
+				User:       signupUsers{},
+				Auth:       authService,
+				Logger:     logger.Nop{},
+				Dispatcher: nopDispatcher{},
+				Challenge:  passChallenge{},
+			}, user_usecases.Options{AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail}})
+			engine := gin.New()
+			engine.Use(middleware.ErrorHandler(logger.Nop{}, format))
+			engine.POST("/user/register", NewUserHandler(useCases, false).Register())
+
+			body := strings.NewReader(`{"email":"not-an-email","password":"short","name":"Al"}`)
+			req := httptest.NewRequest(http.MethodPost, "/user/register", body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+			var response struct {
+				Fields map[string]string `json:"fields"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("body %s: %v", w.Body, err)
+			}
+			for field, message := range map[string]string{"Username": "Invalid email format", "Password": "Invalid password", "Name": "Invalid name length"} {
+				if response.Fields[field] != message {
+					t.Errorf("fields[%s] = %q, want %q; body = %s", field, response.Fields[field], message, w.Body)
+				}
+			}
+			if len(response.Fields) != 3 {
+				t.Errorf("fields = %v, want the three invalid ones", response.Fields)
+			}
+			if authService.signUps != 0 {
+				t.Errorf("signed up %d users from an invalid request", authService.signUps)
+			}
+		})
+	}
+}
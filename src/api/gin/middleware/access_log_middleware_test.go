@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		logBodies   bool
+		target      string
+		contentType string
+		body        string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:   "request fields",
+			target: "/users/42",
+			body:   `{"email":"someone@example.com","password":"hunter2"}`,
+			want: []string{
+				"method=POST", "path=/users/:id", "query=-", "status=201", "latency=",
+				"request_id=req-1", "ip=192.0.2.1", "sub=6f1c1f46",
+			},
+			notWant: []string{"request_body", "response_body", "someone@example.com", "hunter2", "/users/42"},
+		},
+		{
+			name:      "bodies logged with credentials redacted",
+			logBodies: true,
+			target:    "/users/42",
+			body:      `{"email":"someone@example.com","password":"hunter2","nested":{"refresh_token":"r"}}`,
+			want: []string{
+				`request_body={"email":"someone@example.com","nested":{"refresh_token":"[REDACTED]"},"password":"[REDACTED]"}`,
+				`response_body={"accessToken":"[REDACTED]","id":"42"}`,
+			},
+			notWant: []string{"hunter2", "secret-access-token"},
+		},
+		{
+			name:    "query tokens redacted",
+			target:  "/users/42?accessToken=secret-access-token&page=2",
+			want:    []string{"query=accessToken=%5BREDACTED%5D&page=2"},
+			notWant: []string{"secret-access-token"},
+		},
+		{
+			name:        "non-JSON bodies omitted",
+			logBodies:   true,
+			target:      "/users/42",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "password=hunter2",
+			want:        []string{"request_body=[omitted]"},
+			notWant:     []string{"hunter2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			var handlerBody string
+			engine := gin.New()
+			engine.Use(RequestID(), NewAccessLog(log, "error", tt.logBodies, 1024).AccessLogMiddleware())
+			engine.POST("/users/:id", func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				handlerBody = string(body)
+				c.Set("claims", &auth.Claims{Id: "6f1c1f46"})
+				c.JSON(http.StatusCreated, gin.H{"id": c.Param("id"), "accessToken": "secret-access-token"})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set(RequestIDHeader, "req-1")
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			req.Header.Set("Content-Type", contentType)
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+
+			if handlerBody != tt.body {
+				t.Errorf("handler read %q, want the whole body %q", handlerBody, tt.body)
+			}
+			if len(log.errors) != 1 {
+				t.Fatalf("logged %d lines, want 1: %q", len(log.errors), log.errors)
+			}
+			line := log.errors[0]
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("log line %q is missing %q", line, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(line, notWant) {
+					t.Errorf("log line %q has %q", line, notWant)
+				}
+			}
+		})
+	}
+}
+
+// levelLogger keeps each line under the level it was logged at.
+type levelLogger struct {
+	lines map[string][]string
+}
+
+func (l *levelLogger) log(level, format string, v ...interface{}) {
+	if l.lines == nil {
+		l.lines = map[string][]string{}
+	}
+	l.lines[level] = append(l.lines[level], fmt.Sprintf(format, v...))
+}
+
+func (l *levelLogger) Info(format string, v ...interface{})    { l.log("info", format, v...) }
+func (l *levelLogger) Error(format string, v ...interface{})   { l.log("error", format, v...) }
+func (l *levelLogger) Warning(format string, v ...interface{}) { l.log("warning", format, v...) }
+func (l *levelLogger) Debug(format string, v ...interface{})   { l.log("debug", format, v...) }
+
+func TestAccessLogLevelAndRedactFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		level     string
+		wantLevel string
+	}{
+		{level: "debug", wantLevel: "debug"},
+		{level: "INFO", wantLevel: "info"},
+		{level: "warn", wantLevel: "warning"},
+		{level: "warning", wantLevel: "warning"},
+		{level: "error", wantLevel: "error"},
+		{level: "", wantLevel: "info"},
+		{level: "verbose", wantLevel: "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			log := &levelLogger{}
+			engine := gin.New()
+			engine.Use(NewAccessLog(log, tt.level, false, 1024, "apiKey").AccessLogMiddleware())
+			engine.GET("/health", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health?apikey=secret-key", nil))
+
+			lines := log.lines[tt.wantLevel]
+			if len(lines) != 1 || len(log.lines) != 1 {
+				t.Fatalf("logged %v, want one line at %s", log.lines, tt.wantLevel)
+			}
+			for _, want := range []string{"status=204", "query=apikey=%5BREDACTED%5D", "sub=-"} {
+				if !strings.Contains(lines[0], want) {
+					t.Errorf("log line %q is missing %q", lines[0], want)
+				}
+			}
+			if strings.Contains(lines[0], "secret-key") {
+				t.Errorf("log line %q has the configured redact field's value", lines[0])
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/pkg/app_error"
+	"errors"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,33 +14,61 @@ type AuthMiddleware interface {
 }
 
 type AuthMiddlewareImpl struct {
-	auth auth.AuthService
+	auth           auth.AuthService
+	claimsResolver auth.ClaimsResolver
 }
 
-func NewAuthMiddleware(a auth.AuthService) AuthMiddleware {
+// NewAuthMiddleware takes an optional resolver that enriches the claims before
+// they are authorized and stored on the context.
+func NewAuthMiddleware(a auth.AuthService, claimsResolver auth.ClaimsResolver) AuthMiddleware {
 	return &AuthMiddlewareImpl{
-		auth: a,
+		auth:           a,
+		claimsResolver: claimsResolver,
 	}
 }
 
 func (a *AuthMiddlewareImpl) AuthMiddleware(groupNames ...auth.UserGroup) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Error(app_error.NewApiError(401, "Unauthorized"))
+		token, err := BearerToken(c.Request.Header)
+		if err != nil {
+			if err == ErrMissingAuthHeader {
+				setBearerChallenge(c, "", "")
+			} else {
+				setBearerChallenge(c, "invalid_request", "The Authorization header is malformed")
+			}
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
-		token := authHeader[7:] // remove Bearer from token
-
 		claims, err := a.auth.ValidateToken(c.Request.Context(), token)
 		if err != nil {
+			if errors.Is(err, auth.ErrTokenExpired) {
+				setBearerChallenge(c, "invalid_token", "The access token expired")
+			} else {
+				setBearerChallenge(c, "invalid_token", "The access token is invalid")
+			}
 			c.Error(app_error.NewApiError(401, "Unauthorized"))
 			c.Abort()
 			return
 		}
 
+		if a.claimsResolver != nil {
+			if err := a.claimsResolver.Resolve(c.Request.Context(), claims); err != nil {
+				c.Error(err)
+				c.Abort()
+				return
+			}
+		}
+
+		// A valid token without cognito:groups is authenticated but can't be
+		// authorized for anything.
+		if len(claims.UserGroups) == 0 {
+			c.Error(auth.ErrNoGroups)
+			c.Abort()
+			return
+		}
+
 		userGroups := make(map[string]struct{}, len(claims.UserGroups))
 		for _, group := range claims.UserGroups {
 			userGroups[group] = struct{}{}
@@ -53,7 +83,7 @@ func (a *AuthMiddlewareImpl) AuthMiddleware(groupNames ...auth.UserGroup) gin.Ha
 		}
 
 		if !authorized {
-			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Error(auth.ErrForbidden)
 			c.Abort()
 			return
 		}
@@ -64,3 +94,13 @@ func (a *AuthMiddlewareImpl) AuthMiddleware(groupNames ...auth.UserGroup) gin.Ha
 		c.Next()
 	}
 }
+
+// setBearerChallenge sets the RFC 6750 WWW-Authenticate header for a 401.
+// A request without credentials gets the bare challenge, with no error code.
+func setBearerChallenge(c *gin.Context, code, description string) {
+	if code == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		return
+	}
+	c.Header("WWW-Authenticate", fmt.Sprintf("Bearer error=%q, error_description=%q", code, description))
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// groupsAuth accepts any token and hands back the groups it was set up with.
+type groupsAuth struct {
+	auth.AuthService
+	groups []string
+}
+
+func (a groupsAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	return &auth.Claims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", UserGroups: a.groups}, nil
+}
+
+func TestAuthMiddlewareGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		groups      []string
+		route       string
+		format      string
+		wantStatus  int
+		wantMessage string
+		wantCode    string
+	}{
+		{name: "no groups on a user route", route: "/user", wantStatus: http.StatusForbidden, wantMessage: "No groups"},
+		{name: "no groups on an admin route", route: "/admin", wantStatus: http.StatusForbidden, wantMessage: "No groups"},
+		{name: "empty groups on an admin route", groups: []string{}, route: "/admin", wantStatus: http.StatusForbidden, wantMessage: "No groups"},
+		{name: "no groups, problem format", route: "/admin", format: ErrorFormatProblem, wantStatus: http.StatusForbidden, wantCode: "NO_GROUPS"},
+		{name: "user on an admin route", groups: []string{string(auth.GroupUser)}, route: "/admin", wantStatus: http.StatusForbidden, wantMessage: "Forbidden"},
+		{name: "user on a user route", groups: []string{string(auth.GroupUser)}, route: "/user", wantStatus: http.StatusOK},
+		{name: "admin on an admin route", groups: []string{string(auth.GroupAdmin)}, route: "/admin", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := NewAuthMiddleware(groupsAuth{groups: tt.groups}, nil)
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, tt.format))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			engine.GET("/user", authMiddleware.AuthMiddleware(auth.GroupUser), ok)
+			engine.GET("/admin", authMiddleware.AuthMiddleware(auth.GroupAdmin), ok)
+
+			req := httptest.NewRequest(http.MethodGet, tt.route, nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			var body struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if body.Message != tt.wantMessage || body.Code != tt.wantCode {
+				t.Errorf("message = %q code = %q, want %q %q", body.Message, body.Code, tt.wantMessage, tt.wantCode)
+			}
+			if w.Header().Get("WWW-Authenticate") != "" {
+				t.Errorf("WWW-Authenticate = %q on a 403", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// emailAuth is groupsAuth with an email on the claims.
+type emailAuth struct {
+	groupsAuth
+	email string
+}
+
+func (a emailAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	claims, err := a.groupsAuth.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims.Email = a.email
+	return claims, nil
+}
+
+func TestAuthMiddlewareStoresResolvedClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tenantFromEmail := auth.ClaimsResolverFunc(func(ctx context.Context, claims *auth.Claims) error {
+		claims.TenantID = strings.TrimPrefix(claims.Email[strings.LastIndex(claims.Email, "@"):], "@")
+		return nil
+	})
+	rejectAll := auth.ClaimsResolverFunc(func(ctx context.Context, claims *auth.Claims) error {
+		return auth.NewMissingClaimError("custom:tenantId")
+	})
+
+	tests := []struct {
+		name       string
+		resolver   auth.ClaimsResolver
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "no resolver", wantStatus: http.StatusOK},
+		{name: "tenant derived from the email", resolver: tenantFromEmail, wantStatus: http.StatusOK, wantTenant: "acme.example"},
+		{name: "resolver error rejects the request", resolver: rejectAll, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := emailAuth{groupsAuth: groupsAuth{groups: []string{string(auth.GroupUser)}}, email: "someone@acme.example"}
+			var stored *auth.Claims
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ""))
+			engine.GET("/user", NewAuthMiddleware(authService, tt.resolver).AuthMiddleware(auth.GroupUser), func(c *gin.Context) {
+				stored, _ = c.MustGet("claims").(*auth.Claims)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/user", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if stored == nil || stored.TenantID != tt.wantTenant {
+				t.Errorf("claims in context = %+v, want tenant %q", stored, tt.wantTenant)
+			}
+		})
+	}
+}
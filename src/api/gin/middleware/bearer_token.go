@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrMissingAuthHeader   = app_error.NewApiError(http.StatusUnauthorized, "Missing authorization", "Authorization header is required")
+	ErrMalformedAuthHeader = app_error.NewApiError(http.StatusUnauthorized, "Malformed authorization", "Authorization header must be 'Bearer <token>'")
+)
+
+// BearerToken returns the token from a single "Authorization: Bearer <token>"
+// header. The scheme is matched case-insensitively, but there must be exactly
+// one space before a non-empty token with no whitespace in it.
+func BearerToken(header http.Header) (string, error) {
+	values := header.Values("Authorization")
+	if len(values) == 0 || values[0] == "" {
+		return "", ErrMissingAuthHeader
+	}
+	if len(values) > 1 {
+		return "", ErrMalformedAuthHeader
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", ErrMalformedAuthHeader
+	}
+	if strings.ContainsAny(token, " \t\r\n") {
+		return "", ErrMalformedAuthHeader
+	}
+	return token, nil
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []string
+		wantToken string
+		wantErr   error
+	}{
+		{name: "well formed", values: []string{"Bearer abc.def.ghi"}, wantToken: "abc.def.ghi"},
+		{name: "scheme in any case", values: []string{"bEaReR abc.def.ghi"}, wantToken: "abc.def.ghi"},
+		{name: "missing", wantErr: ErrMissingAuthHeader},
+		{name: "empty header", values: []string{""}, wantErr: ErrMissingAuthHeader},
+		{name: "wrong scheme", values: []string{"Basic dXNlcjpwYXNz"}, wantErr: ErrMalformedAuthHeader},
+		{name: "no token", values: []string{"Bearer"}, wantErr: ErrMalformedAuthHeader},
+		{name: "empty token", values: []string{"Bearer "}, wantErr: ErrMalformedAuthHeader},
+		{name: "two spaces", values: []string{"Bearer  abc.def.ghi"}, wantErr: ErrMalformedAuthHeader},
+		{name: "space in token", values: []string{"Bearer abc def"}, wantErr: ErrMalformedAuthHeader},
+		{name: "two headers", values: []string{"Bearer abc", "Bearer def"}, wantErr: ErrMalformedAuthHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tt.values {
+				header.Add("Authorization", v)
+			}
+			token, err := BearerToken(header)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+// expiringAuth accepts "valid" and reports every other token as expired.
+type expiringAuth struct {
+	auth.AuthService
+}
+
+func (expiringAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	if token != "valid" {
+		return nil, auth.ErrTokenExpired
+	}
+	return &auth.Claims{UserGroups: []string{string(auth.GroupUser)}}, nil
+}
+
+func TestAuthMiddlewareChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		wantStatus    int
+		wantChallenge string
+	}{
+		{name: "valid token", header: "Bearer valid", wantStatus: http.StatusOK},
+		{name: "no credentials", wantStatus: http.StatusUnauthorized, wantChallenge: `Bearer`},
+		{
+			name:          "malformed header",
+			header:        "Token valid",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_request", error_description="The Authorization header is malformed"`,
+		},
+		{
+			name:          "expired token",
+			header:        "Bearer stale",
+			wantStatus:    http.StatusUnauthorized,
+			wantChallenge: `Bearer error="invalid_token", error_description="The access token expired"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ""))
+			engine.GET("/me", NewAuthMiddleware(expiringAuth{}, nil).AuthMiddleware(auth.GroupUser), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.wantChallenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.wantChallenge)
+			}
+		})
+	}
+}
@@ -3,24 +3,73 @@ package middleware
 import (
 	"auth-api/src/pkg/app_error"
 	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func ErrorHandler(log logger.Logger) gin.HandlerFunc {
+const (
+	ErrorFormatJSON    = "json"    // the ApiError is serialized as is
+	ErrorFormatProblem = "problem" // RFC 7807 application/problem+json
+)
+
+// StatusClientClosedRequest is nginx's non-standard status for a request the
+// client gave up on before the response.
+const StatusClientClosedRequest = 499
+
+var ErrRequestCanceled = app_error.NewApiError(StatusClientClosedRequest, "Request canceled", "The client closed the request")
+
+func ErrorHandler(log logger.Logger, format string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 		if len(c.Errors) > 0 {
 			err := c.Errors[0]
+			if ctxErr := contextError(c.Request.Context(), err.Err); ctxErr != nil {
+				log.Debug("Request ended by its context: %v", err.Err)
+				renderError(c, format, ctxErr)
+				c.Abort()
+				return
+			}
 			switch e := err.Err.(type) {
 			case *app_error.ApiError:
-				c.JSON(e.StatusCode, e)
+				renderError(c, format, e)
 			default:
 				log.Error("Error occurred %v", e)
-				c.JSON(http.StatusInternalServerError, map[string]string{"message": e.Error()})
+				renderError(c, format, app_error.NewApiError(http.StatusInternalServerError, e.Error()))
 			}
 			c.Abort()
 		}
 	}
 }
+
+// contextError maps an error caused by the request context ending. Besides
+// errors wrapping context.Canceled or context.DeadlineExceeded, that is any
+// server error while the context is done, since upstream calls often report
+// the cancellation as their own failure. A deadline gets the same 503 as
+// TimeoutMiddleware, whichever of the two notices it first.
+func contextError(ctx context.Context, err error) *app_error.ApiError {
+	cause := err
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		var apiErr *app_error.ApiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return nil
+		}
+		if cause = ctx.Err(); cause == nil {
+			return nil
+		}
+	}
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return ErrUpstreamTimeout
+	}
+	return ErrRequestCanceled
+}
+
+func renderError(c *gin.Context, format string, e *app_error.ApiError) {
+	if format == ErrorFormatProblem {
+		c.Render(e.StatusCode, problemRender{problem: e.ToProblem(c.Request.URL.Path)})
+		return
+	}
+	c.JSON(e.StatusCode, e)
+}
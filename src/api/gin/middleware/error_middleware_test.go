@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upstreamCall stands in for a Cognito or database call: it blocks until the
+// request context ends, then fails the way the SDKs do.
+func upstreamCall(started chan<- struct{}, failure func(ctx context.Context) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		close(started)
+		<-c.Request.Context().Done()
+		c.Error(failure(c.Request.Context()))
+	}
+}
+
+func TestErrorHandlerMapsContextEndingMidCall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	wrapped := func(ctx context.Context) error { return fmt.Errorf("cognito: %w", ctx.Err()) }
+	opaque := func(context.Context) error {
+		return app_error.NewApiError(http.StatusInternalServerError, "Cognito call failed")
+	}
+
+	tests := []struct {
+		name    string
+		failure func(ctx context.Context) error
+		// deadline bounds the request; otherwise the client cancels it once
+		// the call has started.
+		deadline    time.Duration
+		withTimeout bool
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "client cancels", failure: wrapped, wantStatus: StatusClientClosedRequest, wantMessage: "Request canceled"},
+		{name: "client cancels, upstream reports its own error", failure: opaque, wantStatus: StatusClientClosedRequest, wantMessage: "Request canceled"},
+		{name: "deadline", failure: wrapped, deadline: 10 * time.Millisecond, wantStatus: http.StatusServiceUnavailable, wantMessage: "Upstream timeout"},
+		{name: "deadline from the timeout middleware", failure: opaque, deadline: 10 * time.Millisecond, withTimeout: true, wantStatus: http.StatusServiceUnavailable, wantMessage: "Upstream timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ""))
+			if tt.withTimeout {
+				engine.Use(TimeoutMiddleware(tt.deadline, ""))
+			}
+			engine.GET("/call", upstreamCall(started, tt.failure))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.deadline > 0 && !tt.withTimeout {
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+			req := httptest.NewRequest(http.MethodGet, "/call", nil).WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				engine.ServeHTTP(w, req)
+			}()
+			<-started
+			if tt.deadline == 0 {
+				cancel()
+			}
+			<-done
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			var body struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if body.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerFormats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	notFound := app_error.NewApiError(http.StatusNotFound, "User not found", "Field: id")
+	conflict := &app_error.ApiError{StatusCode: http.StatusConflict, Message: "Email already in use", Fields: map[string]string{"email": "taken"}}
+
+	tests := []struct {
+		name            string
+		format          string
+		err             error
+		wantStatus      int
+		wantContentType string
+		want            map[string]interface{}
+	}{
+		{
+			name:            "json",
+			format:          ErrorFormatJSON,
+			err:             notFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json; charset=utf-8",
+			want:            map[string]interface{}{"message": "User not found", "description": "Field: id"},
+		},
+		{
+			name:            "problem",
+			format:          ErrorFormatProblem,
+			err:             notFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: app_error.ProblemContentType,
+			want: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "User not found: Field: id",
+				"instance": "/users/42",
+				"code":     "USER_NOT_FOUND",
+			},
+		},
+		{
+			name:            "problem with fields",
+			format:          ErrorFormatProblem,
+			err:             conflict,
+			wantStatus:      http.StatusConflict,
+			wantContentType: app_error.ProblemContentType,
+			want: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Conflict",
+				"status":   float64(http.StatusConflict),
+				"detail":   "Email already in use",
+				"instance": "/users/42",
+				"code":     "EMAIL_ALREADY_IN_USE",
+				"fields":   map[string]interface{}{"email": "taken"},
+			},
+		},
+		{
+			name:            "problem for an unexpected error",
+			format:          ErrorFormatProblem,
+			err:             fmt.Errorf("boom"),
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: app_error.ProblemContentType,
+			want: map[string]interface{}{
+				"type":     "about:blank",
+				"title":    "Internal Server Error",
+				"status":   float64(http.StatusInternalServerError),
+				"detail":   "boom",
+				"instance": "/users/42",
+				"code":     "BOOM",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, tt.format))
+			engine.GET("/users/:id", func(c *gin.Context) { c.Error(tt.err) })
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if fmt.Sprint(body) != fmt.Sprint(tt.want) {
+				t.Errorf("body = %v, want %v", body, tt.want)
+			}
+		})
+	}
+}
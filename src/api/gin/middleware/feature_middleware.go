@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Signup isn't a feature here: auth.disable_signup turns it off with a 403
+// that invites still get past.
+const (
+	FeatureSessions       = "sessions"
+	FeatureDevices        = "devices"
+	FeatureResetPasswords = "reset_passwords"
+)
+
+var (
+	ErrFeatureNotFound       = app_error.NewApiError(http.StatusNotFound, "Not found", "Resource not found")
+	ErrFeatureNotImplemented = app_error.NewApiError(http.StatusNotImplemented, "Feature disabled", "This feature is disabled")
+)
+
+// Features gates routes behind operator controlled switches. A disabled
+// feature answers 404 to hide the endpoint or 501 to acknowledge it.
+type Features struct {
+	disabled       map[string]struct{}
+	disabledStatus int
+	errorFormat    string
+}
+
+func NewFeatures(disabled []string, disabledStatus int, errorFormat string) *Features {
+	set := make(map[string]struct{}, len(disabled))
+	for _, name := range disabled {
+		set[name] = struct{}{}
+	}
+	return &Features{
+		disabled:       set,
+		disabledStatus: disabledStatus,
+		errorFormat:    errorFormat,
+	}
+}
+
+func (f *Features) Enabled(name string) bool {
+	_, disabled := f.disabled[name]
+	return !disabled
+}
+
+func (f *Features) Require(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if f.Enabled(name) {
+			c.Next()
+			return
+		}
+
+		if f.disabledStatus == http.StatusNotImplemented {
+			renderError(c, f.errorFormat, ErrFeatureNotImplemented)
+		} else {
+			renderError(c, f.errorFormat, ErrFeatureNotFound)
+		}
+		c.Abort()
+	}
+}
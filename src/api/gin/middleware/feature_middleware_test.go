@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFeaturesRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		disabled       []string
+		disabledStatus int
+		wantStatus     int
+		wantMessage    string
+	}{
+		{name: "enabled", disabledStatus: http.StatusNotFound, wantStatus: http.StatusOK},
+		{name: "disabled as not found", disabled: []string{FeatureDevices}, disabledStatus: http.StatusNotFound, wantStatus: http.StatusNotFound, wantMessage: `"Not found"`},
+		{name: "disabled as not implemented", disabled: []string{FeatureDevices}, disabledStatus: http.StatusNotImplemented, wantStatus: http.StatusNotImplemented, wantMessage: `"Feature disabled"`},
+		{name: "another feature disabled", disabled: []string{FeatureSessions}, disabledStatus: http.StatusNotImplemented, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			features := NewFeatures(tt.disabled, tt.disabledStatus, "")
+			reached := false
+			engine := gin.New()
+			engine.GET("/auth/devices", features.Require(FeatureDevices), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/devices", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if reached != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler reached = %v with status %d", reached, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantMessage) {
+				t.Errorf("body = %s, want message %s", w.Body, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Destructive admin actions guarded by RequireReauth.
+const (
+	ActionCreateAdmin    = "create_admin"
+	ActionResetPasswords = "reset_passwords"
+	ActionResetTOTP      = "reset_totp"
+	ActionRemoveMFA      = "remove_mfa"
+	ActionFinalizeUser   = "finalize_user"
+	ActionDisableUser    = "disable_user"
+)
+
+// RequireReauth guards destructive actions by requiring the user to have
+// authenticated within maxAge. It relies on auth_time, so a token obtained via
+// refresh doesn't count as a fresh sign in. A stale token gets REAUTH_REQUIRED
+// with the action, so the UI can re-prompt and retry. Must run after
+// AuthMiddleware.
+func RequireReauth(action string, maxAge time.Duration) gin.HandlerFunc {
+	staleErr := auth.NewReauthRequiredError(action)
+	return func(c *gin.Context) {
+		value, exists := c.Get("claims")
+		claims, ok := value.(*auth.Claims)
+		if !exists || !ok {
+			c.Error(app_error.NewApiError(401, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		if claims.AuthTime.IsZero() || time.Since(claims.AuthTime) > maxAge {
+			c.Error(staleErr)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireReauthUsesAuthTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		claims          *auth.Claims
+		wantStatus      int
+		wantMessage     string
+		wantDescription string
+	}{
+		{
+			name:       "signed in within the window",
+			claims:     &auth.Claims{AuthTime: now.Add(-time.Minute)},
+			wantStatus: http.StatusOK,
+		},
+		{
+			// A refresh moves iat but not auth_time, so a just refreshed
+			// token from an old sign in is still stale.
+			name:            "refreshed token from an old sign in",
+			claims:          &auth.Claims{AuthTime: now.Add(-time.Hour)},
+			wantStatus:      http.StatusUnauthorized,
+			wantMessage:     "Reauth required",
+			wantDescription: "Action: " + ActionResetTOTP,
+		},
+		{
+			name:            "token without auth_time",
+			claims:          &auth.Claims{},
+			wantStatus:      http.StatusUnauthorized,
+			wantMessage:     "Reauth required",
+			wantDescription: "Action: " + ActionResetTOTP,
+		},
+		{
+			name:        "no claims",
+			wantStatus:  http.StatusUnauthorized,
+			wantMessage: "Unauthorized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ""))
+			engine.POST("/reset-totp", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("claims", tt.claims)
+				}
+			}, RequireReauth(ActionResetTOTP, 5*time.Minute), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/reset-totp", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			var body struct {
+				Message     string `json:"message"`
+				Description string `json:"description"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if body.Message != tt.wantMessage || body.Description != tt.wantDescription {
+				t.Errorf("body = %+v, want %q %q", body, tt.wantMessage, tt.wantDescription)
+			}
+		})
+	}
+}
+
+// signedInAuth accepts any token as an admin who signed in at authTime.
+type signedInAuth struct {
+	auth.AuthService
+	authTime time.Time
+}
+
+func (a signedInAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	return &auth.Claims{Id: "admin", UserGroups: []string{string(auth.GroupAdmin)}, AuthTime: a.authTime}, nil
+}
+
+func TestStaleAdminMustReauthBeforeDestructiveActions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		action   string
+		signedIn time.Duration
+		wantRun  bool
+	}{
+		{name: "fresh admin creates an admin", action: ActionCreateAdmin, signedIn: time.Minute, wantRun: true},
+		{name: "stale admin creates an admin", action: ActionCreateAdmin, signedIn: time.Hour},
+		{name: "stale admin resets passwords", action: ActionResetPasswords, signedIn: time.Hour},
+		{name: "stale admin resets TOTP", action: ActionResetTOTP, signedIn: time.Hour},
+		{name: "stale admin removes MFA", action: ActionRemoveMFA, signedIn: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := NewAuthMiddleware(signedInAuth{authTime: time.Now().Add(-tt.signedIn)}, nil)
+			ran := false
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ErrorFormatProblem))
+			engine.POST("/destructive", authMiddleware.AuthMiddleware(auth.GroupAdmin), RequireReauth(tt.action, 5*time.Minute), func(c *gin.Context) {
+				ran = true
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/destructive", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if ran != tt.wantRun {
+				t.Fatalf("action ran = %v, want %v", ran, tt.wantRun)
+			}
+			if tt.wantRun {
+				return
+			}
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+			}
+			var body struct {
+				Code   string `json:"code"`
+				Detail string `json:"detail"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %q: %v", w.Body, err)
+			}
+			if body.Code != "REAUTH_REQUIRED" || !strings.HasSuffix(body.Detail, "Action: "+tt.action) {
+				t.Errorf("body = %s, want REAUTH_REQUIRED for %s", w.Body, tt.action)
+			}
+		})
+	}
+}
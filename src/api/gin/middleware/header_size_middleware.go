@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrAuthHeaderTooLarge = app_error.NewApiError(http.StatusRequestHeaderFieldsTooLarge, "Auth header too large", "The Authorization header exceeds the allowed size")
+
+// MaxAuthHeaderSize rejects requests whose Authorization header is longer than
+// maxBytes before anything tries to parse the token. Zero disables the check.
+func MaxAuthHeaderSize(maxBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 {
+			c.Next()
+			return
+		}
+		size := 0
+		for _, value := range c.Request.Header.Values("Authorization") {
+			size += len(value)
+		}
+		if size > maxBytes {
+			c.Error(ErrAuthHeaderTooLarge)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaxAuthHeaderSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		maxBytes   int
+		headers    []string
+		wantStatus int
+	}{
+		{name: "no header", maxBytes: 16, wantStatus: http.StatusOK},
+		{name: "at the limit", maxBytes: 16, headers: []string{"Bearer " + strings.Repeat("a", 9)}, wantStatus: http.StatusOK},
+		{name: "oversized", maxBytes: 16, headers: []string{"Bearer " + strings.Repeat("a", 10)}, wantStatus: http.StatusRequestHeaderFieldsTooLarge},
+		{name: "split across repeated headers", maxBytes: 16, headers: []string{"Bearer aaaaa", "Bearer aaaaa"}, wantStatus: http.StatusRequestHeaderFieldsTooLarge},
+		{name: "check disabled", maxBytes: 0, headers: []string{"Bearer " + strings.Repeat("a", 1<<16)}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ErrorFormatProblem), MaxAuthHeaderSize(tt.maxBytes))
+			engine.GET("/me", func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			for _, header := range tt.headers {
+				req.Header.Add("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if reached != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler reached = %v with status %d", reached, w.Code)
+			}
+			if tt.wantStatus != http.StatusOK && !strings.Contains(w.Body.String(), `"code":"AUTH_HEADER_TOO_LARGE"`) {
+				t.Errorf("body = %s, want code AUTH_HEADER_TOO_LARGE", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HttpsModeRedirect = "redirect" // plain HTTP requests are redirected to https
+	HttpsModeReject   = "reject"   // plain HTTP requests get a 400
+)
+
+var ErrHttpsRequired = app_error.NewApiError(http.StatusBadRequest, "HTTPS required", "Requests must be made over HTTPS")
+
+type Https struct {
+	Mode                string
+	TrustForwardedProto bool
+	ExemptPaths         []string
+	ErrorFormat         string
+	// trustedProxies are the peers whose X-Forwarded-Proto is believed.
+	trustedProxies []*net.IPNet
+}
+
+// NewHttps takes the same trusted proxies as the engine, as addresses or
+// CIDRs. X-Forwarded-Proto from any other peer is ignored, since a client can
+// send it over plain HTTP.
+func NewHttps(mode string, trustForwardedProto bool, trustedProxies []string, errorFormat string, exemptPaths ...string) (*Https, error) {
+	proxies, err := parseProxies(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &Https{
+		Mode:                mode,
+		TrustForwardedProto: trustForwardedProto,
+		ExemptPaths:         exemptPaths,
+		ErrorFormat:         errorFormat,
+		trustedProxies:      proxies,
+	}, nil
+}
+
+func parseProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		nets = append(nets, cidr)
+	}
+	return nets, nil
+}
+
+func (h *Https) HttpsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.isSecure(c) || h.isExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		if h.Mode == HttpsModeRedirect && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) {
+			target := "https://" + c.Request.Host + c.Request.URL.RequestURI()
+			c.Redirect(http.StatusMovedPermanently, target)
+			c.Abort()
+			return
+		}
+
+		// Redirecting a POST would have the client resend credentials in the
+		// clear first, so anything but GET/HEAD is rejected.
+		renderError(c, h.ErrorFormat, ErrHttpsRequired)
+		c.Abort()
+	}
+}
+
+func (h *Https) isSecure(c *gin.Context) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	if !h.TrustForwardedProto || !h.fromTrustedProxy(c.RemoteIP()) {
+		return false
+	}
+	// With several proxies the header is a comma separated list and the first
+	// entry is the one the client used.
+	proto := strings.Split(c.GetHeader("X-Forwarded-Proto"), ",")[0]
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+func (h *Https) fromTrustedProxy(remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range h.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Https) isExempt(path string) bool {
+	for _, p := range h.ExemptPaths {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
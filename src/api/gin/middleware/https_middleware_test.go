@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHttpsTrustsForwardedProtoOnlyFromProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name                string
+		trustForwardedProto bool
+		trustedProxies      []string
+		remoteAddr          string
+		forwardedProto      string
+		wantStatus          int
+	}{
+		{
+			name:           "header ignored when not trusted",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedProto: "https",
+			wantStatus:     http.StatusBadRequest,
+		},
+		{
+			name:                "spoofed by a client",
+			trustForwardedProto: true,
+			trustedProxies:      []string{"10.0.0.0/8"},
+			remoteAddr:          "198.51.100.9:4000",
+			forwardedProto:      "https",
+			wantStatus:          http.StatusBadRequest,
+		},
+		{
+			name:                "no proxies configured",
+			trustForwardedProto: true,
+			remoteAddr:          "10.0.0.5:4000",
+			forwardedProto:      "https",
+			wantStatus:          http.StatusBadRequest,
+		},
+		{
+			name:                "from a trusted proxy",
+			trustForwardedProto: true,
+			trustedProxies:      []string{"10.0.0.0/8"},
+			remoteAddr:          "10.0.0.5:4000",
+			forwardedProto:      "https",
+			wantStatus:          http.StatusOK,
+		},
+		{
+			name:                "from a trusted proxy address",
+			trustForwardedProto: true,
+			trustedProxies:      []string{"10.0.0.5"},
+			remoteAddr:          "10.0.0.5:4000",
+			forwardedProto:      "https, http",
+			wantStatus:          http.StatusOK,
+		},
+		{
+			name:                "plain http through a trusted proxy",
+			trustForwardedProto: true,
+			trustedProxies:      []string{"10.0.0.0/8"},
+			remoteAddr:          "10.0.0.5:4000",
+			forwardedProto:      "http",
+			wantStatus:          http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			https, err := NewHttps(HttpsModeReject, tt.trustForwardedProto, tt.trustedProxies, ErrorFormatJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+			engine := gin.New()
+			engine.Use(https.HttpsMiddleware())
+			engine.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewHttpsRejectsInvalidProxies(t *testing.T) {
+	if _, err := NewHttps(HttpsModeReject, true, []string{"not-an-ip"}, ErrorFormatJSON); err == nil {
+		t.Error("NewHttps accepted an invalid proxy")
+	}
+}
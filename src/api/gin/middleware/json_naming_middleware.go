@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	JSONNamingCamel = "camel" // the field names the outputs are tagged with
+	JSONNamingSnake = "snake"
+)
+
+type jsonNamingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *jsonNamingWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *jsonNamingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Written keeps gin from treating the response as already sent while it is
+// only buffered.
+func (w *jsonNamingWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// JSONNaming rewrites the field names of JSON responses to the naming
+// strategy, keeping their order. With "camel", or anything it doesn't know,
+// responses pass through untouched. Responses are buffered, so it doesn't
+// belong in front of streaming routes.
+func JSONNaming(strategy string) gin.HandlerFunc {
+	if strategy != JSONNamingSnake {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		writer := &jsonNamingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if len(body) == 0 && !c.Writer.Written() {
+			// Nothing was sent, typically because the handler only recorded
+			// an error. Writing here would commit a 200 before the error
+			// handler gets to render it.
+			return
+		}
+		contentType := c.Writer.Header().Get("Content-Type")
+		if len(body) > 0 && strings.HasPrefix(contentType, "application/json") {
+			if renamed, err := renameJSONFields(body, snakeCase); err == nil {
+				body = renamed
+			}
+		}
+		c.Writer.Header().Del("Content-Length")
+		if c.Writer.Status() != http.StatusNoContent && c.Writer.Status() != http.StatusNotModified {
+			c.Writer.Write(body)
+		} else {
+			c.Writer.WriteHeaderNow()
+		}
+	}
+}
+
+// renameJSONFields re-encodes data token by token, renaming object keys.
+// Numbers are kept as written.
+func renameJSONFields(data []byte, rename func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out bytes.Buffer
+	if err := renameJSONValue(dec, &out, rename); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errTrailingJSON
+	}
+	return out.Bytes(), nil
+}
+
+var errTrailingJSON = errors.New("trailing data after JSON value")
+
+func renameJSONValue(dec *json.Decoder, out *bytes.Buffer, rename func(string) string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			out.WriteByte('{')
+			for i := 0; dec.More(); i++ {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				key, _ := json.Marshal(rename(keyToken.(string)))
+				out.Write(key)
+				out.WriteByte(':')
+				if err := renameJSONValue(dec, out, rename); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			out.WriteByte('}')
+		case '[':
+			out.WriteByte('[')
+			for i := 0; dec.More(); i++ {
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				if err := renameJSONValue(dec, out, rename); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return err
+			}
+			out.WriteByte(']')
+		}
+	default:
+		value, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		out.Write(value)
+	}
+	return nil
+}
+
+// snakeCase turns "accessTokenExpiresIn" into "access_token_expires_in" and
+// keeps acronyms together, so "URLExpiry" becomes "url_expiry".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestJSONNaming(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:     "snake renames nested keys in order",
+			strategy: JSONNamingSnake,
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"accessToken": "a", "user": gin.H{"emailVerified": true}})
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"access_token":"a","user":{"email_verified":true}}`,
+		},
+		{
+			name:     "camel passes through",
+			strategy: JSONNamingCamel,
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"accessToken": "a"})
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"accessToken":"a"}`,
+		},
+		{
+			name:     "error recorded by the handler keeps its status",
+			strategy: JSONNamingSnake,
+			handler: func(c *gin.Context) {
+				c.Error(app_error.NewApiError(http.StatusUnauthorized, "Unauthorized"))
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"message":"Unauthorized"}`,
+		},
+		{
+			name:     "no content",
+			strategy: JSONNamingSnake,
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusNoContent, gin.H{})
+			},
+			wantStatus: http.StatusNoContent,
+			wantBody:   ``,
+		},
+		{
+			name:     "non JSON body is untouched",
+			strategy: JSONNamingSnake,
+			handler: func(c *gin.Context) {
+				c.String(http.StatusOK, `{"accessToken":"a"}`)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"accessToken":"a"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(ErrorHandler(&recordingLogger{}, ""))
+			group := engine.Group("/auth")
+			group.Use(JSONNaming(tt.strategy))
+			group.GET("/test", tt.handler)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/test", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAccessLogRedactsSnakeCaseBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := &recordingLogger{}
+	engine := gin.New()
+	engine.Use(NewAccessLog(log, "error", true, 4096).AccessLogMiddleware())
+	group := engine.Group("/auth")
+	group.Use(JSONNaming(JSONNamingSnake))
+	group.POST("/login", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"accessToken":    "secret-access",
+			"idToken":        "secret-id",
+			"refreshToken":   "secret-refresh",
+			"secretCode":     "secret-totp",
+			"devicePassword": "secret-device",
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"a@b.c","new_password":"secret-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(log.errors) != 1 {
+		t.Fatalf("got %d access log lines, want 1", len(log.errors))
+	}
+	if strings.Contains(log.errors[0], "secret-") {
+		t.Errorf("access log leaks a secret: %s", log.errors[0])
+	}
+	if !strings.Contains(log.errors[0], `"access_token":"[REDACTED]"`) {
+		t.Errorf("access log has no redacted access_token: %s", log.errors[0])
+	}
+}
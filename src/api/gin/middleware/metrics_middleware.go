@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/metrics"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests no route matched, so arbitrary paths can't
+// each open a new series.
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels methods outside the standard set, which gin still hands
+// to the middleware when no route matches.
+const otherMethod = "OTHER"
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+type HTTPMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+func NewHTTPMetrics(registry *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.NewCounter("http_requests_total", "HTTP requests served.", "route", "method", "status"),
+		duration: registry.NewHistogram("http_request_duration_seconds", "HTTP request latency.", metrics.DefaultBuckets, "route", "method"),
+	}
+}
+
+// MetricsMiddleware labels requests by the matched route template, e.g.
+// "/api/v1/auth/admin/users/:username", never by the raw path.
+func (m *HTTPMetrics) MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = otherMethod
+		}
+		m.requests.Inc(route, method, strconv.Itoa(c.Writer.Status()))
+		m.duration.Observe(time.Since(start).Seconds(), route, method)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsMiddlewareLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := metrics.NewRegistry()
+	httpMetrics := NewHTTPMetrics(registry)
+	engine := gin.New()
+	engine.Use(httpMetrics.MetricsMiddleware())
+	engine.GET("/api/v1/auth/admin/users/:username", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/auth/admin/users/first@example.com"},
+		{http.MethodGet, "/api/v1/auth/admin/users/second@example.com"},
+		{http.MethodGet, "/no/such/route"},
+		{"PROPFIND", "/no/such/route"},
+		{"X-" + strings.Repeat("A", 64), "/api/v1/auth/admin/users/first@example.com"},
+	}
+	for _, r := range requests {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.path, nil))
+	}
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   float64
+	}{
+		{name: "route template, not the raw path", labels: []string{"/api/v1/auth/admin/users/:username", http.MethodGet, "200"}, want: 2},
+		{name: "unmatched path", labels: []string{unmatchedRoute, http.MethodGet, "404"}, want: 1},
+		{name: "unknown methods share one label", labels: []string{unmatchedRoute, otherMethod, "404"}, want: 2},
+		{name: "raw method never becomes a label", labels: []string{unmatchedRoute, "PROPFIND", "404"}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := httpMetrics.requests.Value(tt.labels...); got != tt.want {
+				t.Errorf("http_requests_total%v = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+
+	if got := httpMetrics.duration.Count("/api/v1/auth/admin/users/:username", http.MethodGet); got != 2 {
+		t.Errorf("http_request_duration_seconds count = %d, want 2", got)
+	}
+}
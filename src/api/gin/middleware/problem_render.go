@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"encoding/json"
+	"net/http"
+)
+
+// problemRender writes a problem document with the application/problem+json
+// content type, which gin's JSON renderer would otherwise overwrite.
+type problemRender struct {
+	problem *app_error.Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	body, err := json.Marshal(r.problem)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", app_error.ProblemContentType)
+}
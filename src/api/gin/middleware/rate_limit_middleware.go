@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrTooManyRequests = app_error.NewApiError(http.StatusTooManyRequests, "Too many requests", "Please try again later")
+
+type rateLimitWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows each client IP a fixed number of requests per window. The
+// IP is gin's ClientIP, which only honours X-Forwarded-For from the trusted
+// proxies. The counters live in memory, so the limit applies per instance.
+type RateLimit struct {
+	limit   int
+	window  time.Duration
+	mu      sync.Mutex
+	clients map[string]*rateLimitWindow
+}
+
+func NewRateLimit(limit int, window time.Duration) *RateLimit {
+	return &RateLimit{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateLimitWindow),
+	}
+}
+
+func (rl *RateLimit) RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl.limit <= 0 {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter := rl.allow(c.ClientIP(), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.Error(ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func (rl *RateLimit) allow(client string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Drop finished windows once the map grows so idle clients don't pile up.
+	if len(rl.clients) > 10000 {
+		for key, w := range rl.clients {
+			if now.Sub(w.start) >= rl.window {
+				delete(rl.clients, key)
+			}
+		}
+	}
+
+	w, ok := rl.clients[client]
+	if !ok || now.Sub(w.start) >= rl.window {
+		rl.clients[client] = &rateLimitWindow{start: now, count: 1}
+		return true, 0
+	}
+	if w.count >= rl.limit {
+		return false, rl.window - now.Sub(w.start)
+	}
+	w.count++
+	return true, 0
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitKeysOnTrustedClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		// forwardedFor is sent on each request in turn.
+		forwardedFor []string
+		wantStatus   []int
+	}{
+		{
+			name:         "spoofed header from an untrusted peer",
+			remoteAddr:   "198.51.100.9:4000",
+			forwardedFor: []string{"203.0.113.1", "203.0.113.2"},
+			wantStatus:   []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:           "clients behind a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.5:4000",
+			forwardedFor:   []string{"203.0.113.1", "203.0.113.2", "203.0.113.1"},
+			wantStatus:     []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:           "header from a peer outside the trusted range",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "198.51.100.9:4000",
+			forwardedFor:   []string{"203.0.113.1", "203.0.113.2"},
+			wantStatus:     []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			if err := engine.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			engine.Use(func(c *gin.Context) {
+				c.Next()
+				if len(c.Errors) > 0 {
+					c.Status(http.StatusTooManyRequests)
+				}
+			})
+			engine.Use(NewRateLimit(1, time.Minute).RateLimitMiddleware())
+			engine.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			for i, forwardedFor := range tt.forwardedFor {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+				if w.Code != tt.wantStatus[i] {
+					t.Errorf("request %d from %s: status = %d, want %d", i, forwardedFor, w.Code, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
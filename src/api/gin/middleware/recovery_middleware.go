@@ -1,17 +1,53 @@
 package middleware
 
 import (
+	"auth-api/src/pkg/app_error"
 	"auth-api/src/pkg/logger"
+	"errors"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RecoveryHandler(log logger.Logger) gin.RecoveryFunc {
-	return func(c *gin.Context, err any) {
+// ErrInternal is all a client sees of a panic; the details only go to the log.
+var ErrInternal = app_error.NewApiError(http.StatusInternalServerError, "Internal error", "Something went wrong, please try again later")
+
+// handlerPanic is a panic recovered on another goroutine, with the stack it
+// had there, raised again for Recovery to handle.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+// Recovery turns a panic in a handler into a 500 and logs it with the stack
+// and request id. http.ErrAbortHandler is re-panicked so net/http still drops
+// the connection as it was asked to. When the response had already started
+// there is nothing clean left to send, so it's only cut short.
+func Recovery(log logger.Logger, format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			stack := debug.Stack()
+			if hp, ok := recovered.(*handlerPanic); ok {
+				recovered, stack = hp.value, hp.stack
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			log.Error("Recovered from panic: %v request_id=%s method=%s path=%s\n%s",
+				recovered, getRequestID(c), c.Request.Method, c.Request.URL.Path, stack)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			renderError(c, format, ErrInternal)
+			c.Abort()
+		}()
 		c.Next()
-		log.Error("Error occurred %v", err)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, map[string]string{"message": "Service Unavailable"})
-		c.Abort()
 	}
 }
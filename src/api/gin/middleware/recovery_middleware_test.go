@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Info(string, ...interface{})    {}
+func (l *recordingLogger) Warning(string, ...interface{}) {}
+func (l *recordingLogger) Debug(string, ...interface{})   {}
+func (l *recordingLogger) Error(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, v...))
+}
+
+func newRecoveryEngine(log *recordingLogger, timeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RequestID(), Recovery(log, ""))
+	group := engine.Group("/api")
+	group.Use(TimeoutMiddleware(timeout, ""))
+	group.GET("/timed", handler)
+	engine.GET("/plain", handler)
+	return engine
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		timeout     time.Duration
+		handler     gin.HandlerFunc
+		wantStatus  int
+		wantMessage string
+		wantLogged  bool
+	}{
+		{
+			name: "panic in handler",
+			path: "/plain",
+			handler: func(c *gin.Context) {
+				var user *struct{ Name string }
+				c.String(http.StatusOK, user.Name)
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Internal error",
+			wantLogged:  true,
+		},
+		{
+			name:    "panic inside the timeout goroutine",
+			path:    "/api/timed",
+			timeout: time.Second,
+			handler: func(c *gin.Context) {
+				var counts map[string]int
+				counts["login"]++
+			},
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Internal error",
+			wantLogged:  true,
+		},
+		{
+			name:    "panic after the timeout answered",
+			path:    "/api/timed",
+			timeout: 10 * time.Millisecond,
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				panic("late")
+			},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantMessage: "Upstream timeout",
+			wantLogged:  true,
+		},
+		{
+			name: "panic after the response started",
+			path: "/plain",
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusAccepted)
+				c.Writer.WriteHeaderNow()
+				panic("half way")
+			},
+			wantStatus: http.StatusAccepted,
+			wantLogged: true,
+		},
+		{
+			name:       "no panic",
+			path:       "/plain",
+			handler:    func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &recordingLogger{}
+			engine := newRecoveryEngine(log, tt.timeout, tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(RequestIDHeader, "req-123")
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantMessage != "" {
+				var body struct {
+					Message string `json:"message"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("body %q is not JSON: %v", w.Body.String(), err)
+				}
+				if body.Message != tt.wantMessage {
+					t.Errorf("message = %q, want %q", body.Message, tt.wantMessage)
+				}
+				if strings.Contains(w.Body.String(), "goroutine") {
+					t.Errorf("body leaks the stack: %s", w.Body.String())
+				}
+			}
+
+			logged := len(log.errors) > 0
+			if logged != tt.wantLogged {
+				t.Fatalf("logged = %v, want %v (%v)", logged, tt.wantLogged, log.errors)
+			}
+			if logged && !strings.Contains(log.errors[0], "request_id=req-123") {
+				t.Errorf("log line has no request id: %s", log.errors[0])
+			}
+		})
+	}
+}
+
+func TestRecoveryRepanicsAbortHandler(t *testing.T) {
+	log := &recordingLogger{}
+	engine := newRecoveryEngine(log, time.Second, func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	for _, path := range []string{"/plain", "/api/timed"} {
+		t.Run(path, func(t *testing.T) {
+			defer func() {
+				if recovered := recover(); recovered != http.ErrAbortHandler {
+					t.Fatalf("recovered %v, want http.ErrAbortHandler", recovered)
+				}
+			}()
+			engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+	requestIDKey    = "request_id"
+	maxRequestIDLen = 128
+)
+
+// RequestID keeps the caller's X-Request-Id, or makes one up, and echoes it
+// back. It goes first so the access log and recovery both see the same id.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLen {
+			requestID = uuid.NewString()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func getRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
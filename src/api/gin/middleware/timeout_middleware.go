@@ -1,40 +1,138 @@
 package middleware
 
 import (
+	"auth-api/src/pkg/app_error"
 	"context"
+	"encoding/json"
 	"net/http"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+var ErrUpstreamTimeout = app_error.NewApiError(http.StatusServiceUnavailable, "Upstream timeout", "The request took too long to complete")
+
+// timeoutWriter drops anything the handler writes once the deadline has
+// passed, even if the handler gets there before the timeout response does.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	mu       sync.Mutex
+	timedOut bool
+	dropped  bool
+}
+
+// drop reports whether a write has to be dropped. The caller holds mu.
+func (w *timeoutWriter) drop() bool {
+	if w.timedOut || w.ctx.Err() == context.DeadlineExceeded {
+		w.dropped = true
+	}
+	return w.dropped
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.drop() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.drop() {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.drop() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) droppedWrites() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// timeout writes the 503 response unless the handler already started one.
+func (w *timeoutWriter) timeout(format string, instance string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	if w.ResponseWriter.Written() {
+		return
+	}
+	var body []byte
+	if format == ErrorFormatProblem {
+		body, _ = json.Marshal(ErrUpstreamTimeout.ToProblem(instance))
+		w.ResponseWriter.Header().Set("Content-Type", app_error.ProblemContentType)
+	} else {
+		body, _ = json.Marshal(ErrUpstreamTimeout)
+		w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.ResponseWriter.WriteHeader(ErrUpstreamTimeout.StatusCode)
+	w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+}
+
+// TimeoutMiddleware bounds the time a handler may take. When the deadline is
+// hit the client gets a 503 UPSTREAM_TIMEOUT right away and the request context
+// is cancelled so in-flight upstream calls give up.
+func TimeoutMiddleware(timeout time.Duration, errorFormat string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
+		tw := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = tw
+		c.Request = c.Request.WithContext(ctx)
+
 		done := make(chan struct{})
+		var panicked *handlerPanic
 
 		go func() {
-			c.Request = c.Request.WithContext(ctx)
+			defer close(done)
+			// Recovery can't see a panic on this goroutine, so it's carried
+			// back and raised again on the request's own.
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicked = &handlerPanic{value: recovered, stack: debug.Stack()}
+				}
+			}()
 			c.Next()
-			done <- struct{}{}
 		}()
 
 		select {
 		case <-done:
-			return
-		case <-ctx.Done():
-			switch ctx.Err() {
-			case context.DeadlineExceeded:
-				c.JSON(http.StatusRequestTimeout, gin.H{"message": "Request timeout"})
-				c.Abort()
-				return
-			default:
-				c.JSON(http.StatusInternalServerError, gin.H{"message": "Internal server error"})
+			// The handler finished, but past the deadline, so what it wrote
+			// was dropped.
+			if tw.droppedWrites() {
+				tw.timeout(errorFormat, c.Request.URL.Path)
 				c.Abort()
-				return
 			}
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				tw.timeout(errorFormat, c.Request.URL.Path)
+			}
+			cancel()
+			// The context must not be handed back to gin while the handler
+			// still uses it.
+			<-done
+			c.Abort()
+		}
+		if panicked != nil {
+			panic(panicked)
 		}
 	}
 }
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"auth-api/src/pkg/app_error"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		work            time.Duration
+		format          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantCtxErr      error
+	}{
+		{name: "fast handler", work: 0, wantStatus: http.StatusOK, wantBody: `"done"`},
+		{name: "slow handler", work: time.Second, wantStatus: http.StatusServiceUnavailable, wantContentType: "application/json", wantBody: `"Upstream timeout"`, wantCtxErr: context.DeadlineExceeded},
+		{name: "slow handler, problem format", work: time.Second, format: ErrorFormatProblem, wantStatus: http.StatusServiceUnavailable, wantContentType: app_error.ProblemContentType, wantBody: `"UPSTREAM_TIMEOUT"`, wantCtxErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctxErr := make(chan error, 1)
+			engine := gin.New()
+			engine.Use(TimeoutMiddleware(20*time.Millisecond, tt.format))
+			engine.GET("/slow", func(c *gin.Context) {
+				// A slow upstream call that honours cancellation.
+				select {
+				case <-time.After(tt.work):
+				case <-c.Request.Context().Done():
+				}
+				ctxErr <- c.Request.Context().Err()
+				c.JSON(http.StatusOK, gin.H{"status": "done"})
+			})
+
+			start := time.Now()
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("answered after %s, want it bounded by the timeout", elapsed)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.wantContentType) {
+				t.Errorf("Content-Type = %q, want %q", w.Header().Get("Content-Type"), tt.wantContentType)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", w.Body, tt.wantBody)
+			}
+			if tt.wantStatus != http.StatusOK && strings.Contains(w.Body.String(), "done") {
+				t.Errorf("body = %s, the handler's late write got through", w.Body)
+			}
+			if err := <-ctxErr; err != tt.wantCtxErr {
+				t.Errorf("handler context error = %v, want %v", err, tt.wantCtxErr)
+			}
+		})
+	}
+}
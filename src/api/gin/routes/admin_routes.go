@@ -4,15 +4,31 @@ import (
 	"auth-api/src/api/gin/handlers"
 	"auth-api/src/api/gin/middleware"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
-	"time"
 )
 
 func (r *routes) configAdminRoutes() {
-	handler := handlers.NewAdminHandler(r.factory.UseCases.UserManager.Admin)
+	handler := handlers.NewAdminHandler(r.factory.UseCases.UserManager.Admin, r.config.Api.Export.Delivery, r.config.Auth.CaseSensitiveUsernames)
 	adminGroup := r.gin.Group("/admin")
-	adminGroup.Use(middleware.TimeoutMiddleware(30 * time.Second))
+	adminGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.Admin, r.config.Api.ErrorFormat))
 
-	adminGroup.PATCH("/", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.Update())
-	adminGroup.POST("/register", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.Register())
+	adminGroup.PATCH("", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.Update())
+	adminGroup.POST("/register", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), middleware.RequireReauth(middleware.ActionCreateAdmin, r.config.Auth.StepUpMaxAge), handler.Register())
+
+	// Managing other admins sits with the rest of the admin API under /auth.
+	adminsGroup := r.gin.Group("/auth/admin/admins")
+	adminsGroup.Use(middleware.JSONNaming(r.config.Api.JSONNaming))
+	adminsGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.Admin, r.config.Api.ErrorFormat))
+	adminsGroup.PATCH("/:id", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.UpdateByID())
+
+	resetPasswordsGroup := r.gin.Group("/auth/admin/reset-passwords")
+	resetPasswordsGroup.Use(r.features.Require(middleware.FeatureResetPasswords))
+	resetPasswordsGroup.Use(middleware.JSONNaming(r.config.Api.JSONNaming))
+	resetPasswordsGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.Admin, r.config.Api.ErrorFormat)) // the job itself runs in the background
+	resetPasswordsGroup.POST("", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), middleware.RequireReauth(middleware.ActionResetPasswords, r.config.Auth.StepUpMaxAge), handler.ResetPasswords())
+	resetPasswordsGroup.GET("/:id", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.ResetPasswordsJob())
+
+	exportGroup := r.gin.Group("/admin/users/export")
+	exportGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.Export, r.config.Api.ErrorFormat)) // pages through the whole pool
+	exportGroup.GET("", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.ExportUsers())
 
 }
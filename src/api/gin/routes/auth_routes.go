@@ -4,36 +4,72 @@ import (
 	"auth-api/src/api/gin/handlers"
 	"auth-api/src/api/gin/middleware"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
-	"time"
 )
 
 func (r *routes) configAuthRoutes() {
-	handler := handlers.NewAuthHandler(r.factory.UseCases.UserManager.Auth)
+	handler := handlers.NewAuthHandler(r.factory.UseCases.UserManager.Auth, r.config.Api.SessionCookie, r.config.Api.RefreshToken, r.config.Api.TokenDelivery, r.config.Auth.CaseSensitiveUsernames)
 	authGroup := r.gin.Group("/auth")
-	authGroup.Use(middleware.TimeoutMiddleware(30 * time.Second))
+	authGroup.Use(middleware.JSONNaming(r.config.Api.JSONNaming))
+	authGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.Auth, r.config.Api.ErrorFormat))
 
 	authGroup.POST("/login", handler.Login())
 	authGroup.POST("/logout", handler.Logout())
 	authGroup.POST("/refresh", handler.RefreshToken())
+	authGroup.POST("/refresh/id-token", handler.RefreshIdToken())
+	authGroup.POST("/refresh/batch", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.RefreshTokenBatch())
+	authGroup.POST("/authorize", handler.Authorize())
+
+	validatePasswordLimit := middleware.NewRateLimit(r.config.Api.RateLimits.ValidatePassword.Limit, r.config.Api.RateLimits.ValidatePassword.Window)
+	authGroup.GET("/validate-password", validatePasswordLimit.RateLimitMiddleware(), handler.ValidatePassword())
+	authGroup.POST("/validate-password", validatePasswordLimit.RateLimitMiddleware(), handler.ValidatePassword())
 	authGroup.POST("/confirm", handler.ConfirmSignUp())
 	authGroup.POST("/send-confirmation-code", handler.SendConfirmationCode())
 	authGroup.POST("/password/forget", handler.SendForgotPasswordCode())
 	authGroup.POST("/password/reset", handler.ResetPassword())
 	authGroup.POST("/password/change", handler.ChangePassword())
 	authGroup.POST("/password/set", handler.SetPassword())
+	authGroup.POST("/devices/confirm", r.features.Require(middleware.FeatureDevices), handler.ConfirmDevice())
+	authGroup.GET("/devices", r.features.Require(middleware.FeatureDevices), handler.ListDevices())
+
+	sessionGroup := authGroup.Group("/session")
+	sessionGroup.Use(r.features.Require(middleware.FeatureSessions))
+	sessionGroup.POST("", handler.CreateSession())
+	sessionGroup.GET("", handler.GetSession())
+	sessionGroup.DELETE("", handler.DeleteSession())
 
 	mfaGroup := authGroup.Group("/mfa")
-	mfaGroup.POST("/", handler.AddMfa())
+	mfaGroup.POST("", handler.AddMfa())
+	mfaGroup.GET("/setup", handler.SetupMfa())
+	mfaGroup.POST("/setup", handler.SetupMfa())
 	mfaGroup.POST("/verify", handler.VerifyMfa())
 	mfaGroup.POST("/remove", handler.RemoveMfa())
-	mfaGroup.POST("/admin/remove", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.AdminRemoveMfa())
+	mfaGroup.POST("/admin/remove", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), middleware.RequireReauth(middleware.ActionRemoveMFA, r.config.Auth.StepUpMaxAge), handler.AdminRemoveMfa())
+	mfaGroup.POST("/enroll", handler.CompleteMfaEnrollment())
 	mfaGroup.POST("/activate", r.authMiddleware.AuthMiddleware(auth.GroupUser), handler.ActivateMfa())
 
+	authGroup.GET("/admin/stats", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.Stats())
+	authGroup.POST("/admin/invites", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.AdminIssueInvite())
+
+	adminUsersGroup := authGroup.Group("/admin/users")
+	adminUsersGroup.Use(r.authMiddleware.AuthMiddleware(auth.GroupAdmin))
+	adminUsersGroup.GET("", handler.AdminListUsers())
+	adminUsersGroup.GET("/:username", handler.AdminGetUser())
+	adminUsersGroup.POST("/:username/mfa/reset-totp", middleware.RequireReauth(middleware.ActionResetTOTP, r.config.Auth.StepUpMaxAge), handler.AdminResetTotp())
+	adminUsersGroup.POST("/:username/move-group", handler.MoveGroup())
+	adminUsersGroup.POST("/:username/finalize", middleware.RequireReauth(middleware.ActionFinalizeUser, r.config.Auth.StepUpMaxAge), handler.AdminFinalizeUser())
+	adminUsersGroup.POST("/:username/disable", middleware.RequireReauth(middleware.ActionDisableUser, r.config.Auth.StepUpMaxAge), handler.AdminDisableUser())
+	adminUsersGroup.GET("/:username/lockout", handler.AdminGetLockout())
+	adminUsersGroup.POST("/:username/unlock", handler.AdminUnlockUser())
+
 	groupsGroup := authGroup.Group("/groups")
 	groupsGroup.POST("/add", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.AddGroup())
 	groupsGroup.POST("/remove", r.authMiddleware.AuthMiddleware(auth.GroupAdmin), handler.RemoveGroup())
 
-	authenticatedGroup := authGroup.Group("/")
+	authenticatedGroup := authGroup.Group("")
 	authenticatedGroup.Use(r.authMiddleware.AuthMiddleware(auth.GroupAdmin, auth.GroupUser))
-	authenticatedGroup.GET("/", handler.GetMe())
+	authenticatedGroup.GET("", handler.GetMe())
+	authenticatedGroup.GET("/user/groups", handler.GetUserGroups())
+	authenticatedGroup.GET("/user/email", handler.GetEmailChange())
+	authenticatedGroup.POST("/user/email", handler.RequestEmailChange())
+	authenticatedGroup.POST("/user/email/verify", handler.VerifyEmailChange())
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"auth-api/src/api/gin/middleware"
+	"auth-api/src/config"
 	"auth-api/src/factory"
 
 	"github.com/gin-gonic/gin"
@@ -15,13 +16,17 @@ type routes struct {
 	gin            *gin.RouterGroup
 	factory        *factory.Factory
 	authMiddleware middleware.AuthMiddleware
+	config         *config.Config
+	features       *middleware.Features
 }
 
-func NewRoutes(g *gin.RouterGroup, factory *factory.Factory, authMiddleware middleware.AuthMiddleware) Routes {
+func NewRoutes(g *gin.RouterGroup, factory *factory.Factory, authMiddleware middleware.AuthMiddleware, config *config.Config) Routes {
 	return &routes{
 		gin:            g,
 		factory:        factory,
 		authMiddleware: authMiddleware,
+		config:         config,
+		features:       middleware.NewFeatures(config.Features.Disabled, config.Features.DisabledStatus, config.Api.ErrorFormat),
 	}
 }
 
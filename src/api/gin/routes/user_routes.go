@@ -4,15 +4,15 @@ import (
 	"auth-api/src/api/gin/handlers"
 	"auth-api/src/api/gin/middleware"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
-	"time"
 )
 
 func (r *routes) configUserRoutes() {
-	handler := handlers.NewUserHandler(r.factory.UseCases.UserManager.User)
+	handler := handlers.NewUserHandler(r.factory.UseCases.UserManager.User, r.config.Auth.CaseSensitiveUsernames)
 	userGroup := r.gin.Group("/user")
-	userGroup.Use(middleware.TimeoutMiddleware(30 * time.Second))
+	userGroup.Use(middleware.TimeoutMiddleware(r.config.Api.Timeouts.User, r.config.Api.ErrorFormat))
 
-	userGroup.PATCH("/", r.authMiddleware.AuthMiddleware(auth.GroupUser), handler.Update())
+	userGroup.PATCH("", r.authMiddleware.AuthMiddleware(auth.GroupUser), handler.Update())
 	userGroup.POST("/register", handler.Register())
+	userGroup.POST("/invite/verify", handler.VerifyInvite())
 
 }
@@ -67,6 +67,15 @@ func main() {
 		return
 	}
 
+	if appConfig.StartupSelfTest {
+		if appConfig.Env == "production" {
+			logger.Warning("Ignoring startup self-test in production")
+		} else if err := factory.UseCases.UserManager.Auth.SelfCheck.Execute(ctx); err != nil {
+			logger.Error("Startup self-test failed %v", err)
+			return
+		}
+	}
+
 	server := server.New(ctx, awsConfig, appConfig, logger, factory)
 
 	var wg sync.WaitGroup
@@ -78,6 +87,14 @@ func main() {
 		}
 	}()
 
+	if factory.OutboxRelay != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			factory.OutboxRelay.Run(ctx)
+		}()
+	}
+
 	wg.Wait()
 
 	<-ctx.Done()
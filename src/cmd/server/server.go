@@ -21,7 +21,7 @@ type Server struct {
 }
 
 func New(ctx context.Context, awsConfig *aws.Config, config *config.Config, logger logger.Logger, factory *factory.Factory) *Server {
-	gin := gin.New(logger, factory)
+	gin := gin.New(logger, config, factory)
 
 	return &Server{
 		config: config,
@@ -34,8 +34,14 @@ func New(ctx context.Context, awsConfig *aws.Config, config *config.Config, logg
 func (s *Server) Start() error {
 	s.log.Info("Starting server %s:%d", s.config.Api.Host, s.config.Api.Port)
 
-	s.gin.SetupMiddlewares()
-	s.gin.SetupApi()
+	if err := s.gin.SetupMiddlewares(); err != nil {
+		s.log.Error("Error setting up middlewares: %v", err)
+		return err
+	}
+	if err := s.gin.SetupApi(); err != nil {
+		s.log.Error("Error setting up API: %v", err)
+		return err
+	}
 
 	go func() {
 		<-s.ctx.Done()
@@ -49,7 +55,7 @@ func (s *Server) Start() error {
 
 	s.server = &http.Server{
 		Addr:    s.config.Api.Host + ":" + strconv.Itoa(s.config.Api.Port),
-		Handler: s.gin.Gin,
+		Handler: s.gin.Handler(),
 	}
 
 	err := s.server.ListenAndServe()
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,11 +13,220 @@ type AwsConfig struct {
 	CognitoClientId   string `mapstructure:"cognito_client_id"`
 	CognitoUserPoolID string `mapstructure:"cognito_user_pool_id"`
 	CodesTable        string `mapstructure:"codes_table"`
+
+	CognitoMaxAttempts int           `mapstructure:"cognito_max_attempts"`
+	CognitoMaxBackoff  time.Duration `mapstructure:"cognito_max_backoff"`
+
+	// TrustedUserPools are accepted as token issuers besides the main pool,
+	// e.g. during a pool migration.
+	TrustedUserPools []TrustedUserPool `mapstructure:"trusted_user_pools"`
+}
+
+// TrustedUserPool names a Cognito pool by Region and UserPoolID, or any other
+// source by Issuer and JWKSURL, which defaults to the issuer's
+// /.well-known/jwks.json.
+type TrustedUserPool struct {
+	Region     string `mapstructure:"region"`
+	UserPoolID string `mapstructure:"user_pool_id"`
+
+	Issuer  string `mapstructure:"issuer"`
+	JWKSURL string `mapstructure:"jwks_url"`
+}
+
+type TimeoutsConfig struct {
+	Auth           time.Duration `mapstructure:"auth"`
+	User           time.Duration `mapstructure:"user"`
+	Admin          time.Duration `mapstructure:"admin"`
+	ResetPasswords time.Duration `mapstructure:"reset_passwords"` // bounds the background job, not a request
+	Export         time.Duration `mapstructure:"export"`
+}
+
+type HttpsConfig struct {
+	Enforce bool   `mapstructure:"enforce"`
+	Mode    string `mapstructure:"mode"`
+	// TrustForwardedProto believes X-Forwarded-Proto, but only from the
+	// Api.TrustedProxies.
+	TrustForwardedProto bool `mapstructure:"trust_forwarded_proto"`
+}
+
+type SessionCookieConfig struct {
+	Name     string `mapstructure:"name"`
+	Domain   string `mapstructure:"domain"`
+	Secure   bool   `mapstructure:"secure"`
+	SameSite string `mapstructure:"same_site"`
+}
+
+// RefreshTokenConfig lists where /auth/refresh looks for the refresh token,
+// in order: "body", "cookie" or both.
+type RefreshTokenConfig struct {
+	Sources    []string `mapstructure:"sources"`
+	CookieName string   `mapstructure:"cookie_name"`
+}
+
+type AccessLogConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Level        string   `mapstructure:"level"`
+	LogBodies    bool     `mapstructure:"log_bodies"`
+	MaxBodyBytes int      `mapstructure:"max_body_bytes"`
+	RedactFields []string `mapstructure:"redact_fields"`
+}
+
+type RateLimitConfig struct {
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+type RateLimitsConfig struct {
+	ValidatePassword RateLimitConfig `mapstructure:"validate_password"`
 }
 
 type ApiConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host          string              `mapstructure:"host"`
+	Port          int                 `mapstructure:"port"`
+	TrailingSlash string              `mapstructure:"trailing_slash"`
+	Timeouts      TimeoutsConfig      `mapstructure:"timeouts"`
+	ErrorFormat   string              `mapstructure:"error_format"`
+	Https         HttpsConfig         `mapstructure:"https"`
+	SessionCookie SessionCookieConfig `mapstructure:"session_cookie"`
+	RefreshToken  RefreshTokenConfig  `mapstructure:"refresh_token"`
+	AccessLog     AccessLogConfig     `mapstructure:"access_log"`
+	RateLimits    RateLimitsConfig    `mapstructure:"rate_limits"`
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For is
+	// believed when resolving the client IP. Empty trusts no proxy.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	MaxAuthHeaderBytes int `mapstructure:"max_auth_header_bytes"`
+	// CursorSecret signs list cursors. When empty a random key is used, and
+	// cursors stop working on restart.
+	CursorSecret string `mapstructure:"cursor_secret"`
+
+	TokenDelivery TokenDeliveryConfig `mapstructure:"token_delivery"`
+
+	Export ExportConfig `mapstructure:"export"`
+
+	// Metrics serves per-route request counts and latencies at GET /metrics.
+	// It is off by default since /metrics is served without authentication.
+	Metrics MetricsConfig `mapstructure:"metrics"`
+
+	// JSONNaming is the field naming of /auth responses, "camel" or "snake".
+	JSONNaming string `mapstructure:"json_naming"`
+}
+
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ExportConfig picks how GET /admin/users/export delivers the file: "inline"
+// streams it, "url" stores it in Bucket under Prefix and returns a presigned
+// link valid for URLExpiry.
+type ExportConfig struct {
+	Delivery  string        `mapstructure:"delivery"`
+	Bucket    string        `mapstructure:"bucket"`
+	Prefix    string        `mapstructure:"prefix"`
+	URLExpiry time.Duration `mapstructure:"url_expiry"`
+}
+
+// maxExportURLExpiry is the longest S3 lets a presigned URL live.
+const maxExportURLExpiry = 7 * 24 * time.Hour
+
+// validate refuses a url delivery that could never hand out a working link.
+func (c ExportConfig) validate() error {
+	if c.Delivery != "url" {
+		return nil
+	}
+	if c.Bucket == "" {
+		return errors.New("api.export.delivery url needs api.export.bucket")
+	}
+	if c.URLExpiry < time.Second || c.URLExpiry > maxExportURLExpiry {
+		return fmt.Errorf("api.export.url_expiry must be between 1s and %s, got %s", maxExportURLExpiry, c.URLExpiry)
+	}
+	return nil
+}
+
+// TokenDeliveryConfig picks which tokens each kind of client receives and
+// where. The client type is read from the X-Client-Type header, then looked
+// up in ClientIDs by the app client the tokens were issued to, then Default.
+// Without Types every token is returned in the body.
+type TokenDeliveryConfig struct {
+	Default      string                           `mapstructure:"default"`
+	ClientIDs    map[string]string                `mapstructure:"client_ids"`
+	Types        map[string]TokenDeliveryStrategy `mapstructure:"types"`
+	CookieMaxAge time.Duration                    `mapstructure:"cookie_max_age"`
+}
+
+// TokenDeliveryStrategy places each token: "body", "none", or for the refresh
+// token also "cookie", which uses api.refresh_token.cookie_name. Anything else
+// is treated as "body".
+type TokenDeliveryStrategy struct {
+	AccessToken  string `mapstructure:"access_token"`
+	IdToken      string `mapstructure:"id_token"`
+	RefreshToken string `mapstructure:"refresh_token"`
+}
+
+type AuthConfig struct {
+	MfaIssuer               string        `mapstructure:"mfa_issuer"`
+	DisableSignup           bool          `mapstructure:"disable_signup"`
+	ResetPasswordsPerSecond int           `mapstructure:"reset_passwords_per_second"`
+	AllowedDeliveryMediums  []string      `mapstructure:"allowed_delivery_mediums"`
+	CaseSensitiveUsernames  bool          `mapstructure:"case_sensitive_usernames"`
+	PasswordPolicyTTL       time.Duration `mapstructure:"password_policy_ttl"`
+	SessionTTL              time.Duration `mapstructure:"session_ttl"`
+	SignupAllowedDomains    []string      `mapstructure:"signup_allowed_domains"`
+	SignupBlockedDomains    []string      `mapstructure:"signup_blocked_domains"`
+	StepUpMaxAge            time.Duration `mapstructure:"step_up_max_age"`
+	ConfirmAutoLogin        bool          `mapstructure:"confirm_auto_login"`
+	StatsWindow             time.Duration `mapstructure:"stats_window"`
+	JwtAllowedAlgorithms    []string      `mapstructure:"jwt_allowed_algorithms"`
+	UserCacheTTL            time.Duration `mapstructure:"user_cache_ttl"`
+	RevocationCacheTTL      time.Duration `mapstructure:"revocation_cache_ttl"`
+	EnforceAdminMFA         bool          `mapstructure:"enforce_admin_mfa"`
+	MFAEnrollmentTTL        time.Duration `mapstructure:"mfa_enrollment_ttl"`
+	MaxSessions             int           `mapstructure:"max_sessions"`
+	SessionLimitMode        string        `mapstructure:"session_limit_mode"`
+	AdminAliasConflict      string        `mapstructure:"admin_alias_conflict"`
+	MaxSessionLength        time.Duration `mapstructure:"max_session_length"`
+	AuthFlow                string        `mapstructure:"auth_flow"`
+	AssignGroupOnConfirm    bool          `mapstructure:"assign_group_on_confirm"`
+
+	// SessionEncryptionKey encrypts the tokens stored with sessions. When
+	// empty a random key is used, and sessions end on restart and don't
+	// carry over between instances.
+	SessionEncryptionKey string `mapstructure:"session_encryption_key"`
+
+	PreAuthChallenge PreAuthChallengeConfig `mapstructure:"pre_auth_challenge"`
+	Lockout          LockoutConfig          `mapstructure:"lockout"`
+	Invites          InvitesConfig          `mapstructure:"invites"`
+	// ClaimsResolvers names the resolvers run on validated tokens, e.g.
+	// "email_domain_tenant", which uses TenantDomains (domain -> tenant).
+	ClaimsResolvers []string          `mapstructure:"claims_resolvers"`
+	TenantDomains   map[string]string `mapstructure:"tenant_domains"`
+
+	// ClaimMappings promote token attributes to typed claims fields; they
+	// run after ClaimsResolvers.
+	ClaimMappings []ClaimMappingConfig `mapstructure:"claim_mappings"`
+
+	// AttributeMappings name user pool attributes the way the API exposes
+	// them. A list rather than a map, since viper lowercases map keys.
+	AttributeMappings []AttributeMappingConfig `mapstructure:"attribute_mappings"`
+
+	// SignupEnumeration hides whether an email already has an account from
+	// signup. Off by default, since such users never hear back from it.
+	SignupEnumeration SignupEnumerationConfig `mapstructure:"signup_enumeration"`
+}
+
+// ClaimMappingConfig copies Attribute (e.g. "custom:tenantId") to the claims
+// field named by Field (e.g. "tenant_id"). Required rejects tokens without it.
+type ClaimMappingConfig struct {
+	Attribute string `mapstructure:"attribute"`
+	Field     string `mapstructure:"field"`
+	Required  bool   `mapstructure:"required"`
+}
+
+// AttributeMappingConfig exposes the user pool attribute Cognito (e.g.
+// "custom:org_id") as Domain (e.g. "orgId") in inputs and outputs.
+type AttributeMappingConfig struct {
+	Domain  string `mapstructure:"domain"`
+	Cognito string `mapstructure:"cognito"`
 }
 
 type SQLDatabaseConfig struct {
@@ -26,20 +237,93 @@ type SQLDatabaseConfig struct {
 	Database string `mapstructure:"database"`
 }
 
+type FeaturesConfig struct {
+	Disabled       []string `mapstructure:"disabled"`
+	DisabledStatus int      `mapstructure:"disabled_status"`
+}
+
+// PreAuthChallengeConfig selects the bot challenge checked before login and
+// signup. An empty Provider disables it.
+type PreAuthChallengeConfig struct {
+	Provider      string        `mapstructure:"provider"`
+	Secret        string        `mapstructure:"secret"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+	LoginFailOpen bool          `mapstructure:"login_fail_open"`
+}
+
+// LockoutConfig blocks password sign in for a username after Threshold wrong
+// passwords within Window, until Window has passed since the last one. A
+// Threshold of zero disables it. The counters live in Postgres, so the
+// threshold holds across instances and restarts.
+type LockoutConfig struct {
+	Threshold int           `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+}
+
+// InvitesConfig signs admin issued signup invites, valid for TTL. An empty
+// Secret disables invites. URL is the signup page invite emails link to,
+// with the token in its invite query parameter.
+type InvitesConfig struct {
+	Secret string        `mapstructure:"secret"`
+	TTL    time.Duration `mapstructure:"ttl"`
+	URL    string        `mapstructure:"url"`
+}
+
+// SignupEnumerationConfig answers signups for existing accounts as if they
+// were new, and holds every signup response for at least MinDuration.
+type SignupEnumerationConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	MinDuration time.Duration `mapstructure:"min_duration"`
+}
+
+type WebhooksConfig struct {
+	SecurityURL string        `mapstructure:"security_url"`
+	Secret      string        `mapstructure:"secret"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+
+	// EventsURL receives the domain events the outbox relay publishes.
+	EventsURL string `mapstructure:"events_url"`
+
+	// UsernameHashKey keys the username hash in login attempt events. It must
+	// differ from Secret, which every receiver holds to verify signatures.
+	UsernameHashKey string `mapstructure:"username_hash_key"`
+}
+
+// OutboxConfig records user changes as events in the outbox table, in the
+// same transaction as the change, and relays them to webhooks.events_url
+// every PollInterval. A message is given up on after MaxAttempts failures.
+type OutboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+}
+
 type Config struct {
-	Aws AwsConfig         `mapstructure:"aws"`
-	Api ApiConfig         `mapstructure:"api"`
-	Sql SQLDatabaseConfig `mapstructure:"sql"`
-	Env string            `mapstructure:"env"`
+	Aws      AwsConfig         `mapstructure:"aws"`
+	Api      ApiConfig         `mapstructure:"api"`
+	Sql      SQLDatabaseConfig `mapstructure:"sql"`
+	Auth     AuthConfig        `mapstructure:"auth"`
+	Features FeaturesConfig    `mapstructure:"features"`
+	Webhooks WebhooksConfig    `mapstructure:"webhooks"`
+	Outbox   OutboxConfig      `mapstructure:"outbox"`
+	Env      string            `mapstructure:"env"`
+	// StartupSelfTest runs a sign in with a throwaway user before serving,
+	// outside production only. It's bound to STARTUP_SELF_TEST.
+	StartupSelfTest bool `mapstructure:"startup_self_test"`
 }
 
 func setDefaults() {
 	viper.SetDefault("env", "development")
+	viper.SetDefault("startup_self_test", false)
+	_ = viper.BindEnv("startup_self_test", "STARTUP_SELF_TEST")
 
 	viper.SetDefault("aws.region", "us-east-1")
 	viper.SetDefault("aws.cognito_client_id", "SET_ME")
 	viper.SetDefault("aws.cognito_user_pool_id", "SET_ME")
 	viper.SetDefault("aws.codes_table", "SET_ME")
+	viper.SetDefault("aws.cognito_max_attempts", 3)
+	viper.SetDefault("aws.cognito_max_backoff", "2s")
 
 	viper.SetDefault("sql.host", "localhost")
 	viper.SetDefault("sql.port", 5432)
@@ -49,6 +333,92 @@ func setDefaults() {
 
 	viper.SetDefault("api.host", "0.0.0.0")
 	viper.SetDefault("api.port", 4000)
+	viper.SetDefault("api.trailing_slash", "redirect")
+	viper.SetDefault("api.timeouts.auth", "30s")
+	viper.SetDefault("api.timeouts.user", "30s")
+	viper.SetDefault("api.timeouts.admin", "30s")
+	viper.SetDefault("api.timeouts.reset_passwords", "10m")
+	viper.SetDefault("api.timeouts.export", "10m")
+	viper.SetDefault("api.error_format", "json")
+	viper.SetDefault("api.https.enforce", true)
+	viper.SetDefault("api.https.mode", "redirect")
+	viper.SetDefault("api.https.trust_forwarded_proto", false)
+	viper.SetDefault("api.session_cookie.name", "session")
+	viper.SetDefault("api.session_cookie.domain", "")
+	viper.SetDefault("api.session_cookie.secure", true)
+	viper.SetDefault("api.session_cookie.same_site", "lax")
+	viper.SetDefault("api.refresh_token.sources", []string{"body"})
+	viper.SetDefault("api.refresh_token.cookie_name", "refresh_token")
+	viper.SetDefault("api.rate_limits.validate_password.limit", 30)
+	viper.SetDefault("api.rate_limits.validate_password.window", "1m")
+	viper.SetDefault("api.trusted_proxies", []string{})
+	viper.SetDefault("api.max_auth_header_bytes", 8192)
+	viper.SetDefault("api.cursor_secret", "")
+	viper.SetDefault("api.token_delivery.default", "")
+	viper.SetDefault("api.token_delivery.cookie_max_age", "720h")
+	viper.SetDefault("api.export.delivery", "inline")
+	viper.SetDefault("api.export.bucket", "")
+	viper.SetDefault("api.export.prefix", "exports/")
+	viper.SetDefault("api.export.url_expiry", "15m")
+	viper.SetDefault("api.metrics.enabled", false)
+	viper.SetDefault("api.json_naming", "camel")
+	viper.SetDefault("api.access_log.enabled", true)
+	viper.SetDefault("api.access_log.level", "info")
+	viper.SetDefault("api.access_log.log_bodies", false)
+	viper.SetDefault("api.access_log.max_body_bytes", 4096)
+	viper.SetDefault("api.access_log.redact_fields", []string{})
+
+	viper.SetDefault("auth.mfa_issuer", "Monitoring System")
+	viper.SetDefault("auth.disable_signup", false)
+	viper.SetDefault("auth.reset_passwords_per_second", 5)
+	viper.SetDefault("auth.allowed_delivery_mediums", []string{"EMAIL"})
+	viper.SetDefault("auth.case_sensitive_usernames", false)
+	viper.SetDefault("auth.password_policy_ttl", "1h")
+	viper.SetDefault("auth.session_ttl", "168h")
+	viper.SetDefault("auth.session_encryption_key", "")
+	viper.SetDefault("auth.signup_allowed_domains", []string{})
+	viper.SetDefault("auth.signup_blocked_domains", []string{})
+	viper.SetDefault("auth.step_up_max_age", "15m")
+	viper.SetDefault("auth.confirm_auto_login", false)
+	viper.SetDefault("auth.stats_window", "24h")
+	viper.SetDefault("auth.jwt_allowed_algorithms", []string{"RS256"})
+	viper.SetDefault("auth.user_cache_ttl", "1m")
+	viper.SetDefault("auth.revocation_cache_ttl", "15s")
+	viper.SetDefault("auth.enforce_admin_mfa", false)
+	viper.SetDefault("auth.mfa_enrollment_ttl", "10m")
+	viper.SetDefault("auth.max_sessions", 0)
+	viper.SetDefault("auth.session_limit_mode", "reject")
+	viper.SetDefault("auth.admin_alias_conflict", "refuse")
+	viper.SetDefault("auth.max_session_length", 0)
+	viper.SetDefault("auth.assign_group_on_confirm", false)
+	viper.SetDefault("auth.lockout.threshold", 0)
+	viper.SetDefault("auth.lockout.window", "15m")
+	viper.SetDefault("auth.invites.secret", "")
+	viper.SetDefault("auth.invites.ttl", "72h")
+	viper.SetDefault("auth.invites.url", "")
+	viper.SetDefault("auth.auth_flow", "USER_PASSWORD_AUTH")
+	viper.SetDefault("auth.pre_auth_challenge.provider", "")
+	viper.SetDefault("auth.pre_auth_challenge.secret", "")
+	viper.SetDefault("auth.pre_auth_challenge.timeout", "5s")
+	viper.SetDefault("auth.pre_auth_challenge.login_fail_open", false)
+	viper.SetDefault("auth.claims_resolvers", []string{})
+	viper.SetDefault("auth.tenant_domains", map[string]string{})
+	viper.SetDefault("auth.signup_enumeration.enabled", false)
+	viper.SetDefault("auth.signup_enumeration.min_duration", "1500ms")
+
+	viper.SetDefault("features.disabled", []string{})
+	viper.SetDefault("features.disabled_status", 404)
+
+	viper.SetDefault("webhooks.security_url", "")
+	viper.SetDefault("webhooks.secret", "")
+	viper.SetDefault("webhooks.timeout", "5s")
+	viper.SetDefault("webhooks.events_url", "")
+	viper.SetDefault("webhooks.username_hash_key", "")
+
+	viper.SetDefault("outbox.enabled", false)
+	viper.SetDefault("outbox.poll_interval", "5s")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.max_attempts", 10)
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -73,5 +443,9 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("error unmarshalling config: %v", err)
 	}
 
+	if err := config.Api.Export.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+
 	return &config, nil
 }
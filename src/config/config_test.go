@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExportConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ExportConfig
+		wantErr bool
+	}{
+		{name: "inline needs nothing else", config: ExportConfig{Delivery: "inline"}},
+		{name: "url with a bucket", config: ExportConfig{Delivery: "url", Bucket: "exports", URLExpiry: 15 * time.Minute}},
+		{name: "url at the longest expiry", config: ExportConfig{Delivery: "url", Bucket: "exports", URLExpiry: 7 * 24 * time.Hour}},
+		{name: "url without a bucket", config: ExportConfig{Delivery: "url", URLExpiry: 15 * time.Minute}, wantErr: true},
+		{name: "url expiring under a second", config: ExportConfig{Delivery: "url", Bucket: "exports", URLExpiry: 500 * time.Millisecond}, wantErr: true},
+		{name: "url outliving what S3 signs", config: ExportConfig{Delivery: "url", Bucket: "exports", URLExpiry: 8 * 24 * time.Hour}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
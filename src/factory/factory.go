@@ -14,14 +14,30 @@ import (
 	admin_usecases "auth-api/src/internal/modules/user-manager/usecases/admin"
 	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
 	user_usecases "auth-api/src/internal/modules/user-manager/usecases/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	audit_infra "auth-api/src/internal/shared/audit/infra/audit"
 	"auth-api/src/internal/shared/code/domain/code"
 	code_infra "auth-api/src/internal/shared/code/infra/code"
 	"auth-api/src/internal/shared/notification/domain/email"
 	email_infra "auth-api/src/internal/shared/notification/infra/email"
+	"auth-api/src/internal/shared/outbox/domain/outbox"
+	outbox_infra "auth-api/src/internal/shared/outbox/infra/outbox"
+	"auth-api/src/internal/shared/session/domain/session"
+	session_infra "auth-api/src/internal/shared/session/infra/session"
+	"auth-api/src/internal/shared/storage/domain/storage"
+	storage_infra "auth-api/src/internal/shared/storage/infra/storage"
+	"auth-api/src/internal/shared/webhook/domain/webhook"
+	webhook_infra "auth-api/src/internal/shared/webhook/infra/webhook"
+	"auth-api/src/pkg/aws_retry"
+	"auth-api/src/pkg/cursor"
 	"auth-api/src/pkg/jwt_verify"
 	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/otpauth"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
@@ -34,23 +50,30 @@ type Factory struct {
 	Repository Repository
 	Service    Service
 	Event      events.EventDispatcher
+	// OutboxRelay is nil unless the outbox is enabled.
+	OutboxRelay *outbox_infra.Relay
 }
 
 type Service struct {
 	Code        code.CodeService
 	Email       email.EmailService
+	Audit       audit.AuditService
+	Session     session.SessionService
 	UserManager UserManagerService
 }
 
 type Repository struct {
 	UserManager UserManagerRepo
 	Code        code.CodeRepository
+	Audit       audit.AuditRepository
+	Session     session.SessionRepository
 }
 
 type UserManagerService struct {
-	Auth  auth.AuthService
-	User  user.UserService
-	Admin admin.AdminService
+	Auth           auth.AuthService
+	User           user.UserService
+	Admin          admin.AdminService
+	ClaimsResolver auth.ClaimsResolver
 }
 
 type UserManagerRepo struct {
@@ -68,11 +91,37 @@ type UseCases struct {
 	UserManager UserManagerUseCases
 }
 
-func newAuthService(logger logger.Logger, awsConfig *aws.Config, config config.Config, email email.EmailService, codeService code.CodeService) auth.AuthService {
-	cognitoClient := cognitoidentityprovider.NewFromConfig(*awsConfig)
-	jwtVerify := jwt_verify.NewAuth(config.Aws.Region, config.Aws.CognitoUserPoolID, logger)
+func newAuthService(ctx context.Context, logger logger.Logger, awsConfig *aws.Config, config config.Config, email email.EmailService, codeService code.CodeService, revocations auth.TokenRevocationStore) (auth.AuthService, error) {
+	authFlow, err := auth_infra.ParseAuthFlow(config.Auth.AuthFlow)
+	if err != nil {
+		return nil, err
+	}
+	cognitoClient := cognitoidentityprovider.NewFromConfig(*awsConfig, func(o *cognitoidentityprovider.Options) {
+		o.Retryer = aws_retry.NewCognitoRetryer(config.Aws.CognitoMaxAttempts, config.Aws.CognitoMaxBackoff)
+	})
+	trusted := make([]jwt_verify.JWTVerify, 0, len(config.Aws.TrustedUserPools))
+	for _, pool := range config.Aws.TrustedUserPools {
+		if pool.Issuer != "" {
+			trusted = append(trusted, jwt_verify.NewSource(pool.Issuer, pool.JWKSURL, logger, config.Auth.JwtAllowedAlgorithms...))
+			continue
+		}
+		trusted = append(trusted, jwt_verify.NewAuth(pool.Region, pool.UserPoolID, logger, config.Auth.JwtAllowedAlgorithms...))
+	}
+	jwtVerify := jwt_verify.NewMultiIssuer(logger, jwt_verify.NewAuth(config.Aws.Region, config.Aws.CognitoUserPoolID, logger, config.Auth.JwtAllowedAlgorithms...), trusted...)
 	jwtVerify.CacheJWK() //TODO: Check when we need to cache the JWK and how to handle the error
-	return auth_infra.NewAuthService(cognitoClient, config.Aws.CognitoClientId, jwtVerify, config.Aws.CognitoUserPoolID, logger, email, codeService)
+	auth_infra.CheckAuthFlow(ctx, cognitoClient, config.Aws.CognitoUserPoolID, config.Aws.CognitoClientId, authFlow, logger)
+	attributeNames := make([]auth_infra.AttributeName, 0, len(config.Auth.AttributeMappings))
+	for _, mapping := range config.Auth.AttributeMappings {
+		attributeNames = append(attributeNames, auth_infra.AttributeName{
+			Domain:  mapping.Domain,
+			Cognito: mapping.Cognito,
+		})
+	}
+	attributes, err := auth_infra.NewAttributeMapping(attributeNames)
+	if err != nil {
+		return nil, err
+	}
+	return auth_infra.NewAuthService(cognitoClient, config.Aws.CognitoClientId, jwtVerify, config.Aws.CognitoUserPoolID, logger, email, codeService, config.Auth.PasswordPolicyTTL, config.Auth.UserCacheTTL, config.Auth.CaseSensitiveUsernames, authFlow, attributes, revocations), nil
 }
 
 func newCodeRepository(awsConfig aws.Config, logger logger.Logger, config config.Config) code.CodeRepository {
@@ -85,25 +134,197 @@ func newEmailService(awsConfig aws.Config, logger logger.Logger) email.EmailServ
 	return email_infra.NewEmailService(sesClient, logger)
 }
 
+// newExportStorage returns nil without a bucket, which leaves export download
+// links disabled.
+func newExportStorage(awsConfig aws.Config, logger logger.Logger, config config.Config) storage.StorageService {
+	if config.Api.Export.Bucket == "" {
+		return nil
+	}
+	return storage_infra.NewS3Storage(awsConfig, config.Api.Export.Bucket, logger)
+}
+
+func allowedDeliveryMediums(mediums []string) []auth.DeliveryMedium {
+	out := make([]auth.DeliveryMedium, 0, len(mediums))
+	for _, medium := range mediums {
+		out = append(out, auth.DeliveryMedium(strings.ToUpper(medium)))
+	}
+	return out
+}
+
+// newSecurityPublisher returns nil when no SIEM endpoint is configured.
+func newSecurityPublisher(logger logger.Logger, config config.Config) webhook.WebhookPublisher {
+	if config.Webhooks.SecurityURL == "" {
+		return nil
+	}
+	return webhook_infra.NewHTTPWebhookPublisher(config.Webhooks.SecurityURL, config.Webhooks.Secret, config.Webhooks.Timeout, logger)
+}
+
+// newOutbox returns no repository and no relay when the outbox is disabled.
+func newOutbox(db *sql.DB, logger logger.Logger, config config.Config) (outbox.OutboxRepository, *outbox_infra.Relay, error) {
+	if !config.Outbox.Enabled {
+		return nil, nil, nil
+	}
+	if config.Webhooks.EventsURL == "" {
+		return nil, nil, errors.New("outbox.enabled needs webhooks.events_url")
+	}
+	repo := outbox_infra.NewOutboxRepository(db, logger)
+	publisher := webhook_infra.NewHTTPWebhookPublisher(config.Webhooks.EventsURL, config.Webhooks.Secret, config.Webhooks.Timeout, logger)
+	relay := outbox_infra.NewRelay(repo, publisher, logger, config.Outbox.PollInterval, config.Outbox.BatchSize, config.Outbox.MaxAttempts)
+	return repo, relay, nil
+}
+
+// newCursorSigner falls back to a random key, which is enough for a single
+// instance but invalidates cursors on every restart.
+func newCursorSigner(secret string) (*cursor.Signer, error) {
+	if secret != "" {
+		return cursor.NewSigner([]byte(secret)), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return cursor.NewSigner(key), nil
+}
+
+// newSessionKey falls back to a random key, like newCursorSigner.
+func newSessionKey(secret string) ([]byte, error) {
+	if secret != "" {
+		return []byte(secret), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
 func New(ctx context.Context, logger logger.Logger, awsConfig aws.Config, config config.Config, db *sql.DB) (*Factory, error) {
-	userRepo := user_infra.NewUserRepository(db, logger)
+	outboxRepo, outboxRelay, err := newOutbox(db, logger, config)
+	if err != nil {
+		return nil, err
+	}
+	userRepo := user_infra.NewUserRepository(db, logger, outboxRepo)
 	adminRepo := admin_infra.NewAdminRepository(db, logger)
 	codeRepo := newCodeRepository(awsConfig, logger, config)
+	auditRepo := audit_infra.NewAuditRepository(db, logger)
+	sessionKey, err := newSessionKey(config.Auth.SessionEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	sessionRepo := session_infra.NewSessionRepository(db, logger, sessionKey)
 
 	codeService := code_infra.NewCodeServiceImpl(codeRepo, logger)
 	emailService := newEmailService(awsConfig, logger)
+	auditService := audit_infra.NewAuditServiceImpl(auditRepo, logger)
+	sessionService := session_infra.NewSessionServiceImpl(sessionRepo, logger, config.Auth.SessionTTL)
+
+	revocations := auth_infra.NewCachedTokenRevocationStore(auth_infra.NewTokenRevocationRepository(db, logger), config.Auth.RevocationCacheTTL, logger)
 
-	authService := newAuthService(logger, &awsConfig, config, emailService, codeService)
+	authService, err := newAuthService(ctx, logger, &awsConfig, config, emailService, codeService, revocations)
+	if err != nil {
+		return nil, err
+	}
 	userService := user_infra.NewUserService(userRepo)
 	adminService := admin_infra.NewAdminService(adminRepo, logger)
 
+	claimMappings := make([]auth_infra.ClaimMapping, 0, len(config.Auth.ClaimMappings))
+	for _, mapping := range config.Auth.ClaimMappings {
+		claimMappings = append(claimMappings, auth_infra.ClaimMapping{
+			Attribute: mapping.Attribute,
+			Field:     mapping.Field,
+			Required:  mapping.Required,
+		})
+	}
+	claimsResolver, err := auth_infra.NewClaimsResolver(config.Auth.ClaimsResolvers, config.Auth.TenantDomains, claimMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	preAuthChallenge, err := auth_infra.NewPreAuthChallenge(config.Auth.PreAuthChallenge.Provider, config.Auth.PreAuthChallenge.Secret, config.Auth.PreAuthChallenge.Timeout, config.Auth.PreAuthChallenge.LoginFailOpen, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mfaIssuer, err := otpauth.ParseIssuer(config.Auth.MfaIssuer)
+	if err != nil {
+		return nil, err
+	}
+
 	dispatcher := eventsIplm.NewEventDispatcher(logger)
+	inviteTokens := auth_infra.NewInviteTokens(config.Auth.Invites.Secret)
+	cursorSigner, err := newCursorSigner(config.Api.CursorSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	authUseCases := auth_usecases.NewUseCases(auth_usecases.Dependencies{
+		Auth:         authService,
+		Dispatcher:   dispatcher,
+		Admin:        adminService,
+		User:         userService,
+		Sessions:     sessionService,
+		Audit:        auditService,
+		Email:        emailService,
+		Logger:       logger,
+		Challenge:    preAuthChallenge,
+		LockoutStore: auth_infra.NewLockoutRepository(db, logger),
+		Invites:      inviteTokens,
+		EmailChanges: auth_infra.NewEmailChangeRepository(db, logger),
+		Cursors:      cursorSigner,
+	}, auth_usecases.Options{
+		MFAIssuer:            mfaIssuer,
+		ConfirmAutoLogin:     config.Auth.ConfirmAutoLogin,
+		AssignGroupOnConfirm: config.Auth.AssignGroupOnConfirm,
+		StatsWindow:          config.Auth.StatsWindow,
+		EnforceAdminMFA:      config.Auth.EnforceAdminMFA,
+		MFAEnrollmentTTL:     config.Auth.MFAEnrollmentTTL,
+		MaxSessions:          config.Auth.MaxSessions,
+		SessionLimitMode:     config.Auth.SessionLimitMode,
+		MaxSessionLength:     config.Auth.MaxSessionLength,
+		LockoutThreshold:     config.Auth.Lockout.Threshold,
+		LockoutWindow:        config.Auth.Lockout.Window,
+		InviteTTL:            config.Auth.Invites.TTL,
+		InviteURL:            config.Auth.Invites.URL,
 
-	authUseCases := auth_usecases.NewUseCases(authService, adminService, userService, logger)
-	adminUseCases := admin_usecases.NewUseCases(adminService, authService, logger)
-	userUseCases := user_usecases.NewUseCases(userService, authService, logger, dispatcher)
+		CaseSensitiveUsernames: config.Auth.CaseSensitiveUsernames,
+	})
+	adminUseCases := admin_usecases.NewUseCases(admin_usecases.Dependencies{
+		Admin:              adminService,
+		Auth:               authService,
+		Audit:              auditService,
+		Sessions:           sessionService,
+		ResetPasswordsJobs: auth_infra.NewResetPasswordsJobRepository(db, logger),
+		ExportStorage:      newExportStorage(awsConfig, logger, config),
+		Logger:             logger,
+	}, admin_usecases.Options{
+		ResetPasswordsPerSecond: config.Auth.ResetPasswordsPerSecond,
+		ResetPasswordsTimeout:   config.Api.Timeouts.ResetPasswords,
+		AliasConflict:           config.Auth.AdminAliasConflict,
+		ExportPrefix:            config.Api.Export.Prefix,
+		ExportURLExpiry:         config.Api.Export.URLExpiry,
+	})
+	userUseCases := user_usecases.NewUseCases(user_usecases.Dependencies{
+		User:       userService,
+		Admin:      adminService,
+		Auth:       authService,
+		Logger:     logger,
+		Dispatcher: dispatcher,
+		Challenge:  preAuthChallenge,
+		Invites:    inviteTokens,
+	}, user_usecases.Options{
+		SignupDisabled: config.Auth.DisableSignup,
+		AllowedMediums: allowedDeliveryMediums(config.Auth.AllowedDeliveryMediums),
+		DomainPolicy: user.EmailDomainPolicy{
+			Allowed: config.Auth.SignupAllowedDomains,
+			Blocked: config.Auth.SignupBlockedDomains,
+		},
+		Enumeration: user.SignupEnumerationProtection{
+			Enabled:     config.Auth.SignupEnumeration.Enabled,
+			MinDuration: config.Auth.SignupEnumeration.MinDuration,
+		},
+	})
 
-	handlers := events_handlers.NewEventsHandlers(logger, *authUseCases)
+	handlers := events_handlers.NewEventsHandlers(logger, *authUseCases, auditService, newSecurityPublisher(logger, config), config.Webhooks.UsernameHashKey)
 	handlers.RegisterHandlers(dispatcher)
 
 	return &Factory{
@@ -112,16 +333,21 @@ func New(ctx context.Context, logger logger.Logger, awsConfig aws.Config, config
 				User:  userRepo,
 				Admin: adminRepo,
 			},
-			Code: codeRepo,
+			Code:    codeRepo,
+			Audit:   auditRepo,
+			Session: sessionRepo,
 		},
 		Service: Service{
 			UserManager: UserManagerService{
-				Auth:  authService,
-				User:  userService,
-				Admin: adminService,
+				Auth:           authService,
+				User:           userService,
+				Admin:          adminService,
+				ClaimsResolver: claimsResolver,
 			},
-			Code:  codeService,
-			Email: emailService,
+			Code:    codeService,
+			Email:   emailService,
+			Audit:   auditService,
+			Session: sessionService,
 		},
 		UseCases: UseCases{
 			UserManager: UserManagerUseCases{
@@ -130,6 +356,7 @@ func New(ctx context.Context, logger logger.Logger, awsConfig aws.Config, config
 				Admin: adminUseCases,
 			},
 		},
-		Event: dispatcher,
+		Event:       dispatcher,
+		OutboxRelay: outboxRelay,
 	}, nil
 }
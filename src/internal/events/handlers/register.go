@@ -2,24 +2,33 @@ package events_handlers
 
 import (
 	"auth-api/src/internal/events"
+	user_manager_auth "auth-api/src/internal/events/handlers/user-manager/auth"
 	user_manager "auth-api/src/internal/events/handlers/user-manager/user"
 	auth_usecases "auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/webhook/domain/webhook"
 	"auth-api/src/pkg/logger"
 )
 
 type EventsHandlers struct {
-	userManagerHandlers *user_manager.EventsHandlers
+	userManagerHandlers     *user_manager.EventsHandlers
+	userManagerAuthHandlers *user_manager_auth.EventsHandlers
 }
 
 func NewEventsHandlers(
 	logger logger.Logger,
 	authUsecases auth_usecases.UseCases,
+	auditService audit.AuditService,
+	securityPublisher webhook.WebhookPublisher,
+	usernameHashKey string,
 ) *EventsHandlers {
 	return &EventsHandlers{
-		userManagerHandlers: user_manager.NewEventsHandlers(logger, authUsecases),
+		userManagerHandlers:     user_manager.NewEventsHandlers(logger, authUsecases, auditService),
+		userManagerAuthHandlers: user_manager_auth.NewEventsHandlers(logger, auditService, securityPublisher, usernameHashKey),
 	}
 }
 
 func (h *EventsHandlers) RegisterHandlers(dispatcher events.EventDispatcher) {
 	h.userManagerHandlers.RegisterHandlers(dispatcher)
+	h.userManagerAuthHandlers.RegisterHandlers(dispatcher)
 }
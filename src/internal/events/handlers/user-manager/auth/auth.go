@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	auth_events "auth-api/src/internal/modules/user-manager/events/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/webhook/domain/webhook"
+	"auth-api/src/pkg/logger"
+)
+
+type EventsHandlers struct {
+	recordLoginAttemptHandler events.EventHandler
+	securityWebhookHandler    events.EventHandler
+}
+
+// NewEventsHandlers takes a nil publisher when no security webhook is
+// configured, in which case only the audit handler is registered.
+func NewEventsHandlers(
+	logger logger.Logger,
+	auditService audit.AuditService,
+	securityPublisher webhook.WebhookPublisher,
+	usernameHashKey string,
+) *EventsHandlers {
+	h := &EventsHandlers{
+		recordLoginAttemptHandler: auth_events.NewRecordLoginAttemptHandler(logger, auditService),
+	}
+	if securityPublisher != nil {
+		h.securityWebhookHandler = auth_events.NewSecurityWebhookHandler(logger, securityPublisher, usernameHashKey)
+	}
+	return h
+}
+
+func (h *EventsHandlers) RegisterHandlers(dispatcher events.EventDispatcher) {
+	dispatcher.Register(auth.LoginAttempted, h.recordLoginAttemptHandler)
+	if h.securityWebhookHandler != nil {
+		dispatcher.Register(auth.LoginAttempted, h.securityWebhookHandler)
+	}
+}
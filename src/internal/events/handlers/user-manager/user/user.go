@@ -5,22 +5,27 @@ import (
 	"auth-api/src/internal/modules/user-manager/domain/user"
 	user_events "auth-api/src/internal/modules/user-manager/events/user"
 	"auth-api/src/internal/modules/user-manager/usecases/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
 	"auth-api/src/pkg/logger"
 )
 
 type EventsHandlers struct {
 	sendConfirmationHandler events.EventHandler
+	recordSignupHandler     events.EventHandler
 }
 
 func NewEventsHandlers(
 	logger logger.Logger,
 	authUsecases auth.UseCases,
+	auditService audit.AuditService,
 ) *EventsHandlers {
 	return &EventsHandlers{
 		sendConfirmationHandler: user_events.NewSendConfirmationHandler(logger, authUsecases),
+		recordSignupHandler:     user_events.NewRecordSignupHandler(logger, auditService),
 	}
 }
 
 func (h *EventsHandlers) RegisterHandlers(dispatcher events.EventDispatcher) {
 	dispatcher.Register(user.UserRegistered, h.sendConfirmationHandler)
+	dispatcher.Register(user.UserRegistered, h.recordSignupHandler)
 }
@@ -5,7 +5,6 @@ import (
 	"auth-api/src/pkg/validator"
 	"fmt"
 	"net/http"
-	"strings"
 )
 
 type CreateAdminInput struct {
@@ -21,11 +20,9 @@ func (input *CreateAdminInput) Validate() error {
 	}
 	input.ID = adminID
 
-	lowerCaseEmail, err := validateEmail(input.Email)
-	if err != nil {
+	if err := validateEmail(input.Email); err != nil {
 		return err
 	}
-	input.Email = lowerCaseEmail
 
 	if err := validator.ValidateStringLength(input.Name, 3, 100); err != nil {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid name length", fmt.Sprintf("Field: %s", "Name"))
@@ -48,11 +45,9 @@ func (input *UpdateAdminInput) Validate() error {
 	input.ID = adminID
 
 	if input.Email != nil {
-		lowerCaseEmail, err := validateEmail(*input.Email)
-		if err != nil {
+		if err := validateEmail(*input.Email); err != nil {
 			return err
 		}
-		input.Email = &lowerCaseEmail
 	}
 
 	if input.Name != nil {
@@ -94,18 +89,12 @@ type GetAdminByEmailInput struct {
 }
 
 func (input *GetAdminByEmailInput) Validate() error {
-	lowerCaseEmail, err := validateEmail(input.Email)
-	if err != nil {
-		return err
-	}
-	input.Email = lowerCaseEmail
-	return nil
+	return validateEmail(input.Email)
 }
 
-func validateEmail(email string) (string, error) {
-	lowerCaseEmail := strings.ToLower(email)
-	if err := validator.ValidateEmail(lowerCaseEmail); err != nil {
-		return "", app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email"))
+func validateEmail(email string) error {
+	if err := validator.ValidateEmail(email); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email"))
 	}
-	return lowerCaseEmail, nil
+	return nil
 }
@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 type UserGroup string
 
 const (
@@ -7,6 +9,21 @@ const (
 	GroupUser  UserGroup = "User"
 )
 
+type DeliveryMedium string
+
+const (
+	DeliveryMediumEmail DeliveryMedium = "EMAIL"
+	DeliveryMediumSMS   DeliveryMedium = "SMS"
+)
+
+// Supported reports whether codes can be sent through the medium. SMS is
+// accepted on input but refused with ErrDeliveryMediumUnsupported: codes go
+// out by email, and SMS needs a Lambda trigger on the pool (see
+// ClientMetadataDeliveryMedium) that nothing deploys yet.
+func (m DeliveryMedium) Supported() bool {
+	return m == DeliveryMediumEmail
+}
+
 type UserStatus string
 
 const (
@@ -21,13 +38,30 @@ type Claims struct {
 	Email      string   `json:"email"`
 	Id         string   `json:"id"`
 	UserGroups []string `json:"groups"`
+	// AuthTime is when the user last actually authenticated. Unlike iat it
+	// doesn't move when the token is refreshed.
+	AuthTime time.Time `json:"authTime"`
+	// TenantID is derived by a ClaimsResolver; it is never in the token.
+	TenantID string `json:"tenantId,omitempty"`
+	// Attributes are the token's "custom:" attributes, for resolvers to
+	// promote to typed fields.
+	Attributes map[string]string `json:"-"`
+	// Scopes are the OAuth scopes an access token was granted.
+	Scopes []string `json:"scopes,omitempty"`
 }
 
 type User struct {
+	// Id is the Cognito sub, which unlike the email never changes. Admin
+	// get and list responses carry it as the user's key.
 	Id     string     `json:"id"`
 	Email  string     `json:"email"`
 	Name   string     `json:"name"`
 	Status UserStatus `json:"status"`
+	// LastModifiedAt moves whenever the pool changes the user's attributes
+	// or status.
+	LastModifiedAt time.Time `json:"lastModifiedAt"`
+	// Attributes are the mapped user pool attributes, by domain name.
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 func (us *UserStatus) Scan(value interface{}) error {
@@ -0,0 +1,27 @@
+package auth
+
+import "context"
+
+// ClaimsResolver derives extra claims, like a tenant, after a token has been
+// validated. It may only add to the claims; the token itself is untouched.
+type ClaimsResolver interface {
+	Resolve(ctx context.Context, claims *Claims) error
+}
+
+type ClaimsResolverFunc func(ctx context.Context, claims *Claims) error
+
+func (f ClaimsResolverFunc) Resolve(ctx context.Context, claims *Claims) error {
+	return f(ctx, claims)
+}
+
+// ChainClaimsResolvers runs resolvers in order and stops at the first error.
+func ChainClaimsResolvers(resolvers ...ClaimsResolver) ClaimsResolver {
+	return ClaimsResolverFunc(func(ctx context.Context, claims *Claims) error {
+		for _, resolver := range resolvers {
+			if err := resolver.Resolve(ctx, claims); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"auth-api/src/pkg/app_error"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// NewCorrelationId tags a login challenge so the later challenge response can
+// be tied back to it in the logs.
+func NewCorrelationId() string {
+	return uuid.NewString()
+}
+
+// ValidateCorrelationId accepts an empty id so older clients keep working.
+func ValidateCorrelationId(id string) error {
+	if id == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid correlation id", fmt.Sprintf("Field: %s", "CorrelationId"))
+	}
+	return nil
+}
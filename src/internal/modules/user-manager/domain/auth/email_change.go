@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/validator"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EmailChangeStatus is where a user's email change is: requested changes
+// stay pending until the code sent to the new address is verified.
+type EmailChangeStatus string
+
+const (
+	EmailChangeNone     EmailChangeStatus = "NONE"
+	EmailChangePending  EmailChangeStatus = "PENDING_VERIFICATION"
+	EmailChangeVerified EmailChangeStatus = "VERIFIED"
+)
+
+type EmailChange struct {
+	UserId        string            `json:"-"`
+	PreviousEmail string            `json:"previousEmail,omitempty"`
+	NewEmail      string            `json:"newEmail,omitempty"`
+	Status        EmailChangeStatus `json:"status"`
+	RequestedAt   *time.Time        `json:"requestedAt,omitempty"`
+	VerifiedAt    *time.Time        `json:"verifiedAt,omitempty"`
+}
+
+// IsPending reports whether the user still has to verify their new email.
+func (e *EmailChange) IsPending() bool {
+	return e != nil && e.Status == EmailChangePending
+}
+
+// EmailChangeStore keeps the latest email change per user id.
+type EmailChangeStore interface {
+	// Get returns nil for a user that never changed their email.
+	Get(ctx context.Context, userId string) (*EmailChange, error)
+	Save(ctx context.Context, change *EmailChange) error
+}
+
+type RequestEmailChangeInput struct {
+	UserId       string
+	CurrentEmail string
+	NewEmail     string
+}
+
+func (input *RequestEmailChangeInput) Validate() error {
+	if input.UserId == "" {
+		return NewValidationError("UserId")
+	}
+	if err := validator.ValidateEmail(input.NewEmail); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "NewEmail"))
+	}
+	if input.NewEmail == input.CurrentEmail {
+		return ErrEmailUnchanged
+	}
+	return nil
+}
+
+type VerifyEmailChangeInput struct {
+	UserId string
+	Code   string
+}
+
+func (input *VerifyEmailChangeInput) Validate() error {
+	var errs app_error.ValidationErrors
+	if input.UserId == "" {
+		errs.Add(NewValidationError("UserId"))
+	}
+	if input.Code == "" {
+		errs.Add(NewValidationError("Code"))
+	}
+	return errs.Err()
+}
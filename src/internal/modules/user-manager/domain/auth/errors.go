@@ -19,4 +19,57 @@ var (
 	ErrUserNotFound               = app_error.NewApiError(404, "User not found")
 	ErrUserAlreadyConfirmed       = app_error.NewApiError(409, "User already confirmed")
 	ErrInvalidUserStatus          = app_error.NewApiError(400, "Invalid user status")
+	ErrAuthenticationResultNil    = app_error.NewApiError(500, "Failed to get authentication result")
+	ErrDeliveryMediumNotAllowed   = app_error.NewApiError(400, "Delivery medium not allowed", fmt.Sprintf("Field: %s", "DeliveryMedium"))
+	ErrDeliveryMediumUnsupported  = app_error.NewApiError(501, "Delivery medium not supported")
+	ErrResetPasswordsInProgress   = app_error.NewApiError(409, "Password reset already in progress")
+	ErrResetPasswordsJobNotFound  = app_error.NewApiError(404, "Reset passwords job not found")
+	ErrConcurrentModification     = app_error.NewApiError(409, "Concurrent modification", "Please try again")
+	ErrAliasAlreadyExists         = newFieldConflictError("Email already in use", "Email")
+	ErrPhoneAliasAlreadyExists    = newFieldConflictError("Phone number already in use", "Phone")
+	ErrLambdaTriggerFailed        = app_error.NewApiError(502, "User pool trigger failed")
+	ErrForbidden                  = app_error.NewApiError(403, "Forbidden", "User is not allowed to access this resource")
+	ErrNoGroups                   = app_error.NewApiError(403, "No groups", "User does not belong to any group")
+	ErrMissingRefreshToken        = app_error.NewApiError(400, "Missing refresh token", "Refresh token is required")
+	ErrMFAEnrollmentRequired      = app_error.NewApiError(403, "MFA enrollment required", "MFA must be set up before signing in")
+	ErrChallengeFailed            = app_error.NewApiError(403, "Challenge failed", "Bot challenge verification failed")
+	ErrChallengeUnavailable       = app_error.NewApiError(403, "Challenge unavailable", "Bot challenge could not be verified, please try again")
+	ErrPasswordMismatch           = app_error.NewApiError(400, "Password mismatch", fmt.Sprintf("Field: %s", "PasswordConfirmation"))
+	ErrNotForceChangePassword     = app_error.NewApiError(409, "User not pending password change", "User is not required to change password")
+	ErrSessionExpired             = app_error.NewApiError(401, "Session expired", "Maximum session length reached, please sign in again")
+	ErrChallengeSessionExpired    = app_error.NewApiError(401, "Challenge session expired", "The sign in session expired, please start the login again")
+	ErrAccountLocked              = app_error.NewApiError(423, "Account locked", "Too many failed sign in attempts, please try again later")
+	ErrTokenExpired               = app_error.NewApiError(401, "Token expired", "The access token expired")
+	ErrInvalidInvite              = app_error.NewApiError(400, "Invalid invite", "Invite is invalid")
+	ErrInviteExpired              = app_error.NewApiError(410, "Invite expired", "Invite has expired, please ask for a new one")
+	ErrInviteEmailMismatch        = app_error.NewApiError(400, "Invite email mismatch", "Invite was issued for another email")
+	ErrInvitesDisabled            = app_error.NewApiError(404, "Invites disabled", "Invites are not enabled")
+	ErrEmailUnchanged             = app_error.NewApiError(400, "Email unchanged", "New email is the current one")
+	ErrNoEmailChangePending       = app_error.NewApiError(409, "No email change pending", "There is no email change to verify")
+	ErrEmailChangePending         = app_error.NewApiError(409, "Email change pending", "Verify your new email before doing this")
+	ErrPreconditionNotMet         = app_error.NewApiError(409, "Precondition not met", "The user doesn't meet a precondition for this operation, e.g. a verified MFA factor")
 )
+
+// newFieldConflictError is a 409 whose Fields name the conflicting field the
+// way a validation error's do, so clients can show it next to that input.
+func newFieldConflictError(message, field string) *app_error.ApiError {
+	err := app_error.NewApiError(409, message, fmt.Sprintf("Field: %s", field))
+	err.Fields = map[string]string{field: message}
+	return err
+}
+
+func NewValidationError(field string) *app_error.ApiError {
+	return app_error.NewApiError(400, "Validation error", fmt.Sprintf("Field: %s", field))
+}
+
+// NewMissingClaimError is returned for a token without a claim the deployment
+// requires.
+func NewMissingClaimError(claim string) *app_error.ApiError {
+	return app_error.NewApiError(401, "Missing claim", fmt.Sprintf("Claim: %s", claim))
+}
+
+// NewReauthRequiredError names the action so the client can prompt for a new
+// sign in and retry that same action afterwards.
+func NewReauthRequiredError(action string) *app_error.ApiError {
+	return app_error.NewApiError(401, "Reauth required", fmt.Sprintf("Action: %s", action))
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/pkg/app_error"
+	"time"
+)
+
+const (
+	LoginAttempted events.EventType = "LoginAttempted"
+)
+
+type LoginOutcome string
+
+const (
+	LoginOutcomeSuccess   LoginOutcome = "SUCCESS"
+	LoginOutcomeChallenge LoginOutcome = "CHALLENGE"
+	LoginOutcomeFailure   LoginOutcome = "FAILURE"
+)
+
+// LoginAttemptedEvent carries the plaintext username only in process; it is
+// hashed before leaving the service.
+type LoginAttemptedEvent struct {
+	Username      string
+	IP            string
+	UserAgent     string
+	Outcome       LoginOutcome
+	Reason        string
+	CorrelationId string
+	OccurredAt    time.Time
+}
+
+func (e *LoginAttemptedEvent) GetType() events.EventType {
+	return LoginAttempted
+}
+
+func (e *LoginAttemptedEvent) Validate() error {
+	if e.Outcome == "" {
+		return app_error.NewApiError(400, "Outcome is required", "Field: Outcome")
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"auth-api/src/pkg/validator"
 	"fmt"
 	"net/http"
-	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
 )
 
 type LoginInput struct {
@@ -14,51 +16,63 @@ type LoginInput struct {
 }
 
 func (input *LoginInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
+	var errs app_error.ValidationErrors
+	errs.Add(validateEmail(input.Username))
 
 	if err := validator.ValidatePassword(input.Password); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password"))
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password")))
 	}
-	return nil
+	return errs.Err()
 }
 
 type SignUpInput struct {
-	Username string
-	Password string
-	Name     string
+	Username       string
+	Password       string
+	Name           string
+	Phone          *string
+	DeliveryMedium DeliveryMedium
+	// PasswordConfirmation is only checked when the client sends it.
+	PasswordConfirmation *string
+	// Attributes are extra user attributes by domain name, e.g. "orgId".
+	Attributes map[string]string
+	// Group is the group the account is put in, GroupUser when empty. It is
+	// set from a verified invite, never from the request.
+	Group UserGroup
 }
 
 func (input *SignUpInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
+	var errs app_error.ValidationErrors
+	errs.Add(validateEmail(input.Username))
 
 	if err := validator.ValidatePassword(input.Password); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password"))
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password")))
+	} else {
+		errs.Add(validatePasswordConfirmation(input.Password, input.PasswordConfirmation))
 	}
 
-	if err := validator.ValidateStringLength(input.Name, 3, 50); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid name length", fmt.Sprintf("Field: %s", "Name"))
+	errs.Add(validateName(input.Name))
+
+	if deliveryMedium, err := validateDeliveryMedium(input.DeliveryMedium); err != nil {
+		errs.Add(err)
+	} else {
+		input.DeliveryMedium = deliveryMedium
+		if input.DeliveryMedium == DeliveryMediumSMS && input.Phone == nil {
+			errs.Add(app_error.NewApiError(http.StatusBadRequest, "Phone is required for SMS delivery", fmt.Sprintf("Field: %s", "Phone")))
+		}
 	}
-	return nil
+	return errs.Err()
 }
 
 type ConfirmSignUpInput struct {
 	Username string
+	// ClientMetadata is handed to the post confirmation trigger.
+	ClientMetadata map[string]string
 }
 
 func (input *ConfirmSignUpInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	return nil
 }
@@ -89,23 +103,44 @@ type CreateAdminInput struct {
 	Password string
 	Name     string
 	Username string
+	// ReassignAlias moves the email alias off any other user already holding
+	// it. When false the creation fails with ErrAliasAlreadyExists instead.
+	ReassignAlias bool
+	// ClientMetadata is handed to the user pool triggers the creation runs.
+	ClientMetadata map[string]string
 }
 
 func (input *CreateAdminInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
+	var errs app_error.ValidationErrors
+	errs.Add(validateEmail(input.Username))
 
 	if err := validator.ValidatePassword(input.Password); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password"))
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password")))
 	}
 
-	if err := validator.ValidateStringLength(input.Name, 3, 50); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid name length", fmt.Sprintf("Field: %s", "Name"))
+	errs.Add(validateName(input.Name))
+	return errs.Err()
+}
+
+// AdminCreateUserInput creates a user outside any group, with a temporary
+// password. SuppressInvite skips the invitation message.
+type AdminCreateUserInput struct {
+	Username          string
+	Name              string
+	TemporaryPassword string
+	SuppressInvite    bool
+}
+
+func (input *AdminCreateUserInput) Validate() error {
+	var errs app_error.ValidationErrors
+	errs.Add(validateEmail(input.Username))
+
+	if err := validator.ValidatePassword(input.TemporaryPassword); err != nil {
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "TemporaryPassword")))
 	}
-	return nil
+
+	errs.Add(validateName(input.Name))
+	return errs.Err()
 }
 
 type AddGroupInput struct {
@@ -114,11 +149,9 @@ type AddGroupInput struct {
 }
 
 func (input *AddGroupInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	if input.GroupName != GroupAdmin && input.GroupName != GroupUser {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "GroupName"))
@@ -132,11 +165,9 @@ type RemoveGroupInput struct {
 }
 
 func (input *RemoveGroupInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	if input.GroupName != GroupAdmin && input.GroupName != GroupUser {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "GroupName"))
@@ -144,6 +175,73 @@ func (input *RemoveGroupInput) Validate() error {
 	return nil
 }
 
+type ConfirmDeviceInput struct {
+	AccessToken    string
+	DeviceKey      string
+	DeviceGroupKey string
+	DeviceName     *string
+}
+
+func (input *ConfirmDeviceInput) Validate() error {
+	if len(input.AccessToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Access token is required", fmt.Sprintf("Field: %s", "AccessToken"))
+	}
+	if len(input.DeviceKey) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Device key is required", fmt.Sprintf("Field: %s", "DeviceKey"))
+	}
+	if len(input.DeviceGroupKey) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Device group key is required", fmt.Sprintf("Field: %s", "DeviceGroupKey"))
+	}
+	if input.DeviceName != nil {
+		if err := validator.ValidateStringLength(*input.DeviceName, 1, 1024); err != nil {
+			return app_error.NewApiError(http.StatusBadRequest, "Invalid device name length", fmt.Sprintf("Field: %s", "DeviceName"))
+		}
+	}
+	return nil
+}
+
+type ListDevicesInput struct {
+	AccessToken string
+}
+
+func (input *ListDevicesInput) Validate() error {
+	if len(input.AccessToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Access token is required", fmt.Sprintf("Field: %s", "AccessToken"))
+	}
+	return nil
+}
+
+type LockoutInput struct {
+	Username string
+}
+
+func (input *LockoutInput) Validate() error {
+	return validateEmail(input.Username)
+}
+
+type ChangeUserGroupInput struct {
+	Username string
+	From     UserGroup
+	To       UserGroup
+}
+
+func (input *ChangeUserGroupInput) Validate() error {
+	if err := validateEmail(input.Username); err != nil {
+		return err
+	}
+
+	if input.From != GroupAdmin && input.From != GroupUser {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "From"))
+	}
+	if input.To != GroupAdmin && input.To != GroupUser {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "To"))
+	}
+	if input.From == input.To {
+		return app_error.NewApiError(http.StatusBadRequest, "Source and target groups must differ", fmt.Sprintf("Field: %s", "To"))
+	}
+	return nil
+}
+
 type AddMFAInput struct {
 	AccessToken string
 }
@@ -166,11 +264,9 @@ func (input *VerifyMFAInput) Validate() error {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid code", fmt.Sprintf("Field: %s", "Code"))
 	}
 
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	if len(input.Session) == 0 {
 		return app_error.NewApiError(http.StatusBadRequest, "Session is required", fmt.Sprintf("Field: %s", "Session"))
@@ -183,12 +279,7 @@ type AdminRemoveMFAInput struct {
 }
 
 func (input *AdminRemoveMFAInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type RemoveMFAInput struct {
@@ -207,12 +298,7 @@ type DeleteUserInput struct {
 }
 
 func (input *DeleteUserInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type ActivateMFAInput struct {
@@ -242,12 +328,54 @@ func (input *LogoutInput) Validate() error {
 	return nil
 }
 
-func validateEmail(username string) (string, error) {
-	lowerCaseUsername := strings.ToLower(username)
-	if err := validator.ValidateEmail(lowerCaseUsername); err != nil {
-		return "", app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Username"))
+type RevokeTokenInput struct {
+	RefreshToken string
+}
+
+func (input *RevokeTokenInput) Validate() error {
+	if len(input.RefreshToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Refresh token is required", fmt.Sprintf("Field: %s", "RefreshToken"))
+	}
+	return nil
+}
+
+// validateEmail only checks the format. Emails reach the domain already in the
+// form usernames are stored in, folded or not as the pool requires.
+func validateEmail(username string) error {
+	if err := validator.ValidateEmail(username); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Username"))
+	}
+	return nil
+}
+
+// validateName counts characters rather than bytes so non-ASCII names get the
+// same room as ASCII ones.
+func validateName(name string) error {
+	if err := validator.ValidatePrintable(name); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid characters in name", fmt.Sprintf("Field: %s", "Name"))
+	}
+	if length := utf8.RuneCountInString(name); length < 3 || length > 50 {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid name length", fmt.Sprintf("Field: %s", "Name"))
+	}
+	return nil
+}
+
+func validatePasswordConfirmation(password string, confirmation *string) error {
+	if confirmation != nil && *confirmation != password {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func validateDeliveryMedium(medium DeliveryMedium) (DeliveryMedium, error) {
+	switch medium {
+	case "":
+		return DeliveryMediumEmail, nil
+	case DeliveryMediumEmail, DeliveryMediumSMS:
+		return medium, nil
+	default:
+		return "", app_error.NewApiError(http.StatusBadRequest, "Invalid delivery medium", fmt.Sprintf("Field: %s", "DeliveryMedium"))
 	}
-	return lowerCaseUsername, nil
 }
 
 type SetPasswordInput struct {
@@ -257,11 +385,9 @@ type SetPasswordInput struct {
 }
 
 func (input *SetPasswordInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	if err := validator.ValidatePassword(input.Password); err != nil {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "Password"))
@@ -279,12 +405,15 @@ type GetUserInput struct {
 }
 
 func (input *GetUserInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
+}
+
+type ListUserGroupsInput struct {
+	Username string
+}
+
+func (input *ListUserGroupsInput) Validate() error {
+	return validateEmail(input.Username)
 }
 
 type AdminLogoutInput struct {
@@ -292,12 +421,7 @@ type AdminLogoutInput struct {
 }
 
 func (input *AdminLogoutInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type ActivateUserInput struct {
@@ -305,12 +429,7 @@ type ActivateUserInput struct {
 }
 
 func (input *ActivateUserInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type VerifyEmailInput struct {
@@ -318,27 +437,27 @@ type VerifyEmailInput struct {
 }
 
 func (input *VerifyEmailInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type GenerateAndSendCodeInput struct {
-	Username   string
-	Subject    string
-	Body       string
-	Identifier string
+	Username       string
+	Subject        string
+	Body           string
+	Identifier     string
+	DeliveryMedium DeliveryMedium
 }
 
 func (input *GenerateAndSendCodeInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
+	if err := validateEmail(input.Username); err != nil {
+		return err
+	}
+
+	deliveryMedium, err := validateDeliveryMedium(input.DeliveryMedium)
 	if err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
+	input.DeliveryMedium = deliveryMedium
 
 	if err := validator.ValidateStringLength(input.Subject, 3, 50); err != nil {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid subject length", fmt.Sprintf("Field: %s", "Subject"))
@@ -369,25 +488,37 @@ func (input *VerifyCodeInput) Validate() error {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid identifier length", fmt.Sprintf("Field: %s", "Identifier"))
 	}
 
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
-		return err
-	}
-	input.Username = lowerCaseUsername
-	return nil
+	return validateEmail(input.Username)
 }
 
 type ChangeForgotPasswordInput struct {
 	Username    string
 	NewPassword string
+	// PasswordConfirmation is only checked when the client sends it.
+	PasswordConfirmation *string
 }
 
 func (input *ChangeForgotPasswordInput) Validate() error {
-	lowerCaseUsername, err := validateEmail(input.Username)
-	if err != nil {
+	var errs app_error.ValidationErrors
+	errs.Add(validateEmail(input.Username))
+
+	if err := validator.ValidatePassword(input.NewPassword); err != nil {
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "NewPassword")))
+	} else {
+		errs.Add(validatePasswordConfirmation(input.NewPassword, input.PasswordConfirmation))
+	}
+	return errs.Err()
+}
+
+type AdminSetPermanentPasswordInput struct {
+	Username    string
+	NewPassword string
+}
+
+func (input *AdminSetPermanentPasswordInput) Validate() error {
+	if err := validateEmail(input.Username); err != nil {
 		return err
 	}
-	input.Username = lowerCaseUsername
 
 	if err := validator.ValidatePassword(input.NewPassword); err != nil {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "NewPassword"))
@@ -402,12 +533,80 @@ type ChangePasswordInput struct {
 }
 
 func (input *ChangePasswordInput) Validate() error {
+	var errs app_error.ValidationErrors
 	if err := validator.ValidatePassword(input.OldPassword); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "OldPassword"))
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "OldPassword")))
 	}
 
 	if err := validator.ValidatePassword(input.NewPassword); err != nil {
-		return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "NewPassword"))
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "NewPassword")))
+	}
+	return errs.Err()
+}
+
+type UpdateUserAttributesInput struct {
+	Id    string
+	Name  *string
+	Email *string
+	// EmailVerified is sent along with Email when set.
+	EmailVerified *bool
+	// Attributes are extra user attributes by domain name, e.g. "orgId".
+	Attributes map[string]string
+}
+
+func (input *UpdateUserAttributesInput) Validate() error {
+	var errs app_error.ValidationErrors
+	if _, err := uuid.Parse(input.Id); err != nil {
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid user ID", fmt.Sprintf("Field: %s", "Id")))
+	}
+
+	if input.Name != nil {
+		errs.Add(validateName(*input.Name))
+	}
+
+	if input.Email != nil {
+		if err := validator.ValidateEmail(*input.Email); err != nil {
+			errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email")))
+		}
+	}
+	return errs.Err()
+}
+
+type ListUsersInput struct {
+	Group     *UserGroup
+	NextToken *string
+	Limit     int32
+}
+
+func (input *ListUsersInput) Validate() error {
+	if input.Group != nil && *input.Group != GroupAdmin && *input.Group != GroupUser {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "Group"))
+	}
+
+	if input.Limit == 0 {
+		input.Limit = 60
+	}
+	if input.Limit < 1 || input.Limit > 60 {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid limit", fmt.Sprintf("Field: %s", "Limit"))
+	}
+	return nil
+}
+
+type DisableUserInput struct {
+	Username string
+}
+
+func (input *DisableUserInput) Validate() error {
+	return validateEmail(input.Username)
+}
+
+type AdminResetPasswordInput struct {
+	Id string
+}
+
+func (input *AdminResetPasswordInput) Validate() error {
+	if _, err := uuid.Parse(input.Id); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid user ID", fmt.Sprintf("Field: %s", "Id"))
 	}
 	return nil
 }
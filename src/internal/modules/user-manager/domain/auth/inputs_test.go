@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"auth-api/src/pkg/app_error"
+	"strings"
+	"testing"
+)
+
+func TestNameValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantMessage string
+	}{
+		{name: "ascii name", value: "Ada Lovelace"},
+		{name: "unicode name counted in characters", value: strings.Repeat("é", 50)},
+		{name: "cjk name", value: "山田太郎"},
+		{name: "too short", value: "Al", wantMessage: "Invalid name length"},
+		{name: "too long", value: strings.Repeat("a", 51), wantMessage: "Invalid name length"},
+		{name: "too long in characters", value: strings.Repeat("é", 51), wantMessage: "Invalid name length"},
+		{name: "newline", value: "Ada\nLovelace", wantMessage: "Invalid characters in name"},
+		{name: "nul byte", value: "Ada\x00", wantMessage: "Invalid characters in name"},
+		{name: "invalid utf-8", value: "Ada \xff\xfe", wantMessage: "Invalid characters in name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := map[string]interface{ Validate() error }{
+				"signup":       &SignUpInput{Username: "ada@example.com", Password: "correct-horse", Name: tt.value},
+				"create admin": &CreateAdminInput{Username: "ada@example.com", Password: "correct-horse", Name: tt.value},
+			}
+			for kind, input := range inputs {
+				err := input.Validate()
+				if tt.wantMessage == "" {
+					if err != nil {
+						t.Errorf("%s: Validate() = %v, want nil", kind, err)
+					}
+					continue
+				}
+				apiErr, ok := err.(*app_error.ApiError)
+				if !ok {
+					t.Fatalf("%s: Validate() = %v, want an ApiError", kind, err)
+				}
+				if apiErr.StatusCode != 400 || apiErr.Message != tt.wantMessage || apiErr.Description != "Field: Name" {
+					t.Errorf("%s: Validate() = %+v, want 400 %q on Name", kind, apiErr, tt.wantMessage)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/validator"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Invite is what an admin issued invite token carries: the email allowed to
+// sign up with it and the group the new account is put in.
+type Invite struct {
+	Email     string
+	Role      UserGroup
+	ExpiresAt time.Time
+}
+
+// InviteTokens signs and checks invite tokens. Verify returns ErrInvalidInvite
+// for a token it didn't sign, or that was altered, and ErrInviteExpired for
+// one past its expiry.
+type InviteTokens interface {
+	Issue(invite Invite) (string, error)
+	Verify(token string) (*Invite, error)
+}
+
+type IssueInviteInput struct {
+	Email string
+	Role  UserGroup
+}
+
+func (input *IssueInviteInput) Validate() error {
+	var errs app_error.ValidationErrors
+	if err := validator.ValidateEmail(input.Email); err != nil {
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email")))
+	}
+	if input.Role != GroupAdmin && input.Role != GroupUser {
+		errs.Add(app_error.NewApiError(http.StatusBadRequest, "Invalid user group", fmt.Sprintf("Field: %s", "Role")))
+	}
+	return errs.Err()
+}
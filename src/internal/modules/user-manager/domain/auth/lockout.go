@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// LockoutState is what a LockoutStore keeps for one username.
+type LockoutState struct {
+	Failures       int
+	FirstFailureAt time.Time
+	LastFailureAt  time.Time
+}
+
+// LockoutStore keeps failed password sign ins per username for the lockout
+// policy. Usernames are already normalized when they get here.
+type LockoutStore interface {
+	// Get returns the zero state for a username without failures.
+	Get(ctx context.Context, username string) (LockoutState, error)
+	// RecordFailure counts a failed attempt, starting over when the first
+	// counted one is older than window.
+	RecordFailure(ctx context.Context, username string, now time.Time, window time.Duration) (LockoutState, error)
+	Reset(ctx context.Context, username string) error
+}
@@ -0,0 +1,14 @@
+package auth
+
+// NextStepMFAEnrollmentRequired is returned instead of tokens when an admin
+// without MFA signs in and admin MFA is enforced.
+const NextStepMFAEnrollmentRequired = "MFA_ENROLLMENT_REQUIRED"
+
+func (c *Claims) HasGroup(group UserGroup) bool {
+	for _, g := range c.UserGroups {
+		if g == string(group) {
+			return true
+		}
+	}
+	return false
+}
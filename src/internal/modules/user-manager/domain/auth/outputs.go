@@ -3,14 +3,35 @@ package auth
 import (
 	"auth-api/src/pkg/app_error"
 	"context"
+	"time"
 )
 
+type DeviceMetadata struct {
+	DeviceKey      string `json:"deviceKey"`
+	DeviceGroupKey string `json:"deviceGroupKey"`
+}
+
 type LoginOutput struct {
-	AccessToken  *string `json:"accessToken,omitempty"`
-	IdToken      *string `json:"idToken,omitempty"`
-	RefreshToken *string `json:"refreshToken,omitempty"`
-	Session      *string `json:"session,omitempty"`
-	NextStep     *string `json:"nextStep,omitempty"`
+	AccessToken       *string         `json:"accessToken,omitempty"`
+	IdToken           *string         `json:"idToken,omitempty"`
+	RefreshToken      *string         `json:"refreshToken,omitempty"`
+	Session           *string         `json:"session,omitempty"`
+	NextStep          *string         `json:"nextStep,omitempty"`
+	NewDeviceMetadata *DeviceMetadata `json:"newDeviceMetadata,omitempty"`
+	// CorrelationId is only set alongside a challenge and should be echoed
+	// back when answering it.
+	CorrelationId *string `json:"correlationId,omitempty"`
+	// SecretCode comes with MFA_ENROLLMENT_REQUIRED; it is the TOTP secret to
+	// register in the authenticator before completing the enrollment.
+	SecretCode *string `json:"secretCode,omitempty"`
+	// ChallengeParameters are the parameters of the challenge in NextStep,
+	// minus anything secret, so the client can render any challenge the
+	// same way.
+	ChallengeParameters map[string]string `json:"challengeParameters,omitempty"`
+}
+
+type UserGroupsOutput struct {
+	Groups []string `json:"groups"`
 }
 
 type SignUpOutput struct {
@@ -47,14 +68,45 @@ func (output *SignUpOutput) Rollback(ctx context.Context) error {
 }
 
 type ConfirmSignUpOutput struct {
+	// Tokens is only set when auto login after confirmation is enabled and the
+	// password was supplied.
+	Tokens *LoginOutput `json:"tokens,omitempty"`
 }
 
+// RefreshTokenOutput leaves out a token the client doesn't get in the body
+// along with its expiry.
 type RefreshTokenOutput struct {
-	AccessToken string `json:"accessToken"`
-	IdToken     string `json:"idToken"`
+	AccessToken          string     `json:"accessToken,omitempty"`
+	IdToken              string     `json:"idToken,omitempty"`
+	AccessTokenExpiresIn int64      `json:"accessTokenExpiresIn,omitempty"`
+	AccessTokenExpiresAt *time.Time `json:"accessTokenExpiresAt,omitempty"`
+	IdTokenExpiresIn     int64      `json:"idTokenExpiresIn,omitempty"`
+	IdTokenExpiresAt     *time.Time `json:"idTokenExpiresAt,omitempty"`
+
+	// Claims is only set when the client asked for it.
+	Claims *RefreshedClaims `json:"claims,omitempty"`
+}
+
+// RefreshedClaims is what the refreshed id token says about the user, so a
+// client can update its cached user without calling /auth/me.
+type RefreshedClaims struct {
+	Id            string   `json:"id"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"emailVerified"`
+	Name          string   `json:"name"`
+	Groups        []string `json:"groups"`
+}
+
+// IdTokenOutput is a refresh trimmed down to the id token, for clients that
+// only need the profile claims again after updating them.
+type IdTokenOutput struct {
+	IdToken          string    `json:"idToken"`
+	IdTokenExpiresIn int64     `json:"idTokenExpiresIn"`
+	IdTokenExpiresAt time.Time `json:"idTokenExpiresAt"`
 }
 
 type GetMeOutput struct {
+	Id       string `json:"id"`
 	Username string `json:"username"`
 	Name     string `json:"name"`
 }
@@ -62,7 +114,10 @@ type GetMeOutput struct {
 type CreateAdminOutput struct {
 	Username string `json:"username"`
 	Id       string `json:"id"`
-	svc      AuthService
+	// ReassignedAliasFrom is the id of the user the email alias was moved
+	// off, if any.
+	ReassignedAliasFrom string `json:"-"`
+	svc                 AuthService
 }
 
 func NewCreateAdminOutput(id string, username string, svc AuthService) *CreateAdminOutput {
@@ -94,6 +149,59 @@ type AddMFAOutput struct {
 	Session    *string `json:"session,omitempty"`
 }
 
+type SetupMFAOutput struct {
+	SecretCode string  `json:"secretCode"`
+	OtpAuthURI string  `json:"otpAuthUri"`
+	Session    *string `json:"session,omitempty"`
+}
+
+type ListUsersOutput struct {
+	Users     []User  `json:"users"`
+	NextToken *string `json:"nextToken,omitempty"`
+}
+
+type ResetPasswordsOutput struct {
+	ConfirmationRequired bool               `json:"confirmationRequired"`
+	Job                  *ResetPasswordsJob `json:"job,omitempty"`
+}
+
+type ConfirmDeviceOutput struct {
+	UserConfirmationNecessary bool `json:"userConfirmationNecessary"`
+	// DevicePassword has to be kept by the client to authenticate the device
+	// later; it is never stored server side.
+	DevicePassword string `json:"devicePassword"`
+}
+
+type Device struct {
+	DeviceKey           string     `json:"deviceKey"`
+	Name                string     `json:"name,omitempty"`
+	LastAuthenticatedAt *time.Time `json:"lastAuthenticatedAt,omitempty"`
+	LastIP              string     `json:"lastIp,omitempty"`
+	// Remembered devices can skip MFA on later logins.
+	Remembered bool `json:"remembered"`
+	// Current marks the device the access token was issued to.
+	Current bool `json:"current"`
+}
+
+type ListDevicesOutput struct {
+	Devices []Device `json:"devices"`
+}
+
+type LockoutStatusOutput struct {
+	Username    string     `json:"username"`
+	Enabled     bool       `json:"enabled"`
+	Locked      bool       `json:"locked"`
+	Failures    int        `json:"failures"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+}
+
+type CodeDeliveryDetails struct {
+	Destination    string         `json:"destination"`
+	DeliveryMedium DeliveryMedium `json:"deliveryMedium"`
+	AttributeName  string         `json:"attributeName"`
+}
+
 type GenerateAndSendCodeOutput struct {
-	Code string `json:"code"`
+	Code         string              `json:"code"`
+	CodeDelivery CodeDeliveryDetails `json:"codeDelivery"`
 }
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"auth-api/src/pkg/app_error"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Special characters accepted by Cognito when RequireSymbols is set.
+const passwordSymbols = "^$*.[]{}()?\"!@#%&/\\,><':;|_~`=+- "
+
+type PasswordPolicy struct {
+	MinimumLength                 int32 `json:"minimumLength"`
+	RequireUppercase              bool  `json:"requireUppercase"`
+	RequireLowercase              bool  `json:"requireLowercase"`
+	RequireNumbers                bool  `json:"requireNumbers"`
+	RequireSymbols                bool  `json:"requireSymbols"`
+	TemporaryPasswordValidityDays int32 `json:"temporaryPasswordValidityDays"`
+}
+
+const (
+	PasswordRuleMinLength = "MIN_LENGTH"
+	PasswordRuleUppercase = "UPPERCASE"
+	PasswordRuleLowercase = "LOWERCASE"
+	PasswordRuleNumber    = "NUMBER"
+	PasswordRuleSymbol    = "SYMBOL"
+)
+
+type PasswordRuleResult struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Check evaluates every rule the policy enables, so callers can show which
+// ones a candidate password still misses.
+func (p *PasswordPolicy) Check(password string) []PasswordRuleResult {
+	var hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case strings.ContainsRune(passwordSymbols, r):
+			hasSymbol = true
+		}
+	}
+
+	results := []PasswordRuleResult{{
+		Rule:    PasswordRuleMinLength,
+		Passed:  int32(len(password)) >= p.MinimumLength,
+		Message: fmt.Sprintf("Password must be at least %d characters", p.MinimumLength),
+	}}
+	if p.RequireUppercase {
+		results = append(results, PasswordRuleResult{Rule: PasswordRuleUppercase, Passed: hasUpper, Message: "Password must contain an uppercase letter"})
+	}
+	if p.RequireLowercase {
+		results = append(results, PasswordRuleResult{Rule: PasswordRuleLowercase, Passed: hasLower, Message: "Password must contain a lowercase letter"})
+	}
+	if p.RequireNumbers {
+		results = append(results, PasswordRuleResult{Rule: PasswordRuleNumber, Passed: hasNumber, Message: "Password must contain a number"})
+	}
+	if p.RequireSymbols {
+		results = append(results, PasswordRuleResult{Rule: PasswordRuleSymbol, Passed: hasSymbol, Message: "Password must contain a special character"})
+	}
+	return results
+}
+
+func (p *PasswordPolicy) Validate(password, field string) error {
+	for _, result := range p.Check(password) {
+		if !result.Passed {
+			return app_error.NewApiError(http.StatusBadRequest, result.Message, fmt.Sprintf("Field: %s", field))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package auth
+
+import "context"
+
+const (
+	PreAuthActionLogin  = "login"
+	PreAuthActionSignup = "signup"
+)
+
+// PreAuthChallenge checks that the caller solved a bot challenge, like a
+// CAPTCHA, before their credentials are looked at. It returns
+// ErrChallengeFailed for a bad token and ErrChallengeUnavailable
+// when the provider couldn't give an answer.
+type PreAuthChallenge interface {
+	Verify(ctx context.Context, input PreAuthChallengeInput) error
+}
+
+type PreAuthChallengeInput struct {
+	Token  string
+	IP     string
+	Action string
+}
+
+type PreAuthChallengeFunc func(ctx context.Context, input PreAuthChallengeInput) error
+
+func (f PreAuthChallengeFunc) Verify(ctx context.Context, input PreAuthChallengeInput) error {
+	return f(ctx, input)
+}
+
+// NoopPreAuthChallenge lets every request through.
+var NoopPreAuthChallenge PreAuthChallenge = PreAuthChallengeFunc(func(ctx context.Context, input PreAuthChallengeInput) error {
+	return nil
+})
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// ResetPasswordsJobStatus is where a reset passwords job is. Only one job
+// can be running at a time across all instances.
+type ResetPasswordsJobStatus string
+
+const (
+	ResetPasswordsJobRunning   ResetPasswordsJobStatus = "RUNNING"
+	ResetPasswordsJobCompleted ResetPasswordsJobStatus = "COMPLETED"
+	ResetPasswordsJobFailed    ResetPasswordsJobStatus = "FAILED"
+)
+
+type ResetPasswordsJob struct {
+	Id         string                  `json:"id"`
+	ActorId    string                  `json:"actorId"`
+	Group      string                  `json:"group"`
+	Status     ResetPasswordsJobStatus `json:"status"`
+	Processed  int                     `json:"processed"`
+	Succeeded  int                     `json:"succeeded"`
+	Failed     int                     `json:"failed"`
+	Error      string                  `json:"error,omitempty"`
+	StartedAt  time.Time               `json:"startedAt"`
+	FinishedAt *time.Time              `json:"finishedAt,omitempty"`
+}
+
+// ResetPasswordsJobStore keeps reset passwords jobs and holds the lock that
+// lets only one of them run.
+type ResetPasswordsJobStore interface {
+	// Start saves job as running, or returns ErrResetPasswordsInProgress when
+	// another job is. A running job started before staleBefore belonged to an
+	// instance that went away and is marked failed first.
+	Start(ctx context.Context, job *ResetPasswordsJob, staleBefore time.Time) error
+	// Save updates the counts, status and error of a started job.
+	Save(ctx context.Context, job *ResetPasswordsJob) error
+	// Get returns nil for an unknown id.
+	Get(ctx context.Context, id string) (*ResetPasswordsJob, error)
+}
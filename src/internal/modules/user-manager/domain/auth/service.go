@@ -8,19 +8,25 @@ type AuthService interface {
 	Login(ctx context.Context, input LoginInput) (*LoginOutput, error)
 	SignUp(ctx context.Context, input SignUpInput) (*SignUpOutput, error)
 	DeleteUser(ctx context.Context, input DeleteUserInput) error
+	DisableUser(ctx context.Context, input DisableUserInput) error
 	ConfirmSignUp(ctx context.Context, input ConfirmSignUpInput) (*ConfirmSignUpOutput, error)
 	GetMe(ctx context.Context, input GetMeInput) (*GetMeOutput, error)
 	ValidateToken(ctx context.Context, token string) (*Claims, error)
 	AddGroup(ctx context.Context, input AddGroupInput) error
 	RemoveGroup(ctx context.Context, input RemoveGroupInput) error
+	ListUserGroups(ctx context.Context, input ListUserGroupsInput) (*UserGroupsOutput, error)
+	ConfirmDevice(ctx context.Context, input ConfirmDeviceInput) (*ConfirmDeviceOutput, error)
+	ListDevices(ctx context.Context, input ListDevicesInput) (*ListDevicesOutput, error)
 	RefreshToken(ctx context.Context, input RefreshTokenInput) (*RefreshTokenOutput, error)
 	CreateAdmin(ctx context.Context, input CreateAdminInput) (*CreateAdminOutput, error)
+	AdminCreateUser(ctx context.Context, input AdminCreateUserInput) error
 	AddMFA(ctx context.Context, input AddMFAInput) (*AddMFAOutput, error)
 	ActivateMFA(ctx context.Context, input ActivateMFAInput) error
 	VerifyMFA(ctx context.Context, input VerifyMFAInput) (*LoginOutput, error)
 	AdminRemoveMFA(ctx context.Context, input AdminRemoveMFAInput) error
 	RemoveMFA(ctx context.Context, input RemoveMFAInput) error
 	Logout(ctx context.Context, input LogoutInput) error
+	RevokeToken(ctx context.Context, input RevokeTokenInput) error
 	SetPassword(ctx context.Context, input SetPasswordInput) (*LoginOutput, error)
 	GetUser(ctx context.Context, input GetUserInput) (*User, error)
 	AdminLogout(ctx context.Context, input AdminLogoutInput) error
@@ -28,5 +34,10 @@ type AuthService interface {
 	GenerateAndSendCode(ctx context.Context, input GenerateAndSendCodeInput) (*GenerateAndSendCodeOutput, error)
 	VerifyCode(ctx context.Context, input VerifyCodeInput) error
 	ChangeForgotPassword(ctx context.Context, input ChangeForgotPasswordInput) error
+	AdminSetPermanentPassword(ctx context.Context, input AdminSetPermanentPasswordInput) error
 	ChangePassword(ctx context.Context, input ChangePasswordInput) error
+	UpdateUserAttributes(ctx context.Context, input UpdateUserAttributesInput) error
+	ListUsers(ctx context.Context, input ListUsersInput) (*ListUsersOutput, error)
+	AdminResetPassword(ctx context.Context, input AdminResetPasswordInput) error
+	GetPasswordPolicy(ctx context.Context) (*PasswordPolicy, error)
 }
@@ -0,0 +1,23 @@
+package auth
+
+import "time"
+
+// Audit actions the stats are aggregated from. MFA entries keep the affected
+// username in Details so the current state per user can be derived.
+const (
+	AuditActionLoginSuccess   = "LOGIN_SUCCESS"
+	AuditActionLoginChallenge = "LOGIN_CHALLENGE"
+	AuditActionLoginFailure   = "LOGIN_FAILURE"
+	AuditActionMFAEnabled     = "MFA_ENABLED"
+	AuditActionMFADisabled    = "MFA_DISABLED"
+)
+
+type StatsOutput struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Logins          int64     `json:"logins"`
+	FailedLogins    int64     `json:"failedLogins"`
+	Signups         int64     `json:"signups"`
+	ActiveMFAUsers  int64     `json:"activeMfaUsers"`
+	FailedLoginRate float64   `json:"failedLoginRate"`
+}
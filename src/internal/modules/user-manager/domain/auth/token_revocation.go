@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// TokenRevocationStore records when a user's tokens were cut off. Tokens are
+// validated locally, so without it they keep working until they expire; the
+// store is shared, so every instance refuses them.
+type TokenRevocationStore interface {
+	Revoke(ctx context.Context, sub string, at time.Time) error
+	// RevokedAt returns nil for a user whose tokens were never revoked.
+	RevokedAt(ctx context.Context, sub string) (*time.Time, error)
+}
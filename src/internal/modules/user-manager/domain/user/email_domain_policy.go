@@ -0,0 +1,43 @@
+package user
+
+import "strings"
+
+// EmailDomainPolicy restricts which email domains may sign up. Entries are
+// either exact domains ("example.com") or wildcards matching any subdomain
+// ("*.example.com"). An empty allowlist allows every domain that isn't blocked.
+type EmailDomainPolicy struct {
+	Allowed []string
+	Blocked []string
+}
+
+func (p EmailDomainPolicy) Allows(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+
+	for _, pattern := range p.Blocked {
+		if matchDomain(pattern, domain) {
+			return false
+		}
+	}
+
+	if len(p.Allowed) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allowed {
+		if matchDomain(pattern, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDomain(pattern, domain string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(domain, "."+suffix)
+	}
+	return domain == pattern
+}
@@ -3,7 +3,9 @@ package user
 import "auth-api/src/pkg/app_error"
 
 var (
-	ErrUserNotFound      = app_error.NewApiError(404, "User not found", "Field: id")
-	ErrUserAlreadyExists = app_error.NewApiError(409, "User already exists", "Field: email")
-	ErrInvalidEmail      = app_error.NewApiError(400, "Invalid email", "Field: email")
+	ErrUserNotFound          = app_error.NewApiError(404, "User not found", "Field: id")
+	ErrUserAlreadyExists     = app_error.NewApiError(409, "User already exists", "Field: email")
+	ErrInvalidEmail          = app_error.NewApiError(400, "Invalid email", "Field: email")
+	ErrSignupDisabled        = app_error.NewApiError(403, "Signup disabled", "Public registration is disabled")
+	ErrEmailDomainNotAllowed = app_error.NewApiError(403, "Email domain not allowed", "Field: email")
 )
@@ -1,15 +1,21 @@
 package user
 
-import "auth-api/src/internal/events"
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+)
 
 const (
 	UserRegistered events.EventType = "UserRegistered"
 	// UserConfirmed  events.EventType = "UserConfirmed"
 )
 
+const AuditActionSignup = "SIGNUP"
+
 type UserRegisteredEvent struct {
 	Email             string
 	NeedsVerification bool
+	DeliveryMedium    auth.DeliveryMedium
 }
 
 func (e *UserRegisteredEvent) GetType() events.EventType {
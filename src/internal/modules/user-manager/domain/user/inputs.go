@@ -5,7 +5,6 @@ import (
 	"auth-api/src/pkg/validator"
 	"fmt"
 	"net/http"
-	"strings"
 )
 
 type CreateUserInput struct {
@@ -22,11 +21,9 @@ func (input *CreateUserInput) Validate() error {
 	}
 	input.ID = userID
 
-	lowerCaseEmail, err := validateEmail(input.Email)
-	if err != nil {
+	if err := validateEmail(input.Email); err != nil {
 		return err
 	}
-	input.Email = lowerCaseEmail
 
 	if err := validator.ValidateStringLength(input.Name, 3, 100); err != nil {
 		return app_error.NewApiError(http.StatusBadRequest, "Invalid name length", fmt.Sprintf("Field: %s", "Name"))
@@ -55,11 +52,9 @@ func (input *UpdateUserInput) Validate() error {
 	input.ID = userID
 
 	if input.Email != nil {
-		lowerCaseEmail, err := validateEmail(*input.Email)
-		if err != nil {
+		if err := validateEmail(*input.Email); err != nil {
 			return err
 		}
-		input.Email = &lowerCaseEmail
 	}
 
 	if input.Name != nil {
@@ -106,18 +101,35 @@ type GetUserByEmailInput struct {
 }
 
 func (input *GetUserByEmailInput) Validate() error {
-	lowerCaseEmail, err := validateEmail(input.Email)
-	if err != nil {
-		return err
+	return validateEmail(input.Email)
+}
+
+const (
+	DefaultListUsersLimit = 50
+	MaxListUsersLimit     = 100
+)
+
+// ListUsersInput pages through users ordered by ID. After is the last ID of
+// the previous page, nil for the first one. A zero Limit means
+// DefaultListUsersLimit.
+type ListUsersInput struct {
+	Limit int
+	After *UserID
+}
+
+func (input *ListUsersInput) Validate() error {
+	if input.Limit == 0 {
+		input.Limit = DefaultListUsersLimit
+	}
+	if input.Limit < 1 || input.Limit > MaxListUsersLimit {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid limit", fmt.Sprintf("Field: %s", "Limit"))
 	}
-	input.Email = lowerCaseEmail
 	return nil
 }
 
-func validateEmail(email string) (string, error) {
-	lowerCaseEmail := strings.ToLower(email)
-	if err := validator.ValidateEmail(lowerCaseEmail); err != nil {
-		return "", app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email"))
+func validateEmail(email string) error {
+	if err := validator.ValidateEmail(email); err != nil {
+		return app_error.NewApiError(http.StatusBadRequest, "Invalid email format", fmt.Sprintf("Field: %s", "Email"))
 	}
-	return lowerCaseEmail, nil
+	return nil
 }
@@ -83,6 +83,7 @@ func (d *DeleteUserOutput) Rollback(ctx context.Context) error {
 		return nil
 	}
 	createUserInput := &CreateUserInput{
+		ID:    d.backup.ID,
 		Email: d.backup.Email,
 		Name:  d.backup.Name,
 		Phone: d.backup.Phone,
@@ -97,3 +98,10 @@ func (d *DeleteUserOutput) Rollback(ctx context.Context) error {
 	}
 	return nil
 }
+
+// ListUsersOutput holds a page of users. Next is the After of the following
+// page, nil on the last one.
+type ListUsersOutput struct {
+	Users []User  `json:"users"`
+	Next  *UserID `json:"next,omitempty"`
+}
@@ -6,4 +6,5 @@ type UserRepository interface {
 	Create(input *CreateUserInput) error
 	Update(user *UpdateUserInput) error
 	Delete(id *DeleteUserInput) error
+	List(input *ListUsersInput) (*ListUsersOutput, error)
 }
@@ -6,4 +6,5 @@ type UserService interface {
 	Create(input *CreateUserInput) (*CreateUserOutput, error)
 	Update(input *UpdateUserInput) (*UpdateUserOutput, error)
 	Delete(input *DeleteUserInput) (*DeleteUserOutput, error)
+	List(input *ListUsersInput) (*ListUsersOutput, error)
 }
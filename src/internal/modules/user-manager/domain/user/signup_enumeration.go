@@ -0,0 +1,12 @@
+package user
+
+import "time"
+
+// SignupEnumerationProtection makes signup answer an email that already has an
+// account the same way as a new one: it reports success and sends nothing.
+// Every attempt also takes at least MinDuration, so the answer can't be told
+// apart by how long it took either.
+type SignupEnumerationProtection struct {
+	Enabled     bool
+	MinDuration time.Duration
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/webhook/domain/webhook"
+	"auth-api/src/pkg/logger"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const securityEventLoginAttempt = "auth.login_attempt"
+
+type LoginAttemptPayload struct {
+	UsernameHash  string    `json:"usernameHash"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"userAgent"`
+	Outcome       string    `json:"outcome"`
+	Reason        string    `json:"reason,omitempty"`
+	CorrelationId string    `json:"correlationId,omitempty"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+type SecurityWebhookHandler struct {
+	logger    logger.Logger
+	publisher webhook.WebhookPublisher
+	hashKey   string
+}
+
+func NewSecurityWebhookHandler(logger logger.Logger, publisher webhook.WebhookPublisher, hashKey string) events.EventHandler {
+	return &SecurityWebhookHandler{
+		logger:    logger,
+		publisher: publisher,
+		hashKey:   hashKey,
+	}
+}
+
+func (h *SecurityWebhookHandler) Handle(event events.Event) error {
+	loginAttemptedEvent, ok := event.(*auth.LoginAttemptedEvent)
+	if !ok {
+		return nil
+	}
+
+	if err := loginAttemptedEvent.Validate(); err != nil {
+		return err
+	}
+
+	payload := LoginAttemptPayload{
+		UsernameHash:  HashUsername(loginAttemptedEvent.Username, h.hashKey),
+		IP:            loginAttemptedEvent.IP,
+		UserAgent:     loginAttemptedEvent.UserAgent,
+		Outcome:       string(loginAttemptedEvent.Outcome),
+		Reason:        loginAttemptedEvent.Reason,
+		CorrelationId: loginAttemptedEvent.CorrelationId,
+		OccurredAt:    loginAttemptedEvent.OccurredAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.publisher.Publish(ctx, securityEventLoginAttempt, payload); err != nil {
+		h.logger.Error("failed to publish login attempt: %v", err)
+		return err
+	}
+	return nil
+}
+
+// HashUsername lets the SIEM correlate attempts on the same account without
+// ever seeing the address. With a key it is an HMAC, which stops anyone holding
+// the events from confirming a guessed address.
+func HashUsername(username, key string) string {
+	normalized := []byte(strings.ToLower(strings.TrimSpace(username)))
+	if key == "" {
+		sum := sha256.Sum256(normalized)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(normalized)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+var loginAuditActions = map[auth.LoginOutcome]string{
+	auth.LoginOutcomeSuccess:   auth.AuditActionLoginSuccess,
+	auth.LoginOutcomeChallenge: auth.AuditActionLoginChallenge,
+	auth.LoginOutcomeFailure:   auth.AuditActionLoginFailure,
+}
+
+type RecordLoginAttemptHandler struct {
+	logger logger.Logger
+	audit  audit.AuditService
+}
+
+func NewRecordLoginAttemptHandler(logger logger.Logger, audit audit.AuditService) events.EventHandler {
+	return &RecordLoginAttemptHandler{
+		logger: logger,
+		audit:  audit,
+	}
+}
+
+func (h *RecordLoginAttemptHandler) Handle(event events.Event) error {
+	loginAttemptedEvent, ok := event.(*auth.LoginAttemptedEvent)
+	if !ok {
+		return nil
+	}
+
+	if err := loginAttemptedEvent.Validate(); err != nil {
+		return err
+	}
+
+	action, ok := loginAuditActions[loginAttemptedEvent.Outcome]
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.audit.Record(ctx, audit.RecordInput{
+		Actor:   loginAttemptedEvent.Username,
+		Action:  action,
+		Details: loginAttemptedEvent.Reason,
+	}); err != nil {
+		h.logger.Error("failed to record login attempt: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package user
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+type RecordSignupHandler struct {
+	logger logger.Logger
+	audit  audit.AuditService
+}
+
+func NewRecordSignupHandler(logger logger.Logger, audit audit.AuditService) events.EventHandler {
+	return &RecordSignupHandler{
+		logger: logger,
+		audit:  audit,
+	}
+}
+
+func (h *RecordSignupHandler) Handle(event events.Event) error {
+	userRegisteredEvent, ok := event.(*user.UserRegisteredEvent)
+	if !ok {
+		return nil
+	}
+
+	if err := userRegisteredEvent.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.audit.Record(ctx, audit.RecordInput{
+		Actor:  userRegisteredEvent.Email,
+		Action: user.AuditActionSignup,
+	}); err != nil {
+		h.logger.Error("failed to record signup: %v", err)
+		return err
+	}
+	return nil
+}
@@ -34,8 +34,9 @@ func (h *SendConfirmationHandler) Handle(event events.Event) error {
 		return nil
 	}
 
-	if err := h.authUsecases.SendConfirmationCode.Execute(context.TODO(), auth.SendConfirmationCodeInput{
-		Username: userRegisteredEvent.Email,
+	if _, err := h.authUsecases.SendConfirmationCode.Execute(context.TODO(), auth.SendConfirmationCodeInput{
+		Username:       userRegisteredEvent.Email,
+		DeliveryMedium: userRegisteredEvent.DeliveryMedium,
 	}); err != nil {
 		h.logger.Error("failed to send confirmation code: %v", err)
 		return err
@@ -70,7 +70,7 @@ func (r *AdminRepository) Update(input *admin.UpdateAdminInput) error {
 		return err
 	}
 
-	query := `UPDATE admins SET name = COALESCE($1, name), email = COALESCE($2, email) WHERE id = $4`
+	query := `UPDATE admins SET name = COALESCE($1, name), email = COALESCE($2, email) WHERE id = $3`
 	_, err := r.db.Exec(query, input.Name, input.Email, input.ID.String())
 	if err != nil {
 		r.logger.Error("Error updating admin: %v", err)
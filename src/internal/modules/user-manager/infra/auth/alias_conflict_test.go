@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/smithy-go"
+)
+
+// aliasCognito is a pool where owner already has the email as an alias;
+// creating a user only succeeds when the alias may be moved.
+type aliasCognito struct {
+	CognitoAPI
+	owner  string
+	forced []bool
+}
+
+func (f *aliasCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func (f *aliasCognito) ListUsers(ctx context.Context, params *cognito.ListUsersInput, optFns ...func(*cognito.Options)) (*cognito.ListUsersOutput, error) {
+	if f.owner == "" {
+		return &cognito.ListUsersOutput{}, nil
+	}
+	return &cognito.ListUsersOutput{Users: []types.UserType{{Attributes: []types.AttributeType{
+		{Name: aws.String("sub"), Value: aws.String(f.owner)},
+		{Name: aws.String("email_verified"), Value: aws.String("true")},
+	}}}}, nil
+}
+
+func (f *aliasCognito) AdminCreateUser(ctx context.Context, params *cognito.AdminCreateUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminCreateUserOutput, error) {
+	f.forced = append(f.forced, params.ForceAliasCreation)
+	if f.owner != "" && !params.ForceAliasCreation {
+		return nil, &smithy.GenericAPIError{Code: "AliasExistsException", Message: "An account with the email already exists."}
+	}
+	return &cognito.AdminCreateUserOutput{User: &types.UserType{Attributes: []types.AttributeType{
+		{Name: aws.String("sub"), Value: aws.String("new-admin")},
+	}}}, nil
+}
+
+func (f *aliasCognito) AdminAddUserToGroup(ctx context.Context, params *cognito.AdminAddUserToGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminAddUserToGroupOutput, error) {
+	return &cognito.AdminAddUserToGroupOutput{}, nil
+}
+
+func TestCreateAdminAliasConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      string
+		reassign   bool
+		wantErr    error
+		wantFrom   string
+		wantForced bool
+	}{
+		{name: "refused when another user has the alias", owner: "old-owner", wantErr: auth.ErrAliasAlreadyExists},
+		{name: "reassigned when allowed", owner: "old-owner", reassign: true, wantForced: true, wantFrom: "old-owner"},
+		{name: "no conflict, refuse", wantForced: false},
+		{name: "no conflict, reassign", reassign: true, wantForced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &aliasCognito{owner: tt.owner}
+			c := &cognitoClient{client: fake, userPoolId: "us-east-1_AbC123", logger: logger.Nop{}}
+
+			out, err := c.CreateAdmin(context.Background(), auth.CreateAdminInput{
+				Username:      "admin@example.com",
+				Password:      "Str0ng!Passw0rd",
+				Name:          "Admin",
+				ReassignAlias: tt.reassign,
+			})
+			if err != tt.wantErr {
+				t.Fatalf("CreateAdmin error = %v, want %v", err, tt.wantErr)
+			}
+			if len(fake.forced) != 1 || fake.forced[0] != tt.wantForced {
+				t.Errorf("ForceAliasCreation = %v, want %v", fake.forced, tt.wantForced)
+			}
+			if err != nil {
+				return
+			}
+			if out.Id != "new-admin" || out.ReassignedAliasFrom != tt.wantFrom {
+				t.Errorf("output = %s from %q, want new-admin from %q", out.Id, out.ReassignedAliasFrom, tt.wantFrom)
+			}
+		})
+	}
+}
+
+// aliasTakenCognito fails every attribute write with an AliasExistsException
+// carrying message.
+type aliasTakenCognito struct {
+	CognitoAPI
+	message string
+}
+
+func (f aliasTakenCognito) err() error {
+	return &smithy.GenericAPIError{Code: "AliasExistsException", Message: f.message}
+}
+
+func (f aliasTakenCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func (f aliasTakenCognito) SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error) {
+	return nil, f.err()
+}
+
+func (f aliasTakenCognito) AdminCreateUser(ctx context.Context, params *cognito.AdminCreateUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminCreateUserOutput, error) {
+	return nil, f.err()
+}
+
+func (f aliasTakenCognito) AdminUpdateUserAttributes(ctx context.Context, params *cognito.AdminUpdateUserAttributesInput, optFns ...func(*cognito.Options)) (*cognito.AdminUpdateUserAttributesOutput, error) {
+	return nil, f.err()
+}
+
+func TestAliasExistsNamesTheConflictingField(t *testing.T) {
+	mapping, err := NewAttributeMapping([]AttributeName{{Domain: "phone", Cognito: "phone_number"}})
+	if err != nil {
+		t.Fatalf("NewAttributeMapping: %v", err)
+	}
+	email, phone := "taken@example.com", "+5511999999999"
+	const sub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+	tests := []struct {
+		name      string
+		message   string
+		run       func(c *cognitoClient) error
+		wantField string
+	}{
+		{
+			name:    "email change, generic message",
+			message: "An account with the given alias already exists.",
+			run: func(c *cognitoClient) error {
+				return c.UpdateUserAttributes(context.Background(), auth.UpdateUserAttributesInput{Id: sub, Email: &email})
+			},
+			wantField: "Email",
+		},
+		{
+			name:    "phone change, generic message",
+			message: "An account with the given alias already exists.",
+			run: func(c *cognitoClient) error {
+				return c.UpdateUserAttributes(context.Background(), auth.UpdateUserAttributesInput{Id: sub, Attributes: map[string]string{"phone": phone}})
+			},
+			wantField: "Phone",
+		},
+		{
+			name:    "phone named by cognito",
+			message: "An account with the phone_number already exists.",
+			run: func(c *cognitoClient) error {
+				_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: email, Password: "Str0ng!Passw0rd", Name: "Member", Phone: &phone})
+				return err
+			},
+			wantField: "Phone",
+		},
+		{
+			name:    "email named by cognito",
+			message: "An account with the email already exists.",
+			run: func(c *cognitoClient) error {
+				_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: email, Password: "Str0ng!Passw0rd", Name: "Member", Phone: &phone})
+				return err
+			},
+			wantField: "Email",
+		},
+		{
+			name:    "admin created user, generic message",
+			message: "An account with the given alias already exists.",
+			run: func(c *cognitoClient) error {
+				return c.AdminCreateUser(context.Background(), auth.AdminCreateUserInput{Username: email, Name: "Member", TemporaryPassword: "Str0ng!Passw0rd"})
+			},
+			wantField: "Email",
+		},
+		{
+			name:    "email and phone written, generic message",
+			message: "An account with the given alias already exists.",
+			run: func(c *cognitoClient) error {
+				_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: email, Password: "Str0ng!Passw0rd", Name: "Member", Phone: &phone})
+				return err
+			},
+			wantField: "Email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: aliasTakenCognito{message: tt.message}, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, attributes: mapping, users: newUserCache(time.Minute, false)}
+
+			var apiErr *app_error.ApiError
+			if err := tt.run(c); !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an ApiError", err)
+			}
+			if apiErr.StatusCode != 409 {
+				t.Errorf("status = %d, want 409", apiErr.StatusCode)
+			}
+			if _, ok := apiErr.Fields[tt.wantField]; !ok || len(apiErr.Fields) != 1 {
+				t.Errorf("fields = %v, want only %s", apiErr.Fields, tt.wantField)
+			}
+		})
+	}
+}
+
+// usernameTakenCognito refuses every signup with UsernameExistsException and
+// resolves the username to existing, or to no one when existing is empty.
+type usernameTakenCognito struct {
+	CognitoAPI
+	existing string
+	sub      string
+}
+
+func (f usernameTakenCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func (f usernameTakenCognito) SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "UsernameExistsException", Message: "User already exists"}
+}
+
+func (f usernameTakenCognito) AdminGetUser(ctx context.Context, params *cognito.AdminGetUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminGetUserOutput, error) {
+	if f.existing == "" {
+		return nil, &smithy.GenericAPIError{Code: "UserNotFoundException", Message: "User does not exist."}
+	}
+	return &cognito.AdminGetUserOutput{Username: aws.String(f.existing), UserAttributes: []types.AttributeType{
+		{Name: aws.String("sub"), Value: aws.String(f.sub)},
+	}}, nil
+}
+
+func TestSignUpUsernameExists(t *testing.T) {
+	const sub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+	tests := []struct {
+		name     string
+		existing string
+		sub      string
+		wantErr  error
+	}{
+		{name: "username taken by the same account", existing: "member@example.com", sub: sub, wantErr: auth.ErrUserAlreadyExists},
+		{name: "username differs only in case", existing: "Member@Example.com", sub: sub, wantErr: auth.ErrUserAlreadyExists},
+		{name: "email as username attribute returns the sub", existing: sub, sub: sub, wantErr: auth.ErrUserAlreadyExists},
+		{name: "email is another account's alias", existing: "someone-else", sub: "1b7a6f0e-2c1d-4e3f-8a9b-0c1d2e3f4a5b", wantErr: auth.ErrAliasAlreadyExists},
+		{name: "username resolves to no one", wantErr: auth.ErrAliasAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: usernameTakenCognito{existing: tt.existing, sub: tt.sub}, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, users: newUserCache(time.Minute, false)}
+
+			_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: "member@example.com", Password: "Str0ng!Passw0rd", Name: "Member"})
+			if err != tt.wantErr {
+				t.Fatalf("SignUp error = %v, want %v", err, tt.wantErr)
+			}
+			var apiErr *app_error.ApiError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != 409 {
+				t.Errorf("err = %v, want a 409", err)
+			}
+		})
+	}
+}
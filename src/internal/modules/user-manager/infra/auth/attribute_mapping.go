@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/deref"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// AttributeName pairs a domain attribute name, e.g. "orgId", with the user
+// pool attribute it is stored as, e.g. "custom:org_id".
+type AttributeName struct {
+	Domain  string
+	Cognito string
+}
+
+// AttributeMapping translates the attributes inputs and outputs carry by
+// domain name to and from their user pool names. A nil mapping knows none.
+type AttributeMapping struct {
+	toCognito   map[string]string
+	fromCognito map[string]string
+}
+
+func NewAttributeMapping(names []AttributeName) (*AttributeMapping, error) {
+	mapping := &AttributeMapping{
+		toCognito:   make(map[string]string, len(names)),
+		fromCognito: make(map[string]string, len(names)),
+	}
+	for _, name := range names {
+		if name.Domain == "" || name.Cognito == "" {
+			return nil, fmt.Errorf("attribute mapping %q -> %q is missing a name", name.Domain, name.Cognito)
+		}
+		if _, ok := mapping.toCognito[name.Domain]; ok {
+			return nil, fmt.Errorf("attribute %q is mapped twice", name.Domain)
+		}
+		if _, ok := mapping.fromCognito[name.Cognito]; ok {
+			return nil, fmt.Errorf("user pool attribute %q is mapped twice", name.Cognito)
+		}
+		mapping.toCognito[name.Domain] = name.Cognito
+		mapping.fromCognito[name.Cognito] = name.Domain
+	}
+	return mapping, nil
+}
+
+// toCognitoAttributes rejects attributes without a mapping, so clients can't
+// write pool attributes the deployment didn't expose.
+func (m *AttributeMapping) toCognitoAttributes(attributes map[string]string) ([]types.AttributeType, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]types.AttributeType, 0, len(names))
+	for _, name := range names {
+		var cognitoName string
+		var ok bool
+		if m != nil {
+			cognitoName, ok = m.toCognito[name]
+		}
+		if !ok {
+			return nil, auth.NewValidationError(fmt.Sprintf("Attributes.%s", name))
+		}
+		out = append(out, types.AttributeType{
+			Name:  aws.String(cognitoName),
+			Value: aws.String(attributes[name]),
+		})
+	}
+	return out, nil
+}
+
+// fromCognitoAttributes keeps only the mapped attributes, by domain name.
+// Cognito sometimes lists an attribute without a value; those are left out
+// rather than reported as empty.
+func (m *AttributeMapping) fromCognitoAttributes(attributes []types.AttributeType) map[string]string {
+	if m == nil || len(m.fromCognito) == 0 {
+		return nil
+	}
+	var out map[string]string
+	for _, attr := range attributes {
+		name, ok := m.fromCognito[deref.String(attr.Name)]
+		if !ok || deref.String(attr.Value) == "" {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[name] = deref.String(attr.Value)
+	}
+	return out
+}
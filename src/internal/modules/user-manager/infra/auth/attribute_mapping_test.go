@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// attributePool stores the attributes written by sign up and updates, by
+// their user pool names, and hands them back on reads.
+type attributePool struct {
+	CognitoAPI
+	attributes map[string]string
+}
+
+func (f *attributePool) write(attributes []types.AttributeType) {
+	for _, attr := range attributes {
+		f.attributes[aws.ToString(attr.Name)] = aws.ToString(attr.Value)
+	}
+}
+
+func (f *attributePool) SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error) {
+	f.write(params.UserAttributes)
+	return &cognito.SignUpOutput{UserSub: aws.String("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e")}, nil
+}
+
+func (f *attributePool) AdminAddUserToGroup(ctx context.Context, params *cognito.AdminAddUserToGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminAddUserToGroupOutput, error) {
+	return &cognito.AdminAddUserToGroupOutput{}, nil
+}
+
+func (f *attributePool) AdminUpdateUserAttributes(ctx context.Context, params *cognito.AdminUpdateUserAttributesInput, optFns ...func(*cognito.Options)) (*cognito.AdminUpdateUserAttributesOutput, error) {
+	f.write(params.UserAttributes)
+	return &cognito.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func (f *attributePool) AdminGetUser(ctx context.Context, params *cognito.AdminGetUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminGetUserOutput, error) {
+	out := &cognito.AdminGetUserOutput{Username: params.Username, UserStatus: types.UserStatusTypeConfirmed}
+	for name, value := range f.attributes {
+		out.UserAttributes = append(out.UserAttributes, types.AttributeType{Name: aws.String(name), Value: aws.String(value)})
+	}
+	return out, nil
+}
+
+func (f *attributePool) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func TestAttributeMappingRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	mapping, err := NewAttributeMapping([]AttributeName{
+		{Domain: "orgId", Cognito: "custom:org_id"},
+		{Domain: "locale", Cognito: "locale"},
+	})
+	if err != nil {
+		t.Fatalf("NewAttributeMapping: %v", err)
+	}
+	pool := &attributePool{attributes: map[string]string{}}
+	c := &cognitoClient{client: pool, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, attributes: mapping, users: newUserCache(time.Minute, false)}
+
+	_, err = c.SignUp(ctx, auth.SignUpInput{
+		Username:   "member@example.com",
+		Password:   "Str0ng!Passw0rd",
+		Name:       "Member",
+		Attributes: map[string]string{"orgId": "org-1"},
+	})
+	if err != nil {
+		t.Fatalf("SignUp: %v", err)
+	}
+	if got := pool.attributes["custom:org_id"]; got != "org-1" {
+		t.Errorf("custom:org_id = %q after sign up, want org-1; pool has %v", got, pool.attributes)
+	}
+	if _, ok := pool.attributes["orgId"]; ok {
+		t.Error("sign up wrote the domain name to the pool")
+	}
+
+	err = c.UpdateUserAttributes(ctx, auth.UpdateUserAttributesInput{
+		Id:         "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e",
+		Attributes: map[string]string{"orgId": "org-2", "locale": "pt-BR"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUserAttributes: %v", err)
+	}
+	if pool.attributes["custom:org_id"] != "org-2" || pool.attributes["locale"] != "pt-BR" {
+		t.Errorf("pool attributes = %v after the update, want custom:org_id and locale set", pool.attributes)
+	}
+
+	// An attribute the mapping doesn't know reads back as nothing.
+	pool.attributes["custom:internal_flag"] = "1"
+	pool.attributes["custom:empty"] = ""
+	user, err := c.GetUser(ctx, auth.GetUserInput{Username: "member@example.com"})
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	want := map[string]string{"orgId": "org-2", "locale": "pt-BR"}
+	if len(user.Attributes) != len(want) {
+		t.Fatalf("Attributes = %v, want %v", user.Attributes, want)
+	}
+	for name, value := range want {
+		if user.Attributes[name] != value {
+			t.Errorf("Attributes[%s] = %q, want %q", name, user.Attributes[name], value)
+		}
+	}
+}
+
+func TestAttributeMappingRejectsUnmappedWrites(t *testing.T) {
+	mapping, err := NewAttributeMapping([]AttributeName{{Domain: "orgId", Cognito: "custom:org_id"}})
+	if err != nil {
+		t.Fatalf("NewAttributeMapping: %v", err)
+	}
+
+	for name, m := range map[string]*AttributeMapping{"mapping": mapping, "no mapping": nil} {
+		t.Run(name, func(t *testing.T) {
+			pool := &attributePool{attributes: map[string]string{}}
+			c := &cognitoClient{client: pool, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, attributes: m, users: newUserCache(time.Minute, false)}
+
+			err := c.UpdateUserAttributes(context.Background(), auth.UpdateUserAttributesInput{
+				Id:         "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e",
+				Attributes: map[string]string{"custom:org_id": "org-1"},
+			})
+			var apiErr *app_error.ApiError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+				t.Fatalf("err = %v, want a 400", err)
+			}
+			if len(pool.attributes) != 0 {
+				t.Errorf("pool attributes = %v, want nothing written", pool.attributes)
+			}
+		})
+	}
+}
+
+func TestNewAttributeMappingRejectsBadConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []AttributeName
+	}{
+		{name: "missing domain name", names: []AttributeName{{Cognito: "custom:org_id"}}},
+		{name: "missing pool name", names: []AttributeName{{Domain: "orgId"}}},
+		{name: "domain name twice", names: []AttributeName{{Domain: "orgId", Cognito: "custom:org_id"}, {Domain: "orgId", Cognito: "custom:org"}}},
+		{name: "pool name twice", names: []AttributeName{{Domain: "orgId", Cognito: "custom:org_id"}, {Domain: "org", Cognito: "custom:org_id"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAttributeMapping(tt.names); err == nil {
+				t.Error("NewAttributeMapping accepted the config")
+			}
+		})
+	}
+}
+
+func TestMappedAttributesLeaveOutValuelessOnes(t *testing.T) {
+	mapping, err := NewAttributeMapping([]AttributeName{
+		{Domain: "orgId", Cognito: "custom:org_id"},
+		{Domain: "locale", Cognito: "locale"},
+		{Domain: "plan", Cognito: "custom:plan"},
+	})
+	if err != nil {
+		t.Fatalf("NewAttributeMapping: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		attributes []types.AttributeType
+		want       map[string]string
+	}{
+		{
+			name: "valued, nil and empty values mixed",
+			attributes: []types.AttributeType{
+				{Name: aws.String("custom:org_id"), Value: aws.String("org-1")},
+				{Name: aws.String("locale"), Value: nil},
+				{Name: aws.String("custom:plan"), Value: aws.String("")},
+				{Name: aws.String("email"), Value: aws.String("member@example.com")},
+			},
+			want: map[string]string{"orgId": "org-1"},
+		},
+		{
+			name: "only valueless attributes",
+			attributes: []types.AttributeType{
+				{Name: aws.String("custom:org_id"), Value: nil},
+				{Name: aws.String("locale"), Value: aws.String("")},
+			},
+		},
+		{
+			name:       "attribute without a name",
+			attributes: []types.AttributeType{{Value: aws.String("orphan")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapping.fromCognitoAttributes(tt.attributes)
+			if tt.want == nil && got != nil {
+				t.Fatalf("attributes = %v, want none so the field is left out", got)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("attributes = %v, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if got[name] != value {
+					t.Errorf("attributes[%s] = %q, want %q", name, got[name], value)
+				}
+			}
+		})
+	}
+}
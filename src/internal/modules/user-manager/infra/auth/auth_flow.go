@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/user_srp"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Login flows selectable through auth.auth_flow. USER_PASSWORD_AUTH sends the
+// plaintext password to Cognito and is only kept for existing deployments.
+var authFlowClientSettings = map[types.AuthFlowType]types.ExplicitAuthFlowsType{
+	types.AuthFlowTypeUserPasswordAuth:      types.ExplicitAuthFlowsTypeAllowUserPasswordAuth,
+	types.AuthFlowTypeUserSrpAuth:           types.ExplicitAuthFlowsTypeAllowUserSrpAuth,
+	types.AuthFlowTypeAdminUserPasswordAuth: types.ExplicitAuthFlowsTypeAllowAdminUserPasswordAuth,
+}
+
+func ParseAuthFlow(flow string) (types.AuthFlowType, error) {
+	authFlow := types.AuthFlowType(flow)
+	if _, ok := authFlowClientSettings[authFlow]; !ok {
+		return "", fmt.Errorf("unsupported auth flow %q", flow)
+	}
+	return authFlow, nil
+}
+
+// CheckAuthFlow warns about settings that would make every login with flow
+// fail. It only warns, since the server may lack permission to describe the
+// client.
+func CheckAuthFlow(ctx context.Context, client *cognito.Client, userPoolId, clientId string, flow types.AuthFlowType, logger logger.Logger) {
+	if flow == types.AuthFlowTypeUserPasswordAuth {
+		logger.Warning("Auth flow USER_PASSWORD_AUTH is deprecated, it sends plaintext passwords to Cognito; switch to USER_SRP_AUTH or ADMIN_USER_PASSWORD_AUTH")
+	}
+	if flow == types.AuthFlowTypeUserSrpAuth {
+		if _, err := user_srp.NewClient(userPoolId); err != nil {
+			logger.Warning("Auth flow USER_SRP_AUTH needs a user pool id like region_id: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out, err := client.DescribeUserPoolClient(ctx, &cognito.DescribeUserPoolClientInput{
+		UserPoolId: aws.String(userPoolId),
+		ClientId:   aws.String(clientId),
+	})
+	if err != nil {
+		logger.Warning("Could not check the app client settings for auth flow %s: %v", flow, err)
+		return
+	}
+	if out.UserPoolClient == nil {
+		return
+	}
+	if out.UserPoolClient.ClientSecret != nil {
+		logger.Warning("App client has a secret, which this server doesn't send; logins will fail")
+	}
+	required := authFlowClientSettings[flow]
+	for _, allowed := range out.UserPoolClient.ExplicitAuthFlows {
+		if allowed == required {
+			return
+		}
+	}
+	logger.Warning("Auth flow %s is selected but the app client doesn't allow %s", flow, required)
+}
+
+type initiateLoginOutput struct {
+	challengeName       types.ChallengeNameType
+	challengeParameters map[string]string
+	session             *string
+	result              *types.AuthenticationResultType
+}
+
+// initiateLogin starts a password login with the configured flow. For SRP it
+// also answers the PASSWORD_VERIFIER challenge, so callers see the same
+// outcome whichever flow is used.
+func (c *cognitoClient) initiateLogin(ctx context.Context, username, password string) (*initiateLoginOutput, error) {
+	switch c.authFlow {
+	case types.AuthFlowTypeAdminUserPasswordAuth:
+		out, err := c.client.AdminInitiateAuth(ctx, &cognito.AdminInitiateAuthInput{
+			AuthFlow:   c.authFlow,
+			UserPoolId: aws.String(c.userPoolId),
+			ClientId:   aws.String(c.clientId),
+			AuthParameters: map[string]string{
+				"USERNAME": username,
+				"PASSWORD": password,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &initiateLoginOutput{out.ChallengeName, out.ChallengeParameters, out.Session, out.AuthenticationResult}, nil
+
+	case types.AuthFlowTypeUserSrpAuth:
+		srp, err := user_srp.NewClient(c.userPoolId)
+		if err != nil {
+			return nil, err
+		}
+		out, err := c.client.InitiateAuth(ctx, &cognito.InitiateAuthInput{
+			AuthFlow: c.authFlow,
+			ClientId: aws.String(c.clientId),
+			AuthParameters: map[string]string{
+				"USERNAME": username,
+				"SRP_A":    srp.SRPA(),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if out.ChallengeName != types.ChallengeNameTypePasswordVerifier {
+			return &initiateLoginOutput{out.ChallengeName, out.ChallengeParameters, out.Session, out.AuthenticationResult}, nil
+		}
+
+		params := out.ChallengeParameters
+		userID := params["USER_ID_FOR_SRP"]
+		claim, err := srp.Claim(userID, password, params["SALT"], params["SRP_B"], params["SECRET_BLOCK"], time.Now())
+		if err != nil {
+			return nil, err
+		}
+		respond, err := c.client.RespondToAuthChallenge(ctx, &cognito.RespondToAuthChallengeInput{
+			ChallengeName: types.ChallengeNameTypePasswordVerifier,
+			ClientId:      aws.String(c.clientId),
+			Session:       out.Session,
+			ChallengeResponses: map[string]string{
+				"USERNAME":                    userID,
+				"PASSWORD_CLAIM_SECRET_BLOCK": params["SECRET_BLOCK"],
+				"PASSWORD_CLAIM_SIGNATURE":    claim.Signature,
+				"TIMESTAMP":                   claim.Timestamp,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &initiateLoginOutput{respond.ChallengeName, respond.ChallengeParameters, respond.Session, respond.AuthenticationResult}, nil
+
+	default:
+		out, err := c.client.InitiateAuth(ctx, &cognito.InitiateAuthInput{
+			AuthFlow: types.AuthFlowTypeUserPasswordAuth,
+			ClientId: aws.String(c.clientId),
+			AuthParameters: map[string]string{
+				"USERNAME": username,
+				"PASSWORD": password,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &initiateLoginOutput{out.ChallengeName, out.ChallengeParameters, out.Session, out.AuthenticationResult}, nil
+	}
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"auth-api/src/pkg/device_srp"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// flowCognito records how a login was started. InitiateAuth with SRP gets a
+// PASSWORD_VERIFIER challenge back, like Cognito sends.
+type flowCognito struct {
+	CognitoAPI
+	initiate      []*cognito.InitiateAuthInput
+	adminInitiate []*cognito.AdminInitiateAuthInput
+	respond       []*cognito.RespondToAuthChallengeInput
+}
+
+func (f *flowCognito) InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error) {
+	f.initiate = append(f.initiate, params)
+	if params.AuthFlow != types.AuthFlowTypeUserSrpAuth {
+		return &cognito.InitiateAuthOutput{AuthenticationResult: &types.AuthenticationResultType{AccessToken: aws.String("access")}}, nil
+	}
+	return &cognito.InitiateAuthOutput{
+		ChallengeName: types.ChallengeNameTypePasswordVerifier,
+		Session:       aws.String("session"),
+		ChallengeParameters: map[string]string{
+			"USER_ID_FOR_SRP": "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e",
+			"SALT":            "80f1e2d3c4b5a697",
+			"SRP_B":           new(big.Int).Exp(device_srp.G, big.NewInt(987654321), device_srp.N).Text(16),
+			"SECRET_BLOCK":    base64.StdEncoding.EncodeToString([]byte("secret-block")),
+		},
+	}, nil
+}
+
+func (f *flowCognito) AdminInitiateAuth(ctx context.Context, params *cognito.AdminInitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.AdminInitiateAuthOutput, error) {
+	f.adminInitiate = append(f.adminInitiate, params)
+	return &cognito.AdminInitiateAuthOutput{AuthenticationResult: &types.AuthenticationResultType{AccessToken: aws.String("access")}}, nil
+}
+
+func (f *flowCognito) RespondToAuthChallenge(ctx context.Context, params *cognito.RespondToAuthChallengeInput, optFns ...func(*cognito.Options)) (*cognito.RespondToAuthChallengeOutput, error) {
+	f.respond = append(f.respond, params)
+	return &cognito.RespondToAuthChallengeOutput{AuthenticationResult: &types.AuthenticationResultType{AccessToken: aws.String("access")}}, nil
+}
+
+func TestInitiateLoginSendsTheConfiguredFlow(t *testing.T) {
+	tests := []struct {
+		name      string
+		flow      types.AuthFlowType
+		wantAdmin bool
+		wantFlow  types.AuthFlowType
+	}{
+		{name: "user password", flow: types.AuthFlowTypeUserPasswordAuth, wantFlow: types.AuthFlowTypeUserPasswordAuth},
+		{name: "unset falls back to user password", wantFlow: types.AuthFlowTypeUserPasswordAuth},
+		{name: "admin user password", flow: types.AuthFlowTypeAdminUserPasswordAuth, wantAdmin: true, wantFlow: types.AuthFlowTypeAdminUserPasswordAuth},
+		{name: "user srp", flow: types.AuthFlowTypeUserSrpAuth, wantFlow: types.AuthFlowTypeUserSrpAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &flowCognito{}
+			c := &cognitoClient{
+				client:     fake,
+				clientId:   "client",
+				userPoolId: "us-east-1_AbC123",
+				authFlow:   tt.flow,
+				logger:     logger.Nop{},
+			}
+
+			out, err := c.initiateLogin(context.Background(), "member@example.com", "Password1!")
+			if err != nil {
+				t.Fatalf("initiateLogin = %v", err)
+			}
+			if out.result == nil || aws.ToString(out.result.AccessToken) != "access" {
+				t.Errorf("result = %+v, want the tokens", out.result)
+			}
+
+			var params map[string]string
+			if tt.wantAdmin {
+				if len(fake.adminInitiate) != 1 || len(fake.initiate) != 0 {
+					t.Fatalf("AdminInitiateAuth called %d times and InitiateAuth %d, want 1 and 0", len(fake.adminInitiate), len(fake.initiate))
+				}
+				in := fake.adminInitiate[0]
+				if in.AuthFlow != tt.wantFlow || aws.ToString(in.UserPoolId) != "us-east-1_AbC123" {
+					t.Errorf("AdminInitiateAuth flow = %s pool = %s, want %s us-east-1_AbC123", in.AuthFlow, aws.ToString(in.UserPoolId), tt.wantFlow)
+				}
+				params = in.AuthParameters
+			} else {
+				if len(fake.initiate) != 1 || len(fake.adminInitiate) != 0 {
+					t.Fatalf("InitiateAuth called %d times and AdminInitiateAuth %d, want 1 and 0", len(fake.initiate), len(fake.adminInitiate))
+				}
+				if got := fake.initiate[0].AuthFlow; got != tt.wantFlow {
+					t.Errorf("InitiateAuth flow = %s, want %s", got, tt.wantFlow)
+				}
+				params = fake.initiate[0].AuthParameters
+			}
+
+			if params["USERNAME"] != "member@example.com" {
+				t.Errorf("USERNAME = %q, want member@example.com", params["USERNAME"])
+			}
+			if tt.wantFlow != types.AuthFlowTypeUserSrpAuth {
+				if params["PASSWORD"] != "Password1!" {
+					t.Errorf("PASSWORD not sent with %s", tt.wantFlow)
+				}
+				if len(fake.respond) != 0 {
+					t.Errorf("RespondToAuthChallenge called %d times, want 0", len(fake.respond))
+				}
+				return
+			}
+
+			if _, ok := params["PASSWORD"]; ok {
+				t.Error("PASSWORD sent with USER_SRP_AUTH")
+			}
+			if params["SRP_A"] == "" {
+				t.Error("SRP_A not sent")
+			}
+			if len(fake.respond) != 1 {
+				t.Fatalf("RespondToAuthChallenge called %d times, want 1", len(fake.respond))
+			}
+			respond := fake.respond[0]
+			if respond.ChallengeName != types.ChallengeNameTypePasswordVerifier || aws.ToString(respond.Session) != "session" {
+				t.Errorf("answered %s with session %q, want PASSWORD_VERIFIER with session", respond.ChallengeName, aws.ToString(respond.Session))
+			}
+			answers := respond.ChallengeResponses
+			if answers["USERNAME"] != "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e" {
+				t.Errorf("USERNAME = %q, want USER_ID_FOR_SRP", answers["USERNAME"])
+			}
+			for _, key := range []string{"PASSWORD_CLAIM_SECRET_BLOCK", "PASSWORD_CLAIM_SIGNATURE", "TIMESTAMP"} {
+				if answers[key] == "" {
+					t.Errorf("%s missing from the challenge answer", key)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAuthFlow(t *testing.T) {
+	for _, flow := range []string{"USER_PASSWORD_AUTH", "USER_SRP_AUTH", "ADMIN_USER_PASSWORD_AUTH"} {
+		if got, err := ParseAuthFlow(flow); err != nil || string(got) != flow {
+			t.Errorf("ParseAuthFlow(%q) = %q, %v", flow, got, err)
+		}
+	}
+	for _, flow := range []string{"", "CUSTOM_AUTH", "user_srp_auth"} {
+		if _, err := ParseAuthFlow(flow); err == nil {
+			t.Errorf("ParseAuthFlow(%q) accepted", flow)
+		}
+	}
+}
@@ -6,37 +6,71 @@ import (
 	"auth-api/src/internal/shared/code/domain/code"
 	"auth-api/src/internal/shared/notification/domain/email"
 	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/device_srp"
 	"auth-api/src/pkg/jwt_verify"
 	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/mask"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+const concurrentModificationRetryDelay = 250 * time.Millisecond
+
+// ClientMetadataDeliveryMedium is the ClientMetadata key SignUp passes the
+// requested delivery medium under, "EMAIL" or "SMS". Cognito ignores
+// ClientMetadata itself; it only reaches the pool's pre sign-up and custom
+// message Lambda triggers, so a trigger that reads this key is what would
+// send codes by SMS.
+const ClientMetadataDeliveryMedium = "deliveryMedium"
+
 type cognitoClient struct {
-	client     *cognito.Client
+	client     CognitoAPI
 	clientId   string
 	userPoolId string
+	authFlow   types.AuthFlowType
 	jwtVerify  jwt_verify.JWTVerify
 	logger     logger.Logger
 	email      email.EmailService
 	code       code.CodeService
+
+	passwordPolicyMu        sync.Mutex
+	passwordPolicy          *auth.PasswordPolicy
+	passwordPolicyExpiresAt time.Time
+	passwordPolicyTTL       time.Duration
+
+	users       *userCache
+	revocations auth.TokenRevocationStore
+	attributes  *AttributeMapping
 }
 
-func NewAuthService(cognito *cognito.Client, clientId string, jwtVerify jwt_verify.JWTVerify, userPoolId string, logger logger.Logger, email email.EmailService, code code.CodeService) auth.AuthService {
+func NewAuthService(cognito CognitoAPI, clientId string, jwtVerify jwt_verify.JWTVerify, userPoolId string, logger logger.Logger, email email.EmailService, code code.CodeService, passwordPolicyTTL, userCacheTTL time.Duration, caseSensitiveUsernames bool, authFlow types.AuthFlowType, attributes *AttributeMapping, revocations auth.TokenRevocationStore) auth.AuthService {
 	return &cognitoClient{
-		client:     cognito,
-		clientId:   clientId,
-		jwtVerify:  jwtVerify,
-		userPoolId: userPoolId,
-		logger:     logger,
-		email:      email,
-		code:       code,
+		client:            cognito,
+		clientId:          clientId,
+		authFlow:          authFlow,
+		jwtVerify:         jwtVerify,
+		userPoolId:        userPoolId,
+		logger:            logger,
+		email:             email,
+		code:              code,
+		passwordPolicyTTL: passwordPolicyTTL,
+		users:             newUserCache(userCacheTTL, caseSensitiveUsernames),
+		revocations:       revocations,
+		attributes:        attributes,
 	}
 }
 
@@ -59,7 +93,7 @@ func (c *cognitoClient) AddMFA(ctx context.Context, input auth.AddMFAInput) (*au
 	}
 
 	return &auth.AddMFAOutput{
-		SecretCode: *associateSoftwareTokenOutput.SecretCode,
+		SecretCode: deref.String(associateSoftwareTokenOutput.SecretCode),
 		Session:    associateSoftwareTokenOutput.Session,
 	}, nil
 }
@@ -103,6 +137,9 @@ func (c *cognitoClient) ActivateMFA(ctx context.Context, input auth.ActivateMFAI
 
 	_, err = c.client.SetUserMFAPreference(ctx, setUserMFAPreferenceInput)
 	if err != nil {
+		if preconditionErr := preconditionNotMetError(err); preconditionErr != nil {
+			return preconditionErr
+		}
 		c.logger.Error("Cognito set user MFA preference error", err)
 		return app_error.NewApiError(500, "Failed to set user MFA preference")
 	}
@@ -127,15 +164,24 @@ func (c *cognitoClient) VerifyMFA(ctx context.Context, input auth.VerifyMFAInput
 
 	cognitoOut, err := c.client.RespondToAuthChallenge(ctx, respondToAuthChallengeInput)
 	if err != nil {
+		if isChallengeSessionExpired(err) {
+			return nil, auth.ErrChallengeSessionExpired
+		}
+		if strings.Contains(err.Error(), "CodeMismatchException") {
+			return nil, auth.ErrInvalidMfaCode
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito respond to auth challenge error", err)
 		return nil, auth.ErrFailedToRespondToChallenge
 	}
 
-	return &auth.LoginOutput{
-		AccessToken:  cognitoOut.AuthenticationResult.AccessToken,
-		RefreshToken: cognitoOut.AuthenticationResult.RefreshToken,
-		IdToken:      cognitoOut.AuthenticationResult.IdToken,
-	}, nil
+	if cognitoOut.AuthenticationResult == nil {
+		return nil, auth.ErrAuthenticationResultNil
+	}
+
+	return newLoginOutput(cognitoOut.AuthenticationResult), nil
 }
 
 func (c *cognitoClient) AdminRemoveMFA(ctx context.Context, input auth.AdminRemoveMFAInput) error {
@@ -157,6 +203,9 @@ func (c *cognitoClient) AdminRemoveMFA(ctx context.Context, input auth.AdminRemo
 
 	_, err := c.client.AdminSetUserMFAPreference(ctx, adminSetUserMFAPreferenceInput)
 	if err != nil {
+		if preconditionErr := preconditionNotMetError(err); preconditionErr != nil {
+			return preconditionErr
+		}
 		c.logger.Error("Cognito remove MFA error", err)
 		return app_error.NewApiError(500, "Failed to remove MFA")
 	}
@@ -182,6 +231,9 @@ func (c *cognitoClient) RemoveMFA(ctx context.Context, input auth.RemoveMFAInput
 
 	_, err := c.client.SetUserMFAPreference(ctx, setUserMFAPreferenceInput)
 	if err != nil {
+		if preconditionErr := preconditionNotMetError(err); preconditionErr != nil {
+			return preconditionErr
+		}
 		c.logger.Error("Cognito remove MFA error", err)
 		return app_error.NewApiError(500, "Failed to remove MFA")
 	}
@@ -197,15 +249,7 @@ func (c *cognitoClient) Login(ctx context.Context, input auth.LoginInput) (*auth
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	initiateAuthInput := &cognito.InitiateAuthInput{
-		AuthFlow: "USER_PASSWORD_AUTH",
-		AuthParameters: map[string]string{
-			"USERNAME": input.Username,
-			"PASSWORD": input.Password,
-		},
-		ClientId: aws.String(c.clientId),
-	}
-	cognitoOut, err := c.client.InitiateAuth(ctx, initiateAuthInput)
+	cognitoOut, err := c.initiateLogin(ctx, input.Username, input.Password)
 	if err != nil {
 		errorType := err.Error()
 		if strings.Contains(errorType, "NotAuthorizedException") {
@@ -217,64 +261,112 @@ func (c *cognitoClient) Login(ctx context.Context, input auth.LoginInput) (*auth
 		if strings.Contains(errorType, "UserNotConfirmedException") {
 			return nil, auth.ErrUserNotConfirmed
 		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito login error", err)
 		return nil, err
 	}
 
-	if cognitoOut.ChallengeName != "" {
+	if cognitoOut.challengeName != "" {
 		return &auth.LoginOutput{
-			Session:  cognitoOut.Session,
-			NextStep: (*string)(&cognitoOut.ChallengeName),
+			Session:             cognitoOut.session,
+			NextStep:            (*string)(&cognitoOut.challengeName),
+			ChallengeParameters: sanitizeChallengeParameters(cognitoOut.challengeParameters),
 		}, nil
 	}
 
-	if cognitoOut.AuthenticationResult == nil {
-		return nil, app_error.NewApiError(500, "Failed to get authentication result")
+	if cognitoOut.result == nil {
+		return nil, auth.ErrAuthenticationResultNil
 	}
 
+	return newLoginOutput(cognitoOut.result), nil
+}
+
+// newLoginOutput keeps the device metadata Cognito hands out alongside the
+// tokens when device remembering is on; the client needs it to confirm the
+// device.
+func newLoginOutput(result *types.AuthenticationResultType) *auth.LoginOutput {
 	out := &auth.LoginOutput{
-		AccessToken:  cognitoOut.AuthenticationResult.AccessToken,
-		RefreshToken: cognitoOut.AuthenticationResult.RefreshToken,
-		IdToken:      cognitoOut.AuthenticationResult.IdToken,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		IdToken:      result.IdToken,
+	}
+	if metadata := result.NewDeviceMetadata; metadata != nil {
+		out.NewDeviceMetadata = &auth.DeviceMetadata{
+			DeviceKey:      deref.String(metadata.DeviceKey),
+			DeviceGroupKey: deref.String(metadata.DeviceGroupKey),
+		}
 	}
-
-	return out, nil
+	return out
 }
 
 func (c *cognitoClient) SignUp(ctx context.Context, input auth.SignUpInput) (o *auth.SignUpOutput, execErr error) {
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
+	if !input.DeliveryMedium.Supported() {
+		return nil, auth.ErrDeliveryMediumUnsupported
+	}
+
+	if err := c.validatePassword(ctx, input.Password, "Password"); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
+	userAttributes := []types.AttributeType{
+		{
+			Name:  aws.String("email"),
+			Value: aws.String(input.Username),
+		},
+		{
+			Name:  aws.String("name"),
+			Value: aws.String(input.Name),
+		},
+	}
+	if input.Phone != nil {
+		userAttributes = append(userAttributes, types.AttributeType{
+			Name:  aws.String("phone_number"),
+			Value: aws.String(*input.Phone),
+		})
+	}
+	mapped, err := c.attributes.toCognitoAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	userAttributes = append(userAttributes, mapped...)
+
 	signUpInput := &cognito.SignUpInput{
-		ClientId: aws.String(c.clientId),
-		Username: aws.String(input.Username),
-		Password: aws.String(input.Password),
-		UserAttributes: []types.AttributeType{
-			{
-				Name:  aws.String("email"),
-				Value: aws.String(input.Username),
-			},
-			{
-				Name:  aws.String("name"),
-				Value: aws.String(input.Name),
-			},
+		ClientId:       aws.String(c.clientId),
+		Username:       aws.String(input.Username),
+		Password:       aws.String(input.Password),
+		UserAttributes: userAttributes,
+		ClientMetadata: map[string]string{
+			ClientMetadataDeliveryMedium: string(input.DeliveryMedium),
 		},
 	}
 	cognitoOut, err := c.client.SignUp(ctx, signUpInput)
 	if err != nil {
 		errorType := err.Error()
 		if strings.Contains(errorType, "UsernameExistsException") {
-			return nil, auth.ErrUserAlreadyExists
+			return nil, c.usernameExistsError(ctx, input.Username)
+		}
+		if strings.Contains(errorType, "AliasExistsException") {
+			return nil, aliasExistsError(err, userAttributes)
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
+		if strings.Contains(errorType, "InvalidParameterException") {
+			return nil, invalidParameterError(err)
 		}
 		c.logger.Error("Cognito signup error", err)
 		return nil, err
 	}
 
-	out := auth.NewSignUpOutput(*cognitoOut.UserSub, input.Username, false, c)
+	out := auth.NewSignUpOutput(deref.String(cognitoOut.UserSub), input.Username, cognitoOut.UserConfirmed, c)
 
 	defer func() {
 		if execErr != nil {
@@ -285,9 +377,13 @@ func (c *cognitoClient) SignUp(ctx context.Context, input auth.SignUpInput) (o *
 		}
 	}()
 
+	group := input.Group
+	if group == "" {
+		group = auth.GroupUser
+	}
 	err = c.AddGroup(ctx, auth.AddGroupInput{
 		Username:  input.Username,
-		GroupName: auth.GroupUser,
+		GroupName: group,
 	})
 	if err != nil {
 		return nil, err
@@ -296,17 +392,51 @@ func (c *cognitoClient) SignUp(ctx context.Context, input auth.SignUpInput) (o *
 	return out, nil
 }
 
+// usernameExistsError tells a taken username apart from an email that is
+// already another account's alias; Cognito reports both as
+// UsernameExistsException when signing up with the email as username.
+func (c *cognitoClient) usernameExistsError(ctx context.Context, username string) error {
+	cognitoOut, err := c.client.AdminGetUser(ctx, &cognito.AdminGetUserInput{
+		UserPoolId: aws.String(c.userPoolId),
+		Username:   aws.String(username),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UserNotFoundException") {
+			return auth.ErrAliasAlreadyExists
+		}
+		c.logger.Error("Cognito get user after username exists error", err)
+		return auth.ErrUserAlreadyExists
+	}
+
+	// AdminGetUser resolves aliases, so getting back someone else means the
+	// email belongs to them. Pools using the email as username attribute
+	// return the sub as Username instead; that is still the same account.
+	existing := deref.String(cognitoOut.Username)
+	if strings.EqualFold(existing, username) {
+		return auth.ErrUserAlreadyExists
+	}
+	for _, attr := range cognitoOut.UserAttributes {
+		if deref.String(attr.Name) == "sub" && deref.String(attr.Value) == existing {
+			return auth.ErrUserAlreadyExists
+		}
+	}
+	return auth.ErrAliasAlreadyExists
+}
+
 func (c *cognitoClient) ConfirmSignUp(ctx context.Context, input auth.ConfirmSignUpInput) (*auth.ConfirmSignUpOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
 
+	defer c.users.invalidateUsername(input.Username)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	adminConfirmSignUpInput := &cognito.AdminConfirmSignUpInput{
-		UserPoolId: aws.String(c.userPoolId),
-		Username:   aws.String(input.Username),
+		UserPoolId:     aws.String(c.userPoolId),
+		Username:       aws.String(input.Username),
+		ClientMetadata: input.ClientMetadata,
 	}
 
 	_, err := c.client.AdminConfirmSignUp(ctx, adminConfirmSignUpInput)
@@ -315,6 +445,9 @@ func (c *cognitoClient) ConfirmSignUp(ctx context.Context, input auth.ConfirmSig
 		if strings.Contains(errorType, "UserNotFoundException") {
 			return nil, auth.ErrUserNotFound
 		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito confirm signup error", err)
 		return nil, err
 	}
@@ -346,9 +479,22 @@ func (c *cognitoClient) GetMe(ctx context.Context, input auth.GetMeInput) (*auth
 		return nil, err
 	}
 
+	var id, name, email string
+	for _, attr := range cognitoOut.UserAttributes {
+		switch deref.String(attr.Name) {
+		case "sub":
+			id = deref.String(attr.Value)
+		case "name":
+			name = deref.String(attr.Value)
+		case "email":
+			email = deref.String(attr.Value)
+		}
+	}
+
 	out := &auth.GetMeOutput{
-		Username: *cognitoOut.Username,
-		Name:     *cognitoOut.UserAttributes[0].Value, //TODO: handle this better
+		Id:       id,
+		Username: deref.String(cognitoOut.Username),
+		Name:     displayName(name, email),
 	}
 
 	return out, nil
@@ -357,14 +503,32 @@ func (c *cognitoClient) GetMe(ctx context.Context, input auth.GetMeInput) (*auth
 func (c *cognitoClient) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
 
 	_, claims, err := c.jwtVerify.ParseJWT(token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, auth.ErrTokenExpired
+		}
+		return nil, err
+	}
+	revokedAt, err := c.revocations.RevokedAt(ctx, claims.Sub)
 	if err != nil {
 		return nil, err
 	}
+	// Conservative within the second of the revocation, since iat has no
+	// finer resolution.
+	if revokedAt != nil && claims.Iat <= revokedAt.Unix() {
+		return nil, auth.ErrInvalidAccessCode
+	}
+
+	// Left zero when absent so freshness checks treat the token as stale.
+	authTime, _ := claims.GetAuthTime()
 
 	return &auth.Claims{
 		Email:      claims.Email,
 		Id:         claims.Sub,
 		UserGroups: claims.UserGroups,
+		AuthTime:   authTime,
+		Attributes: claims.Custom,
+		Scopes:     strings.Fields(claims.Scope),
 	}, nil
 }
 
@@ -378,7 +542,10 @@ func (c *cognitoClient) AddGroup(ctx context.Context, input auth.AddGroupInput)
 		GroupName:  aws.String(string(input.GroupName)),
 	}
 
-	_, err := c.client.AdminAddUserToGroup(ctx, addUserToGroupInput)
+	err := c.retryOnConcurrentModification(ctx, func() error {
+		_, err := c.client.AdminAddUserToGroup(ctx, addUserToGroupInput)
+		return err
+	})
 	if err != nil {
 		errorType := err.Error()
 		if strings.Contains(errorType, "UserNotFoundException") {
@@ -387,6 +554,9 @@ func (c *cognitoClient) AddGroup(ctx context.Context, input auth.AddGroupInput)
 		if strings.Contains(errorType, "ResourceNotFoundException") {
 			return app_error.NewApiError(404, "Group not found")
 		}
+		if strings.Contains(errorType, "ConcurrentModificationException") {
+			return auth.ErrConcurrentModification
+		}
 		c.logger.Error("Cognito add group error", err)
 		return err
 	}
@@ -408,7 +578,10 @@ func (c *cognitoClient) RemoveGroup(ctx context.Context, input auth.RemoveGroupI
 		GroupName:  aws.String(string(input.GroupName)),
 	}
 
-	_, err := c.client.AdminRemoveUserFromGroup(ctx, removeUserFromGroupInput)
+	err := c.retryOnConcurrentModification(ctx, func() error {
+		_, err := c.client.AdminRemoveUserFromGroup(ctx, removeUserFromGroupInput)
+		return err
+	})
 	if err != nil {
 		errorType := err.Error()
 		if strings.Contains(errorType, "UserNotFoundException") {
@@ -417,6 +590,9 @@ func (c *cognitoClient) RemoveGroup(ctx context.Context, input auth.RemoveGroupI
 		if strings.Contains(errorType, "ResourceNotFoundException") {
 			return auth.ErrInvalidGroup
 		}
+		if strings.Contains(errorType, "ConcurrentModificationException") {
+			return auth.ErrConcurrentModification
+		}
 		c.logger.Error("Cognito remove group error", err)
 		return err
 	}
@@ -445,13 +621,38 @@ func (c *cognitoClient) RefreshToken(ctx context.Context, input auth.RefreshToke
 		if strings.Contains(errorType, "NotAuthorizedException") {
 			return nil, auth.ErrInvalidRefreshToken
 		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito refresh token error", err)
 		return nil, err
 	}
 
+	if cognitoOut.AuthenticationResult == nil {
+		return nil, auth.ErrAuthenticationResultNil
+	}
+
+	accessToken := deref.String(cognitoOut.AuthenticationResult.AccessToken)
+	idToken := deref.String(cognitoOut.AuthenticationResult.IdToken)
+
+	accessTokenExpiresAt, err := tokenExpiresAt(accessToken)
+	if err != nil {
+		c.logger.Error("Error reading access token expiry", err)
+		return nil, app_error.NewApiError(500, "Failed to read token expiry")
+	}
+	idTokenExpiresAt, err := tokenExpiresAt(idToken)
+	if err != nil {
+		c.logger.Error("Error reading id token expiry", err)
+		return nil, app_error.NewApiError(500, "Failed to read token expiry")
+	}
+
 	out := &auth.RefreshTokenOutput{
-		AccessToken: *cognitoOut.AuthenticationResult.AccessToken,
-		IdToken:     *cognitoOut.AuthenticationResult.IdToken,
+		AccessToken:          accessToken,
+		IdToken:              idToken,
+		AccessTokenExpiresIn: int64(time.Until(accessTokenExpiresAt).Seconds()),
+		AccessTokenExpiresAt: &accessTokenExpiresAt,
+		IdTokenExpiresIn:     int64(time.Until(idTokenExpiresAt).Seconds()),
+		IdTokenExpiresAt:     &idTokenExpiresAt,
 	}
 
 	return out, nil
@@ -462,9 +663,24 @@ func (c *cognitoClient) CreateAdmin(ctx context.Context, input auth.CreateAdminI
 		return nil, err
 	}
 
+	// Cognito only reports a policy violation on the temporary password after
+	// the fact and without saying which rule failed.
+	if err := c.validatePassword(ctx, input.Password, "Password"); err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
+	var aliasOwner string
+	if input.ReassignAlias {
+		owner, err := c.emailAliasOwner(ctx, input.Username)
+		if err != nil {
+			return nil, err
+		}
+		aliasOwner = owner
+	}
+
 	createUserInput := &cognito.AdminCreateUserInput{
 		UserPoolId: aws.String(c.userPoolId),
 		Username:   aws.String(input.Username),
@@ -482,7 +698,8 @@ func (c *cognitoClient) CreateAdmin(ctx context.Context, input auth.CreateAdminI
 		DesiredDeliveryMediums: []types.DeliveryMediumType{
 			types.DeliveryMediumTypeEmail,
 		},
-		ForceAliasCreation: true,
+		ForceAliasCreation: input.ReassignAlias,
+		ClientMetadata:     input.ClientMetadata,
 	}
 
 	cognitoOut, err := c.client.AdminCreateUser(ctx, createUserInput)
@@ -491,19 +708,33 @@ func (c *cognitoClient) CreateAdmin(ctx context.Context, input auth.CreateAdminI
 		if strings.Contains(errorType, "UsernameExistsException") {
 			return nil, auth.ErrUserAlreadyExists
 		}
+		if strings.Contains(errorType, "AliasExistsException") {
+			return nil, aliasExistsError(err, createUserInput.UserAttributes)
+		}
+		if strings.Contains(errorType, "InvalidParameterException") {
+			return nil, invalidParameterError(err)
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito admin create user error", err)
 		return nil, err
 	}
 
 	var userId string
-	for _, attr := range cognitoOut.User.Attributes {
-		if *attr.Name == "sub" {
-			userId = *attr.Value
-			break
+	if cognitoOut.User != nil {
+		for _, attr := range cognitoOut.User.Attributes {
+			if deref.String(attr.Name) == "sub" {
+				userId = deref.String(attr.Value)
+				break
+			}
 		}
 	}
 
 	out := auth.NewCreateAdminOutput(userId, input.Username, c)
+	if aliasOwner != userId {
+		out.ReassignedAliasFrom = aliasOwner
+	}
 	defer func() {
 		if execErr != nil {
 			if err := out.Rollback(ctx); err != nil {
@@ -527,11 +758,65 @@ func (c *cognitoClient) CreateAdmin(ctx context.Context, input auth.CreateAdminI
 	return out, nil
 }
 
+func (c *cognitoClient) AdminCreateUser(ctx context.Context, input auth.AdminCreateUserInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	createUserInput := &cognito.AdminCreateUserInput{
+		UserPoolId: aws.String(c.userPoolId),
+		Username:   aws.String(input.Username),
+		UserAttributes: []types.AttributeType{
+			{
+				Name:  aws.String("email"),
+				Value: aws.String(input.Username),
+			},
+			{
+				Name:  aws.String("name"),
+				Value: aws.String(input.Name),
+			},
+		},
+		TemporaryPassword: aws.String(input.TemporaryPassword),
+	}
+	if input.SuppressInvite {
+		createUserInput.MessageAction = types.MessageActionTypeSuppress
+	}
+
+	_, err := c.client.AdminCreateUser(ctx, createUserInput)
+	if err != nil {
+		errorType := err.Error()
+		if strings.Contains(errorType, "UsernameExistsException") {
+			return auth.ErrUserAlreadyExists
+		}
+		if strings.Contains(errorType, "AliasExistsException") {
+			return aliasExistsError(err, createUserInput.UserAttributes)
+		}
+		if strings.Contains(errorType, "InvalidPasswordException") {
+			return app_error.NewApiError(http.StatusBadRequest, "Invalid password", fmt.Sprintf("Field: %s", "TemporaryPassword"))
+		}
+		if strings.Contains(errorType, "InvalidParameterException") {
+			return invalidParameterError(err)
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return lambdaErr
+		}
+		c.logger.Error("Cognito admin create user error", err)
+		return err
+	}
+
+	return nil
+}
+
 func (c *cognitoClient) DeleteUser(ctx context.Context, input auth.DeleteUserInput) error {
 	if err := input.Validate(); err != nil {
 		return err
 	}
 
+	defer c.users.invalidateUsername(input.Username)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -578,11 +863,41 @@ func (c *cognitoClient) Logout(ctx context.Context, input auth.LogoutInput) erro
 	return nil
 }
 
+// RevokeToken ends a single sign in: the refresh token and the access tokens
+// issued from it stop working, other sessions of the user are unaffected.
+func (c *cognitoClient) RevokeToken(ctx context.Context, input auth.RevokeTokenInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	revokeTokenInput := &cognito.RevokeTokenInput{
+		ClientId: aws.String(c.clientId),
+		Token:    aws.String(input.RefreshToken),
+	}
+
+	_, err := c.client.RevokeToken(ctx, revokeTokenInput)
+	if err != nil {
+		c.logger.Error("Cognito revoke token error", err)
+		return err
+	}
+
+	return nil
+}
+
 func (c *cognitoClient) SetPassword(ctx context.Context, input auth.SetPasswordInput) (o *auth.LoginOutput, execErr error) {
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
 
+	if err := c.validatePassword(ctx, input.Password, "Password"); err != nil {
+		return nil, err
+	}
+
+	defer c.users.invalidateUsername(input.Username)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -602,15 +917,21 @@ func (c *cognitoClient) SetPassword(ctx context.Context, input auth.SetPasswordI
 		if strings.Contains(errorType, "UserNotFoundException") {
 			return nil, auth.ErrUserNotFound
 		}
+		if isChallengeSessionExpired(err) {
+			return nil, auth.ErrChallengeSessionExpired
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return nil, lambdaErr
+		}
 		c.logger.Error("Cognito set password error", err)
 		return nil, err
 	}
 
-	return &auth.LoginOutput{
-		AccessToken:  authOut.AuthenticationResult.AccessToken,
-		RefreshToken: authOut.AuthenticationResult.RefreshToken,
-		IdToken:      authOut.AuthenticationResult.IdToken,
-	}, nil
+	if authOut.AuthenticationResult == nil {
+		return nil, auth.ErrAuthenticationResultNil
+	}
+
+	return newLoginOutput(authOut.AuthenticationResult), nil
 }
 
 func (c *cognitoClient) GetUser(ctx context.Context, input auth.GetUserInput) (*auth.User, error) {
@@ -618,6 +939,10 @@ func (c *cognitoClient) GetUser(ctx context.Context, input auth.GetUserInput) (*
 		return nil, err
 	}
 
+	if cached, ok := c.users.get(input.Username); ok {
+		return cached, nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -636,43 +961,9 @@ func (c *cognitoClient) GetUser(ctx context.Context, input auth.GetUserInput) (*
 		return nil, err
 	}
 
-	var username, name, id string
-	var status auth.UserStatus
-
-	switch cognitoOut.UserStatus {
-	case types.UserStatusTypeUnconfirmed:
-		status = auth.Unconfirmed
-	case types.UserStatusTypeConfirmed, types.UserStatusTypeArchived, types.UserStatusTypeCompromised, types.UserStatusTypeExternalProvider:
-		status = auth.Confirmed
-	case types.UserStatusTypeUnknown:
-		status = auth.Unknown
-	case types.UserStatusTypeResetRequired:
-		status = auth.ResetRequired
-	case types.UserStatusTypeForceChangePassword:
-		status = auth.ForceChangePasswd
-	default:
-		status = auth.Unknown
-	}
-
-	for _, attr := range cognitoOut.UserAttributes {
-		switch *attr.Name {
-		case "email":
-			username = *attr.Value
-		case "name":
-			name = *attr.Value
-		case "sub":
-			id = *attr.Value
-		}
-	}
-
-	out := &auth.User{
-		Email:  username,
-		Name:   name,
-		Id:     id,
-		Status: status,
-	}
-
-	return out, nil
+	user := newUser(cognitoOut.UserAttributes, cognitoOut.UserStatus, cognitoOut.UserLastModifiedDate, c.attributes)
+	c.users.set(input.Username, user)
+	return user, nil
 }
 
 func (c *cognitoClient) AdminLogout(ctx context.Context, input auth.AdminLogoutInput) error {
@@ -683,9 +974,15 @@ func (c *cognitoClient) AdminLogout(ctx context.Context, input auth.AdminLogoutI
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	return c.adminGlobalSignOut(ctx, input.Username)
+}
+
+// adminGlobalSignOut revokes every refresh token the user holds, so no new
+// access tokens can be minted for sessions they already have.
+func (c *cognitoClient) adminGlobalSignOut(ctx context.Context, username string) error {
 	adminUserGlobalSignOutInput := &cognito.AdminUserGlobalSignOutInput{
 		UserPoolId: aws.String(c.userPoolId),
-		Username:   aws.String(input.Username),
+		Username:   aws.String(username),
 	}
 
 	_, err := c.client.AdminUserGlobalSignOut(ctx, adminUserGlobalSignOutInput)
@@ -706,6 +1003,8 @@ func (c *cognitoClient) VerifyEmail(ctx context.Context, input auth.VerifyEmailI
 		return err
 	}
 
+	defer c.users.invalidateUsername(input.Username)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -750,6 +1049,10 @@ func (c *cognitoClient) GenerateAndSendCode(ctx context.Context, input auth.Gene
 		CanContainLetters: false,
 	}
 
+	if !input.DeliveryMedium.Supported() {
+		return nil, auth.ErrDeliveryMediumUnsupported
+	}
+
 	code, err := c.code.GenerateAndSave(ctx, *generateAndSendInput)
 	if err != nil {
 		return nil, err
@@ -766,6 +1069,11 @@ func (c *cognitoClient) GenerateAndSendCode(ctx context.Context, input auth.Gene
 
 	return &auth.GenerateAndSendCodeOutput{
 		Code: code.Value,
+		CodeDelivery: auth.CodeDeliveryDetails{
+			Destination:    mask.Destination(string(auth.DeliveryMediumEmail), input.Username),
+			DeliveryMedium: auth.DeliveryMediumEmail,
+			AttributeName:  "email",
+		},
 	}, nil
 
 }
@@ -797,6 +1105,25 @@ func (c *cognitoClient) ChangeForgotPassword(ctx context.Context, input auth.Cha
 		return err
 	}
 
+	return c.AdminSetPermanentPassword(ctx, auth.AdminSetPermanentPasswordInput{
+		Username:    input.Username,
+		NewPassword: input.NewPassword,
+	})
+}
+
+// AdminSetPermanentPassword also clears FORCE_CHANGE_PASSWORD, since Cognito
+// confirms the user when the password is set as permanent.
+func (c *cognitoClient) AdminSetPermanentPassword(ctx context.Context, input auth.AdminSetPermanentPasswordInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.validatePassword(ctx, input.NewPassword, "NewPassword"); err != nil {
+		return err
+	}
+
+	defer c.users.invalidateUsername(input.Username)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -813,7 +1140,7 @@ func (c *cognitoClient) ChangeForgotPassword(ctx context.Context, input auth.Cha
 		if strings.Contains(errorType, "UserNotFoundException") {
 			return auth.ErrUserNotFound
 		}
-		c.logger.Error("Cognito change forgot password error", err)
+		c.logger.Error("Cognito admin set user password error", err)
 		return err
 	}
 
@@ -825,6 +1152,10 @@ func (c *cognitoClient) ChangePassword(ctx context.Context, input auth.ChangePas
 		return err
 	}
 
+	if err := c.validatePassword(ctx, input.NewPassword, "NewPassword"); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -846,3 +1177,695 @@ func (c *cognitoClient) ChangePassword(ctx context.Context, input auth.ChangePas
 
 	return nil
 }
+
+func (c *cognitoClient) UpdateUserAttributes(ctx context.Context, input auth.UpdateUserAttributesInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	var attributes []types.AttributeType
+	if input.Name != nil {
+		attributes = append(attributes, types.AttributeType{
+			Name:  aws.String("name"),
+			Value: aws.String(*input.Name),
+		})
+	}
+	if input.Email != nil {
+		attributes = append(attributes, types.AttributeType{
+			Name:  aws.String("email"),
+			Value: aws.String(*input.Email),
+		})
+		if input.EmailVerified != nil {
+			attributes = append(attributes, types.AttributeType{
+				Name:  aws.String("email_verified"),
+				Value: aws.String(strconv.FormatBool(*input.EmailVerified)),
+			})
+		}
+	}
+	mapped, err := c.attributes.toCognitoAttributes(input.Attributes)
+	if err != nil {
+		return err
+	}
+	attributes = append(attributes, mapped...)
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	// Runs once the write is done so the next read fetches the new profile.
+	defer c.users.invalidateSub(input.Id)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	updateUserAttributesInput := &cognito.AdminUpdateUserAttributesInput{
+		UserPoolId:     aws.String(c.userPoolId),
+		Username:       aws.String(input.Id), // the sub resolves to the user like its username does
+		UserAttributes: attributes,
+	}
+
+	_, err = c.client.AdminUpdateUserAttributes(ctx, updateUserAttributesInput)
+	if err != nil {
+		errorType := err.Error()
+		if strings.Contains(errorType, "UserNotFoundException") {
+			return auth.ErrUserNotFound
+		}
+		if strings.Contains(errorType, "AliasExistsException") {
+			return aliasExistsError(err, attributes)
+		}
+		if strings.Contains(errorType, "InvalidParameterException") {
+			return invalidParameterError(err)
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return lambdaErr
+		}
+		c.logger.Error("Cognito update user attributes error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *cognitoClient) retryOnConcurrentModification(ctx context.Context, call func() error) error {
+	err := call()
+	if err == nil || !strings.Contains(err.Error(), "ConcurrentModificationException") {
+		return err
+	}
+
+	c.logger.Warning("Cognito concurrent modification, retrying in %v", concurrentModificationRetryDelay)
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(concurrentModificationRetryDelay):
+	}
+
+	return call()
+}
+
+func tokenExpiresAt(token string) (time.Time, error) {
+	claims, err := jwt_verify.ParseUnverifiedClaims(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// emailAliasOwner returns the id of the user whose verified email alias
+// matches email. Cognito only moves verified aliases, so unverified holders
+// are ignored.
+func (c *cognitoClient) emailAliasOwner(ctx context.Context, email string) (string, error) {
+	cognitoOut, err := c.client.ListUsers(ctx, &cognito.ListUsersInput{
+		UserPoolId: aws.String(c.userPoolId),
+		Filter:     aws.String(fmt.Sprintf("email = %q", email)),
+	})
+	if err != nil {
+		c.logger.Error("Cognito list users by email error", err)
+		return "", err
+	}
+
+	for _, u := range cognitoOut.Users {
+		var sub string
+		verified := false
+		for _, attr := range u.Attributes {
+			switch deref.String(attr.Name) {
+			case "sub":
+				sub = deref.String(attr.Value)
+			case "email_verified":
+				verified = deref.String(attr.Value) == "true"
+			}
+		}
+		if verified {
+			return sub, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *cognitoClient) ListUsers(ctx context.Context, input auth.ListUsersInput) (*auth.ListUsersOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if input.Group != nil {
+		listUsersInGroupInput := &cognito.ListUsersInGroupInput{
+			UserPoolId: aws.String(c.userPoolId),
+			GroupName:  aws.String(string(*input.Group)),
+			Limit:      aws.Int32(input.Limit),
+			NextToken:  input.NextToken,
+		}
+
+		cognitoOut, err := c.client.ListUsersInGroup(ctx, listUsersInGroupInput)
+		if err != nil {
+			errorType := err.Error()
+			if strings.Contains(errorType, "ResourceNotFoundException") {
+				return nil, auth.ErrInvalidGroup
+			}
+			c.logger.Error("Cognito list users in group error", err)
+			return nil, err
+		}
+
+		users := make([]auth.User, 0, len(cognitoOut.Users))
+		for _, u := range cognitoOut.Users {
+			users = append(users, *newUser(u.Attributes, u.UserStatus, u.UserLastModifiedDate, c.attributes))
+		}
+
+		return &auth.ListUsersOutput{
+			Users:     users,
+			NextToken: cognitoOut.NextToken,
+		}, nil
+	}
+
+	listUsersInput := &cognito.ListUsersInput{
+		UserPoolId:      aws.String(c.userPoolId),
+		Limit:           aws.Int32(input.Limit),
+		PaginationToken: input.NextToken,
+	}
+
+	cognitoOut, err := c.client.ListUsers(ctx, listUsersInput)
+	if err != nil {
+		c.logger.Error("Cognito list users error", err)
+		return nil, err
+	}
+
+	users := make([]auth.User, 0, len(cognitoOut.Users))
+	for _, u := range cognitoOut.Users {
+		users = append(users, *newUser(u.Attributes, u.UserStatus, u.UserLastModifiedDate, c.attributes))
+	}
+
+	return &auth.ListUsersOutput{
+		Users:     users,
+		NextToken: cognitoOut.PaginationToken,
+	}, nil
+}
+
+func (c *cognitoClient) AdminResetPassword(ctx context.Context, input auth.AdminResetPasswordInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	defer c.users.invalidateUsername(input.Id)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	adminResetUserPasswordInput := &cognito.AdminResetUserPasswordInput{
+		UserPoolId: aws.String(c.userPoolId),
+		Username:   aws.String(input.Id),
+	}
+
+	_, err := c.client.AdminResetUserPassword(ctx, adminResetUserPasswordInput)
+	if err != nil {
+		errorType := err.Error()
+		if strings.Contains(errorType, "UserNotFoundException") {
+			return auth.ErrUserNotFound
+		}
+		if lambdaErr := c.lambdaTriggerError(err); lambdaErr != nil {
+			return lambdaErr
+		}
+		c.logger.Error("Cognito admin reset user password error", err)
+		return err
+	}
+
+	// The user must set a new password, so tokens they already hold stop
+	// working now instead of when they expire. The global sign out keeps new
+	// access tokens from being minted; the revocation refuses the ones
+	// already out.
+	if err := c.revokeTokens(ctx, input.Id); err != nil {
+		return err
+	}
+	if err := c.adminGlobalSignOut(ctx, input.Id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *cognitoClient) DisableUser(ctx context.Context, input auth.DisableUserInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	user, err := c.GetUser(ctx, auth.GetUserInput{Username: input.Username})
+	if err != nil {
+		return err
+	}
+
+	defer c.users.invalidateUsername(input.Username)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	adminDisableUserInput := &cognito.AdminDisableUserInput{
+		UserPoolId: aws.String(c.userPoolId),
+		Username:   aws.String(input.Username),
+	}
+
+	if _, err := c.client.AdminDisableUser(ctx, adminDisableUserInput); err != nil {
+		errorType := err.Error()
+		if strings.Contains(errorType, "UserNotFoundException") {
+			return auth.ErrUserNotFound
+		}
+		c.logger.Error("Cognito admin disable user error", err)
+		return err
+	}
+
+	// Cognito revokes the refresh tokens itself; the access tokens already
+	// out are refused through the revocation.
+	return c.revokeTokens(ctx, user.Id)
+}
+
+// revokeTokens refuses the tokens sub already holds. iat only has second
+// resolution, so ValidateToken also refuses tokens issued in the second of
+// the revocation. Both callers leave the user unable to get a new token in
+// that second anyway, a disabled user can't sign in and a reset one has to
+// set a password first, so it doesn't wait the second out.
+func (c *cognitoClient) revokeTokens(ctx context.Context, sub string) error {
+	return c.revocations.Revoke(ctx, sub, time.Now())
+}
+
+// displayName falls back to the local part of the email for users without a
+// name attribute, like federated ones, and to empty when there is no email
+// either.
+func displayName(name, email string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	if at := strings.LastIndex(email, "@"); at > 0 {
+		return email[:at]
+	}
+	return ""
+}
+
+func newUser(attributes []types.AttributeType, userStatus types.UserStatusType, lastModified *time.Time, mapping *AttributeMapping) *auth.User {
+	var username, name, id string
+	var status auth.UserStatus
+
+	switch userStatus {
+	case types.UserStatusTypeUnconfirmed:
+		status = auth.Unconfirmed
+	case types.UserStatusTypeConfirmed, types.UserStatusTypeArchived, types.UserStatusTypeCompromised, types.UserStatusTypeExternalProvider:
+		status = auth.Confirmed
+	case types.UserStatusTypeUnknown:
+		status = auth.Unknown
+	case types.UserStatusTypeResetRequired:
+		status = auth.ResetRequired
+	case types.UserStatusTypeForceChangePassword:
+		status = auth.ForceChangePasswd
+	default:
+		status = auth.Unknown
+	}
+
+	for _, attr := range attributes {
+		switch deref.String(attr.Name) {
+		case "email":
+			username = deref.String(attr.Value)
+		case "name":
+			name = deref.String(attr.Value)
+		case "sub":
+			id = deref.String(attr.Value)
+		}
+	}
+
+	user := &auth.User{
+		Email:  username,
+		Name:   displayName(name, username),
+		Id:     id,
+		Status: status,
+	}
+	user.Attributes = mapping.fromCognitoAttributes(attributes)
+	if lastModified != nil {
+		user.LastModifiedAt = *lastModified
+	}
+	return user
+}
+
+func (c *cognitoClient) GetPasswordPolicy(ctx context.Context) (*auth.PasswordPolicy, error) {
+	c.passwordPolicyMu.Lock()
+	defer c.passwordPolicyMu.Unlock()
+
+	if c.passwordPolicy != nil && time.Now().Before(c.passwordPolicyExpiresAt) {
+		return c.passwordPolicy, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	describeUserPoolInput := &cognito.DescribeUserPoolInput{
+		UserPoolId: aws.String(c.userPoolId),
+	}
+
+	cognitoOut, err := c.client.DescribeUserPool(ctx, describeUserPoolInput)
+	if err != nil {
+		c.logger.Error("Cognito describe user pool error", err)
+		return nil, err
+	}
+
+	policy := &auth.PasswordPolicy{}
+	if cognitoOut.UserPool != nil && cognitoOut.UserPool.Policies != nil && cognitoOut.UserPool.Policies.PasswordPolicy != nil {
+		cognitoPolicy := cognitoOut.UserPool.Policies.PasswordPolicy
+		policy.MinimumLength = deref.Int32(cognitoPolicy.MinimumLength)
+		policy.RequireUppercase = cognitoPolicy.RequireUppercase
+		policy.RequireLowercase = cognitoPolicy.RequireLowercase
+		policy.RequireNumbers = cognitoPolicy.RequireNumbers
+		policy.RequireSymbols = cognitoPolicy.RequireSymbols
+		policy.TemporaryPasswordValidityDays = cognitoPolicy.TemporaryPasswordValidityDays
+	}
+
+	c.passwordPolicy = policy
+	c.passwordPolicyExpiresAt = time.Now().Add(c.passwordPolicyTTL)
+
+	return policy, nil
+}
+
+// validatePassword checks a password against the pool policy before it reaches
+// Cognito. If the policy can't be fetched the check is skipped and Cognito has
+// the final word.
+func (c *cognitoClient) validatePassword(ctx context.Context, password, field string) error {
+	policy, err := c.GetPasswordPolicy(ctx)
+	if err != nil {
+		c.logger.Warning("Skipping local password policy check: %v", err)
+		return nil
+	}
+	return policy.Validate(password, field)
+}
+
+// lambdaTriggerError maps failures raised by the pool's Lambda triggers. A
+// validation rejection is the trigger telling the client what is wrong, so its
+// message is passed on; anything else is the trigger itself misbehaving.
+// Returns nil when err didn't come from a trigger.
+func (c *cognitoClient) lambdaTriggerError(err error) error {
+	errorType := err.Error()
+	if strings.Contains(errorType, "UserLambdaValidationException") {
+		var apiErr smithy.APIError
+		message := ""
+		if errors.As(err, &apiErr) {
+			message = sanitizeLambdaMessage(apiErr.ErrorMessage())
+		}
+		if message == "" {
+			message = "Rejected by user pool trigger"
+		}
+		return app_error.NewApiError(http.StatusBadRequest, "Validation failed", message)
+	}
+	if strings.Contains(errorType, "UnexpectedLambdaException") || strings.Contains(errorType, "InvalidLambdaResponseException") {
+		c.logger.Error("Cognito lambda trigger error", err)
+		return auth.ErrLambdaTriggerFailed
+	}
+	return nil
+}
+
+// preconditionNotMetError maps PreconditionNotMetException, raised e.g. when
+// an MFA preference names a factor the user hasn't verified. Cognito's
+// message says which precondition failed, so it is passed on. Returns nil for
+// any other error.
+func preconditionNotMetError(err error) error {
+	if !strings.Contains(err.Error(), "PreconditionNotMetException") {
+		return nil
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if message := sanitizeLambdaMessage(apiErr.ErrorMessage()); message != "" {
+			return app_error.NewApiError(http.StatusConflict, "Precondition not met", message)
+		}
+	}
+	return auth.ErrPreconditionNotMet
+}
+
+const maxLambdaMessageLength = 200
+
+// sanitizeLambdaMessage strips Cognito's "<Trigger> failed with error" prefix
+// and anything that shouldn't be echoed back to a client.
+func sanitizeLambdaMessage(message string) string {
+	if i := strings.Index(message, "failed with error "); i >= 0 {
+		message = message[i+len("failed with error "):]
+	}
+	message = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, message)
+	message = strings.TrimSpace(message)
+	if runes := []rune(message); len(runes) > maxLambdaMessageLength {
+		message = string(runes[:maxLambdaMessageLength])
+	}
+	return message
+}
+
+// Cognito attribute and parameter names mapped to the fields clients send.
+var cognitoFieldNames = map[string]string{
+	"email":        "Email",
+	"phone_number": "Phone",
+	"name":         "Name",
+	"username":     "Username",
+	"password":     "Password",
+}
+
+// aliasExistsError names the attribute whose value another account already
+// has as an alias. Cognito's message usually says which; when it doesn't, the
+// attributes the operation was writing decide, email winning over phone.
+func aliasExistsError(err error, attributes []types.AttributeType) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		message := strings.ToLower(apiErr.ErrorMessage())
+		switch {
+		case strings.Contains(message, "phone"):
+			return auth.ErrPhoneAliasAlreadyExists
+		case strings.Contains(message, "email"):
+			return auth.ErrAliasAlreadyExists
+		}
+	}
+
+	var writesEmail, writesPhone bool
+	for _, attr := range attributes {
+		switch deref.String(attr.Name) {
+		case "email":
+			writesEmail = true
+		case "phone_number":
+			writesPhone = true
+		}
+	}
+	if writesPhone && !writesEmail {
+		return auth.ErrPhoneAliasAlreadyExists
+	}
+	return auth.ErrAliasAlreadyExists
+}
+
+// isChallengeSessionExpired tells a challenge Session that timed out, which
+// Cognito reports as NotAuthorizedException ("Invalid session for the user,
+// session is expired."), from other rejections of the response.
+func isChallengeSessionExpired(err error) bool {
+	if !strings.Contains(err.Error(), "NotAuthorizedException") {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return strings.Contains(strings.ToLower(apiErr.ErrorMessage()), "session")
+	}
+	return false
+}
+
+// invalidParameterError turns Cognito's catch-all InvalidParameterException
+// into a validation error naming the offending field when the message allows.
+func invalidParameterError(err error) error {
+	message := err.Error()
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		message = apiErr.ErrorMessage()
+	}
+	return auth.NewValidationError(invalidParameterField(message))
+}
+
+func invalidParameterField(message string) string {
+	lower := strings.ToLower(message)
+
+	// "Attributes did not conform to the schema: name: The attribute is required".
+	// The name is followed by ": ", as custom attributes have a colon of their own.
+	if rest, ok := cutFold(message, lower, "did not conform to the schema:"); ok {
+		if attribute, _, ok := strings.Cut(strings.TrimSpace(rest), ": "); ok {
+			return cognitoFieldName(strings.TrimSpace(attribute))
+		}
+	}
+
+	// "1 validation error detected: Value at 'username' failed to satisfy constraint"
+	if rest, ok := cutFold(message, lower, "value at '"); ok {
+		if attribute, _, ok := strings.Cut(rest, "'"); ok {
+			return cognitoFieldName(attribute)
+		}
+	}
+
+	switch {
+	case strings.Contains(lower, "phone"):
+		return "Phone"
+	case strings.Contains(lower, "email"):
+		return "Email"
+	case strings.Contains(lower, "password"):
+		return "Password"
+	case strings.Contains(lower, "username"):
+		return "Username"
+	}
+	return "Unknown"
+}
+
+// cutFold finds the lowercase sep in lower, the lowercased message, and returns
+// what follows it in message, so names keep their case.
+func cutFold(message, lower, sep string) (string, bool) {
+	i := strings.Index(lower, sep)
+	if i < 0 || len(lower) != len(message) {
+		return "", false
+	}
+	return message[i+len(sep):], true
+}
+
+func cognitoFieldName(attribute string) string {
+	attribute = strings.TrimPrefix(attribute, "custom:")
+	// "userAttributes.1.member.value" style paths only name the list
+	if i := strings.Index(attribute, "."); i >= 0 {
+		attribute = attribute[:i]
+	}
+	if field, ok := cognitoFieldNames[strings.ToLower(attribute)]; ok {
+		return field
+	}
+	return attribute
+}
+
+// ListUserGroups pages through every group the user is in.
+func (c *cognitoClient) ListUserGroups(ctx context.Context, input auth.ListUserGroupsInput) (*auth.UserGroupsOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out := &auth.UserGroupsOutput{Groups: []string{}}
+	var nextToken *string
+	for {
+		cognitoOut, err := c.client.AdminListGroupsForUser(ctx, &cognito.AdminListGroupsForUserInput{
+			UserPoolId: aws.String(c.userPoolId),
+			Username:   aws.String(input.Username),
+			NextToken:  nextToken,
+		})
+		if err != nil {
+			errorType := err.Error()
+			if strings.Contains(errorType, "UserNotFoundException") {
+				return nil, auth.ErrUserNotFound
+			}
+			c.logger.Error("Cognito list groups for user error", err)
+			return nil, err
+		}
+		for _, group := range cognitoOut.Groups {
+			out.Groups = append(out.Groups, aws.ToString(group.GroupName))
+		}
+		if cognitoOut.NextToken == nil {
+			return out, nil
+		}
+		nextToken = cognitoOut.NextToken
+	}
+}
+
+func (c *cognitoClient) ConfirmDevice(ctx context.Context, input auth.ConfirmDeviceInput) (*auth.ConfirmDeviceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	verifier, err := device_srp.NewVerifier(input.DeviceGroupKey, input.DeviceKey)
+	if err != nil {
+		c.logger.Error("Device verifier generation error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	confirmDeviceInput := &cognito.ConfirmDeviceInput{
+		AccessToken: aws.String(input.AccessToken),
+		DeviceKey:   aws.String(input.DeviceKey),
+		DeviceName:  input.DeviceName,
+		DeviceSecretVerifierConfig: &types.DeviceSecretVerifierConfigType{
+			PasswordVerifier: aws.String(verifier.PasswordVerifier),
+			Salt:             aws.String(verifier.Salt),
+		},
+	}
+
+	cognitoOut, err := c.client.ConfirmDevice(ctx, confirmDeviceInput)
+	if err != nil {
+		errorType := err.Error()
+		if strings.Contains(errorType, "NotAuthorizedException") {
+			return nil, auth.ErrInvalidAccessCode
+		}
+		if strings.Contains(errorType, "ResourceNotFoundException") {
+			return nil, app_error.NewApiError(http.StatusNotFound, "Device not found", fmt.Sprintf("Field: %s", "DeviceKey"))
+		}
+		c.logger.Error("Cognito confirm device error", err)
+		return nil, err
+	}
+
+	return &auth.ConfirmDeviceOutput{
+		UserConfirmationNecessary: cognitoOut.UserConfirmationNecessary,
+		DevicePassword:            verifier.DevicePassword,
+	}, nil
+}
+
+func (c *cognitoClient) ListDevices(ctx context.Context, input auth.ListDevicesInput) (*auth.ListDevicesOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var cognitoDevices []types.DeviceType
+	var paginationToken *string
+	for {
+		cognitoOut, err := c.client.ListDevices(ctx, &cognito.ListDevicesInput{
+			AccessToken:     aws.String(input.AccessToken),
+			Limit:           aws.Int32(60),
+			PaginationToken: paginationToken,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "NotAuthorizedException") {
+				return nil, auth.ErrInvalidAccessCode
+			}
+			c.logger.Error("Cognito list devices error", err)
+			return nil, err
+		}
+		cognitoDevices = append(cognitoDevices, cognitoOut.Devices...)
+		if deref.String(cognitoOut.PaginationToken) == "" {
+			break
+		}
+		paginationToken = cognitoOut.PaginationToken
+	}
+
+	// Cognito already accepted the token, so a parse failure here only means
+	// no device is flagged as current.
+	var currentDeviceKey string
+	if _, claims, err := c.jwtVerify.ParseJWT(input.AccessToken); err == nil {
+		currentDeviceKey = claims.DeviceKey
+	}
+
+	devices := make([]auth.Device, 0, len(cognitoDevices))
+	for _, cognitoDevice := range cognitoDevices {
+		device := auth.Device{
+			DeviceKey:           deref.String(cognitoDevice.DeviceKey),
+			LastAuthenticatedAt: cognitoDevice.DeviceLastAuthenticatedDate,
+		}
+		device.Current = currentDeviceKey != "" && device.DeviceKey == currentDeviceKey
+		for _, attr := range cognitoDevice.DeviceAttributes {
+			switch deref.String(attr.Name) {
+			case "device_name":
+				device.Name = deref.String(attr.Value)
+			case "last_ip_used":
+				device.LastIP = deref.String(attr.Value)
+			case "dev:device_remembered_status":
+				device.Remembered = deref.String(attr.Value) == "remembered"
+			}
+		}
+		devices = append(devices, device)
+	}
+
+	return &auth.ListDevicesOutput{
+		Devices: devices,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/jwt_verify"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestNewUserTakesIdFromSub(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes []types.AttributeType
+		wantId     string
+	}{
+		{
+			name: "sub attribute",
+			attributes: []types.AttributeType{
+				{Name: aws.String("email"), Value: aws.String("member@example.com")},
+				{Name: aws.String("sub"), Value: aws.String("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e")},
+			},
+			wantId: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e",
+		},
+		{
+			name: "email is never used as the id",
+			attributes: []types.AttributeType{
+				{Name: aws.String("email"), Value: aws.String("member@example.com")},
+			},
+			wantId: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newUser(tt.attributes, types.UserStatusTypeConfirmed, nil, nil)
+			if user.Id != tt.wantId {
+				t.Errorf("Id = %q, want %q", user.Id, tt.wantId)
+			}
+			if user.Email != "member@example.com" {
+				t.Errorf("Email = %q, want member@example.com", user.Email)
+			}
+		})
+	}
+}
+
+// profileCognito answers GetUser with attributes.
+type profileCognito struct {
+	CognitoAPI
+	attributes []types.AttributeType
+}
+
+func (f profileCognito) GetUser(ctx context.Context, params *cognito.GetUserInput, optFns ...func(*cognito.Options)) (*cognito.GetUserOutput, error) {
+	return &cognito.GetUserOutput{Username: aws.String("federated_123"), UserAttributes: f.attributes}, nil
+}
+
+func TestUsersWithoutANameFallBackToTheEmail(t *testing.T) {
+	sub := types.AttributeType{Name: aws.String("sub"), Value: aws.String("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e")}
+	email := types.AttributeType{Name: aws.String("email"), Value: aws.String("member@example.com")}
+
+	tests := []struct {
+		name       string
+		attributes []types.AttributeType
+		want       string
+	}{
+		{name: "name set", attributes: []types.AttributeType{sub, email, {Name: aws.String("name"), Value: aws.String("Member")}}, want: "Member"},
+		{name: "no name attribute", attributes: []types.AttributeType{sub, email}, want: "member"},
+		{name: "nil name", attributes: []types.AttributeType{sub, email, {Name: aws.String("name")}}, want: "member"},
+		{name: "blank name", attributes: []types.AttributeType{sub, email, {Name: aws.String("name"), Value: aws.String("  ")}}, want: "member"},
+		{name: "no name or email", attributes: []types.AttributeType{sub}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if user := newUser(tt.attributes, types.UserStatusTypeConfirmed, nil, nil); user.Name != tt.want {
+				t.Errorf("newUser name = %q, want %q", user.Name, tt.want)
+			}
+
+			c := &cognitoClient{client: profileCognito{attributes: tt.attributes}, logger: logger.Nop{}}
+			me, err := c.GetMe(context.Background(), auth.GetMeInput{AccessToken: "access"})
+			if err != nil {
+				t.Fatalf("GetMe: %v", err)
+			}
+			if me.Name != tt.want {
+				t.Errorf("GetMe name = %q, want %q", me.Name, tt.want)
+			}
+		})
+	}
+}
+
+// issuedAt verifies every token as issued to sub at iat.
+type issuedAt struct {
+	jwt_verify.JWTVerify
+	sub string
+	iat time.Time
+}
+
+func (v issuedAt) ParseJWT(string) (*jwt.Token, *jwt_verify.Claims, error) {
+	return &jwt.Token{}, &jwt_verify.Claims{Sub: v.sub, Iat: v.iat.Unix()}, nil
+}
+
+// memoryRevocations stands in for the shared table every instance reads.
+type memoryRevocations map[string]time.Time
+
+func (m memoryRevocations) Revoke(ctx context.Context, sub string, at time.Time) error {
+	m[sub] = at
+	return nil
+}
+
+func (m memoryRevocations) RevokedAt(ctx context.Context, sub string) (*time.Time, error) {
+	at, ok := m[sub]
+	if !ok {
+		return nil, nil
+	}
+	return &at, nil
+}
+
+func TestValidateTokenRefusesRevokedTokens(t *testing.T) {
+	const sub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+	revokedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		sub     string
+		iat     time.Time
+		wantErr error
+	}{
+		{name: "issued before", sub: sub, iat: revokedAt.Add(-time.Hour), wantErr: auth.ErrInvalidAccessCode},
+		{name: "issued the same second", sub: sub, iat: revokedAt, wantErr: auth.ErrInvalidAccessCode},
+		{name: "issued after", sub: sub, iat: revokedAt.Add(time.Second)},
+		{name: "another user", sub: "0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d61", iat: revokedAt.Add(-time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Revoked through one instance, validated by another.
+			revocations := memoryRevocations{}
+			if err := revocations.Revoke(context.Background(), sub, revokedAt); err != nil {
+				t.Fatal(err)
+			}
+			c := &cognitoClient{jwtVerify: issuedAt{sub: tt.sub, iat: tt.iat}, revocations: revocations}
+
+			_, err := c.ValidateToken(context.Background(), "token")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateToken = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// authenticatedAt verifies every token as issued at iat to a user who signed
+// in at authTime, nil for a token without auth_time.
+type authenticatedAt struct {
+	jwt_verify.JWTVerify
+	iat      time.Time
+	authTime *time.Time
+}
+
+func (v authenticatedAt) ParseJWT(string) (*jwt.Token, *jwt_verify.Claims, error) {
+	claims := &jwt_verify.Claims{Sub: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", Iat: v.iat.Unix()}
+	if v.authTime != nil {
+		claims.AuthTime = jwt.NewNumericDate(*v.authTime)
+	}
+	return &jwt.Token{}, claims, nil
+}
+
+func TestValidateTokenTakesAuthTimeNotIat(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	signedIn := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		authTime *time.Time
+		want     time.Time
+	}{
+		{name: "refreshed token keeps the sign in time", authTime: &signedIn, want: signedIn},
+		{name: "fresh sign in", authTime: &now, want: now},
+		{name: "no auth_time", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{jwtVerify: authenticatedAt{iat: now, authTime: tt.authTime}, revocations: memoryRevocations{}}
+
+			claims, err := c.ValidateToken(context.Background(), "token")
+			if err != nil {
+				t.Fatalf("ValidateToken = %v", err)
+			}
+			if !claims.AuthTime.Equal(tt.want) {
+				t.Errorf("AuthTime = %v, want %v", claims.AuthTime, tt.want)
+			}
+		})
+	}
+}
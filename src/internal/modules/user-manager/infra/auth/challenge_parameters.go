@@ -0,0 +1,37 @@
+package auth
+
+import "strings"
+
+// sensitiveChallengeParameters are fragments of parameter names that are
+// never sent to the client. The SRP ones (SRP_B, SALT, SECRET_BLOCK,
+// USER_ID_FOR_SRP) only matter to the server side of the exchange.
+var sensitiveChallengeParameters = []string{
+	"TOKEN", "SECRET", "SESSION", "PASSWORD", "SRP", "SALT", "KEY",
+}
+
+// sanitizeChallengeParameters copies Cognito's challenge parameters without
+// the ones that look like tokens or secrets. It returns nil when nothing is
+// left, so the field is left out of the response.
+func sanitizeChallengeParameters(params map[string]string) map[string]string {
+	var out map[string]string
+	for name, value := range params {
+		if isSensitiveChallengeParameter(name) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string, len(params))
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func isSensitiveChallengeParameter(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, fragment := range sensitiveChallengeParameters {
+		if strings.Contains(upper, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// challengeCognito answers every login with the same challenge.
+type challengeCognito struct {
+	CognitoAPI
+	name   types.ChallengeNameType
+	params map[string]string
+}
+
+func (f *challengeCognito) InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error) {
+	return &cognito.InitiateAuthOutput{ChallengeName: f.name, ChallengeParameters: f.params, Session: aws.String("session")}, nil
+}
+
+func (f *challengeCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func TestLoginSurfacesSanitizedChallengeParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge types.ChallengeNameType
+		params    map[string]string
+		want      map[string]string
+	}{
+		{
+			name:      "sms mfa",
+			challenge: types.ChallengeNameTypeSmsMfa,
+			params:    map[string]string{"CODE_DELIVERY_DELIVERY_MEDIUM": "SMS", "CODE_DELIVERY_DESTINATION": "+*******1234", "USER_ID_FOR_SRP": "member"},
+			want:      map[string]string{"CODE_DELIVERY_DELIVERY_MEDIUM": "SMS", "CODE_DELIVERY_DESTINATION": "+*******1234"},
+		},
+		{
+			name:      "new password",
+			challenge: types.ChallengeNameTypeNewPasswordRequired,
+			params:    map[string]string{"requiredAttributes": `["userAttributes.name"]`, "userAttributes": `{"email":"member@example.com"}`},
+			want:      map[string]string{"requiredAttributes": `["userAttributes.name"]`, "userAttributes": `{"email":"member@example.com"}`},
+		},
+		{
+			name:      "custom challenge",
+			challenge: types.ChallengeNameTypeCustomChallenge,
+			params:    map[string]string{"question": "Favourite colour?", "answerToken": "t", "client_secret": "s", "sessionId": "x", "signingKey": "k", "SALT": "salt", "SRP_B": "b", "SECRET_BLOCK": "block", "tempPassword": "p"},
+			want:      map[string]string{"question": "Favourite colour?"},
+		},
+		{
+			name:      "only sensitive parameters",
+			challenge: types.ChallengeNameTypePasswordVerifier,
+			params:    map[string]string{"SALT": "salt", "SRP_B": "b", "SECRET_BLOCK": "block"},
+		},
+		{name: "no parameters", challenge: types.ChallengeNameTypeSoftwareTokenMfa},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: &challengeCognito{name: tt.challenge, params: tt.params}, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}}
+
+			out, err := c.Login(context.Background(), auth.LoginInput{Username: "member@example.com", Password: "Str0ng!Passw0rd"})
+			if err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if aws.ToString(out.NextStep) != string(tt.challenge) {
+				t.Errorf("NextStep = %q, want %q", aws.ToString(out.NextStep), tt.challenge)
+			}
+			if tt.want == nil && out.ChallengeParameters != nil {
+				t.Errorf("ChallengeParameters = %v, want none so the field is left out", out.ChallengeParameters)
+			}
+			if len(out.ChallengeParameters) != len(tt.want) {
+				t.Fatalf("ChallengeParameters = %v, want %v", out.ChallengeParameters, tt.want)
+			}
+			for name, want := range tt.want {
+				if got := out.ChallengeParameters[name]; got != want {
+					t.Errorf("ChallengeParameters[%s] = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/smithy-go"
+)
+
+// respondingCognito fails every challenge response with err.
+type respondingCognito struct {
+	CognitoAPI
+	err error
+}
+
+func (f respondingCognito) RespondToAuthChallenge(ctx context.Context, params *cognito.RespondToAuthChallengeInput, optFns ...func(*cognito.Options)) (*cognito.RespondToAuthChallengeOutput, error) {
+	return nil, f.err
+}
+
+func (f respondingCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func TestChallengeSessionExpired(t *testing.T) {
+	expired := &smithy.GenericAPIError{Code: "NotAuthorizedException", Message: "Invalid session for the user, session is expired."}
+	mismatch := &smithy.GenericAPIError{Code: "CodeMismatchException", Message: "Invalid code received for user"}
+	wrongPassword := &smithy.GenericAPIError{Code: "NotAuthorizedException", Message: "Incorrect username or password."}
+
+	verifyMFA := func(c *cognitoClient) error {
+		_, err := c.VerifyMFA(context.Background(), auth.VerifyMFAInput{Username: "member@example.com", Code: "123456", Session: "session"})
+		return err
+	}
+	newPassword := func(c *cognitoClient) error {
+		_, err := c.SetPassword(context.Background(), auth.SetPasswordInput{Username: "member@example.com", Password: "Str0ng!Passw0rd", Session: "session"})
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		run     func(c *cognitoClient) error
+		err     error
+		want    error
+		notWant error
+	}{
+		{name: "mfa with an expired session", run: verifyMFA, err: expired, want: auth.ErrChallengeSessionExpired},
+		{name: "mfa with a wrong code", run: verifyMFA, err: mismatch, want: auth.ErrInvalidMfaCode},
+		{name: "mfa rejected for another reason", run: verifyMFA, err: wrongPassword, want: auth.ErrFailedToRespondToChallenge},
+		{name: "new password with an expired session", run: newPassword, err: expired, want: auth.ErrChallengeSessionExpired},
+		{name: "new password rejected for another reason", run: newPassword, err: wrongPassword, notWant: auth.ErrChallengeSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: respondingCognito{err: tt.err}, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, users: newUserCache(time.Minute, false)}
+
+			err := tt.run(c)
+			if tt.want != nil && err != tt.want {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if err == nil || (tt.notWant != nil && err == tt.notWant) {
+				t.Errorf("err = %v, want some other error", err)
+			}
+		})
+	}
+
+	if auth.ErrChallengeSessionExpired.StatusCode != 401 || auth.ErrChallengeSessionExpired.Code() == auth.ErrInvalidMfaCode.Code() {
+		t.Errorf("ErrChallengeSessionExpired = %d %s, want a 401 with its own code", auth.ErrChallengeSessionExpired.StatusCode, auth.ErrChallengeSessionExpired.Code())
+	}
+}
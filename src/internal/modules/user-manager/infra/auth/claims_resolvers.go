@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"fmt"
+	"strings"
+)
+
+const ClaimsResolverEmailDomainTenant = "email_domain_tenant"
+
+// ClaimMapping copies a token attribute, e.g. "custom:tenantId", to a Claims
+// field named in claimFields. A Required mapping rejects tokens without it.
+type ClaimMapping struct {
+	Attribute string
+	Field     string
+	Required  bool
+}
+
+// claimFields are the Claims fields a ClaimMapping can target.
+var claimFields = map[string]func(claims *auth.Claims, value string){
+	"tenant_id": func(claims *auth.Claims, value string) { claims.TenantID = value },
+}
+
+// NewClaimsResolver builds the resolvers a deployment enables by name. The
+// mappings run last, so a value from the token wins over a derived one.
+func NewClaimsResolver(names []string, tenantDomains map[string]string, mappings []ClaimMapping) (auth.ClaimsResolver, error) {
+	resolvers := make([]auth.ClaimsResolver, 0, len(names)+1)
+	for _, name := range names {
+		switch name {
+		case ClaimsResolverEmailDomainTenant:
+			resolvers = append(resolvers, newEmailDomainTenantResolver(tenantDomains))
+		default:
+			return nil, fmt.Errorf("unknown claims resolver %q", name)
+		}
+	}
+	if len(mappings) > 0 {
+		resolver, err := newClaimMappingResolver(mappings)
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, resolver)
+	}
+	return auth.ChainClaimsResolvers(resolvers...), nil
+}
+
+func newClaimMappingResolver(mappings []ClaimMapping) (auth.ClaimsResolver, error) {
+	for _, mapping := range mappings {
+		if mapping.Attribute == "" {
+			return nil, fmt.Errorf("claim mapping to %q has no attribute", mapping.Field)
+		}
+		if _, ok := claimFields[mapping.Field]; !ok {
+			return nil, fmt.Errorf("unknown claim field %q", mapping.Field)
+		}
+	}
+	return auth.ClaimsResolverFunc(func(ctx context.Context, claims *auth.Claims) error {
+		for _, mapping := range mappings {
+			value := claims.Attributes[mapping.Attribute]
+			if value == "" {
+				if mapping.Required {
+					return auth.NewMissingClaimError(mapping.Attribute)
+				}
+				continue
+			}
+			claimFields[mapping.Field](claims, value)
+		}
+		return nil
+	}), nil
+}
+
+// newEmailDomainTenantResolver sets TenantID from the email domain, through
+// tenantDomains when the domain is listed and to the domain itself otherwise.
+func newEmailDomainTenantResolver(tenantDomains map[string]string) auth.ClaimsResolver {
+	tenants := make(map[string]string, len(tenantDomains))
+	for domain, tenant := range tenantDomains {
+		tenants[strings.ToLower(domain)] = tenant
+	}
+	return auth.ClaimsResolverFunc(func(ctx context.Context, claims *auth.Claims) error {
+		at := strings.LastIndex(claims.Email, "@")
+		if at < 0 {
+			return nil
+		}
+		domain := strings.ToLower(claims.Email[at+1:])
+		if tenant, ok := tenants[domain]; ok {
+			claims.TenantID = tenant
+			return nil
+		}
+		claims.TenantID = domain
+		return nil
+	})
+}
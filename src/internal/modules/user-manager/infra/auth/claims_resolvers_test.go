@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClaimsResolver(t *testing.T) {
+	tests := []struct {
+		name          string
+		names         []string
+		tenantDomains map[string]string
+		mappings      []ClaimMapping
+		claims        auth.Claims
+		wantTenant    string
+		wantErr       string
+	}{
+		{
+			name:       "tenant from the email domain",
+			names:      []string{ClaimsResolverEmailDomainTenant},
+			claims:     auth.Claims{Email: "someone@Acme.example"},
+			wantTenant: "acme.example",
+		},
+		{
+			name:          "tenant from a listed domain",
+			names:         []string{ClaimsResolverEmailDomainTenant},
+			tenantDomains: map[string]string{"ACME.example": "acme"},
+			claims:        auth.Claims{Email: "someone@acme.example"},
+			wantTenant:    "acme",
+		},
+		{
+			name:   "no email leaves the tenant empty",
+			names:  []string{ClaimsResolverEmailDomainTenant},
+			claims: auth.Claims{},
+		},
+		{
+			name:       "token attribute wins over the email domain",
+			names:      []string{ClaimsResolverEmailDomainTenant},
+			mappings:   []ClaimMapping{{Attribute: "custom:tenantId", Field: "tenant_id"}},
+			claims:     auth.Claims{Email: "someone@acme.example", Attributes: map[string]string{"custom:tenantId": "t-42"}},
+			wantTenant: "t-42",
+		},
+		{
+			name:       "required attribute present",
+			mappings:   []ClaimMapping{{Attribute: "custom:tenantId", Field: "tenant_id", Required: true}},
+			claims:     auth.Claims{Attributes: map[string]string{"custom:tenantId": "t-42"}},
+			wantTenant: "t-42",
+		},
+		{
+			name:     "optional attribute missing",
+			mappings: []ClaimMapping{{Attribute: "custom:tenantId", Field: "tenant_id"}},
+			claims:   auth.Claims{Email: "someone@acme.example"},
+		},
+		{
+			name:     "required attribute missing",
+			mappings: []ClaimMapping{{Attribute: "custom:tenantId", Field: "tenant_id", Required: true}},
+			claims:   auth.Claims{Email: "someone@acme.example"},
+			wantErr:  "MISSING_CLAIM",
+		},
+		{
+			name:   "nothing enabled",
+			claims: auth.Claims{Email: "someone@acme.example"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewClaimsResolver(tt.names, tt.tenantDomains, tt.mappings)
+			if err != nil {
+				t.Fatalf("NewClaimsResolver: %v", err)
+			}
+
+			claims := tt.claims
+			err = resolver.Resolve(context.Background(), &claims)
+			if tt.wantErr != "" {
+				var apiErr *app_error.ApiError
+				if !errors.As(err, &apiErr) || apiErr.Code() != tt.wantErr {
+					t.Fatalf("Resolve error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if claims.TenantID != tt.wantTenant {
+				t.Errorf("TenantID = %q, want %q", claims.TenantID, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestClaimsResolverRejectsBadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		mappings []ClaimMapping
+	}{
+		{name: "unknown resolver", names: []string{"ldap_roles"}},
+		{name: "mapping without attribute", mappings: []ClaimMapping{{Field: "tenant_id"}}},
+		{name: "mapping to an unknown field", mappings: []ClaimMapping{{Attribute: "custom:role", Field: "role"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClaimsResolver(tt.names, nil, tt.mappings); err == nil {
+				t.Error("NewClaimsResolver accepted the config")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// fakeCognito answers the calls a test sets up; any other call panics on the
+// nil embedded interface.
+type fakeCognito struct {
+	CognitoAPI
+	associate *cognito.AssociateSoftwareTokenOutput
+	getUser   *cognito.GetUserOutput
+	signUps   []*cognito.SignUpInput
+}
+
+func (f *fakeCognito) AssociateSoftwareToken(ctx context.Context, params *cognito.AssociateSoftwareTokenInput, optFns ...func(*cognito.Options)) (*cognito.AssociateSoftwareTokenOutput, error) {
+	return f.associate, nil
+}
+
+func (f *fakeCognito) GetUser(ctx context.Context, params *cognito.GetUserInput, optFns ...func(*cognito.Options)) (*cognito.GetUserOutput, error) {
+	return f.getUser, nil
+}
+
+func (f *fakeCognito) SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error) {
+	f.signUps = append(f.signUps, params)
+	return &cognito.SignUpOutput{UserSub: aws.String("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e")}, nil
+}
+
+func (f *fakeCognito) AdminAddUserToGroup(ctx context.Context, params *cognito.AdminAddUserToGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminAddUserToGroupOutput, error) {
+	return &cognito.AdminAddUserToGroupOutput{}, nil
+}
+
+func TestNilFieldsFromCognito(t *testing.T) {
+	c := &cognitoClient{
+		client: &fakeCognito{
+			associate: &cognito.AssociateSoftwareTokenOutput{},
+			getUser: &cognito.GetUserOutput{UserAttributes: []types.AttributeType{
+				{Name: aws.String("sub")},
+				{Name: nil, Value: aws.String("orphan")},
+				{Name: aws.String("email"), Value: aws.String("member@example.com")},
+			}},
+		},
+		logger: logger.Nop{},
+	}
+
+	mfa, err := c.AddMFA(context.Background(), auth.AddMFAInput{AccessToken: "access"})
+	if err != nil {
+		t.Fatalf("AddMFA = %v", err)
+	}
+	if mfa.SecretCode != "" || mfa.Session != nil {
+		t.Errorf("AddMFA = %+v, want zero values", mfa)
+	}
+
+	me, err := c.GetMe(context.Background(), auth.GetMeInput{AccessToken: "access"})
+	if err != nil {
+		t.Fatalf("GetMe = %v", err)
+	}
+	if me.Id != "" || me.Username != "" || me.Name != "member" {
+		t.Errorf("GetMe = %+v, want no id or username and the name taken from the email", me)
+	}
+}
+
+func TestSignUpRequestsTheChosenMedium(t *testing.T) {
+	phone := "+11234567890"
+
+	tests := []struct {
+		name       string
+		medium     auth.DeliveryMedium
+		phone      *string
+		wantErr    error
+		wantMedium string
+	}{
+		{name: "email", medium: auth.DeliveryMediumEmail, wantMedium: "EMAIL"},
+		{name: "defaults to email", wantMedium: "EMAIL"},
+		{name: "sms is refused before Cognito", medium: auth.DeliveryMediumSMS, phone: &phone, wantErr: auth.ErrDeliveryMediumUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCognito{}
+			c := &cognitoClient{
+				client: fake,
+				logger: logger.Nop{},
+				// Cached, so the policy isn't fetched from the pool.
+				passwordPolicy:          &auth.PasswordPolicy{},
+				passwordPolicyExpiresAt: time.Now().Add(time.Hour),
+			}
+
+			_, err := c.SignUp(context.Background(), auth.SignUpInput{
+				Username:       "member@example.com",
+				Password:       "Password1!",
+				Name:           "Member",
+				Phone:          tt.phone,
+				DeliveryMedium: tt.medium,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SignUp = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(fake.signUps) != 0 {
+					t.Errorf("Cognito SignUp called %d times, want 0", len(fake.signUps))
+				}
+				return
+			}
+			if len(fake.signUps) != 1 {
+				t.Fatalf("Cognito SignUp called %d times, want 1", len(fake.signUps))
+			}
+			if got := fake.signUps[0].ClientMetadata[ClientMetadataDeliveryMedium]; got != tt.wantMedium {
+				t.Errorf("ClientMetadata[%s] = %q, want %q", ClientMetadataDeliveryMedium, got, tt.wantMedium)
+			}
+		})
+	}
+}
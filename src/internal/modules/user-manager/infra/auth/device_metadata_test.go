@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// deviceCognito issues tokens for every sign in, with new device metadata
+// when metadata is set.
+type deviceCognito struct {
+	CognitoAPI
+	metadata *types.NewDeviceMetadataType
+}
+
+func (f *deviceCognito) result() *types.AuthenticationResultType {
+	return &types.AuthenticationResultType{
+		AccessToken:       aws.String("access"),
+		IdToken:           aws.String("id"),
+		RefreshToken:      aws.String("refresh"),
+		NewDeviceMetadata: f.metadata,
+	}
+}
+
+func (f *deviceCognito) InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error) {
+	return &cognito.InitiateAuthOutput{AuthenticationResult: f.result()}, nil
+}
+
+func (f *deviceCognito) RespondToAuthChallenge(ctx context.Context, params *cognito.RespondToAuthChallengeInput, optFns ...func(*cognito.Options)) (*cognito.RespondToAuthChallengeOutput, error) {
+	return &cognito.RespondToAuthChallengeOutput{AuthenticationResult: f.result()}, nil
+}
+
+func (f *deviceCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func TestNewDeviceMetadataIsSurfaced(t *testing.T) {
+	signIns := map[string]func(c *cognitoClient) (*auth.LoginOutput, error){
+		"login": func(c *cognitoClient) (*auth.LoginOutput, error) {
+			return c.Login(context.Background(), auth.LoginInput{Username: "member@example.com", Password: "Str0ng!Passw0rd"})
+		},
+		"mfa": func(c *cognitoClient) (*auth.LoginOutput, error) {
+			return c.VerifyMFA(context.Background(), auth.VerifyMFAInput{Username: "member@example.com", Code: "123456", Session: "session"})
+		},
+		"new password": func(c *cognitoClient) (*auth.LoginOutput, error) {
+			return c.SetPassword(context.Background(), auth.SetPasswordInput{Username: "member@example.com", Password: "Str0ng!Passw0rd", Session: "session"})
+		},
+	}
+
+	tests := []struct {
+		name     string
+		metadata *types.NewDeviceMetadataType
+		want     *auth.DeviceMetadata
+	}{
+		{
+			name:     "new device",
+			metadata: &types.NewDeviceMetadataType{DeviceKey: aws.String("us-east-1_device"), DeviceGroupKey: aws.String("group")},
+			want:     &auth.DeviceMetadata{DeviceKey: "us-east-1_device", DeviceGroupKey: "group"},
+		},
+		{name: "device remembering off"},
+	}
+
+	for _, tt := range tests {
+		for via, signIn := range signIns {
+			t.Run(tt.name+" via "+via, func(t *testing.T) {
+				c := &cognitoClient{client: &deviceCognito{metadata: tt.metadata}, clientId: "client", userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, users: newUserCache(time.Minute, false)}
+
+				out, err := signIn(c)
+				if err != nil {
+					t.Fatalf("sign in: %v", err)
+				}
+				if aws.ToString(out.AccessToken) != "access" || aws.ToString(out.RefreshToken) != "refresh" {
+					t.Errorf("tokens = %+v, want them passed on", out)
+				}
+				switch {
+				case tt.want == nil && out.NewDeviceMetadata != nil:
+					t.Errorf("NewDeviceMetadata = %+v, want none", out.NewDeviceMetadata)
+				case tt.want != nil && (out.NewDeviceMetadata == nil || *out.NewDeviceMetadata != *tt.want):
+					t.Errorf("NewDeviceMetadata = %+v, want %+v", out.NewDeviceMetadata, tt.want)
+				}
+			})
+		}
+	}
+}
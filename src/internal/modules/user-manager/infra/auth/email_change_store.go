@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+)
+
+// EmailChangeRepository keeps email changes in Postgres, so a pending change
+// survives restarts and is known to every instance.
+type EmailChangeRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewEmailChangeRepository(db *sql.DB, logger logger.Logger) auth.EmailChangeStore {
+	return &EmailChangeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *EmailChangeRepository) Get(ctx context.Context, userId string) (*auth.EmailChange, error) {
+	change := auth.EmailChange{UserId: userId}
+	query := `SELECT previous_email, new_email, status, requested_at, verified_at FROM email_changes WHERE user_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, userId).Scan(&change.PreviousEmail, &change.NewEmail, &change.Status, &change.RequestedAt, &change.VerifiedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("Error getting email change: %v", err)
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *EmailChangeRepository) Save(ctx context.Context, change *auth.EmailChange) error {
+	query := `INSERT INTO email_changes (user_id, previous_email, new_email, status, requested_at, verified_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET previous_email = EXCLUDED.previous_email, new_email = EXCLUDED.new_email, status = EXCLUDED.status, requested_at = EXCLUDED.requested_at, verified_at = EXCLUDED.verified_at`
+	if _, err := r.db.ExecContext(ctx, query, change.UserId, change.PreviousEmail, change.NewEmail, change.Status, change.RequestedAt, change.VerifiedAt); err != nil {
+		r.logger.Error("Error saving email change: %v", err)
+		return err
+	}
+	return nil
+}
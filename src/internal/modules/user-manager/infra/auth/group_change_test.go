@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/smithy-go"
+)
+
+// groupCognito fails group changes with errs in order, then succeeds.
+type groupCognito struct {
+	CognitoAPI
+	errs  []error
+	calls int
+}
+
+func (f *groupCognito) next() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *groupCognito) AdminAddUserToGroup(ctx context.Context, params *cognito.AdminAddUserToGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminAddUserToGroupOutput, error) {
+	return &cognito.AdminAddUserToGroupOutput{}, f.next()
+}
+
+func (f *groupCognito) AdminRemoveUserFromGroup(ctx context.Context, params *cognito.AdminRemoveUserFromGroupInput, optFns ...func(*cognito.Options)) (*cognito.AdminRemoveUserFromGroupOutput, error) {
+	return &cognito.AdminRemoveUserFromGroupOutput{}, f.next()
+}
+
+func TestGroupChangeRetriesConcurrentModification(t *testing.T) {
+	concurrent := &smithy.GenericAPIError{Code: "ConcurrentModificationException", Message: "group changed"}
+	notFound := &smithy.GenericAPIError{Code: "UserNotFoundException", Message: "no user"}
+
+	changes := map[string]func(c *cognitoClient) error{
+		"add": func(c *cognitoClient) error {
+			return c.AddGroup(context.Background(), auth.AddGroupInput{Username: "member@example.com", GroupName: auth.GroupUser})
+		},
+		"remove": func(c *cognitoClient) error {
+			return c.RemoveGroup(context.Background(), auth.RemoveGroupInput{Username: "member@example.com", GroupName: auth.GroupUser})
+		},
+	}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "no conflict", wantCalls: 1},
+		{name: "retried once and passes", errs: []error{concurrent}, wantCalls: 2},
+		{name: "conflict again is a 409", errs: []error{concurrent, concurrent}, wantErr: auth.ErrConcurrentModification, wantCalls: 2},
+		{name: "other errors are not retried", errs: []error{notFound}, wantErr: auth.ErrUserNotFound, wantCalls: 1},
+	}
+
+	for change, run := range changes {
+		for _, tt := range tests {
+			t.Run(change+"/"+tt.name, func(t *testing.T) {
+				fake := &groupCognito{errs: append([]error(nil), tt.errs...)}
+				c := &cognitoClient{client: fake, userPoolId: "us-east-1_AbC123", logger: logger.Nop{}}
+
+				err := run(c)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				if fake.calls != tt.wantCalls {
+					t.Errorf("Cognito called %d times, want %d", fake.calls, tt.wantCalls)
+				}
+			})
+		}
+	}
+
+	if auth.ErrConcurrentModification.StatusCode != 409 {
+		t.Errorf("ErrConcurrentModification status = %d, want 409", auth.ErrConcurrentModification.StatusCode)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestInvalidParameterError(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantField string
+	}{
+		{name: "missing required attribute", message: "Attributes did not conform to the schema: name: The attribute is required", wantField: "Name"},
+		{name: "missing custom attribute", message: "Attributes did not conform to the schema: custom:tenant: The attribute is required", wantField: "tenant"},
+		{name: "bad phone format", message: "Invalid phone number format.", wantField: "Phone"},
+		{name: "bad email", message: "Invalid email address format.", wantField: "Email"},
+		{name: "constraint on a parameter", message: "1 validation error detected: Value at 'username' failed to satisfy constraint: Member must satisfy regular expression pattern", wantField: "Username"},
+		{name: "constraint on an attribute list", message: "1 validation error detected: Value at 'userAttributes.1.member.value' failed to satisfy constraint", wantField: "userAttributes"},
+		{name: "password", message: "Password does not conform to policy", wantField: "Password"},
+		{name: "nothing to go on", message: "Invalid request", wantField: "Unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := invalidParameterError(&smithy.GenericAPIError{Code: "InvalidParameterException", Message: tt.message})
+
+			var apiErr *app_error.ApiError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an ApiError", err)
+			}
+			want := auth.NewValidationError(tt.wantField)
+			if apiErr.StatusCode != want.StatusCode || apiErr.Message != want.Message || apiErr.Description != want.Description {
+				t.Errorf("err = %v, want %v", apiErr, want)
+			}
+		})
+	}
+}
+
+func TestSignUpInvalidParameterIsAValidationError(t *testing.T) {
+	c := &cognitoClient{
+		client: triggerCognito{err: &smithy.GenericAPIError{Code: "InvalidParameterException", Message: "Invalid phone number format."}},
+		logger: logger.Nop{},
+		// Cached, so the policy isn't fetched from the pool.
+		passwordPolicy:          &auth.PasswordPolicy{},
+		passwordPolicyExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: "member@example.com", Password: "Password1!", Name: "Member"})
+
+	var apiErr *app_error.ApiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || apiErr.Description != "Field: Phone" {
+		t.Errorf("SignUp = %v, want a 400 on Phone", err)
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// inviteIssuer keeps invite tokens apart from any other HS256 token signed
+// with the same secret.
+const inviteIssuer = "auth-api/invite"
+
+type inviteClaims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type inviteTokens struct {
+	secret []byte
+}
+
+// NewInviteTokens signs invites with HS256. Without a secret invites are
+// disabled and both methods return ErrInvitesDisabled.
+func NewInviteTokens(secret string) auth.InviteTokens {
+	return &inviteTokens{secret: []byte(secret)}
+}
+
+func (t *inviteTokens) Issue(invite auth.Invite) (string, error) {
+	if len(t.secret) == 0 {
+		return "", auth.ErrInvitesDisabled
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, inviteClaims{
+		Role: string(invite.Role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    inviteIssuer,
+			Subject:   invite.Email,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(invite.ExpiresAt),
+		},
+	})
+	return token.SignedString(t.secret)
+}
+
+func (t *inviteTokens) Verify(token string) (*auth.Invite, error) {
+	if len(t.secret) == 0 {
+		return nil, auth.ErrInvitesDisabled
+	}
+	var claims inviteClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(inviteIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, auth.ErrInviteExpired
+		}
+		return nil, auth.ErrInvalidInvite
+	}
+
+	role := auth.UserGroup(claims.Role)
+	if claims.Subject == "" || (role != auth.GroupAdmin && role != auth.GroupUser) {
+		return nil, auth.ErrInvalidInvite
+	}
+	return &auth.Invite{
+		Email:     claims.Subject,
+		Role:      role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
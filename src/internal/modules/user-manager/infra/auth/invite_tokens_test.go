@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestInviteTokens(t *testing.T) {
+	tokens := NewInviteTokens("invite-secret")
+	valid := auth.Invite{Email: "new@example.com", Role: auth.GroupAdmin, ExpiresAt: time.Now().Add(time.Hour)}
+
+	issue := func(t *testing.T, tokens auth.InviteTokens, invite auth.Invite) string {
+		t.Helper()
+		token, err := tokens.Issue(invite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	signClaims := func(t *testing.T, method jwt.SigningMethod, claims inviteClaims, key any) string {
+		t.Helper()
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	claimsFor := func(role string) inviteClaims {
+		return inviteClaims{
+			Role: role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    inviteIssuer,
+				Subject:   "new@example.com",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tokens  auth.InviteTokens
+		token   func(t *testing.T) string
+		want    *auth.Invite
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			tokens: tokens,
+			token:  func(t *testing.T) string { return issue(t, tokens, valid) },
+			want:   &valid,
+		},
+		{
+			name:   "expired",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				expired := valid
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				return issue(t, tokens, expired)
+			},
+			wantErr: auth.ErrInviteExpired,
+		},
+		{
+			name:   "tampered payload",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				parts := strings.Split(issue(t, tokens, valid), ".")
+				forged := signClaims(t, jwt.SigningMethodHS256, claimsFor(string(auth.GroupAdmin)), []byte("other"))
+				parts[1] = strings.Split(forged, ".")[1]
+				return strings.Join(parts, ".")
+			},
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:    "signed with another secret",
+			tokens:  tokens,
+			token:   func(t *testing.T) string { return issue(t, NewInviteTokens("other-secret"), valid) },
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:   "another issuer",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				claims := claimsFor(string(auth.GroupUser))
+				claims.Issuer = "someone-else"
+				return signClaims(t, jwt.SigningMethodHS256, claims, []byte("invite-secret"))
+			},
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:   "another algorithm",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				return signClaims(t, jwt.SigningMethodHS512, claimsFor(string(auth.GroupUser)), []byte("invite-secret"))
+			},
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:   "unknown role",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				return signClaims(t, jwt.SigningMethodHS256, claimsFor("Root"), []byte("invite-secret"))
+			},
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:   "no expiry",
+			tokens: tokens,
+			token: func(t *testing.T) string {
+				claims := claimsFor(string(auth.GroupUser))
+				claims.ExpiresAt = nil
+				return signClaims(t, jwt.SigningMethodHS256, claims, []byte("invite-secret"))
+			},
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:    "garbage",
+			tokens:  tokens,
+			token:   func(t *testing.T) string { return "not-a-token" },
+			wantErr: auth.ErrInvalidInvite,
+		},
+		{
+			name:    "disabled",
+			tokens:  NewInviteTokens(""),
+			token:   func(t *testing.T) string { return issue(t, tokens, valid) },
+			wantErr: auth.ErrInvitesDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tokens.Verify(tt.token(t))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Email != tt.want.Email || got.Role != tt.want.Role || !got.ExpiresAt.Equal(tt.want.ExpiresAt.Truncate(time.Second)) {
+				t.Errorf("Verify = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewInviteTokens("").Issue(valid); !errors.Is(err, auth.ErrInvitesDisabled) {
+		t.Errorf("Issue without a secret: err = %v, want ErrInvitesDisabled", err)
+	}
+}
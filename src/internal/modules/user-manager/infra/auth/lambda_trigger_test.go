@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/smithy-go"
+)
+
+// triggerCognito fails every call that runs a pool trigger with err, as the
+// trigger would.
+type triggerCognito struct {
+	CognitoAPI
+	err error
+}
+
+func (f triggerCognito) SignUp(ctx context.Context, params *cognito.SignUpInput, optFns ...func(*cognito.Options)) (*cognito.SignUpOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) AdminConfirmSignUp(ctx context.Context, params *cognito.AdminConfirmSignUpInput, optFns ...func(*cognito.Options)) (*cognito.AdminConfirmSignUpOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) RespondToAuthChallenge(ctx context.Context, params *cognito.RespondToAuthChallengeInput, optFns ...func(*cognito.Options)) (*cognito.RespondToAuthChallengeOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) AdminCreateUser(ctx context.Context, params *cognito.AdminCreateUserInput, optFns ...func(*cognito.Options)) (*cognito.AdminCreateUserOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) AdminUpdateUserAttributes(ctx context.Context, params *cognito.AdminUpdateUserAttributesInput, optFns ...func(*cognito.Options)) (*cognito.AdminUpdateUserAttributesOutput, error) {
+	return nil, f.err
+}
+
+func (f triggerCognito) AdminResetUserPassword(ctx context.Context, params *cognito.AdminResetUserPasswordInput, optFns ...func(*cognito.Options)) (*cognito.AdminResetUserPasswordOutput, error) {
+	return nil, f.err
+}
+
+func TestLambdaTriggerErrors(t *testing.T) {
+	triggerErr := func(code, message string) error {
+		return &smithy.GenericAPIError{Code: code, Message: message}
+	}
+
+	calls := map[string]func(c *cognitoClient) error{
+		"sign up": func(c *cognitoClient) error {
+			_, err := c.SignUp(context.Background(), auth.SignUpInput{Username: "member@example.com", Password: "Password1!", Name: "Member"})
+			return err
+		},
+		"confirm sign up": func(c *cognitoClient) error {
+			_, err := c.ConfirmSignUp(context.Background(), auth.ConfirmSignUpInput{Username: "member@example.com"})
+			return err
+		},
+		"login": func(c *cognitoClient) error {
+			_, err := c.Login(context.Background(), auth.LoginInput{Username: "member@example.com", Password: "Password1!"})
+			return err
+		},
+		"verify mfa": func(c *cognitoClient) error {
+			_, err := c.VerifyMFA(context.Background(), auth.VerifyMFAInput{Username: "member@example.com", Code: "123456", Session: "session"})
+			return err
+		},
+		"new password": func(c *cognitoClient) error {
+			_, err := c.SetPassword(context.Background(), auth.SetPasswordInput{Username: "member@example.com", Password: "Password1!", Session: "session"})
+			return err
+		},
+		"refresh": func(c *cognitoClient) error {
+			_, err := c.RefreshToken(context.Background(), auth.RefreshTokenInput{RefreshToken: "refresh"})
+			return err
+		},
+		"create admin": func(c *cognitoClient) error {
+			_, err := c.CreateAdmin(context.Background(), auth.CreateAdminInput{Username: "admin@example.com", Password: "Password1!", Name: "Admin"})
+			return err
+		},
+		"admin create user": func(c *cognitoClient) error {
+			return c.AdminCreateUser(context.Background(), auth.AdminCreateUserInput{Username: "member@example.com", TemporaryPassword: "Password1!", Name: "Member"})
+		},
+		"update attributes": func(c *cognitoClient) error {
+			name := "Member"
+			return c.UpdateUserAttributes(context.Background(), auth.UpdateUserAttributesInput{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", Name: &name})
+		},
+		"admin reset password": func(c *cognitoClient) error {
+			return c.AdminResetPassword(context.Background(), auth.AdminResetPasswordInput{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"})
+		},
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		wantStatus      int
+		wantDescription string
+	}{
+		{
+			name:            "validation message passed on",
+			err:             triggerErr("UserLambdaValidationException", "PreSignUp failed with error Email domain not allowed."),
+			wantStatus:      400,
+			wantDescription: "Email domain not allowed.",
+		},
+		{
+			name:            "control characters dropped",
+			err:             triggerErr("UserLambdaValidationException", "PostConfirmation failed with error bad\ninput\t"),
+			wantStatus:      400,
+			wantDescription: "badinput",
+		},
+		{
+			name:            "long message cut",
+			err:             triggerErr("UserLambdaValidationException", "PreSignUp failed with error "+strings.Repeat("x", 300)),
+			wantStatus:      400,
+			wantDescription: strings.Repeat("x", maxLambdaMessageLength),
+		},
+		{
+			name:            "no message",
+			err:             triggerErr("UserLambdaValidationException", "PreSignUp failed with error "),
+			wantStatus:      400,
+			wantDescription: "Rejected by user pool trigger",
+		},
+		{
+			name:       "trigger crashed",
+			err:        triggerErr("UnexpectedLambdaException", "PreSignUp invocation failed due to error AccessDeniedException."),
+			wantStatus: 502,
+		},
+		{
+			name:       "trigger answered garbage",
+			err:        triggerErr("InvalidLambdaResponseException", "Invalid lambda response"),
+			wantStatus: 502,
+		},
+	}
+
+	for call, run := range calls {
+		for _, tt := range tests {
+			t.Run(call+"/"+tt.name, func(t *testing.T) {
+				c := &cognitoClient{
+					client: triggerCognito{err: tt.err},
+					users:  newUserCache(time.Minute, false),
+					logger: logger.Nop{},
+					// Cached, so the policy isn't fetched from the pool.
+					passwordPolicy:          &auth.PasswordPolicy{},
+					passwordPolicyExpiresAt: time.Now().Add(time.Hour),
+				}
+
+				var apiErr *app_error.ApiError
+				if err := run(c); !errors.As(err, &apiErr) {
+					t.Fatalf("err = %v, want an ApiError", err)
+				}
+				if apiErr.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+				}
+				if tt.wantStatus == 502 && apiErr != auth.ErrLambdaTriggerFailed {
+					t.Errorf("err = %v, want %v", apiErr, auth.ErrLambdaTriggerFailed)
+				}
+				if tt.wantStatus == 400 && apiErr.Description != tt.wantDescription {
+					t.Errorf("description = %q, want %q", apiErr.Description, tt.wantDescription)
+				}
+			})
+		}
+	}
+}
+
+func TestLambdaTriggerErrorIgnoresOtherErrors(t *testing.T) {
+	c := &cognitoClient{logger: logger.Nop{}}
+	for _, err := range []error{
+		&smithy.GenericAPIError{Code: "NotAuthorizedException", Message: "Incorrect username or password."},
+		errors.New("connection reset"),
+	} {
+		if got := c.lambdaTriggerError(err); got != nil {
+			t.Errorf("lambdaTriggerError(%v) = %v, want nil", err, got)
+		}
+	}
+}
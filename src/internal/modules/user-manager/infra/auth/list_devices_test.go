@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/jwt_verify"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// deviceListCognito serves pages of devices, one page per call.
+type deviceListCognito struct {
+	CognitoAPI
+	pages  [][]types.DeviceType
+	tokens []string
+}
+
+func (f *deviceListCognito) ListDevices(ctx context.Context, params *cognito.ListDevicesInput, optFns ...func(*cognito.Options)) (*cognito.ListDevicesOutput, error) {
+	f.tokens = append(f.tokens, aws.ToString(params.PaginationToken))
+	page := len(f.tokens) - 1
+	out := &cognito.ListDevicesOutput{Devices: f.pages[page]}
+	if page+1 < len(f.pages) {
+		out.PaginationToken = aws.String("next")
+	}
+	return out, nil
+}
+
+// onDevice verifies every token as issued to deviceKey; a zero key fails
+// the parse.
+type onDevice struct {
+	jwt_verify.JWTVerify
+	deviceKey string
+}
+
+func (v onDevice) ParseJWT(string) (*jwt.Token, *jwt_verify.Claims, error) {
+	if v.deviceKey == "" {
+		return nil, nil, errors.New("unparseable")
+	}
+	return &jwt.Token{}, &jwt_verify.Claims{DeviceKey: v.deviceKey}, nil
+}
+
+func cognitoDevice(key string, lastAuthenticated time.Time, attributes ...string) types.DeviceType {
+	device := types.DeviceType{DeviceKey: aws.String(key), DeviceLastAuthenticatedDate: aws.Time(lastAuthenticated)}
+	for i := 0; i < len(attributes); i += 2 {
+		device.DeviceAttributes = append(device.DeviceAttributes, types.AttributeType{Name: aws.String(attributes[i]), Value: aws.String(attributes[i+1])})
+	}
+	return device
+}
+
+func TestListDevices(t *testing.T) {
+	laptopAt := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	phoneAt := time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)
+	laptop := cognitoDevice("us-east-1_laptop", laptopAt,
+		"device_name", "Laptop", "last_ip_used", "203.0.113.7", "dev:device_remembered_status", "remembered")
+	phone := cognitoDevice("us-east-1_phone", phoneAt,
+		"device_name", "Phone", "dev:device_remembered_status", "not_remembered")
+
+	tests := []struct {
+		name      string
+		pages     [][]types.DeviceType
+		deviceKey string
+		want      []auth.Device
+	}{
+		{
+			name:      "current device flagged",
+			pages:     [][]types.DeviceType{{laptop, phone}},
+			deviceKey: "us-east-1_phone",
+			want: []auth.Device{
+				{DeviceKey: "us-east-1_laptop", Name: "Laptop", LastAuthenticatedAt: &laptopAt, LastIP: "203.0.113.7", Remembered: true},
+				{DeviceKey: "us-east-1_phone", Name: "Phone", LastAuthenticatedAt: &phoneAt, Current: true},
+			},
+		},
+		{
+			name:  "token not issued to a device",
+			pages: [][]types.DeviceType{{laptop}, {phone}},
+			want: []auth.Device{
+				{DeviceKey: "us-east-1_laptop", Name: "Laptop", LastAuthenticatedAt: &laptopAt, LastIP: "203.0.113.7", Remembered: true},
+				{DeviceKey: "us-east-1_phone", Name: "Phone", LastAuthenticatedAt: &phoneAt},
+			},
+		},
+		{
+			name:  "no devices",
+			pages: [][]types.DeviceType{nil},
+			want:  []auth.Device{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &deviceListCognito{pages: tt.pages}
+			c := &cognitoClient{client: fake, logger: logger.Nop{}, jwtVerify: onDevice{deviceKey: tt.deviceKey}}
+
+			out, err := c.ListDevices(context.Background(), auth.ListDevicesInput{AccessToken: "access"})
+			if err != nil {
+				t.Fatalf("ListDevices: %v", err)
+			}
+			if !reflect.DeepEqual(out.Devices, tt.want) {
+				t.Errorf("devices = %+v\nwant %+v", out.Devices, tt.want)
+			}
+			if len(fake.tokens) != len(tt.pages) {
+				t.Errorf("fetched %d pages, want %d", len(fake.tokens), len(tt.pages))
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// LockoutRepository keeps the counters in Postgres, so every instance counts
+// against the same threshold and a restart doesn't reset them.
+type LockoutRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewLockoutRepository(db *sql.DB, logger logger.Logger) auth.LockoutStore {
+	return &LockoutRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *LockoutRepository) Get(ctx context.Context, username string) (auth.LockoutState, error) {
+	var state auth.LockoutState
+	query := `SELECT failures, first_failure_at, last_failure_at FROM login_failures WHERE username = $1`
+	if err := r.db.QueryRowContext(ctx, query, username).Scan(&state.Failures, &state.FirstFailureAt, &state.LastFailureAt); err != nil {
+		if err == sql.ErrNoRows {
+			return auth.LockoutState{}, nil
+		}
+		r.logger.Error("Error getting lockout state: %v", err)
+		return auth.LockoutState{}, err
+	}
+	return state, nil
+}
+
+// RecordFailure counts in a single upsert, so concurrent failures from
+// several instances are all counted.
+func (r *LockoutRepository) RecordFailure(ctx context.Context, username string, now time.Time, window time.Duration) (auth.LockoutState, error) {
+	// Drop stale entries so one-off typos don't pile up.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_failures WHERE last_failure_at < $1`, now.Add(-window)); err != nil {
+		r.logger.Warning("Error pruning lockout states: %v", err)
+	}
+
+	var state auth.LockoutState
+	query := `INSERT INTO login_failures (username, failures, first_failure_at, last_failure_at) VALUES ($1, 1, $2, $2)
+		ON CONFLICT (username) DO UPDATE SET
+			failures = CASE WHEN login_failures.first_failure_at < $3 THEN 1 ELSE login_failures.failures + 1 END,
+			first_failure_at = CASE WHEN login_failures.first_failure_at < $3 THEN EXCLUDED.first_failure_at ELSE login_failures.first_failure_at END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures, first_failure_at, last_failure_at`
+	if err := r.db.QueryRowContext(ctx, query, username, now, now.Add(-window)).Scan(&state.Failures, &state.FirstFailureAt, &state.LastFailureAt); err != nil {
+		r.logger.Error("Error recording failed sign in: %v", err)
+		return auth.LockoutState{}, err
+	}
+	return state, nil
+}
+
+func (r *LockoutRepository) Reset(ctx context.Context, username string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_failures WHERE username = $1`, username); err != nil {
+		r.logger.Error("Error resetting lockout state: %v", err)
+		return err
+	}
+	return nil
+}
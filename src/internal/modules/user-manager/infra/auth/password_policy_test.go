@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// poolCognito describes a pool whose minimum length grows by one on every
+// call, so a refetch is visible. err fails the next call only.
+type poolCognito struct {
+	CognitoAPI
+	calls int
+	err   error
+}
+
+func (f *poolCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	f.calls++
+	if err := f.err; err != nil {
+		f.err = nil
+		return nil, err
+	}
+	return &cognito.DescribeUserPoolOutput{UserPool: &types.UserPoolType{
+		Policies: &types.UserPoolPolicyType{PasswordPolicy: &types.PasswordPolicyType{
+			MinimumLength:    aws.Int32(int32(7 + f.calls)),
+			RequireUppercase: true,
+			RequireNumbers:   true,
+		}},
+	}}, nil
+}
+
+func TestGetPasswordPolicyCache(t *testing.T) {
+	tests := []struct {
+		name string
+		// expire moves the cached policy past its TTL between the calls.
+		expire     bool
+		firstErr   error
+		wantLength int32
+		wantCalls  int
+	}{
+		{name: "cached within the TTL", wantLength: 8, wantCalls: 1},
+		{name: "refetched after the TTL", expire: true, wantLength: 9, wantCalls: 2},
+		{name: "a failed fetch isn't cached", firstErr: errors.New("throttled"), wantLength: 9, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &poolCognito{err: tt.firstErr}
+			c := &cognitoClient{client: fake, userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, passwordPolicyTTL: time.Hour}
+
+			first, err := c.GetPasswordPolicy(context.Background())
+			if !errors.Is(err, tt.firstErr) {
+				t.Fatalf("first GetPasswordPolicy = %v, want %v", err, tt.firstErr)
+			}
+			if err == nil && (first.MinimumLength != 8 || !first.RequireUppercase || !first.RequireNumbers || first.RequireSymbols) {
+				t.Errorf("first policy = %+v, want the pool's", first)
+			}
+			if tt.expire {
+				c.passwordPolicyExpiresAt = time.Now().Add(-time.Second)
+			}
+
+			second, err := c.GetPasswordPolicy(context.Background())
+			if err != nil {
+				t.Fatalf("second GetPasswordPolicy = %v", err)
+			}
+			if second.MinimumLength != tt.wantLength {
+				t.Errorf("MinimumLength = %d, want %d", second.MinimumLength, tt.wantLength)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("DescribeUserPool called %d times, want %d", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordUsesThePoolPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		poolErr  error
+		wantErr  bool
+	}{
+		{name: "meets the policy", password: "Passw0rdX"},
+		{name: "too short", password: "Pa0", wantErr: true},
+		{name: "no uppercase", password: "passw0rdx", wantErr: true},
+		{name: "no number", password: "Passwordx", wantErr: true},
+		{name: "checked by Cognito alone when the policy can't be read", password: "x", poolErr: errors.New("throttled")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: &poolCognito{err: tt.poolErr}, logger: logger.Nop{}, passwordPolicyTTL: time.Hour}
+			err := c.validatePassword(context.Background(), tt.password, "Password")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePassword(%q) = %v, want error %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/smithy-go"
+)
+
+// passwordCognito records the passwords set through it and answers with err.
+type passwordCognito struct {
+	CognitoAPI
+	set []*cognito.AdminSetUserPasswordInput
+	err error
+}
+
+func (f *passwordCognito) DescribeUserPool(ctx context.Context, params *cognito.DescribeUserPoolInput, optFns ...func(*cognito.Options)) (*cognito.DescribeUserPoolOutput, error) {
+	return nil, errors.New("no policy in this test")
+}
+
+func (f *passwordCognito) AdminSetUserPassword(ctx context.Context, params *cognito.AdminSetUserPasswordInput, optFns ...func(*cognito.Options)) (*cognito.AdminSetUserPasswordOutput, error) {
+	f.set = append(f.set, params)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognito.AdminSetUserPasswordOutput{}, nil
+}
+
+func TestAdminSetPermanentPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "set as permanent"},
+		{name: "unknown user", err: &smithy.GenericAPIError{Code: "UserNotFoundException", Message: "User does not exist."}, wantErr: auth.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &passwordCognito{err: tt.err}
+			c := &cognitoClient{client: fake, userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, users: newUserCache(time.Minute, false)}
+
+			err := c.AdminSetPermanentPassword(context.Background(), auth.AdminSetPermanentPasswordInput{
+				Username:    "stuck@example.com",
+				NewPassword: "Str0ng!Passw0rd",
+			})
+			if err != tt.wantErr {
+				t.Fatalf("AdminSetPermanentPassword error = %v, want %v", err, tt.wantErr)
+			}
+			if len(fake.set) != 1 || !fake.set[0].Permanent {
+				t.Fatalf("AdminSetUserPassword calls = %+v, want one permanent", fake.set)
+			}
+		})
+	}
+
+	if auth.ErrUserNotFound.StatusCode != 404 {
+		t.Errorf("ErrUserNotFound status = %d, want 404", auth.ErrUserNotFound.StatusCode)
+	}
+}
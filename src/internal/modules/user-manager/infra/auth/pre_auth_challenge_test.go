@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSiteVerifyChallenge(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        string
+		token         string
+		loginFailOpen bool
+		status        int
+		body          string
+		wantErr       error
+	}{
+		{name: "solved", action: auth.PreAuthActionLogin, token: "solved", status: http.StatusOK, body: `{"success":true}`},
+		{name: "rejected", action: auth.PreAuthActionSignup, token: "bot", status: http.StatusOK, body: `{"success":false,"error-codes":["invalid-input-response"]}`, wantErr: auth.ErrChallengeFailed},
+		{name: "no token", action: auth.PreAuthActionLogin, status: http.StatusOK, body: `{"success":true}`, wantErr: auth.ErrChallengeFailed},
+		{name: "provider down fails signup closed", action: auth.PreAuthActionSignup, token: "solved", loginFailOpen: true, status: http.StatusBadGateway, wantErr: auth.ErrChallengeUnavailable},
+		{name: "provider down fails login closed", action: auth.PreAuthActionLogin, token: "solved", status: http.StatusBadGateway, wantErr: auth.ErrChallengeUnavailable},
+		{name: "provider down lets login through when open", action: auth.PreAuthActionLogin, token: "solved", loginFailOpen: true, status: http.StatusBadGateway},
+		{name: "garbled answer", action: auth.PreAuthActionSignup, token: "solved", status: http.StatusOK, body: `<html>`, wantErr: auth.ErrChallengeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form map[string]string
+			provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = map[string]string{"secret": r.PostForm.Get("secret"), "response": r.PostForm.Get("response"), "remoteip": r.PostForm.Get("remoteip")}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer provider.Close()
+
+			challenge, err := NewPreAuthChallenge(PreAuthProviderTurnstile, "site-secret", time.Second, tt.loginFailOpen, logger.Nop{})
+			if err != nil {
+				t.Fatalf("NewPreAuthChallenge: %v", err)
+			}
+			challenge.(*siteVerifyChallenge).verifyURL = provider.URL
+
+			err = challenge.Verify(context.Background(), auth.PreAuthChallengeInput{Token: tt.token, IP: "203.0.113.7", Action: tt.action})
+			if err != tt.wantErr {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.token == "" {
+				if form != nil {
+					t.Error("asked the provider about an empty token")
+				}
+				return
+			}
+			if form["secret"] != "site-secret" || form["response"] != tt.token || form["remoteip"] != "203.0.113.7" {
+				t.Errorf("siteverify form = %v", form)
+			}
+		})
+	}
+}
+
+func TestNewPreAuthChallenge(t *testing.T) {
+	challenge, err := NewPreAuthChallenge("", "", time.Second, false, logger.Nop{})
+	if err != nil {
+		t.Fatalf("no provider: %v", err)
+	}
+	if err := challenge.Verify(context.Background(), auth.PreAuthChallengeInput{Action: auth.PreAuthActionSignup}); err != nil {
+		t.Errorf("no-op challenge rejected a request: %v", err)
+	}
+	if _, err := NewPreAuthChallenge("hcaptcha", "secret", time.Second, false, logger.Nop{}); err == nil {
+		t.Error("accepted an unknown provider")
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/smithy-go"
+)
+
+// mfaPreferenceCognito verifies every TOTP code and fails MFA preference
+// changes with err.
+type mfaPreferenceCognito struct {
+	CognitoAPI
+	err error
+}
+
+func (f mfaPreferenceCognito) VerifySoftwareToken(ctx context.Context, params *cognito.VerifySoftwareTokenInput, optFns ...func(*cognito.Options)) (*cognito.VerifySoftwareTokenOutput, error) {
+	return &cognito.VerifySoftwareTokenOutput{Status: types.VerifySoftwareTokenResponseTypeSuccess}, nil
+}
+
+func (f mfaPreferenceCognito) SetUserMFAPreference(ctx context.Context, params *cognito.SetUserMFAPreferenceInput, optFns ...func(*cognito.Options)) (*cognito.SetUserMFAPreferenceOutput, error) {
+	return nil, f.err
+}
+
+func (f mfaPreferenceCognito) AdminSetUserMFAPreference(ctx context.Context, params *cognito.AdminSetUserMFAPreferenceInput, optFns ...func(*cognito.Options)) (*cognito.AdminSetUserMFAPreferenceOutput, error) {
+	return nil, f.err
+}
+
+func TestMFAPreferencePreconditionNotMet(t *testing.T) {
+	calls := map[string]func(c *cognitoClient) error{
+		"activate": func(c *cognitoClient) error {
+			return c.ActivateMFA(context.Background(), auth.ActivateMFAInput{AccessToken: "access", Code: "123456"})
+		},
+		"remove": func(c *cognitoClient) error {
+			return c.RemoveMFA(context.Background(), auth.RemoveMFAInput{AccessToken: "access"})
+		},
+		"admin remove": func(c *cognitoClient) error {
+			return c.AdminRemoveMFA(context.Background(), auth.AdminRemoveMFAInput{Username: "member@example.com"})
+		},
+	}
+
+	tests := []struct {
+		name            string
+		err             error
+		wantStatus      int
+		wantDescription string
+	}{
+		{
+			name:            "cognito's message passed on",
+			err:             &smithy.GenericAPIError{Code: "PreconditionNotMetException", Message: "User does not have a verified phone number."},
+			wantStatus:      409,
+			wantDescription: "User does not have a verified phone number.",
+		},
+		{
+			name:            "no message",
+			err:             &smithy.GenericAPIError{Code: "PreconditionNotMetException"},
+			wantStatus:      409,
+			wantDescription: auth.ErrPreconditionNotMet.Description,
+		},
+		{
+			name:       "other errors stay a 500",
+			err:        &smithy.GenericAPIError{Code: "InternalErrorException", Message: "boom"},
+			wantStatus: 500,
+		},
+	}
+
+	for call, run := range calls {
+		for _, tt := range tests {
+			t.Run(call+"/"+tt.name, func(t *testing.T) {
+				c := &cognitoClient{client: mfaPreferenceCognito{err: tt.err}, userPoolId: "us-east-1_AbC123", logger: logger.Nop{}, users: newUserCache(time.Minute, false)}
+
+				var apiErr *app_error.ApiError
+				if err := run(c); !errors.As(err, &apiErr) {
+					t.Fatalf("err = %v, want an ApiError", err)
+				}
+				if apiErr.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+				}
+				if tt.wantStatus != 409 {
+					return
+				}
+				if apiErr.Code() != "PRECONDITION_NOT_MET" || apiErr.Description != tt.wantDescription {
+					t.Errorf("err = %q %q, want PRECONDITION_NOT_MET %q", apiErr.Code(), apiErr.Description, tt.wantDescription)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// refreshCognito answers REFRESH_TOKEN_AUTH with result, or err.
+type refreshCognito struct {
+	CognitoAPI
+	result *types.AuthenticationResultType
+	err    error
+}
+
+func (f refreshCognito) InitiateAuth(ctx context.Context, params *cognito.InitiateAuthInput, optFns ...func(*cognito.Options)) (*cognito.InitiateAuthOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cognito.InitiateAuthOutput{AuthenticationResult: f.result}, nil
+}
+
+func signedToken(t *testing.T, exp time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": exp.Unix()}).SignedString([]byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRefreshTokenExpiries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	accessExp := now.Add(time.Hour)
+	idExp := now.Add(30 * time.Minute)
+
+	c := &cognitoClient{
+		client: refreshCognito{result: &types.AuthenticationResultType{
+			AccessToken: aws.String(signedToken(t, accessExp)),
+			IdToken:     aws.String(signedToken(t, idExp)),
+		}},
+		logger: logger.Nop{},
+	}
+
+	out, err := c.RefreshToken(context.Background(), auth.RefreshTokenInput{RefreshToken: "refresh"})
+	if err != nil {
+		t.Fatalf("RefreshToken = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		expiresIn int64
+		want      time.Time
+	}{
+		{name: "access token", expiresAt: out.AccessTokenExpiresAt, expiresIn: out.AccessTokenExpiresIn, want: accessExp},
+		{name: "id token", expiresAt: out.IdTokenExpiresAt, expiresIn: out.IdTokenExpiresIn, want: idExp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expiresAt == nil || !tt.expiresAt.Equal(tt.want) {
+				t.Errorf("expiresAt = %v, want %v", tt.expiresAt, tt.want)
+			}
+			// A second of slack for the clock moving during the call.
+			wantIn := int64(time.Until(tt.want).Seconds())
+			if tt.expiresIn < wantIn-1 || tt.expiresIn > wantIn+1 {
+				t.Errorf("expiresIn = %d, want about %d", tt.expiresIn, wantIn)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenErrors(t *testing.T) {
+	valid := signedToken(t, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		fake       refreshCognito
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:    "refresh token refused",
+			fake:    refreshCognito{err: &smithy.GenericAPIError{Code: "NotAuthorizedException", Message: "expired"}},
+			wantErr: auth.ErrInvalidRefreshToken,
+		},
+		{
+			name:    "no result",
+			fake:    refreshCognito{},
+			wantErr: auth.ErrAuthenticationResultNil,
+		},
+		{
+			name:       "access token without a readable expiry",
+			fake:       refreshCognito{result: &types.AuthenticationResultType{AccessToken: aws.String("opaque"), IdToken: aws.String(valid)}},
+			wantStatus: 500,
+		},
+		{
+			name:       "id token without a readable expiry",
+			fake:       refreshCognito{result: &types.AuthenticationResultType{AccessToken: aws.String(valid), IdToken: aws.String("opaque")}},
+			wantStatus: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &cognitoClient{client: tt.fake, logger: logger.Nop{}}
+			out, err := c.RefreshToken(context.Background(), auth.RefreshTokenInput{RefreshToken: "refresh"})
+			if out != nil {
+				t.Errorf("RefreshToken = %+v, want no output", out)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantStatus != 0 {
+				var apiErr *app_error.ApiError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+					t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ResetPasswordsJobRepository keeps the jobs in Postgres. The unique index
+// on running jobs is the lock, so a second instance can't start a reset
+// while one is running elsewhere.
+type ResetPasswordsJobRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewResetPasswordsJobRepository(db *sql.DB, logger logger.Logger) auth.ResetPasswordsJobStore {
+	return &ResetPasswordsJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ResetPasswordsJobRepository) Start(ctx context.Context, job *auth.ResetPasswordsJob, staleBefore time.Time) error {
+	abandon := `UPDATE reset_passwords_jobs SET status = $1, error = 'abandoned', finished_at = NOW() WHERE status = $2 AND started_at < $3`
+	if _, err := r.db.ExecContext(ctx, abandon, auth.ResetPasswordsJobFailed, auth.ResetPasswordsJobRunning, staleBefore); err != nil {
+		r.logger.Warning("Error abandoning stale reset passwords jobs: %v", err)
+	}
+
+	query := `INSERT INTO reset_passwords_jobs (id, actor_id, group_name, status, started_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (status) WHERE status = 'RUNNING' DO NOTHING
+		RETURNING id`
+	var id string
+	if err := r.db.QueryRowContext(ctx, query, job.Id, job.ActorId, job.Group, job.Status, job.StartedAt).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return auth.ErrResetPasswordsInProgress
+		}
+		r.logger.Error("Error starting reset passwords job: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *ResetPasswordsJobRepository) Save(ctx context.Context, job *auth.ResetPasswordsJob) error {
+	query := `UPDATE reset_passwords_jobs SET status = $2, processed = $3, succeeded = $4, failed = $5, error = $6, finished_at = $7 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, job.Id, job.Status, job.Processed, job.Succeeded, job.Failed, job.Error, job.FinishedAt); err != nil {
+		r.logger.Error("Error saving reset passwords job: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *ResetPasswordsJobRepository) Get(ctx context.Context, id string) (*auth.ResetPasswordsJob, error) {
+	job := auth.ResetPasswordsJob{Id: id}
+	query := `SELECT actor_id, group_name, status, processed, succeeded, failed, error, started_at, finished_at FROM reset_passwords_jobs WHERE id = $1`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&job.ActorId, &job.Group, &job.Status, &job.Processed, &job.Succeeded, &job.Failed, &job.Error, &job.StartedAt, &job.FinishedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("Error getting reset passwords job: %v", err)
+		return nil, err
+	}
+	return &job, nil
+}
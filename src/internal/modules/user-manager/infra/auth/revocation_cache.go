@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+// maxRevocationCacheEntries bounds the cache between sweeps of expired
+// entries.
+const maxRevocationCacheEntries = 10000
+
+type revocationCacheEntry struct {
+	revokedAt *time.Time
+	expiresAt time.Time
+}
+
+// revocationCache keeps ValidateToken from reading the shared store on every
+// request. Revocations made through this instance are applied to the cache
+// right away; those made by other instances are seen once the entry expires,
+// so the TTL bounds how long a revoked token keeps working elsewhere.
+//
+// A store failure fails open, like the lockout policy: the last known answer
+// is served, or the token is let through when there is none. Tokens are
+// verified locally and are short lived, so an outage of the store shouldn't
+// take every authenticated request down with it.
+type revocationCache struct {
+	store   auth.TokenRevocationStore
+	ttl     time.Duration
+	logger  logger.Logger
+	mu      sync.Mutex
+	entries map[string]revocationCacheEntry
+}
+
+// NewCachedTokenRevocationStore puts an in-process cache in front of store.
+// It caches nothing when ttl is zero, but still fails open.
+func NewCachedTokenRevocationStore(store auth.TokenRevocationStore, ttl time.Duration, logger logger.Logger) auth.TokenRevocationStore {
+	return &revocationCache{
+		store:   store,
+		ttl:     ttl,
+		logger:  logger,
+		entries: make(map[string]revocationCacheEntry),
+	}
+}
+
+func (rc *revocationCache) Revoke(ctx context.Context, sub string, at time.Time) error {
+	if err := rc.store.Revoke(ctx, sub, at); err != nil {
+		return err
+	}
+	// The store keeps the latest revocation, and so does the cache.
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if entry, ok := rc.entries[sub]; ok && entry.revokedAt != nil && entry.revokedAt.After(at) {
+		at = *entry.revokedAt
+	}
+	rc.setLocked(sub, &at)
+	return nil
+}
+
+func (rc *revocationCache) RevokedAt(ctx context.Context, sub string) (*time.Time, error) {
+	rc.mu.Lock()
+	entry, cached := rc.entries[sub]
+	rc.mu.Unlock()
+	if cached && time.Now().Before(entry.expiresAt) {
+		return entry.revokedAt, nil
+	}
+
+	revokedAt, err := rc.store.RevokedAt(ctx, sub)
+	if err != nil {
+		rc.logger.Warning("Token revocation store unavailable, failing open: %v", err)
+		if cached {
+			return entry.revokedAt, nil
+		}
+		return nil, nil
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.setLocked(sub, revokedAt)
+	return revokedAt, nil
+}
+
+func (rc *revocationCache) setLocked(sub string, revokedAt *time.Time) {
+	if rc.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	if len(rc.entries) >= maxRevocationCacheEntries {
+		for key, entry := range rc.entries {
+			if now.After(entry.expiresAt) {
+				delete(rc.entries, key)
+			}
+		}
+	}
+	rc.entries[sub] = revocationCacheEntry{revokedAt: revokedAt, expiresAt: now.Add(rc.ttl)}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+)
+
+// countingRevocations counts reads of the shared store and fails them while
+// err is set.
+type countingRevocations struct {
+	memoryRevocations
+	reads int
+	err   error
+}
+
+func (c *countingRevocations) RevokedAt(ctx context.Context, sub string) (*time.Time, error) {
+	c.reads++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.memoryRevocations.RevokedAt(ctx, sub)
+}
+
+func TestRevocationCacheEvictsDisabledUser(t *testing.T) {
+	const sub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+	store := &countingRevocations{memoryRevocations: memoryRevocations{}}
+	c := &cognitoClient{
+		jwtVerify:   issuedAt{sub: sub, iat: time.Now().Add(-time.Minute)},
+		revocations: NewCachedTokenRevocationStore(store, time.Hour, logger.Nop{}),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.ValidateToken(context.Background(), "token"); err != nil {
+			t.Fatalf("ValidateToken = %v, want nil", err)
+		}
+	}
+	if store.reads != 1 {
+		t.Fatalf("store reads = %d, want 1", store.reads)
+	}
+
+	// What DisableUser and AdminResetPassword run once Cognito is done.
+	if err := c.revokeTokens(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ValidateToken(context.Background(), "token"); !errors.Is(err, auth.ErrInvalidAccessCode) {
+		t.Errorf("ValidateToken = %v, want %v", err, auth.ErrInvalidAccessCode)
+	}
+	if store.reads != 1 {
+		t.Errorf("store reads = %d, want 1", store.reads)
+	}
+}
+
+// resetCognito resets passwords and signs users out without complaint.
+type resetCognito struct {
+	CognitoAPI
+}
+
+func (resetCognito) AdminResetUserPassword(ctx context.Context, params *cognito.AdminResetUserPasswordInput, optFns ...func(*cognito.Options)) (*cognito.AdminResetUserPasswordOutput, error) {
+	return &cognito.AdminResetUserPasswordOutput{}, nil
+}
+
+func (resetCognito) AdminUserGlobalSignOut(ctx context.Context, params *cognito.AdminUserGlobalSignOutInput, optFns ...func(*cognito.Options)) (*cognito.AdminUserGlobalSignOutOutput, error) {
+	return &cognito.AdminUserGlobalSignOutOutput{}, nil
+}
+
+// The bulk reset paces AdminResetPassword itself, so a reset must not wait
+// on the revocation.
+func TestAdminResetPasswordDoesNotWait(t *testing.T) {
+	c := &cognitoClient{
+		client:      resetCognito{},
+		users:       newUserCache(time.Minute, false),
+		logger:      logger.Nop{},
+		revocations: NewCachedTokenRevocationStore(memoryRevocations{}, time.Hour, logger.Nop{}),
+	}
+
+	const resets = 5
+	start := time.Now()
+	for i := 0; i < resets; i++ {
+		if err := c.AdminResetPassword(context.Background(), auth.AdminResetPasswordInput{Id: fmt.Sprintf("0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d6%d", i)}); err != nil {
+			t.Fatalf("AdminResetPassword: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("%d resets took %s, want them not to wait on the revocation", resets, elapsed)
+	}
+}
+
+func TestRevocationCacheFailsOpen(t *testing.T) {
+	const sub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		// warm reads the store once before it fails.
+		warm bool
+		want *time.Time
+	}{
+		{name: "nothing cached lets the token through"},
+		{name: "expired entry is served", warm: true, want: &revokedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &countingRevocations{memoryRevocations: memoryRevocations{sub: revokedAt}}
+			cache := NewCachedTokenRevocationStore(store, time.Nanosecond, logger.Nop{})
+			if tt.warm {
+				if _, err := cache.RevokedAt(context.Background(), sub); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			store.err = errors.New("connection refused")
+			got, err := cache.RevokedAt(context.Background(), sub)
+			if err != nil {
+				t.Fatalf("RevokedAt error = %v, want nil", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("RevokedAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// tokenRevocationTTL is the longest access token lifetime Cognito allows; any
+// token issued before a revocation is expired by then anyway.
+const tokenRevocationTTL = 24 * time.Hour
+
+type TokenRevocationRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewTokenRevocationRepository(db *sql.DB, logger logger.Logger) auth.TokenRevocationStore {
+	return &TokenRevocationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *TokenRevocationRepository) Revoke(ctx context.Context, sub string, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM token_revocations WHERE revoked_at < $1`, at.Add(-tokenRevocationTTL)); err != nil {
+		r.logger.Warning("Error pruning token revocations: %v", err)
+	}
+
+	query := `INSERT INTO token_revocations (sub, revoked_at) VALUES ($1, $2) ON CONFLICT (sub) DO UPDATE SET revoked_at = GREATEST(token_revocations.revoked_at, EXCLUDED.revoked_at)`
+	if _, err := r.db.ExecContext(ctx, query, sub, at); err != nil {
+		r.logger.Error("Error revoking tokens: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *TokenRevocationRepository) RevokedAt(ctx context.Context, sub string) (*time.Time, error) {
+	var revokedAt time.Time
+	query := `SELECT revoked_at FROM token_revocations WHERE sub = $1 AND revoked_at > $2`
+	if err := r.db.QueryRowContext(ctx, query, sub, time.Now().Add(-tokenRevocationTTL)).Scan(&revokedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		r.logger.Error("Error getting token revocation: %v", err)
+		return nil, err
+	}
+	return &revokedAt, nil
+}
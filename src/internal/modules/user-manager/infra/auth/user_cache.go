@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/validator"
+	"sync"
+	"time"
+)
+
+type userCacheEntry struct {
+	user      auth.User
+	usernames []string
+	expiresAt time.Time
+}
+
+// userCache holds AdminGetUser results keyed by sub. Lookups come in by
+// username, so usernames are kept as aliases of the sub they resolved to and
+// dropped along with it. Writes that change a user must invalidate it; the TTL
+// only bounds staleness from changes made outside this service.
+type userCache struct {
+	mu            sync.Mutex
+	ttl           time.Duration
+	caseSensitive bool
+	bySub         map[string]*userCacheEntry
+	subByUsername map[string]string
+}
+
+// newUserCache returns a cache that stores nothing when ttl is zero.
+// caseSensitive follows auth.case_sensitive_usernames.
+func newUserCache(ttl time.Duration, caseSensitive bool) *userCache {
+	return &userCache{
+		ttl:           ttl,
+		caseSensitive: caseSensitive,
+		bySub:         make(map[string]*userCacheEntry),
+		subByUsername: make(map[string]string),
+	}
+}
+
+func (uc *userCache) get(username string) (*auth.User, bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	sub, ok := uc.subByUsername[uc.normalizeUsername(username)]
+	if !ok {
+		return nil, false
+	}
+	entry, ok := uc.bySub[sub]
+	if !ok || time.Now().After(entry.expiresAt) {
+		uc.removeLocked(sub)
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+func (uc *userCache) set(username string, user *auth.User) {
+	if uc.ttl <= 0 || user == nil || user.Id == "" {
+		return
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	username = uc.normalizeUsername(username)
+	if previous, known := uc.subByUsername[username]; known && previous != user.Id {
+		uc.removeLocked(previous)
+	}
+	entry, ok := uc.bySub[user.Id]
+	if !ok {
+		entry = &userCacheEntry{}
+		uc.bySub[user.Id] = entry
+	}
+	entry.user = *user
+	entry.expiresAt = time.Now().Add(uc.ttl)
+	if _, known := uc.subByUsername[username]; !known {
+		entry.usernames = append(entry.usernames, username)
+	}
+	uc.subByUsername[username] = user.Id
+}
+
+func (uc *userCache) invalidateSub(sub string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.removeLocked(sub)
+}
+
+// invalidateUsername is for writes that only know the username; it drops the
+// whole entry so other aliases of the same user don't keep serving it.
+func (uc *userCache) invalidateUsername(username string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	username = uc.normalizeUsername(username)
+	if sub, ok := uc.subByUsername[username]; ok {
+		uc.removeLocked(sub)
+	}
+	// The username may also be the sub itself.
+	uc.removeLocked(username)
+}
+
+func (uc *userCache) removeLocked(sub string) {
+	entry, ok := uc.bySub[sub]
+	if !ok {
+		return
+	}
+	for _, username := range entry.usernames {
+		delete(uc.subByUsername, username)
+	}
+	delete(uc.bySub, sub)
+}
+
+// normalizeUsername keys the cache the way usernames are stored.
+func (uc *userCache) normalizeUsername(username string) string {
+	return validator.NormalizeEmail(username, uc.caseSensitive)
+}
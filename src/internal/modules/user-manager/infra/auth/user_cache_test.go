@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"testing"
+	"time"
+)
+
+func TestUserCacheFollowsUsernameCaseSensitivity(t *testing.T) {
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		lookup        string
+		wantHit       bool
+	}{
+		{name: "insensitive, same case", lookup: "Member@Example.com", wantHit: true},
+		{name: "insensitive, other case", lookup: "member@example.com", wantHit: true},
+		{name: "sensitive, same case", caseSensitive: true, lookup: "Member@Example.com", wantHit: true},
+		{name: "sensitive, other case", caseSensitive: true, lookup: "member@example.com", wantHit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newUserCache(time.Minute, tt.caseSensitive)
+			cache.set("Member@Example.com", &auth.User{Id: "sub-1", Email: "Member@Example.com"})
+
+			_, hit := cache.get(tt.lookup)
+			if hit != tt.wantHit {
+				t.Errorf("get(%q) hit = %v, want %v", tt.lookup, hit, tt.wantHit)
+			}
+		})
+	}
+}
@@ -2,20 +2,65 @@ package user
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/outbox/domain/outbox"
 	"auth-api/src/pkg/logger"
+	"context"
 	"database/sql"
 )
 
+const (
+	outboxEventUserCreated = "user.created"
+	outboxEventUserDeleted = "user.deleted"
+)
+
+// outboxUserPayload only identifies the user; receivers look up the rest.
+type outboxUserPayload struct {
+	UserID string `json:"userId"`
+}
+
 type UserRepository struct {
 	db     *sql.DB
 	logger logger.Logger
+	outbox outbox.OutboxRepository
 }
 
-func NewUserRepository(db *sql.DB, logger logger.Logger) user.UserRepository {
+// NewUserRepository takes a nil outbox when the outbox is disabled, in which
+// case writes don't record events.
+func NewUserRepository(db *sql.DB, logger logger.Logger, outbox outbox.OutboxRepository) user.UserRepository {
 	return &UserRepository{
 		db:     db,
 		logger: logger,
+		outbox: outbox,
+	}
+}
+
+// execWithEvent runs query and, with an outbox, adds the event in the same
+// transaction.
+func (r *UserRepository) execWithEvent(event string, userID user.UserID, query string, args ...any) error {
+	ctx := context.Background()
+	if r.outbox == nil {
+		_, err := r.db.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	message, err := outbox.NewMessage(event, outboxUserPayload{UserID: userID.String()})
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
 	}
+	if err := r.outbox.Add(ctx, tx, message); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *UserRepository) GetByID(input *user.GetUserInput) (*user.User, error) {
@@ -58,7 +103,7 @@ func (r *UserRepository) Create(input *user.CreateUserInput) error {
 	}
 
 	query := `INSERT INTO users (id, name, email, phone) VALUES ($1, $2, $3, $4)`
-	if _, err := r.db.Exec(query, input.ID.String(), input.Name, input.Email, input.Phone); err != nil {
+	if err := r.execWithEvent(outboxEventUserCreated, input.ID, query, input.ID.String(), input.Name, input.Email, input.Phone); err != nil {
 		r.logger.Error("Error creating user: %v", err)
 		return err
 	}
@@ -84,9 +129,50 @@ func (r *UserRepository) Delete(input *user.DeleteUserInput) error {
 	}
 
 	query := `DELETE FROM users WHERE id = $1`
-	if _, err := r.db.Exec(query, input.ID.String()); err != nil {
+	if err := r.execWithEvent(outboxEventUserDeleted, input.ID, query, input.ID.String()); err != nil {
 		r.logger.Error("Error deleting user: %v", err)
 		return err
 	}
 	return nil
 }
+
+func (r *UserRepository) List(input *user.ListUsersInput) (*user.ListUsersOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	// One row past the page tells whether there is another one.
+	var after *string
+	if input.After != nil {
+		id := input.After.String()
+		after = &id
+	}
+	query := `SELECT id, name, email, phone FROM users WHERE $1::text IS NULL OR id > $1 ORDER BY id LIMIT $2`
+	rows, err := r.db.Query(query, after, input.Limit+1)
+	if err != nil {
+		r.logger.Error("Error listing users: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := &user.ListUsersOutput{Users: make([]user.User, 0, input.Limit)}
+	for rows.Next() {
+		var usr user.User
+		if err := rows.Scan(&usr.ID, &usr.Name, &usr.Email, &usr.Phone); err != nil {
+			r.logger.Error("Error scanning user: %v", err)
+			return nil, err
+		}
+		out.Users = append(out.Users, usr)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error listing users: %v", err)
+		return nil, err
+	}
+
+	if len(out.Users) > input.Limit {
+		out.Users = out.Users[:input.Limit]
+		next := out.Users[input.Limit-1].ID
+		out.Next = &next
+	}
+	return out, nil
+}
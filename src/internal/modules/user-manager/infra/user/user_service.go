@@ -109,3 +109,11 @@ func (u *UserService) Delete(id *user.DeleteUserInput) (*user.DeleteUserOutput,
 	return out, nil
 
 }
+
+func (u *UserService) List(input *user.ListUsersInput) (*user.ListUsersOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return u.repo.List(input)
+}
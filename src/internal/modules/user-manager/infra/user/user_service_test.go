@@ -0,0 +1,267 @@
+package user
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/pkg/app_error"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// memoryUsers is a UserRepository kept in a map. The service only reaches it
+// through the interface, so it stands in for the Postgres one.
+type memoryUsers struct {
+	users map[user.UserID]user.User
+	lists []user.ListUsersInput
+}
+
+func newMemoryUsers(users ...user.User) *memoryUsers {
+	m := &memoryUsers{users: map[user.UserID]user.User{}}
+	for _, u := range users {
+		m.users[u.ID] = u
+	}
+	return m
+}
+
+func (m *memoryUsers) GetByID(input *user.GetUserInput) (*user.User, error) {
+	id, _ := user.ParseUserID(input.ID)
+	u, ok := m.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (m *memoryUsers) GetByEmail(input *user.GetUserByEmailInput) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Email == input.Email {
+			return &u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *memoryUsers) Create(input *user.CreateUserInput) error {
+	m.users[input.ID] = user.User{ID: input.ID, Name: input.Name, Email: input.Email, Phone: input.Phone}
+	return nil
+}
+
+func (m *memoryUsers) Update(input *user.UpdateUserInput) error {
+	u := m.users[input.ID]
+	if input.Name != nil {
+		u.Name = *input.Name
+	}
+	if input.Email != nil {
+		u.Email = *input.Email
+	}
+	if input.Phone != nil {
+		u.Phone = input.Phone
+	}
+	m.users[input.ID] = u
+	return nil
+}
+
+func (m *memoryUsers) Delete(input *user.DeleteUserInput) error {
+	delete(m.users, input.ID)
+	return nil
+}
+
+func (m *memoryUsers) List(input *user.ListUsersInput) (*user.ListUsersOutput, error) {
+	m.lists = append(m.lists, *input)
+	ids := make([]string, 0, len(m.users))
+	for id := range m.users {
+		if input.After == nil || id.String() > input.After.String() {
+			ids = append(ids, id.String())
+		}
+	}
+	sort.Strings(ids)
+
+	out := &user.ListUsersOutput{Users: []user.User{}}
+	for _, id := range ids {
+		if len(out.Users) == input.Limit {
+			last := out.Users[len(out.Users)-1].ID
+			out.Next = &last
+			break
+		}
+		parsed, _ := user.ParseUserID(id)
+		out.Users = append(out.Users, m.users[parsed])
+	}
+	return out, nil
+}
+
+func mustUserID(t *testing.T, id string) user.UserID {
+	t.Helper()
+	parsed, err := user.ParseUserID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func isBadRequest(err error) bool {
+	var apiErr *app_error.ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 400
+}
+
+const (
+	adaID   = "11111111-1111-4111-8111-111111111111"
+	graceID = "22222222-2222-4222-8222-222222222222"
+	alanID  = "33333333-3333-4333-8333-333333333333"
+)
+
+func TestUserServiceGet(t *testing.T) {
+	ada := user.User{ID: mustUserID(t, adaID), Name: "Ada Lovelace", Email: "ada@example.com"}
+	svc := NewUserService(newMemoryUsers(ada))
+
+	got, err := svc.GetByID(&user.GetUserInput{ID: adaID})
+	if err != nil || got.Email != ada.Email {
+		t.Errorf("GetByID = %+v, %v, want ada", got, err)
+	}
+	if _, err := svc.GetByID(&user.GetUserInput{ID: graceID}); err != user.ErrUserNotFound {
+		t.Errorf("GetByID of an unknown user = %v, want %v", err, user.ErrUserNotFound)
+	}
+	if _, err := svc.GetByID(&user.GetUserInput{ID: "not-a-uuid"}); !isBadRequest(err) {
+		t.Errorf("GetByID of a bad id = %v, want a 400", err)
+	}
+
+	got, err = svc.GetByEmail(&user.GetUserByEmailInput{Email: "ada@example.com"})
+	if err != nil || got.ID != ada.ID {
+		t.Errorf("GetByEmail = %+v, %v, want ada", got, err)
+	}
+	if _, err := svc.GetByEmail(&user.GetUserByEmailInput{Email: "grace@example.com"}); err != user.ErrUserNotFound {
+		t.Errorf("GetByEmail of an unknown user = %v, want %v", err, user.ErrUserNotFound)
+	}
+	if _, err := svc.GetByEmail(&user.GetUserByEmailInput{Email: "not-an-email"}); !isBadRequest(err) {
+		t.Errorf("GetByEmail of a bad email = %v, want a 400", err)
+	}
+}
+
+func TestUserServiceCreate(t *testing.T) {
+	repo := newMemoryUsers(user.User{ID: mustUserID(t, adaID), Name: "Ada Lovelace", Email: "ada@example.com"})
+	svc := NewUserService(repo)
+
+	out, err := svc.Create(&user.CreateUserInput{ID: mustUserID(t, graceID), Name: "Grace Hopper", Email: "grace@example.com"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, ok := repo.users[mustUserID(t, graceID)]; !ok {
+		t.Error("Create didn't store the user")
+	}
+
+	if _, err := svc.Create(&user.CreateUserInput{ID: mustUserID(t, alanID), Name: "Someone Else", Email: "ada@example.com"}); err != user.ErrUserAlreadyExists {
+		t.Errorf("Create with a taken email = %v, want %v", err, user.ErrUserAlreadyExists)
+	}
+	if _, err := svc.Create(&user.CreateUserInput{ID: mustUserID(t, alanID), Name: "Al", Email: "alan@example.com"}); !isBadRequest(err) {
+		t.Errorf("Create with a short name = %v, want a 400", err)
+	}
+	if len(repo.users) != 2 {
+		t.Errorf("stored %d users, want the rejected ones left out", len(repo.users))
+	}
+
+	if err := out.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if _, ok := repo.users[mustUserID(t, graceID)]; ok {
+		t.Error("Rollback left the created user")
+	}
+}
+
+func TestUserServiceUpdate(t *testing.T) {
+	ada := user.User{ID: mustUserID(t, adaID), Name: "Ada Lovelace", Email: "ada@example.com"}
+	repo := newMemoryUsers(ada)
+	svc := NewUserService(repo)
+
+	name := "Ada King"
+	out, err := svc.Update(&user.UpdateUserInput{ID: ada.ID, Name: &name})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := repo.users[ada.ID]; got.Name != name || got.Email != ada.Email {
+		t.Errorf("stored %+v, want only the name changed", got)
+	}
+
+	if _, err := svc.Update(&user.UpdateUserInput{ID: mustUserID(t, graceID), Name: &name}); err != user.ErrUserNotFound {
+		t.Errorf("Update of an unknown user = %v, want %v", err, user.ErrUserNotFound)
+	}
+	short := "Al"
+	if _, err := svc.Update(&user.UpdateUserInput{ID: ada.ID, Name: &short}); !isBadRequest(err) {
+		t.Errorf("Update with a short name = %v, want a 400", err)
+	}
+
+	if err := out.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got := repo.users[ada.ID]; got.Name != ada.Name {
+		t.Errorf("name = %q after the rollback, want %q", got.Name, ada.Name)
+	}
+}
+
+func TestUserServiceDelete(t *testing.T) {
+	ada := user.User{ID: mustUserID(t, adaID), Name: "Ada Lovelace", Email: "ada@example.com"}
+	repo := newMemoryUsers(ada)
+	svc := NewUserService(repo)
+
+	out, err := svc.Delete(&user.DeleteUserInput{ID: ada.ID})
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := repo.users[ada.ID]; ok {
+		t.Error("Delete left the user")
+	}
+	if _, err := svc.Delete(&user.DeleteUserInput{ID: ada.ID}); err != user.ErrUserNotFound {
+		t.Errorf("Delete of a deleted user = %v, want %v", err, user.ErrUserNotFound)
+	}
+
+	if err := out.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got, ok := repo.users[ada.ID]; !ok || got.Email != ada.Email {
+		t.Errorf("Rollback restored %+v, want ada back", got)
+	}
+}
+
+func TestUserServiceList(t *testing.T) {
+	repo := newMemoryUsers(
+		user.User{ID: mustUserID(t, adaID), Name: "Ada Lovelace", Email: "ada@example.com"},
+		user.User{ID: mustUserID(t, graceID), Name: "Grace Hopper", Email: "grace@example.com"},
+		user.User{ID: mustUserID(t, alanID), Name: "Alan Turing", Email: "alan@example.com"},
+	)
+	svc := NewUserService(repo)
+
+	all, err := svc.List(&user.ListUsersInput{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all.Users) != 3 || all.Next != nil {
+		t.Errorf("List = %d users next %v, want all 3 on one page", len(all.Users), all.Next)
+	}
+	if repo.lists[0].Limit != user.DefaultListUsersLimit {
+		t.Errorf("repository asked for %d, want the default %d", repo.lists[0].Limit, user.DefaultListUsersLimit)
+	}
+
+	var seen []string
+	input := &user.ListUsersInput{Limit: 2}
+	for {
+		page, err := svc.List(input)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, u := range page.Users {
+			seen = append(seen, u.ID.String())
+		}
+		if page.Next == nil {
+			break
+		}
+		input = &user.ListUsersInput{Limit: 2, After: page.Next}
+	}
+	if want := []string{adaID, graceID, alanID}; len(seen) != len(want) || seen[0] != want[0] || seen[1] != want[1] || seen[2] != want[2] {
+		t.Errorf("paged through %v, want %v", seen, want)
+	}
+
+	for _, limit := range []int{-1, user.MaxListUsersLimit + 1} {
+		if _, err := svc.List(&user.ListUsersInput{Limit: limit}); !isBadRequest(err) {
+			t.Errorf("List with limit %d = %v, want a 400", limit, err)
+		}
+	}
+}
@@ -3,17 +3,51 @@ package admin
 import (
 	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/internal/shared/storage/domain/storage"
 	"auth-api/src/pkg/logger"
+	"time"
 )
 
 type UseCases struct {
-	Register *RegisterAdminUseCase
-	Update   *UpdateAdminUseCase
+	Register          *RegisterAdminUseCase
+	Update            *UpdateAdminUseCase
+	ResetPasswords    *ResetPasswordsUseCase
+	ResetPasswordsJob *GetResetPasswordsJobUseCase
+	ExportUsers       *ExportUsersUseCase
+	StoreExport       *StoreExportUseCase
 }
 
-func NewUseCases(adminService admin.AdminService, authService auth.AuthService, logger logger.Logger) *UseCases {
+// Dependencies are the services the admin use cases run on. ExportStorage
+// may be nil, which leaves export download links off.
+type Dependencies struct {
+	Admin              admin.AdminService
+	Auth               auth.AuthService
+	Audit              audit.AuditService
+	Sessions           session.SessionService
+	ResetPasswordsJobs auth.ResetPasswordsJobStore
+	ExportStorage      storage.StorageService
+	Logger             logger.Logger
+}
+
+// Options tune the admin use cases.
+type Options struct {
+	ResetPasswordsPerSecond int
+	ResetPasswordsTimeout   time.Duration
+	AliasConflict           string
+	ExportPrefix            string
+	ExportURLExpiry         time.Duration
+}
+
+func NewUseCases(deps Dependencies, opts Options) *UseCases {
+	adminService, authService, auditService, logger := deps.Admin, deps.Auth, deps.Audit, deps.Logger
 	return &UseCases{
-		Register: NewRegisterAdminUseCase(adminService, authService, logger),
-		Update:   NewUpdateAdminUseCase(adminService, logger),
+		Register:          NewRegisterAdminUseCase(adminService, authService, auditService, logger, opts.AliasConflict),
+		Update:            NewUpdateAdminUseCase(adminService, authService, logger),
+		ResetPasswords:    NewResetPasswordsUseCase(adminService, authService, auditService, deps.Sessions, deps.ResetPasswordsJobs, logger, opts.ResetPasswordsPerSecond, opts.ResetPasswordsTimeout),
+		ResetPasswordsJob: NewGetResetPasswordsJobUseCase(deps.ResetPasswordsJobs, logger),
+		ExportUsers:       NewExportUsersUseCase(authService, auditService, logger),
+		StoreExport:       NewStoreExportUseCase(deps.ExportStorage, logger, opts.ExportPrefix, opts.ExportURLExpiry),
 	}
 }
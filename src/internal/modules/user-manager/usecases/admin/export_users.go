@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/paginate"
+	"context"
+	"errors"
+	"fmt"
+)
+
+const auditActionExportUsers = "EXPORT_USERS"
+
+type ExportUsersUseCase struct {
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
+}
+
+type ExportUsersInput struct {
+	ActorID string
+	Group   *auth.UserGroup
+}
+
+func (input *ExportUsersInput) Validate() error {
+	if input.Group != nil && *input.Group != auth.GroupAdmin && *input.Group != auth.GroupUser {
+		return auth.ErrInvalidGroup
+	}
+	return nil
+}
+
+func NewExportUsersUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *ExportUsersUseCase {
+	return &ExportUsersUseCase{
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// Execute hands each user to yield as soon as its page arrives, so only one
+// page is ever held in memory. Paging stops once ctx is cancelled, e.g. when
+// the client goes away, or when yield fails.
+func (uc *ExportUsersUseCase) Execute(ctx context.Context, input ExportUsersInput, yield func(auth.User) error) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	group := "all"
+	if input.Group != nil {
+		group = string(*input.Group)
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionExportUsers,
+		Details: fmt.Sprintf("group=%s", group),
+	}); err != nil {
+		uc.logger.Error("Error recording export users audit entry: %s", err)
+		return err
+	}
+
+	fetch := func(ctx context.Context, nextToken *string) ([]auth.User, *string, error) {
+		listUsersInput := auth.ListUsersInput{
+			Group:     input.Group,
+			NextToken: nextToken,
+		}
+		if err := listUsersInput.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		page, err := uc.auth.ListUsers(ctx, listUsersInput)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Users, page.NextToken, nil
+	}
+
+	err := paginate.Paginate(ctx, fetch, 0, yield)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		uc.logger.Error("Error exporting users: %s", err)
+	}
+	return err
+}
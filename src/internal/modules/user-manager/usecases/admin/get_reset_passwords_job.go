@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type GetResetPasswordsJobUseCase struct {
+	jobs   auth.ResetPasswordsJobStore
+	logger logger.Logger
+}
+
+type GetResetPasswordsJobInput struct {
+	Id string
+}
+
+func (input *GetResetPasswordsJobInput) Validate() error {
+	if _, err := uuid.Parse(input.Id); err != nil {
+		return auth.NewValidationError("Id")
+	}
+	return nil
+}
+
+func NewGetResetPasswordsJobUseCase(jobs auth.ResetPasswordsJobStore, logger logger.Logger) *GetResetPasswordsJobUseCase {
+	return &GetResetPasswordsJobUseCase{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+func (uc *GetResetPasswordsJobUseCase) Execute(ctx context.Context, input GetResetPasswordsJobInput) (*auth.ResetPasswordsJob, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	job, err := uc.jobs.Get(ctx, input.Id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, auth.ErrResetPasswordsJobNotFound
+	}
+	return job, nil
+}
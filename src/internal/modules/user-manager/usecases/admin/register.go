@@ -3,26 +3,40 @@ package admin
 import (
 	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
 	"auth-api/src/pkg/logger"
 	"context"
+	"fmt"
+)
+
+const (
+	AliasConflictRefuse   = "refuse"
+	AliasConflictReassign = "reassign"
+
+	auditActionAliasReassigned = "ADMIN_ALIAS_REASSIGNED"
 )
 
 type RegisterAdminUseCase struct {
-	adminService admin.AdminService
-	auth         auth.AuthService
-	logger       logger.Logger
+	adminService  admin.AdminService
+	auth          auth.AuthService
+	audit         audit.AuditService
+	logger        logger.Logger
+	aliasConflict string
 }
 
 type RegisterAdminInput struct {
+	ActorID     string
 	SignupAdmin auth.CreateAdminInput
 	admin.CreateAdminInput
 }
 
-func NewRegisterAdminUseCase(adminService admin.AdminService, auth auth.AuthService, logger logger.Logger) *RegisterAdminUseCase {
+func NewRegisterAdminUseCase(adminService admin.AdminService, auth auth.AuthService, audit audit.AuditService, logger logger.Logger, aliasConflict string) *RegisterAdminUseCase {
 	return &RegisterAdminUseCase{
-		adminService: adminService,
-		auth:         auth,
-		logger:       logger,
+		adminService:  adminService,
+		auth:          auth,
+		audit:         audit,
+		logger:        logger,
+		aliasConflict: aliasConflict,
 	}
 }
 
@@ -45,6 +59,8 @@ func (uc *RegisterAdminUseCase) Execute(ctx context.Context, input RegisterAdmin
 		return admin.ErrAdminAlreadyExists
 	}
 
+	input.SignupAdmin.ReassignAlias = uc.aliasConflict == AliasConflictReassign
+	input.SignupAdmin.ClientMetadata = provisioningMetadata(input.SignupAdmin.ClientMetadata, input.ActorID)
 	signUpOutput, err := uc.auth.CreateAdmin(ctx, input.SignupAdmin)
 	if err != nil {
 		return err
@@ -80,5 +96,30 @@ func (uc *RegisterAdminUseCase) Execute(ctx context.Context, input RegisterAdmin
 		}
 	}()
 
+	if signUpOutput.ReassignedAliasFrom != "" {
+		// The alias has already moved in Cognito, so a failure here is only
+		// logged.
+		if err := uc.audit.Record(ctx, audit.RecordInput{
+			Actor:   input.ActorID,
+			Action:  auditActionAliasReassigned,
+			Details: fmt.Sprintf("email=%s from=%s to=%s", input.SignupAdmin.Username, signUpOutput.ReassignedAliasFrom, signUpOutput.Id),
+		}); err != nil {
+			uc.logger.Error("Error recording alias reassignment audit entry: %s", err)
+		}
+	}
+
 	return nil
 }
+
+// provisioningMetadata tells the user pool triggers who provisioned the user
+// and how, on top of what the caller passed. Those two keys can't be
+// overridden by the caller.
+func provisioningMetadata(metadata map[string]string, actorID string) map[string]string {
+	out := make(map[string]string, len(metadata)+2)
+	for key, value := range metadata {
+		out[key] = value
+	}
+	out["provisionedBy"] = actorID
+	out["source"] = "admin"
+	return out
+}
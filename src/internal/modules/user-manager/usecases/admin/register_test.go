@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+)
+
+// newAdmins has no admin rows yet and accepts any new one.
+type newAdmins struct {
+	admin.AdminService
+	created int
+}
+
+func (s *newAdmins) GetByEmail(*admin.GetAdminByEmailInput) (*admin.Admin, error) {
+	return nil, admin.ErrAdminNotFound
+}
+
+func (s *newAdmins) Create(input *admin.CreateAdminInput) (*admin.CreateAdminOutput, error) {
+	s.created++
+	return admin.NewCreateAdminOutput(&input.ID, s), nil
+}
+
+// aliasOwnerAuth creates admins in a pool where owner already holds the
+// email alias, moving it only when asked to.
+type aliasOwnerAuth struct {
+	auth.AuthService
+	owner    string
+	reassign []bool
+}
+
+func (a *aliasOwnerAuth) CreateAdmin(ctx context.Context, input auth.CreateAdminInput) (*auth.CreateAdminOutput, error) {
+	a.reassign = append(a.reassign, input.ReassignAlias)
+	if a.owner != "" && !input.ReassignAlias {
+		return nil, auth.ErrAliasAlreadyExists
+	}
+	out := auth.NewCreateAdminOutput("0b7f3c1e-6a3e-4a8e-9d57-2f1b8c0e5a11", input.Username, a)
+	if input.ReassignAlias {
+		out.ReassignedAliasFrom = a.owner
+	}
+	return out, nil
+}
+
+func TestRegisterAdminAliasConflict(t *testing.T) {
+	tests := []struct {
+		name          string
+		aliasConflict string
+		owner         string
+		wantErr       error
+		wantReassign  bool
+		wantAudit     []string
+	}{
+		{name: "refuse", aliasConflict: AliasConflictRefuse, owner: "old-owner", wantErr: auth.ErrAliasAlreadyExists},
+		{name: "unset refuses", owner: "old-owner", wantErr: auth.ErrAliasAlreadyExists},
+		{name: "reassign", aliasConflict: AliasConflictReassign, owner: "old-owner", wantReassign: true, wantAudit: []string{auditActionAliasReassigned}},
+		{name: "reassign without a conflict", aliasConflict: AliasConflictReassign, wantReassign: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admins := &newAdmins{}
+			authService := &aliasOwnerAuth{owner: tt.owner}
+			auditService := &recordingAudit{}
+			uc := NewRegisterAdminUseCase(admins, authService, auditService, logger.Nop{}, tt.aliasConflict)
+
+			err := uc.Execute(context.Background(), RegisterAdminInput{
+				ActorID:          "actor",
+				SignupAdmin:      auth.CreateAdminInput{Username: "admin@example.com", Password: "Str0ng!Passw0rd", Name: "Admin"},
+				CreateAdminInput: admin.CreateAdminInput{Name: "Admin", Email: "admin@example.com"},
+			})
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if len(authService.reassign) != 1 || authService.reassign[0] != tt.wantReassign {
+				t.Errorf("ReassignAlias = %v, want %v", authService.reassign, tt.wantReassign)
+			}
+			wantCreated := 1
+			if tt.wantErr != nil {
+				wantCreated = 0
+			}
+			if admins.created != wantCreated {
+				t.Errorf("created %d admin rows, want %d", admins.created, wantCreated)
+			}
+			if len(auditService.actions) != len(tt.wantAudit) || (len(tt.wantAudit) > 0 && auditService.actions[0] != tt.wantAudit[0]) {
+				t.Errorf("audited %v, want %v", auditService.actions, tt.wantAudit)
+			}
+		})
+	}
+}
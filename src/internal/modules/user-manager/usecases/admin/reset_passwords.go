@@ -0,0 +1,283 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/paginate"
+	"auth-api/src/pkg/validator"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	resetPasswordsCodeIdentifier   = "RESET_PASSWORDS_CODE"
+	auditActionResetPasswords      = "RESET_PASSWORDS"
+	auditActionResetPasswordsDone  = "RESET_PASSWORDS_COMPLETED"
+	defaultResetPasswordsPerSecond = 5
+	defaultResetPasswordsTimeout   = 10 * time.Minute
+	resetPasswordsFinishTimeout    = 10 * time.Second
+)
+
+type ResetPasswordsUseCase struct {
+	adminService admin.AdminService
+	auth         auth.AuthService
+	audit        audit.AuditService
+	sessions     session.SessionService
+	jobs         auth.ResetPasswordsJobStore
+	logger       logger.Logger
+	perSecond    int
+	// timeout bounds the job, which runs after the request has been answered.
+	timeout time.Duration
+}
+
+type ResetPasswordsInput struct {
+	ActorID          admin.AdminID
+	Group            *auth.UserGroup
+	ConfirmationCode string
+}
+
+func (input *ResetPasswordsInput) Validate() error {
+	actorID, err := admin.ParseAdminID(input.ActorID.String())
+	if err != nil {
+		return err
+	}
+	input.ActorID = actorID
+
+	if input.Group != nil && *input.Group != auth.GroupAdmin && *input.Group != auth.GroupUser {
+		return auth.ErrInvalidGroup
+	}
+
+	if input.ConfirmationCode != "" {
+		if err := validator.ValidateNumeric(input.ConfirmationCode); err != nil {
+			return auth.NewValidationError("ConfirmationCode")
+		}
+	}
+	return nil
+}
+
+func NewResetPasswordsUseCase(adminService admin.AdminService, auth auth.AuthService, audit audit.AuditService, sessions session.SessionService, jobs auth.ResetPasswordsJobStore, logger logger.Logger, perSecond int, timeout time.Duration) *ResetPasswordsUseCase {
+	if perSecond <= 0 {
+		perSecond = defaultResetPasswordsPerSecond
+	}
+	if timeout <= 0 {
+		timeout = defaultResetPasswordsTimeout
+	}
+	return &ResetPasswordsUseCase{
+		adminService: adminService,
+		auth:         auth,
+		audit:        audit,
+		sessions:     sessions,
+		jobs:         jobs,
+		logger:       logger,
+		perSecond:    perSecond,
+		timeout:      timeout,
+	}
+}
+
+func (uc *ResetPasswordsUseCase) Execute(ctx context.Context, input ResetPasswordsInput) (*auth.ResetPasswordsOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	getAdminInput := &admin.GetAdminInput{
+		ID: input.ActorID.String(),
+	}
+	if err := getAdminInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	actor, err := uc.adminService.GetByID(getAdminInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ConfirmationCode == "" {
+		return uc.requestConfirmation(ctx, actor)
+	}
+
+	verifyCodeInput := auth.VerifyCodeInput{
+		Username:   actor.Email,
+		Code:       input.ConfirmationCode,
+		Identifier: resetPasswordsCodeIdentifier,
+	}
+	if err := verifyCodeInput.Validate(); err != nil {
+		return nil, err
+	}
+	if err := uc.auth.VerifyCode(ctx, verifyCodeInput); err != nil {
+		return nil, err
+	}
+
+	group := "all"
+	if input.Group != nil {
+		group = string(*input.Group)
+	}
+
+	now := time.Now()
+	job := &auth.ResetPasswordsJob{
+		Id:        uuid.NewString(),
+		ActorId:   actor.ID.String(),
+		Group:     group,
+		Status:    auth.ResetPasswordsJobRunning,
+		StartedAt: now,
+	}
+	if err := uc.jobs.Start(ctx, job, now.Add(-uc.timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   actor.ID.String(),
+		Action:  auditActionResetPasswords,
+		Details: fmt.Sprintf("job=%s group=%s", job.Id, group),
+	}); err != nil {
+		uc.logger.Error("Error recording reset passwords audit entry: %s", err)
+		markFinished(job, err)
+		uc.release(ctx, job)
+		return nil, err
+	}
+
+	// The job runs to completion, or its own deadline, after the admin's
+	// request is answered; its progress is read back by id.
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), uc.timeout)
+	started := *job
+	go func() {
+		defer cancel()
+		uc.run(jobCtx, job, input.Group)
+	}()
+
+	return &auth.ResetPasswordsOutput{
+		Job: &started,
+	}, nil
+}
+
+func (uc *ResetPasswordsUseCase) run(ctx context.Context, job *auth.ResetPasswordsJob, group *auth.UserGroup) {
+	err := uc.resetAll(ctx, job, group)
+
+	markFinished(job, err)
+
+	// A job that hit its deadline still has to be recorded and marked failed.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resetPasswordsFinishTimeout)
+	defer cancel()
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   job.ActorId,
+		Action:  auditActionResetPasswordsDone,
+		Details: fmt.Sprintf("job=%s group=%s status=%s processed=%d succeeded=%d failed=%d", job.Id, job.Group, job.Status, job.Processed, job.Succeeded, job.Failed),
+	}); err != nil {
+		uc.logger.Error("Error recording reset passwords completion audit entry: %s", err)
+	}
+	uc.release(ctx, job)
+}
+
+func markFinished(job *auth.ResetPasswordsJob, err error) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = auth.ResetPasswordsJobCompleted
+	if err != nil {
+		job.Status = auth.ResetPasswordsJobFailed
+		job.Error = err.Error()
+	}
+}
+
+// release saves how the job ended, which lets the next one start.
+func (uc *ResetPasswordsUseCase) release(ctx context.Context, job *auth.ResetPasswordsJob) {
+	if err := uc.jobs.Save(ctx, job); err != nil {
+		uc.logger.Error("Error finishing reset passwords job %s: %v", job.Id, err)
+	}
+}
+
+func (uc *ResetPasswordsUseCase) requestConfirmation(ctx context.Context, actor *admin.Admin) (*auth.ResetPasswordsOutput, error) {
+	generateAndSendCodeInput := auth.GenerateAndSendCodeInput{
+		Username:   actor.Email,
+		Identifier: resetPasswordsCodeIdentifier,
+		Subject:    "Confirm password reset for all users",
+		Body:       "Your confirmation code to reset user passwords is: %s",
+	}
+	if err := generateAndSendCodeInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.auth.GenerateAndSendCode(ctx, generateAndSendCodeInput); err != nil {
+		uc.logger.Error("failed to generate code: %v", err)
+		return nil, err
+	}
+
+	return &auth.ResetPasswordsOutput{
+		ConfirmationRequired: true,
+	}, nil
+}
+
+func (uc *ResetPasswordsUseCase) resetAll(ctx context.Context, job *auth.ResetPasswordsJob, group *auth.UserGroup) error {
+	ticker := time.NewTicker(time.Second / time.Duration(uc.perSecond))
+	defer ticker.Stop()
+
+	fetch := func(ctx context.Context, nextToken *string) ([]auth.User, *string, error) {
+		listUsersInput := auth.ListUsersInput{
+			Group:     group,
+			NextToken: nextToken,
+		}
+		if err := listUsersInput.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		page, err := uc.auth.ListUsers(ctx, listUsersInput)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Users, page.NextToken, nil
+	}
+
+	err := paginate.Paginate(ctx, fetch, 0, func(u auth.User) error {
+		if u.Id == job.ActorId {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		job.Processed++
+		resetInput := auth.AdminResetPasswordInput{
+			Id: u.Id,
+		}
+		err := resetInput.Validate()
+		if err == nil {
+			err = uc.auth.AdminResetPassword(ctx, resetInput)
+		}
+		if err != nil {
+			job.Failed++
+			uc.logger.Warning("Error resetting password of user %s: %v", u.Id, err)
+			uc.saveProgress(ctx, job)
+			return nil
+		}
+		job.Succeeded++
+		// The reset revoked the user's tokens, so their sessions are dead
+		// and must stop counting against the session limit.
+		if err := uc.sessions.DeleteSignIns(ctx, session.DeleteSignInsInput{
+			Username: u.Email,
+		}); err != nil {
+			uc.logger.Warning("Error deleting sessions after password reset: %v", err)
+		}
+		uc.saveProgress(ctx, job)
+		return nil
+	})
+
+	uc.logger.Info("Reset passwords job %s finished: processed=%d succeeded=%d failed=%d", job.Id, job.Processed, job.Succeeded, job.Failed)
+
+	return err
+}
+
+// saveProgress keeps the counts of a running job current for its status
+// endpoint. A failed save only makes them lag.
+func (uc *ResetPasswordsUseCase) saveProgress(ctx context.Context, job *auth.ResetPasswordsJob) {
+	if err := uc.jobs.Save(ctx, job); err != nil {
+		uc.logger.Warning("Error saving reset passwords job progress: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+const resetActorID = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+type actorAdmins struct {
+	admin.AdminService
+}
+
+func (actorAdmins) GetByID(*admin.GetAdminInput) (*admin.Admin, error) {
+	id, _ := admin.ParseAdminID(resetActorID)
+	return &admin.Admin{ID: id, Email: "admin@example.com"}, nil
+}
+
+// pagedAuth serves users two pages at a time and counts the resets, each of
+// which fails once ctx is done.
+type pagedAuth struct {
+	auth.AuthService
+	pages  [][]auth.User
+	resets int
+}
+
+func (a *pagedAuth) VerifyCode(context.Context, auth.VerifyCodeInput) error { return nil }
+
+func (a *pagedAuth) ListUsers(ctx context.Context, input auth.ListUsersInput) (*auth.ListUsersOutput, error) {
+	page := 0
+	if input.NextToken != nil {
+		page = int((*input.NextToken)[0] - '0')
+	}
+	out := &auth.ListUsersOutput{Users: a.pages[page]}
+	if page+1 < len(a.pages) {
+		next := string(rune('0' + page + 1))
+		out.NextToken = &next
+	}
+	return out, nil
+}
+
+func (a *pagedAuth) AdminResetPassword(ctx context.Context, input auth.AdminResetPasswordInput) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.resets++
+	return nil
+}
+
+type recordingAudit struct {
+	audit.AuditService
+	actions []string
+}
+
+func (a *recordingAudit) Record(ctx context.Context, input audit.RecordInput) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	a.actions = append(a.actions, input.Action)
+	return nil
+}
+
+// releasedSessions records whose sign ins were deleted.
+type releasedSessions struct {
+	session.SessionService
+	released []string
+}
+
+func (s *releasedSessions) DeleteSignIns(ctx context.Context, input session.DeleteSignInsInput) error {
+	s.released = append(s.released, input.Username)
+	return nil
+}
+
+// memoryJobs keeps jobs the way the Postgres store does, one running at a
+// time, and closes finished once a job is saved as done.
+type memoryJobs struct {
+	mu       sync.Mutex
+	jobs     map[string]auth.ResetPasswordsJob
+	finished chan struct{}
+}
+
+func newMemoryJobs() *memoryJobs {
+	return &memoryJobs{jobs: map[string]auth.ResetPasswordsJob{}, finished: make(chan struct{})}
+}
+
+func (s *memoryJobs) Start(ctx context.Context, job *auth.ResetPasswordsJob, staleBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, running := range s.jobs {
+		if running.Status != auth.ResetPasswordsJobRunning {
+			continue
+		}
+		if running.StartedAt.Before(staleBefore) {
+			running.Status = auth.ResetPasswordsJobFailed
+			s.jobs[id] = running
+			continue
+		}
+		return auth.ErrResetPasswordsInProgress
+	}
+	s.jobs[job.Id] = *job
+	return nil
+}
+
+func (s *memoryJobs) Save(ctx context.Context, job *auth.ResetPasswordsJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Id] = *job
+	if job.Status != auth.ResetPasswordsJobRunning {
+		close(s.finished)
+	}
+	return nil
+}
+
+func (s *memoryJobs) Get(ctx context.Context, id string) (*auth.ResetPasswordsJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func TestResetPasswordsRunsInTheBackground(t *testing.T) {
+	users := func(ids ...string) []auth.User {
+		out := make([]auth.User, len(ids))
+		for i, id := range ids {
+			out[i] = auth.User{Id: id, Email: id + "@example.com"}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name          string
+		cancelRequest bool
+	}{
+		{name: "request stays"},
+		{name: "request cancelled", cancelRequest: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &pagedAuth{pages: [][]auth.User{
+				users("0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d61", resetActorID),
+				users("0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d62", "0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d63"),
+			}}
+			auditService := &recordingAudit{}
+			sessions := &releasedSessions{}
+			jobs := newMemoryJobs()
+			uc := NewResetPasswordsUseCase(actorAdmins{}, authService, auditService, sessions, jobs, logger.Nop{}, 1000, time.Minute)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			actorID, _ := admin.ParseAdminID(resetActorID)
+			out, err := uc.Execute(ctx, ResetPasswordsInput{ActorID: actorID, ConfirmationCode: "123456"})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if out.Job == nil || out.Job.Status != auth.ResetPasswordsJobRunning {
+				t.Fatalf("Execute returned job %+v, want a running one", out.Job)
+			}
+			if tt.cancelRequest {
+				cancel()
+			}
+
+			select {
+			case <-jobs.finished:
+			case <-time.After(5 * time.Second):
+				t.Fatal("job never finished")
+			}
+
+			job, _ := uc.jobs.Get(context.Background(), out.Job.Id)
+			if job.Status != auth.ResetPasswordsJobCompleted || job.FinishedAt == nil {
+				t.Errorf("job status = %s finished at %v, want it completed", job.Status, job.FinishedAt)
+			}
+			// The actor is skipped; everyone else on both pages is reset.
+			if job.Processed != 3 || job.Succeeded != 3 || authService.resets != 3 {
+				t.Errorf("processed=%d succeeded=%d resets=%d, want 3 each", job.Processed, job.Succeeded, authService.resets)
+			}
+			if len(sessions.released) != 3 {
+				t.Errorf("released sessions of %v, want the 3 users reset", sessions.released)
+			}
+			wantActions := []string{auditActionResetPasswords, auditActionResetPasswordsDone}
+			if len(auditService.actions) != len(wantActions) {
+				t.Fatalf("audit actions = %v, want %v", auditService.actions, wantActions)
+			}
+			for i, action := range wantActions {
+				if auditService.actions[i] != action {
+					t.Errorf("audit actions = %v, want %v", auditService.actions, wantActions)
+				}
+			}
+		})
+	}
+}
+
+func TestResetPasswordsOneJobAtATime(t *testing.T) {
+	actorID, _ := admin.ParseAdminID(resetActorID)
+
+	tests := []struct {
+		name      string
+		startedAt time.Time
+		wantErr   error
+	}{
+		{name: "another job running", startedAt: time.Now(), wantErr: auth.ErrResetPasswordsInProgress},
+		{name: "another job abandoned", startedAt: time.Now().Add(-2 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := newMemoryJobs()
+			jobs.jobs["running"] = auth.ResetPasswordsJob{Id: "running", Status: auth.ResetPasswordsJobRunning, StartedAt: tt.startedAt}
+			uc := NewResetPasswordsUseCase(actorAdmins{}, &pagedAuth{pages: [][]auth.User{nil}}, &recordingAudit{}, &releasedSessions{}, jobs, logger.Nop{}, 1000, time.Minute)
+
+			_, err := uc.Execute(context.Background(), ResetPasswordsInput{ActorID: actorID, ConfirmationCode: "123456"})
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				<-jobs.finished
+			}
+		})
+	}
+}
+
+func TestResetPasswordsValidatesTheCode(t *testing.T) {
+	actorID, _ := admin.ParseAdminID(resetActorID)
+	input := ResetPasswordsInput{ActorID: actorID, ConfirmationCode: "12ab56"}
+
+	err := input.Validate()
+	apiErr, ok := err.(*app_error.ApiError)
+	if !ok {
+		t.Fatalf("Validate error = %v, want an ApiError", err)
+	}
+	if apiErr.Code() != "VALIDATION_ERROR" || apiErr.Description != "Field: ConfirmationCode" {
+		t.Errorf("Validate error = %s %q, want VALIDATION_ERROR on ConfirmationCode", apiErr.Code(), apiErr.Description)
+	}
+}
+
+func TestResetPasswordsKeepsTheConfiguredPace(t *testing.T) {
+	const resets, perSecond = 10, 50
+	page := make([]auth.User, resets)
+	for i := range page {
+		id := fmt.Sprintf("0b6c1f46-3b6c-4bde-9a43-6d0d3b8f0d%02d", i)
+		page[i] = auth.User{Id: id, Email: id + "@example.com"}
+	}
+	authService := &pagedAuth{pages: [][]auth.User{page}}
+	jobs := newMemoryJobs()
+	uc := NewResetPasswordsUseCase(actorAdmins{}, authService, &recordingAudit{}, &releasedSessions{}, jobs, logger.Nop{}, perSecond, time.Minute)
+
+	actorID, _ := admin.ParseAdminID(resetActorID)
+	start := time.Now()
+	if _, err := uc.Execute(context.Background(), ResetPasswordsInput{ActorID: actorID, ConfirmationCode: "123456"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	<-jobs.finished
+	elapsed := time.Since(start)
+
+	// One reset per tick, so N resets take about N/perSecond.
+	want := time.Second * resets / perSecond
+	if elapsed < want*3/4 || elapsed > want*3 {
+		t.Errorf("%d resets at %d per second took %s, want about %s", resets, perSecond, elapsed, want)
+	}
+	if authService.resets != resets {
+		t.Errorf("resets = %d, want %d", authService.resets, resets)
+	}
+}
@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"auth-api/src/internal/shared/storage/domain/storage"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrExportStorageDisabled = app_error.NewApiError(404, "Export storage disabled", "Export download links are not enabled")
+
+// StoreExportUseCase uploads a finished export and hands back a short-lived
+// link to it, for clients that fetch the file later instead of streaming it.
+type StoreExportUseCase struct {
+	storage storage.StorageService
+	logger  logger.Logger
+	prefix  string
+	expiry  time.Duration
+}
+
+type StoreExportInput struct {
+	Extension   string
+	ContentType string
+	Body        io.ReadSeeker
+	Size        int64
+}
+
+type StoreExportOutput struct {
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// NewStoreExportUseCase takes a nil storage when no bucket is configured.
+func NewStoreExportUseCase(storage storage.StorageService, logger logger.Logger, prefix string, expiry time.Duration) *StoreExportUseCase {
+	return &StoreExportUseCase{
+		storage: storage,
+		logger:  logger,
+		prefix:  prefix,
+		expiry:  expiry,
+	}
+}
+
+// Enabled reports whether a bucket is configured, so callers can fail before
+// building an export nobody can store.
+func (uc *StoreExportUseCase) Enabled() bool {
+	return uc.storage != nil
+}
+
+func (uc *StoreExportUseCase) Execute(ctx context.Context, input StoreExportInput) (*StoreExportOutput, error) {
+	if uc.storage == nil {
+		return nil, ErrExportStorageDisabled
+	}
+
+	now := time.Now()
+	filename := fmt.Sprintf("users.%s", input.Extension)
+	key := fmt.Sprintf("%s%s-%s.%s", uc.prefix, now.UTC().Format("20060102T150405Z"), uuid.NewString(), input.Extension)
+
+	if err := uc.storage.PutObject(ctx, storage.PutObjectInput{
+		Key:         key,
+		ContentType: input.ContentType,
+		Body:        input.Body,
+		Size:        input.Size,
+	}); err != nil {
+		uc.logger.Error("Error storing users export: %s", err)
+		return nil, err
+	}
+
+	url, err := uc.storage.PresignGet(ctx, storage.PresignGetInput{
+		Key:      key,
+		Expiry:   uc.expiry,
+		Filename: filename,
+	})
+	if err != nil {
+		uc.logger.Error("Error presigning users export: %s", err)
+		return nil, err
+	}
+
+	return &StoreExportOutput{
+		Url:       url,
+		ExpiresAt: now.Add(uc.expiry),
+	}, nil
+}
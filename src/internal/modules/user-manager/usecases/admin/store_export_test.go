@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"auth-api/src/internal/shared/storage/domain/storage"
+	"auth-api/src/pkg/logger"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePresigner keeps what was uploaded and presigned, and links to the key.
+type fakePresigner struct {
+	put     storage.PutObjectInput
+	presign storage.PresignGetInput
+}
+
+func (s *fakePresigner) PutObject(ctx context.Context, input storage.PutObjectInput) error {
+	s.put = input
+	return nil
+}
+
+func (s *fakePresigner) PresignGet(ctx context.Context, input storage.PresignGetInput) (string, error) {
+	if err := input.Validate(); err != nil {
+		return "", err
+	}
+	s.presign = input
+	return "https://exports.example.com/" + input.Key, nil
+}
+
+func TestStoreExportLinkExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		expiry  time.Duration
+		wantErr error
+	}{
+		{name: "configured expiry", expiry: 15 * time.Minute},
+		{name: "short expiry", expiry: time.Second},
+		{name: "no expiry", expiry: 0, wantErr: storage.ErrPresignExpiryInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presigner := &fakePresigner{}
+			uc := NewStoreExportUseCase(presigner, logger.Nop{}, "exports/", tt.expiry)
+
+			before := time.Now()
+			out, err := uc.Execute(context.Background(), StoreExportInput{
+				Extension:   "csv",
+				ContentType: "text/csv",
+				Body:        strings.NewReader("id,email\n"),
+				Size:        9,
+			})
+			after := time.Now()
+
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if presigner.presign.Expiry != tt.expiry {
+				t.Errorf("presigned for %s, want %s", presigner.presign.Expiry, tt.expiry)
+			}
+			if out.ExpiresAt.Before(before.Add(tt.expiry)) || out.ExpiresAt.After(after.Add(tt.expiry)) {
+				t.Errorf("expiresAt = %s, want %s from now", out.ExpiresAt, tt.expiry)
+			}
+			if presigner.presign.Key != presigner.put.Key || !strings.HasPrefix(presigner.put.Key, "exports/") {
+				t.Errorf("presigned %q after uploading %q, want the same key under exports/", presigner.presign.Key, presigner.put.Key)
+			}
+			if out.Url != "https://exports.example.com/"+presigner.put.Key {
+				t.Errorf("url = %q, want the presigned one", out.Url)
+			}
+			if presigner.presign.Filename != "users.csv" {
+				t.Errorf("filename = %q, want users.csv", presigner.presign.Filename)
+			}
+		})
+	}
+}
+
+func TestStoreExportDisabled(t *testing.T) {
+	uc := NewStoreExportUseCase(nil, logger.Nop{}, "exports/", time.Minute)
+	if uc.Enabled() {
+		t.Error("Enabled() = true without storage")
+	}
+	if _, err := uc.Execute(context.Background(), StoreExportInput{Extension: "csv"}); err != ErrExportStorageDisabled {
+		t.Errorf("Execute error = %v, want %v", err, ErrExportStorageDisabled)
+	}
+}
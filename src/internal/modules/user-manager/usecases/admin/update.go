@@ -2,12 +2,14 @@ package admin
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type UpdateAdminUseCase struct {
 	adminService admin.AdminService
+	auth         auth.AuthService
 	logger       logger.Logger
 }
 
@@ -15,9 +17,10 @@ type UpdateAdminInput struct {
 	admin.UpdateAdminInput
 }
 
-func NewUpdateAdminUseCase(adminService admin.AdminService, logger logger.Logger) *UpdateAdminUseCase {
+func NewUpdateAdminUseCase(adminService admin.AdminService, auth auth.AuthService, logger logger.Logger) *UpdateAdminUseCase {
 	return &UpdateAdminUseCase{
 		adminService: adminService,
+		auth:         auth,
 		logger:       logger,
 	}
 }
@@ -39,5 +42,18 @@ func (uc *UpdateAdminUseCase) Execute(ctx context.Context, input UpdateAdminInpu
 		}
 	}()
 
+	updateAttributesInput := auth.UpdateUserAttributesInput{
+		Id:    input.UpdateAdminInput.ID.String(),
+		Name:  input.UpdateAdminInput.Name,
+		Email: input.UpdateAdminInput.Email,
+	}
+	if err := updateAttributesInput.Validate(); err != nil {
+		return err
+	}
+
+	if err := uc.auth.UpdateUserAttributes(ctx, updateAttributesInput); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+)
+
+// memoryAdmins keeps one admin row and hands out rollbacks that restore it.
+type memoryAdmins struct {
+	admin.AdminService
+	row     admin.Admin
+	updates int
+}
+
+func (m *memoryAdmins) Update(input *admin.UpdateAdminInput) (*admin.UpdateAdminOutput, error) {
+	backup := m.row
+	if input.Name != nil {
+		m.row.Name = *input.Name
+	}
+	if input.Email != nil {
+		m.row.Email = *input.Email
+	}
+	m.updates++
+	return admin.NewUpdateAdminOutput(&backup, m), nil
+}
+
+// attributesAuth records the attribute update sent to Cognito and fails it
+// with err.
+type attributesAuth struct {
+	auth.AuthService
+	err     error
+	updated *auth.UpdateUserAttributesInput
+}
+
+func (a *attributesAuth) UpdateUserAttributes(ctx context.Context, input auth.UpdateUserAttributesInput) error {
+	a.updated = &input
+	return a.err
+}
+
+func TestUpdateAdmin(t *testing.T) {
+	cognitoErr := errors.New("cognito unavailable")
+
+	tests := []struct {
+		name      string
+		cognito   error
+		wantErr   error
+		wantName  string
+		wantEmail string
+	}{
+		{
+			name:      "updated in both",
+			wantName:  "New Name",
+			wantEmail: "new@example.com",
+		},
+		{
+			name:      "cognito failure rolls the row back",
+			cognito:   cognitoErr,
+			wantErr:   cognitoErr,
+			wantName:  "Old Name",
+			wantEmail: "old@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, _ := admin.ParseAdminID(resetActorID)
+			admins := &memoryAdmins{row: admin.Admin{ID: id, Name: "Old Name", Email: "old@example.com"}}
+			authService := &attributesAuth{err: tt.cognito}
+			uc := NewUpdateAdminUseCase(admins, authService, logger.Nop{})
+
+			name, email := "New Name", "new@example.com"
+			err := uc.Execute(context.Background(), UpdateAdminInput{admin.UpdateAdminInput{ID: id, Name: &name, Email: &email}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if authService.updated == nil || authService.updated.Id != resetActorID || *authService.updated.Email != "new@example.com" {
+				t.Errorf("cognito update = %+v, want the new email for %s", authService.updated, resetActorID)
+			}
+			if admins.row.Name != tt.wantName || admins.row.Email != tt.wantEmail {
+				t.Errorf("row = %+v, want %s <%s>", admins.row, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
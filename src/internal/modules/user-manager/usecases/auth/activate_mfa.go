@@ -2,20 +2,26 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type ActivateMFAUseCase struct {
-	auth auth.AuthService
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
 }
 
 type ActivateMFAInput struct {
 	auth.ActivateMFAInput
 }
 
-func NewActivateMFAUseCase(auth auth.AuthService) *ActivateMFAUseCase {
+func NewActivateMFAUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *ActivateMFAUseCase {
 	return &ActivateMFAUseCase{
-		auth: auth,
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
 	}
 }
 
@@ -24,5 +30,15 @@ func (uc *ActivateMFAUseCase) Execute(ctx context.Context, input ActivateMFAInpu
 		return err
 	}
 
-	return uc.auth.ActivateMFA(ctx, input.ActivateMFAInput)
+	if err := uc.auth.ActivateMFA(ctx, input.ActivateMFAInput); err != nil {
+		return err
+	}
+
+	me, err := uc.auth.GetMe(ctx, auth.GetMeInput{AccessToken: input.AccessToken})
+	if err != nil {
+		uc.logger.Error("Error resolving user for MFA audit entry: %s", err)
+		return nil
+	}
+	recordMFAChange(ctx, uc.audit, uc.logger, me.Username, me.Username, auth.AuditActionMFAEnabled)
+	return nil
 }
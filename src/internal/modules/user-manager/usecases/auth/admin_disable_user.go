@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+)
+
+const auditActionDisableUser = "DISABLE_USER"
+
+type AdminDisableUserUseCase struct {
+	auth   auth.AuthService
+	audit  audit.AuditService
+	limit  *sessionLimitPolicy
+	logger logger.Logger
+}
+
+type AdminDisableUserInput struct {
+	ActorID string
+	auth.DisableUserInput
+}
+
+func NewAdminDisableUserUseCase(auth auth.AuthService, audit audit.AuditService, limit *sessionLimitPolicy, logger logger.Logger) *AdminDisableUserUseCase {
+	return &AdminDisableUserUseCase{
+		auth:   auth,
+		audit:  audit,
+		limit:  limit,
+		logger: logger,
+	}
+}
+
+// Execute keeps the user from signing in again, cuts off the tokens they
+// already hold and drops their sessions.
+func (uc *AdminDisableUserUseCase) Execute(ctx context.Context, input AdminDisableUserInput) error {
+	if err := input.DisableUserInput.Validate(); err != nil {
+		return err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionDisableUser,
+		Details: fmt.Sprintf("username=%s", input.Username),
+	}); err != nil {
+		uc.logger.Error("Error recording disable user audit entry: %s", err)
+		return err
+	}
+
+	if err := uc.auth.DisableUser(ctx, input.DisableUserInput); err != nil {
+		return err
+	}
+	uc.limit.release(ctx, input.Username)
+	return nil
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+)
+
+// disablingAuth records the users disabled.
+type disablingAuth struct {
+	auth.AuthService
+	disabled []string
+}
+
+func (a *disablingAuth) DisableUser(ctx context.Context, input auth.DisableUserInput) error {
+	a.disabled = append(a.disabled, input.Username)
+	return nil
+}
+
+// failingAudit refuses every entry with err.
+type failingAudit struct {
+	audit.AuditService
+	err     error
+	actions []string
+}
+
+func (a *failingAudit) Record(ctx context.Context, input audit.RecordInput) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.actions = append(a.actions, input.Action)
+	return nil
+}
+
+func TestAdminDisableUser(t *testing.T) {
+	auditErr := errors.New("audit unavailable")
+
+	tests := []struct {
+		name         string
+		username     string
+		auditErr     error
+		wantErr      bool
+		wantDisabled []string
+	}{
+		{name: "disabled and audited", username: "member@example.com", wantDisabled: []string{"member@example.com"}},
+		{name: "invalid username", username: "not-an-email", wantErr: true},
+		{name: "not disabled without an audit entry", username: "member@example.com", auditErr: auditErr, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &disablingAuth{}
+			auditService := &failingAudit{err: tt.auditErr}
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger.Nop{}, false)
+			uc := NewAdminDisableUserUseCase(authService, auditService, limit, logger.Nop{})
+
+			err := uc.Execute(context.Background(), AdminDisableUserInput{
+				ActorID:          "admin-1",
+				DisableUserInput: auth.DisableUserInput{Username: tt.username},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(authService.disabled) != len(tt.wantDisabled) || (len(tt.wantDisabled) > 0 && authService.disabled[0] != tt.wantDisabled[0]) {
+				t.Errorf("disabled = %v, want %v", authService.disabled, tt.wantDisabled)
+			}
+			if len(tt.wantDisabled) > 0 && (len(auditService.actions) != 1 || auditService.actions[0] != auditActionDisableUser) {
+				t.Errorf("audit actions = %v, want [%s]", auditService.actions, auditActionDisableUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+)
+
+const auditActionFinalizeUser = "FINALIZE_USER"
+
+type AdminFinalizeUserUseCase struct {
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
+}
+
+type AdminFinalizeUserInput struct {
+	ActorID string
+	auth.AdminSetPermanentPasswordInput
+}
+
+func NewAdminFinalizeUserUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *AdminFinalizeUserUseCase {
+	return &AdminFinalizeUserUseCase{
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// Execute sets a permanent password for a user stuck in
+// FORCE_CHANGE_PASSWORD, which confirms them in Cognito.
+func (uc *AdminFinalizeUserUseCase) Execute(ctx context.Context, input AdminFinalizeUserInput) error {
+	if err := input.AdminSetPermanentPasswordInput.Validate(); err != nil {
+		return err
+	}
+
+	// Checked before anything is audited so a rejected password leaves no
+	// trace of a finalization that never happened.
+	if policy, err := uc.auth.GetPasswordPolicy(ctx); err == nil {
+		if err := policy.Validate(input.NewPassword, "NewPassword"); err != nil {
+			return err
+		}
+	}
+
+	user, err := uc.auth.GetUser(ctx, auth.GetUserInput{Username: input.Username})
+	if err != nil {
+		return err
+	}
+	if user.Status != auth.ForceChangePasswd {
+		return auth.ErrNotForceChangePassword
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionFinalizeUser,
+		Details: fmt.Sprintf("username=%s", input.Username),
+	}); err != nil {
+		uc.logger.Error("Error recording finalize user audit entry: %s", err)
+		return err
+	}
+
+	return uc.auth.AdminSetPermanentPassword(ctx, input.AdminSetPermanentPasswordInput)
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+)
+
+// pendingUserAuth has one user in status and records the permanent
+// passwords set for them.
+type pendingUserAuth struct {
+	auth.AuthService
+	status    auth.UserStatus
+	permanent []auth.AdminSetPermanentPasswordInput
+}
+
+func (a *pendingUserAuth) GetPasswordPolicy(context.Context) (*auth.PasswordPolicy, error) {
+	return &auth.PasswordPolicy{MinimumLength: 10, RequireNumbers: true}, nil
+}
+
+func (a *pendingUserAuth) GetUser(ctx context.Context, input auth.GetUserInput) (*auth.User, error) {
+	if a.status == "" {
+		return nil, auth.ErrUserNotFound
+	}
+	return &auth.User{Email: input.Username, Status: a.status}, nil
+}
+
+func (a *pendingUserAuth) AdminSetPermanentPassword(ctx context.Context, input auth.AdminSetPermanentPasswordInput) error {
+	a.permanent = append(a.permanent, input)
+	a.status = auth.Confirmed
+	return nil
+}
+
+func TestAdminFinalizeUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    auth.UserStatus
+		password  string
+		auditErr  error
+		wantErr   error
+		wantField string
+		wantSet   bool
+	}{
+		{name: "forced state cleared and audited", status: auth.ForceChangePasswd, password: "Str0ng!Passw0rd", wantSet: true},
+		{name: "already confirmed", status: auth.Confirmed, password: "Str0ng!Passw0rd", wantErr: auth.ErrNotForceChangePassword},
+		{name: "unknown user", password: "Str0ng!Passw0rd", wantErr: auth.ErrUserNotFound},
+		{name: "password below the pool policy", status: auth.ForceChangePasswd, password: "Str0ng!Pa", wantField: "NewPassword"},
+		{name: "audit failure stops the change", status: auth.ForceChangePasswd, password: "Str0ng!Passw0rd", auditErr: errors.New("audit down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &pendingUserAuth{status: tt.status}
+			auditService := &auditLog{err: tt.auditErr}
+			uc := NewAdminFinalizeUserUseCase(authService, auditService, logger.Nop{})
+
+			err := uc.Execute(context.Background(), AdminFinalizeUserInput{
+				ActorID: "admin-1",
+				AdminSetPermanentPasswordInput: auth.AdminSetPermanentPasswordInput{
+					Username:    "stuck@example.com",
+					NewPassword: tt.password,
+				},
+			})
+			switch {
+			case tt.wantField != "":
+				if !isFieldError(err, tt.wantField) {
+					t.Fatalf("Execute error = %v, want a %s validation error", err, tt.wantField)
+				}
+			case tt.auditErr != nil:
+				if err != tt.auditErr {
+					t.Fatalf("Execute error = %v, want %v", err, tt.auditErr)
+				}
+			case err != tt.wantErr:
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !tt.wantSet {
+				if len(authService.permanent) != 0 {
+					t.Errorf("set a permanent password %+v, want none", authService.permanent)
+				}
+				if len(auditService.entries) != 0 {
+					t.Errorf("audited %+v, want nothing", auditService.entries)
+				}
+				return
+			}
+			if len(authService.permanent) != 1 || authService.permanent[0].Username != "stuck@example.com" || authService.permanent[0].NewPassword != tt.password {
+				t.Errorf("permanent passwords = %+v, want one for stuck@example.com", authService.permanent)
+			}
+			if authService.status != auth.Confirmed {
+				t.Errorf("status = %s, want %s", authService.status, auth.Confirmed)
+			}
+			if len(auditService.entries) != 1 {
+				t.Fatalf("audited %d entries, want 1", len(auditService.entries))
+			}
+			entry := auditService.entries[0]
+			if entry.Actor != "admin-1" || entry.Action != auditActionFinalizeUser || entry.Details != "username=stuck@example.com" {
+				t.Errorf("audit entry = %+v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+)
+
+const auditActionUnlockUser = "UNLOCK_USER"
+
+type AdminGetLockoutUseCase struct {
+	lockout *lockoutPolicy
+}
+
+func NewAdminGetLockoutUseCase(lockout *lockoutPolicy) *AdminGetLockoutUseCase {
+	return &AdminGetLockoutUseCase{
+		lockout: lockout,
+	}
+}
+
+func (uc *AdminGetLockoutUseCase) Execute(ctx context.Context, input auth.LockoutInput) (*auth.LockoutStatusOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	return uc.lockout.status(ctx, input.Username)
+}
+
+type AdminUnlockUserUseCase struct {
+	lockout *lockoutPolicy
+	audit   audit.AuditService
+	logger  logger.Logger
+}
+
+type AdminUnlockUserInput struct {
+	ActorID string
+	auth.LockoutInput
+}
+
+func NewAdminUnlockUserUseCase(lockout *lockoutPolicy, audit audit.AuditService, logger logger.Logger) *AdminUnlockUserUseCase {
+	return &AdminUnlockUserUseCase{
+		lockout: lockout,
+		audit:   audit,
+		logger:  logger,
+	}
+}
+
+// Execute clears the user's failed sign ins, lifting any lockout right away.
+func (uc *AdminUnlockUserUseCase) Execute(ctx context.Context, input AdminUnlockUserInput) error {
+	if err := input.LockoutInput.Validate(); err != nil {
+		return err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionUnlockUser,
+		Details: fmt.Sprintf("username=%s", input.Username),
+	}); err != nil {
+		uc.logger.Error("Error recording unlock user audit entry: %s", err)
+		return err
+	}
+
+	return uc.lockout.unlock(ctx, input.Username)
+}
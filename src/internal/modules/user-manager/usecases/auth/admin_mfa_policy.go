@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/jwt_verify"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+// adminMFAPolicy withholds tokens from admins who haven't set up MFA. A user
+// with MFA never gets tokens straight from a password login, Cognito answers
+// with a challenge first, so tokens at this point mean no MFA.
+type adminMFAPolicy struct {
+	enforce       bool
+	auth          auth.AuthService
+	sessions      session.SessionService
+	logger        logger.Logger
+	enrollmentTTL time.Duration
+}
+
+func newAdminMFAPolicy(enforce bool, auth auth.AuthService, sessions session.SessionService, logger logger.Logger, enrollmentTTL time.Duration) *adminMFAPolicy {
+	return &adminMFAPolicy{
+		enforce:       enforce,
+		auth:          auth,
+		sessions:      sessions,
+		logger:        logger,
+		enrollmentTTL: enrollmentTTL,
+	}
+}
+
+func (p *adminMFAPolicy) requiresEnrollment(ctx context.Context, output *auth.LoginOutput) (bool, error) {
+	if !p.enforce || output == nil || output.NextStep != nil || output.AccessToken == nil {
+		return false, nil
+	}
+	claims, err := p.auth.ValidateToken(ctx, *output.AccessToken)
+	if err != nil {
+		return false, err
+	}
+	return claims.HasGroup(auth.GroupAdmin), nil
+}
+
+// apply swaps the tokens of an admin without MFA for an enrollment challenge.
+// The tokens are parked in an enrollment session and only released once the
+// authenticator is verified.
+func (p *adminMFAPolicy) apply(ctx context.Context, username string, output *auth.LoginOutput) (*auth.LoginOutput, error) {
+	required, err := p.requiresEnrollment(ctx, output)
+	if err != nil || !required {
+		return output, err
+	}
+
+	accessToken := deref.String(output.AccessToken)
+	setup, err := p.auth.AddMFA(ctx, auth.AddMFAInput{AccessToken: accessToken})
+	if err != nil {
+		return nil, err
+	}
+
+	var accessTokenExpiresAt time.Time
+	if claims, err := jwt_verify.ParseUnverifiedClaims(accessToken); err == nil {
+		accessTokenExpiresAt = time.Unix(claims.Exp, 0)
+	}
+
+	token, _, err := p.sessions.Create(ctx, session.CreateInput{
+		Purpose:              session.PurposeMFAEnrollment,
+		TTL:                  p.enrollmentTTL,
+		Username:             username,
+		AccessToken:          accessToken,
+		IdToken:              deref.String(output.IdToken),
+		RefreshToken:         deref.String(output.RefreshToken),
+		AccessTokenExpiresAt: accessTokenExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Info("MFA enrollment required for admin %s", username)
+	nextStep := auth.NextStepMFAEnrollmentRequired
+	return &auth.LoginOutput{
+		Session:    &token,
+		NextStep:   &nextStep,
+		SecretCode: &setup.SecretCode,
+	}, nil
+}
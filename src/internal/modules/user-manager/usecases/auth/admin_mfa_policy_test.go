@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+	"time"
+)
+
+// parkingSessions keeps the enrollment sessions the policy creates.
+type parkingSessions struct {
+	session.SessionService
+	created []session.CreateInput
+}
+
+func (s *parkingSessions) Create(ctx context.Context, input session.CreateInput) (string, *session.Session, error) {
+	s.created = append(s.created, input)
+	return "enrollment-session", &session.Session{}, nil
+}
+
+func TestAdminMFAPolicy(t *testing.T) {
+	accessToken, idToken, refreshToken := "access", "id", "refresh"
+	tokens := func() *auth.LoginOutput {
+		return &auth.LoginOutput{AccessToken: &accessToken, IdToken: &idToken, RefreshToken: &refreshToken}
+	}
+	challenge := func() *auth.LoginOutput {
+		nextStep, session := "SOFTWARE_TOKEN_MFA", "challenge-session"
+		return &auth.LoginOutput{NextStep: &nextStep, Session: &session}
+	}
+
+	tests := []struct {
+		name       string
+		enforce    bool
+		groups     []string
+		output     *auth.LoginOutput
+		wantEnroll bool
+	}{
+		{name: "admin without MFA", enforce: true, groups: []string{string(auth.GroupAdmin)}, output: tokens(), wantEnroll: true},
+		{name: "user without MFA", enforce: true, groups: []string{string(auth.GroupUser)}, output: tokens()},
+		{name: "admin with MFA is challenged as usual", enforce: true, groups: []string{string(auth.GroupAdmin)}, output: challenge()},
+		{name: "admin without MFA, policy off", groups: []string{string(auth.GroupAdmin)}, output: tokens()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &parkingSessions{}
+			policy := newAdminMFAPolicy(tt.enforce, &confirmAuth{groups: tt.groups}, sessions, logger.Nop{}, 10*time.Minute)
+
+			out, err := policy.apply(context.Background(), "someone@example.com", tt.output)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+
+			if !tt.wantEnroll {
+				if out != tt.output {
+					t.Errorf("output = %+v, want the login output untouched", out)
+				}
+				if len(sessions.created) != 0 {
+					t.Errorf("created %d enrollment sessions, want none", len(sessions.created))
+				}
+				return
+			}
+
+			if out.NextStep == nil || *out.NextStep != auth.NextStepMFAEnrollmentRequired {
+				t.Fatalf("next step = %v, want %s", out.NextStep, auth.NextStepMFAEnrollmentRequired)
+			}
+			if out.AccessToken != nil || out.IdToken != nil || out.RefreshToken != nil {
+				t.Error("admin without MFA was handed tokens")
+			}
+			if out.Session == nil || *out.Session != "enrollment-session" || out.SecretCode == nil || *out.SecretCode != "SECRET" {
+				t.Errorf("output = %+v, want the enrollment session and secret", out)
+			}
+			if len(sessions.created) != 1 {
+				t.Fatalf("created %d enrollment sessions, want 1", len(sessions.created))
+			}
+			parked := sessions.created[0]
+			if parked.Purpose != session.PurposeMFAEnrollment || parked.TTL != 10*time.Minute || parked.Username != "someone@example.com" {
+				t.Errorf("session = %+v, want a 10m MFA enrollment session for the admin", parked)
+			}
+			if parked.AccessToken != accessToken || parked.IdToken != idToken || parked.RefreshToken != refreshToken {
+				t.Errorf("session holds %q %q %q, want the withheld tokens", parked.AccessToken, parked.IdToken, parked.RefreshToken)
+			}
+		})
+	}
+}
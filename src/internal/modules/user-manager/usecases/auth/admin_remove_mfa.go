@@ -2,20 +2,27 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type AdminRemoveMFAUseCase struct {
-	auth auth.AuthService
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
 }
 
 type AdminRemoveMFAInput struct {
+	ActorID string
 	auth.AdminRemoveMFAInput
 }
 
-func NewAdminRemoveMFAUseCase(auth auth.AuthService) *AdminRemoveMFAUseCase {
+func NewAdminRemoveMFAUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *AdminRemoveMFAUseCase {
 	return &AdminRemoveMFAUseCase{
-		auth: auth,
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
 	}
 }
 
@@ -24,5 +31,9 @@ func (uc *AdminRemoveMFAUseCase) Execute(ctx context.Context, input AdminRemoveM
 		return err
 	}
 
-	return uc.auth.AdminRemoveMFA(ctx, input.AdminRemoveMFAInput)
+	if err := uc.auth.AdminRemoveMFA(ctx, input.AdminRemoveMFAInput); err != nil {
+		return err
+	}
+	recordMFAChange(ctx, uc.audit, uc.logger, input.ActorID, input.Username, auth.AuditActionMFADisabled)
+	return nil
 }
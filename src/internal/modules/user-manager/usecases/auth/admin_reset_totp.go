@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/validator"
+	"context"
+	"fmt"
+)
+
+const auditActionResetTOTP = "RESET_TOTP"
+
+type AdminResetTOTPUseCase struct {
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
+}
+
+type AdminResetTOTPInput struct {
+	ActorID string
+	auth.AdminRemoveMFAInput
+	Reason string
+}
+
+func (input *AdminResetTOTPInput) Validate() error {
+	if err := input.AdminRemoveMFAInput.Validate(); err != nil {
+		return err
+	}
+	if err := validator.ValidateStringLength(input.Reason, 3, 500); err != nil {
+		return auth.NewValidationError("Reason")
+	}
+	return nil
+}
+
+func NewAdminResetTOTPUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *AdminResetTOTPUseCase {
+	return &AdminResetTOTPUseCase{
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// Execute disables the user's software token MFA so they enroll a new
+// authenticator on their next login.
+func (uc *AdminResetTOTPUseCase) Execute(ctx context.Context, input AdminResetTOTPInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionResetTOTP,
+		Details: fmt.Sprintf("username=%s reason=%s", input.Username, input.Reason),
+	}); err != nil {
+		uc.logger.Error("Error recording reset TOTP audit entry: %s", err)
+		return err
+	}
+
+	if err := uc.auth.AdminRemoveMFA(ctx, input.AdminRemoveMFAInput); err != nil {
+		return err
+	}
+	recordMFAChange(ctx, uc.audit, uc.logger, input.ActorID, input.Username, auth.AuditActionMFADisabled)
+	return nil
+}
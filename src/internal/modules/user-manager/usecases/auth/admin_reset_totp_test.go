@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// mfaRemovingAuth records the users whose MFA was removed.
+type mfaRemovingAuth struct {
+	auth.AuthService
+	removed []string
+	err     error
+}
+
+func (a *mfaRemovingAuth) AdminRemoveMFA(ctx context.Context, input auth.AdminRemoveMFAInput) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.removed = append(a.removed, input.Username)
+	return nil
+}
+
+// auditLog keeps every entry recorded, or refuses them all with err.
+type auditLog struct {
+	audit.AuditService
+	entries []audit.RecordInput
+	err     error
+}
+
+func (a *auditLog) Record(ctx context.Context, input audit.RecordInput) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.entries = append(a.entries, input)
+	return nil
+}
+
+// isFieldError tells a 400 naming field.
+func isFieldError(err error, field string) bool {
+	var apiErr *app_error.ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 400 && apiErr.Description == "Field: "+field
+}
+
+func TestAdminResetTOTP(t *testing.T) {
+	cognitoErr := errors.New("cognito down")
+	auditErr := errors.New("audit unavailable")
+
+	tests := []struct {
+		name        string
+		reason      string
+		removeErr   error
+		auditErr    error
+		wantErr     error
+		wantField   string
+		wantRemoved []string
+		wantEntries []audit.RecordInput
+	}{
+		{
+			name:        "MFA disabled with the reason logged",
+			reason:      "lost phone",
+			wantRemoved: []string{"member@example.com"},
+			wantEntries: []audit.RecordInput{
+				{Actor: "admin-1", Action: auditActionResetTOTP, Details: "username=member@example.com reason=lost phone"},
+				{Actor: "admin-1", Action: auth.AuditActionMFADisabled, Details: "member@example.com"},
+			},
+		},
+		{
+			name:      "reason required",
+			reason:    "",
+			wantField: "Reason",
+		},
+		{
+			name:     "not reset without an audit entry",
+			reason:   "lost phone",
+			auditErr: auditErr,
+			wantErr:  auditErr,
+		},
+		{
+			name:      "Cognito failure leaves only the request logged",
+			reason:    "lost phone",
+			removeErr: cognitoErr,
+			wantErr:   cognitoErr,
+			wantEntries: []audit.RecordInput{
+				{Actor: "admin-1", Action: auditActionResetTOTP, Details: "username=member@example.com reason=lost phone"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &mfaRemovingAuth{err: tt.removeErr}
+			auditService := &auditLog{err: tt.auditErr}
+			uc := NewAdminResetTOTPUseCase(authService, auditService, logger.Nop{})
+
+			err := uc.Execute(context.Background(), AdminResetTOTPInput{
+				ActorID:             "admin-1",
+				AdminRemoveMFAInput: auth.AdminRemoveMFAInput{Username: "member@example.com"},
+				Reason:              tt.reason,
+			})
+
+			switch {
+			case tt.wantField != "":
+				if !isFieldError(err, tt.wantField) {
+					t.Fatalf("Execute = %v, want a 400 on %s", err, tt.wantField)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("Execute = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(authService.removed, tt.wantRemoved) {
+				t.Errorf("MFA removed for %v, want %v", authService.removed, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(auditService.entries, tt.wantEntries) {
+				t.Errorf("audit = %+v, want %+v", auditService.entries, tt.wantEntries)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/cursor"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const adminUsersSub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+type listedAuth struct {
+	auth.AuthService
+}
+
+func (listedAuth) GetUser(context.Context, auth.GetUserInput) (*auth.User, error) {
+	return &auth.User{Id: adminUsersSub, Email: "member@example.com"}, nil
+}
+
+func (listedAuth) ListUsers(context.Context, auth.ListUsersInput) (*auth.ListUsersOutput, error) {
+	return &auth.ListUsersOutput{Users: []auth.User{{Id: adminUsersSub, Email: "member@example.com"}}}, nil
+}
+
+func TestAdminUserOutputsCarryTheSub(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		execute func() (interface{}, error)
+	}{
+		{
+			name: "get",
+			execute: func() (interface{}, error) {
+				return NewAdminGetUserUseCase(listedAuth{}).Execute(ctx, auth.GetUserInput{Username: "member@example.com"})
+			},
+		},
+		{
+			name: "list",
+			execute: func() (interface{}, error) {
+				return NewAdminListUsersUseCase(listedAuth{}, cursor.NewSigner([]byte("k"))).Execute(ctx, AdminListUsersInput{})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), `"id":"`+adminUsersSub+`"`) {
+				t.Errorf("body = %s, want the sub as id", body)
+			}
+		})
+	}
+}
+
+// countedAuth is listedAuth counting the lookups that reach the service.
+type countedAuth struct {
+	listedAuth
+	gets int
+}
+
+func (a *countedAuth) GetUser(ctx context.Context, input auth.GetUserInput) (*auth.User, error) {
+	a.gets++
+	return a.listedAuth.GetUser(ctx, input)
+}
+
+func TestAdminGetUserValidatesTheUsername(t *testing.T) {
+	authService := &countedAuth{}
+	_, err := NewAdminGetUserUseCase(authService).Execute(context.Background(), auth.GetUserInput{Username: "not-an-email"})
+	if !isFieldError(err, "Username") {
+		t.Errorf("err = %v, want a Username validation error", err)
+	}
+	if authService.gets != 0 {
+		t.Errorf("looked up %d users for an invalid username", authService.gets)
+	}
+}
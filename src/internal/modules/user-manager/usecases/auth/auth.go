@@ -1,23 +1,43 @@
 package auth
 
 import (
+	"auth-api/src/internal/events"
 	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/notification/domain/email"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/cursor"
 	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/otpauth"
+	"time"
 )
 
 type UseCases struct {
 	Login                  *LoginUseCase
 	AddGroup               *AddGroupUseCase
 	RemoveGroup            *RemoveGroupUseCase
+	ChangeUserGroup        *ChangeUserGroupUseCase
 	RefreshToken           *RefreshTokenUseCase
+	RefreshTokenBatch      *RefreshTokenBatchUseCase
 	AddMFA                 *AddMFAUseCase
+	SetupMFA               *SetupMFAUseCase
 	VerifyMFA              *VerifyMFAUseCase
 	AdminRemoveMFA         *AdminRemoveMFAUseCase
+	AdminResetTOTP         *AdminResetTOTPUseCase
+	AdminFinalizeUser      *AdminFinalizeUserUseCase
+	AdminDisableUser       *AdminDisableUserUseCase
+	AdminGetLockout        *AdminGetLockoutUseCase
+	AdminUnlockUser        *AdminUnlockUserUseCase
+	AdminIssueInvite       *AdminIssueInviteUseCase
+	AdminGetUser           *AdminGetUserUseCase
+	AdminListUsers         *AdminListUsersUseCase
 	RemoveMFA              *RemoveMFAUseCase
 	ConfirmSignUp          *ConfirmSignUpUseCase
 	GetMe                  *GetMeUseCase
+	ConfirmDevice          *ConfirmDeviceUseCase
+	ListDevices            *ListDevicesUseCase
 	ActivateMFA            *ActivateMFAUseCase
 	Logout                 *LogoutUseCase
 	SetPassword            *SetPasswordUseCase
@@ -25,26 +45,105 @@ type UseCases struct {
 	ChangePassword         *ChangePasswordUseCase
 	ResetPassword          *ResetPasswordUseCase
 	SendForgotPasswordCode *SendForgotPasswordCodeUseCase
+	CreateSession          *CreateSessionUseCase
+	GetSession             *GetSessionUseCase
+	DeleteSession          *DeleteSessionUseCase
+	GetStats               *GetStatsUseCase
+	CompleteMFAEnrollment  *CompleteMFAEnrollmentUseCase
+	ValidatePassword       *ValidatePasswordUseCase
+	SelfCheck              *SelfCheckUseCase
+	RequestEmailChange     *RequestEmailChangeUseCase
+	VerifyEmailChange      *VerifyEmailChangeUseCase
+	GetEmailChange         *GetEmailChangeUseCase
+	Authorize              *AuthorizeUseCase
 }
 
-func NewUseCases(authService auth.AuthService, adminService admin.AdminService, userService user.UserService, logger logger.Logger) *UseCases {
+// Dependencies are the services the auth use cases run on.
+type Dependencies struct {
+	Auth         auth.AuthService
+	Dispatcher   events.EventDispatcher
+	Admin        admin.AdminService
+	User         user.UserService
+	Sessions     session.SessionService
+	Audit        audit.AuditService
+	Email        email.EmailService
+	Logger       logger.Logger
+	Challenge    auth.PreAuthChallenge
+	LockoutStore auth.LockoutStore
+	Invites      auth.InviteTokens
+	EmailChanges auth.EmailChangeStore
+	Cursors      *cursor.Signer
+}
+
+// Options tune the auth use cases. Zero values leave the optional policies,
+// like the session limit or the lockout, off.
+type Options struct {
+	MFAIssuer            otpauth.Issuer
+	ConfirmAutoLogin     bool
+	AssignGroupOnConfirm bool
+	StatsWindow          time.Duration
+	EnforceAdminMFA      bool
+	MFAEnrollmentTTL     time.Duration
+	MaxSessions          int
+	SessionLimitMode     string
+	MaxSessionLength     time.Duration
+	LockoutThreshold     int
+	LockoutWindow        time.Duration
+	InviteTTL            time.Duration
+	InviteURL            string
+	// CaseSensitiveUsernames keeps emails as entered when keying sign ins.
+	CaseSensitiveUsernames bool
+}
+
+func NewUseCases(deps Dependencies, opts Options) *UseCases {
+	authService, sessionService, auditService, logger := deps.Auth, deps.Sessions, deps.Audit, deps.Logger
+	mfaPolicy := newAdminMFAPolicy(opts.EnforceAdminMFA, authService, sessionService, logger, opts.MFAEnrollmentTTL)
+	sessionLength := newSessionLengthPolicy(opts.MaxSessionLength, authService, logger)
+	lockout := newLockoutPolicy(opts.LockoutThreshold, opts.LockoutWindow, deps.LockoutStore, logger)
+	sessionLimit := newSessionLimitPolicy(opts.MaxSessions, opts.SessionLimitMode, authService, sessionService, logger, opts.CaseSensitiveUsernames)
+	refreshToken := NewRefreshTokenUseCase(authService, sessionLength, logger)
+	login := NewLoginUseCase(authService, deps.Dispatcher, logger, mfaPolicy, deps.Challenge, lockout, sessionLimit)
 	return &UseCases{
-		Login:                  NewLoginUseCase(authService),
-		AddGroup:               NewAddGroupUseCase(adminService, userService, authService, logger),
+		Login:                  login,
+		AddGroup:               NewAddGroupUseCase(deps.Admin, deps.User, authService, logger),
 		RemoveGroup:            NewRemoveGroupUseCase(authService, logger),
-		RefreshToken:           NewRefreshTokenUseCase(authService),
+		ChangeUserGroup:        NewChangeUserGroupUseCase(deps.Admin, deps.User, authService, auditService, logger),
+		RefreshToken:           refreshToken,
+		RefreshTokenBatch:      NewRefreshTokenBatchUseCase(refreshToken, logger),
 		AddMFA:                 NewAddMFAUseCase(authService),
-		VerifyMFA:              NewVerifyMFAUseCase(authService),
-		AdminRemoveMFA:         NewAdminRemoveMFAUseCase(authService),
-		RemoveMFA:              NewRemoveMFAUseCase(authService),
-		ConfirmSignUp:          NewConfirmSignUpUseCase(authService),
+		SetupMFA:               NewSetupMFAUseCase(authService, opts.MFAIssuer),
+		VerifyMFA:              NewVerifyMFAUseCase(authService, sessionLimit, logger),
+		AdminRemoveMFA:         NewAdminRemoveMFAUseCase(authService, auditService, logger),
+		AdminResetTOTP:         NewAdminResetTOTPUseCase(authService, auditService, logger),
+		AdminFinalizeUser:      NewAdminFinalizeUserUseCase(authService, auditService, logger),
+		AdminDisableUser:       NewAdminDisableUserUseCase(authService, auditService, sessionLimit, logger),
+		AdminGetLockout:        NewAdminGetLockoutUseCase(lockout),
+		AdminUnlockUser:        NewAdminUnlockUserUseCase(lockout, auditService, logger),
+		AdminIssueInvite:       NewAdminIssueInviteUseCase(deps.Invites, deps.Email, auditService, logger, opts.InviteTTL, opts.InviteURL),
+		AdminGetUser:           NewAdminGetUserUseCase(authService),
+		AdminListUsers:         NewAdminListUsersUseCase(authService, deps.Cursors),
+		RemoveMFA:              NewRemoveMFAUseCase(authService, auditService, logger),
+		ConfirmSignUp:          NewConfirmSignUpUseCase(authService, logger, opts.ConfirmAutoLogin, opts.AssignGroupOnConfirm, deps.Invites, login),
 		GetMe:                  NewGetMeUseCase(authService),
-		ActivateMFA:            NewActivateMFAUseCase(authService),
-		Logout:                 NewLogoutUseCase(authService),
-		SetPassword:            NewSetPasswordUseCase(authService),
+		ConfirmDevice:          NewConfirmDeviceUseCase(authService),
+		ListDevices:            NewListDevicesUseCase(authService),
+		ActivateMFA:            NewActivateMFAUseCase(authService, auditService, logger),
+		Logout:                 NewLogoutUseCase(authService, sessionLimit),
+		SetPassword:            NewSetPasswordUseCase(authService, logger, mfaPolicy, sessionLimit),
 		SendConfirmationCode:   NewSendConfirmationCodeUseCase(logger, authService),
-		ChangePassword:         NewChangePasswordUseCase(authService),
+		ChangePassword:         NewChangePasswordUseCase(authService, deps.EmailChanges),
 		ResetPassword:          NewResetPasswordUseCase(authService),
 		SendForgotPasswordCode: NewSendForgotPasswordCodeUseCase(logger, authService),
+		CreateSession:          NewCreateSessionUseCase(authService, deps.Dispatcher, logger, mfaPolicy, sessionLimit, deps.Challenge, lockout),
+		GetSession:             NewGetSessionUseCase(authService, sessionService, sessionLength),
+		DeleteSession:          NewDeleteSessionUseCase(authService, sessionService, logger),
+		GetStats:               NewGetStatsUseCase(auditService, logger, opts.StatsWindow),
+		CompleteMFAEnrollment:  NewCompleteMFAEnrollmentUseCase(authService, sessionService, sessionLimit, auditService, logger),
+		ValidatePassword:       NewValidatePasswordUseCase(authService),
+		SelfCheck:              NewSelfCheckUseCase(authService, logger),
+		RequestEmailChange:     NewRequestEmailChangeUseCase(authService, deps.EmailChanges, logger),
+		VerifyEmailChange:      NewVerifyEmailChangeUseCase(authService, deps.EmailChanges, auditService, logger),
+		GetEmailChange:         NewGetEmailChangeUseCase(deps.EmailChanges),
+		Authorize:              NewAuthorizeUseCase(authService, logger),
 	}
 }
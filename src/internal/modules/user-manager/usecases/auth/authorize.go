@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+)
+
+// Reasons an AuthorizeOutput gives for its decision.
+const (
+	AuthorizeReasonAllowed      = "ALLOWED"
+	AuthorizeReasonTokenExpired = "TOKEN_EXPIRED"
+	AuthorizeReasonTokenInvalid = "TOKEN_INVALID"
+	AuthorizeReasonMissingGroup = "MISSING_GROUP"
+	AuthorizeReasonMissingScope = "MISSING_SCOPE"
+)
+
+type AuthorizeUseCase struct {
+	auth   auth.AuthService
+	logger logger.Logger
+}
+
+// AuthorizeInput asks whether Token may do something that needs one of
+// Groups, like AuthMiddleware does, and every one of Scopes. Either may be
+// left empty to skip that check.
+type AuthorizeInput struct {
+	Token  string
+	Groups []string
+	Scopes []string
+}
+
+func (input *AuthorizeInput) Validate() error {
+	if len(input.Token) == 0 {
+		return auth.NewValidationError("Token")
+	}
+	return nil
+}
+
+// AuthorizeOutput is a decision, not an error: a denied token still answers
+// 200. Missing lists the groups or scopes the token lacked.
+type AuthorizeOutput struct {
+	Allowed bool         `json:"allowed"`
+	Reason  string       `json:"reason"`
+	Missing []string     `json:"missing,omitempty"`
+	Claims  *auth.Claims `json:"claims,omitempty"`
+}
+
+func NewAuthorizeUseCase(auth auth.AuthService, logger logger.Logger) *AuthorizeUseCase {
+	return &AuthorizeUseCase{
+		auth:   auth,
+		logger: logger,
+	}
+}
+
+func (uc *AuthorizeUseCase) Execute(ctx context.Context, input AuthorizeInput) (*AuthorizeOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	claims, err := uc.auth.ValidateToken(ctx, input.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return &AuthorizeOutput{Reason: AuthorizeReasonTokenExpired}, nil
+		}
+		uc.logger.Debug("Authorize probe got an invalid token: %v", err)
+		return &AuthorizeOutput{Reason: AuthorizeReasonTokenInvalid}, nil
+	}
+
+	if len(input.Groups) > 0 && !containsAny(claims.UserGroups, input.Groups) {
+		return &AuthorizeOutput{Reason: AuthorizeReasonMissingGroup, Missing: input.Groups}, nil
+	}
+	if missing := missingFrom(claims.Scopes, input.Scopes); len(missing) > 0 {
+		return &AuthorizeOutput{Reason: AuthorizeReasonMissingScope, Missing: missing}, nil
+	}
+
+	return &AuthorizeOutput{Allowed: true, Reason: AuthorizeReasonAllowed, Claims: claims}, nil
+}
+
+func containsAny(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func missingFrom(have, want []string) []string {
+	var missing []string
+	for _, w := range want {
+		if !containsAny(have, []string{w}) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
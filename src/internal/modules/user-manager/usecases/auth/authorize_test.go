@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// probedAuth knows one valid token, "member", and one that expired.
+type probedAuth struct {
+	auth.AuthService
+}
+
+func (probedAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	switch token {
+	case "member":
+		return &auth.Claims{Id: "member", UserGroups: []string{"user"}, Scopes: []string{"profile", "email"}}, nil
+	case "expired":
+		return nil, fmt.Errorf("validate: %w", auth.ErrTokenExpired)
+	default:
+		return nil, errors.New("token is malformed")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       AuthorizeInput
+		wantAllowed bool
+		wantReason  string
+		wantMissing []string
+	}{
+		{name: "nothing required", input: AuthorizeInput{Token: "member"}, wantAllowed: true, wantReason: AuthorizeReasonAllowed},
+		{name: "one of the groups", input: AuthorizeInput{Token: "member", Groups: []string{"admin", "user"}}, wantAllowed: true, wantReason: AuthorizeReasonAllowed},
+		{name: "every scope", input: AuthorizeInput{Token: "member", Groups: []string{"user"}, Scopes: []string{"profile", "email"}}, wantAllowed: true, wantReason: AuthorizeReasonAllowed},
+		{name: "missing group", input: AuthorizeInput{Token: "member", Groups: []string{"admin"}}, wantReason: AuthorizeReasonMissingGroup, wantMissing: []string{"admin"}},
+		{name: "missing scope", input: AuthorizeInput{Token: "member", Scopes: []string{"profile", "billing"}}, wantReason: AuthorizeReasonMissingScope, wantMissing: []string{"billing"}},
+		{name: "expired token", input: AuthorizeInput{Token: "expired", Groups: []string{"user"}}, wantReason: AuthorizeReasonTokenExpired},
+		{name: "invalid token", input: AuthorizeInput{Token: "garbage"}, wantReason: AuthorizeReasonTokenInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewAuthorizeUseCase(probedAuth{}, logger.Nop{}).Execute(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if out.Allowed != tt.wantAllowed || out.Reason != tt.wantReason {
+				t.Errorf("decision = %v %s, want %v %s", out.Allowed, out.Reason, tt.wantAllowed, tt.wantReason)
+			}
+			if fmt.Sprint(out.Missing) != fmt.Sprint(tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", out.Missing, tt.wantMissing)
+			}
+			if (out.Claims != nil) != tt.wantAllowed {
+				t.Errorf("claims = %+v, want them only on an allowed token", out.Claims)
+			}
+		})
+	}
+}
+
+func TestAuthorizeRequiresAToken(t *testing.T) {
+	if _, err := NewAuthorizeUseCase(probedAuth{}, logger.Nop{}).Execute(context.Background(), AuthorizeInput{}); !isFieldError(err, "Token") {
+		t.Errorf("Execute without a token = %v, want a 400 on Token", err)
+	}
+}
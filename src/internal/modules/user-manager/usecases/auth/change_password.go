@@ -6,7 +6,8 @@ import (
 )
 
 type ChangePasswordUseCase struct {
-	auth auth.AuthService
+	auth         auth.AuthService
+	emailChanges auth.EmailChangeStore
 }
 
 type ChangePasswordInput struct {
@@ -15,9 +16,10 @@ type ChangePasswordInput struct {
 	NewPassword string
 }
 
-func NewChangePasswordUseCase(auth auth.AuthService) *ChangePasswordUseCase {
+func NewChangePasswordUseCase(auth auth.AuthService, emailChanges auth.EmailChangeStore) *ChangePasswordUseCase {
 	return &ChangePasswordUseCase{
-		auth: auth,
+		auth:         auth,
+		emailChanges: emailChanges,
 	}
 }
 
@@ -31,6 +33,10 @@ func (uc *ChangePasswordUseCase) Execute(ctx context.Context, input ChangePasswo
 		return err
 	}
 
+	if err := requireVerifiedEmail(ctx, uc.auth, uc.emailChanges, input.AccessToken); err != nil {
+		return err
+	}
+
 	if err := uc.auth.ChangePassword(ctx, changePasswordInput); err != nil {
 		return err
 	}
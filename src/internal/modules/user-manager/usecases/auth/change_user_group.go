@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+)
+
+const auditActionChangeUserGroup = "CHANGE_USER_GROUP"
+
+type ChangeUserGroupUseCase struct {
+	adminService admin.AdminService
+	userService  user.UserService
+	auth         auth.AuthService
+	audit        audit.AuditService
+	logger       logger.Logger
+}
+
+type ChangeUserGroupInput struct {
+	ActorID string
+	auth.ChangeUserGroupInput
+}
+
+func NewChangeUserGroupUseCase(adminService admin.AdminService, userService user.UserService, auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *ChangeUserGroupUseCase {
+	return &ChangeUserGroupUseCase{
+		adminService: adminService,
+		userService:  userService,
+		auth:         auth,
+		audit:        audit,
+		logger:       logger,
+	}
+}
+
+// Execute adds the user to the target group and then removes them from the
+// source one, creating the target's admins or users row like AddGroup does.
+// If a later step fails, whatever this call added is undone, so the user
+// never ends up in both groups or in neither. A membership the user already
+// had is left alone.
+func (uc *ChangeUserGroupUseCase) Execute(ctx context.Context, input ChangeUserGroupInput) (execErr error) {
+	if err := input.ChangeUserGroupInput.Validate(); err != nil {
+		return err
+	}
+
+	// Rollbacks run even when the request was cancelled half way, or they
+	// would fail along with it.
+	rollbackCtx := context.WithoutCancel(ctx)
+
+	getUserOutput, err := uc.auth.GetUser(ctx, auth.GetUserInput{Username: input.Username})
+	if err != nil {
+		return err
+	}
+	if getUserOutput == nil {
+		return auth.ErrUserNotFound
+	}
+
+	groups, err := uc.auth.ListUserGroups(ctx, auth.ListUserGroupsInput{Username: input.Username})
+	if err != nil {
+		return err
+	}
+	alreadyInTarget := false
+	for _, group := range groups.Groups {
+		if group == string(input.To) {
+			alreadyInTarget = true
+			break
+		}
+	}
+
+	rollbackRow, err := uc.ensureRow(input.To, getUserOutput)
+	if err != nil {
+		return err
+	}
+	if rollbackRow != nil {
+		defer func() {
+			if execErr != nil {
+				if err := rollbackRow(rollbackCtx); err != nil {
+					uc.logger.Error("Error rolling back %s row: %s", input.To, err)
+				}
+			}
+		}()
+	}
+
+	if !alreadyInTarget {
+		if err := uc.auth.AddGroup(ctx, auth.AddGroupInput{
+			Username:  input.Username,
+			GroupName: input.To,
+		}); err != nil {
+			return err
+		}
+		defer func() {
+			if execErr != nil {
+				uc.logger.Info("Rollback change user group")
+				if err := uc.auth.RemoveGroup(rollbackCtx, auth.RemoveGroupInput{
+					Username:  input.Username,
+					GroupName: input.To,
+				}); err != nil {
+					uc.logger.Error("Rollback change user group error: %s", err)
+				}
+			}
+		}()
+	}
+
+	if err := uc.auth.RemoveGroup(ctx, auth.RemoveGroupInput{
+		Username:  input.Username,
+		GroupName: input.From,
+	}); err != nil {
+		return err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.ActorID,
+		Action:  auditActionChangeUserGroup,
+		Details: fmt.Sprintf("username=%s from=%s to=%s", input.Username, input.From, input.To),
+	}); err != nil {
+		uc.logger.Error("Error recording change user group audit entry: %s", err)
+	}
+
+	err = uc.auth.AdminLogout(ctx, auth.AdminLogoutInput{ // Force logout so the next token carries the new group
+		Username: input.Username,
+	})
+	if err != nil {
+		uc.logger.Error("Error admin logging out: %s", err)
+	}
+
+	return nil
+}
+
+// ensureRow creates the admins or users row for group when the user has
+// none yet, and returns how to remove it again. It returns a nil rollback
+// when the row already existed.
+func (uc *ChangeUserGroupUseCase) ensureRow(group auth.UserGroup, authUser *auth.User) (func(context.Context) error, error) {
+	switch group {
+	case auth.GroupAdmin:
+		getByEmailInput := &admin.GetAdminByEmailInput{Email: authUser.Email}
+		if err := getByEmailInput.Validate(); err != nil {
+			return nil, err
+		}
+		exists, err := uc.adminService.GetByEmail(getByEmailInput)
+		if err != nil && err != admin.ErrAdminNotFound {
+			return nil, err
+		}
+		if exists != nil {
+			return nil, nil
+		}
+
+		adminId, err := admin.ParseAdminID(authUser.Id)
+		if err != nil {
+			return nil, err
+		}
+		createInput := &admin.CreateAdminInput{ID: adminId, Name: authUser.Name, Email: authUser.Email}
+		if err := createInput.Validate(); err != nil {
+			return nil, err
+		}
+		createOut, err := uc.adminService.Create(createInput)
+		if err != nil {
+			return nil, err
+		}
+		return createOut.Rollback, nil
+
+	case auth.GroupUser:
+		getByEmailInput := &user.GetUserByEmailInput{Email: authUser.Email}
+		if err := getByEmailInput.Validate(); err != nil {
+			return nil, err
+		}
+		exists, err := uc.userService.GetByEmail(getByEmailInput)
+		if err != nil && err != user.ErrUserNotFound {
+			return nil, err
+		}
+		if exists != nil {
+			return nil, nil
+		}
+
+		userId, err := user.ParseUserID(authUser.Id)
+		if err != nil {
+			return nil, err
+		}
+		createInput := &user.CreateUserInput{ID: userId, Name: authUser.Name, Email: authUser.Email}
+		if err := createInput.Validate(); err != nil {
+			return nil, err
+		}
+		createOut, err := uc.userService.Create(createInput)
+		if err != nil {
+			return nil, err
+		}
+		return createOut.Rollback, nil
+	}
+	return nil, auth.ErrInvalidGroup
+}
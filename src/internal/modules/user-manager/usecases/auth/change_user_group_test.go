@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+)
+
+const changeGroupUserID = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+// groupAuth keeps Cognito group memberships in a map and fails RemoveGroup
+// for removeErrGroup.
+type groupAuth struct {
+	auth.AuthService
+	groups         map[auth.UserGroup]bool
+	removeErrGroup auth.UserGroup
+	rollbackCtxErr error
+}
+
+func (a *groupAuth) GetUser(context.Context, auth.GetUserInput) (*auth.User, error) {
+	return &auth.User{Id: changeGroupUserID, Email: "member@example.com", Name: "Member"}, nil
+}
+
+func (a *groupAuth) ListUserGroups(context.Context, auth.ListUserGroupsInput) (*auth.UserGroupsOutput, error) {
+	out := &auth.UserGroupsOutput{}
+	for group := range a.groups {
+		out.Groups = append(out.Groups, string(group))
+	}
+	return out, nil
+}
+
+func (a *groupAuth) AddGroup(ctx context.Context, input auth.AddGroupInput) error {
+	a.groups[input.GroupName] = true
+	return nil
+}
+
+func (a *groupAuth) RemoveGroup(ctx context.Context, input auth.RemoveGroupInput) error {
+	if input.GroupName == a.removeErrGroup {
+		return errors.New("cognito down")
+	}
+	a.rollbackCtxErr = ctx.Err()
+	delete(a.groups, input.GroupName)
+	return nil
+}
+
+func (a *groupAuth) AdminLogout(context.Context, auth.AdminLogoutInput) error { return nil }
+
+type memberAdmins struct {
+	admin.AdminService
+	exists  bool
+	created bool
+	deleted bool
+}
+
+func (s *memberAdmins) GetByEmail(*admin.GetAdminByEmailInput) (*admin.Admin, error) {
+	if s.exists || s.created {
+		return &admin.Admin{}, nil
+	}
+	return nil, admin.ErrAdminNotFound
+}
+
+func (s *memberAdmins) Create(input *admin.CreateAdminInput) (*admin.CreateAdminOutput, error) {
+	s.created = true
+	return admin.NewCreateAdminOutput(&input.ID, s), nil
+}
+
+func (s *memberAdmins) Delete(input *admin.DeleteAdminInput) (*admin.DeleteAdminOutput, error) {
+	s.deleted = true
+	return &admin.DeleteAdminOutput{}, nil
+}
+
+type memberUsers struct {
+	user.UserService
+}
+
+func (memberUsers) GetByEmail(*user.GetUserByEmailInput) (*user.User, error) {
+	return &user.User{}, nil
+}
+
+type nopAudit struct {
+	audit.AuditService
+}
+
+func (nopAudit) Record(context.Context, audit.RecordInput) error { return nil }
+
+func TestChangeUserGroup(t *testing.T) {
+	tests := []struct {
+		name           string
+		groups         []auth.UserGroup
+		adminRowExists bool
+		removeErrGroup auth.UserGroup
+		wantErr        bool
+		wantGroups     []auth.UserGroup
+		wantRowCreated bool
+		wantRowDeleted bool
+	}{
+		{
+			name:           "promotes and creates the admins row",
+			groups:         []auth.UserGroup{auth.GroupUser},
+			wantGroups:     []auth.UserGroup{auth.GroupAdmin},
+			wantRowCreated: true,
+		},
+		{
+			name:           "keeps an existing admins row",
+			groups:         []auth.UserGroup{auth.GroupUser},
+			adminRowExists: true,
+			wantGroups:     []auth.UserGroup{auth.GroupAdmin},
+		},
+		{
+			name:           "failure rolls back what was added",
+			groups:         []auth.UserGroup{auth.GroupUser},
+			removeErrGroup: auth.GroupUser,
+			wantErr:        true,
+			wantGroups:     []auth.UserGroup{auth.GroupUser},
+			wantRowCreated: true,
+			wantRowDeleted: true,
+		},
+		{
+			name:           "failure keeps a membership the user already had",
+			groups:         []auth.UserGroup{auth.GroupUser, auth.GroupAdmin},
+			adminRowExists: true,
+			removeErrGroup: auth.GroupUser,
+			wantErr:        true,
+			wantGroups:     []auth.UserGroup{auth.GroupUser, auth.GroupAdmin},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &groupAuth{groups: map[auth.UserGroup]bool{}, removeErrGroup: tt.removeErrGroup}
+			for _, group := range tt.groups {
+				authService.groups[group] = true
+			}
+			admins := &memberAdmins{exists: tt.adminRowExists}
+			uc := NewChangeUserGroupUseCase(admins, memberUsers{}, authService, nopAudit{}, logger.Nop{})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.wantErr {
+				// The rollback must not inherit a cancelled request.
+				cancel()
+			} else {
+				defer cancel()
+			}
+			err := uc.Execute(ctx, ChangeUserGroupInput{
+				ActorID: "actor",
+				ChangeUserGroupInput: auth.ChangeUserGroupInput{
+					Username: "member@example.com",
+					From:     auth.GroupUser,
+					To:       auth.GroupAdmin,
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(authService.groups) != len(tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", authService.groups, tt.wantGroups)
+			}
+			for _, group := range tt.wantGroups {
+				if !authService.groups[group] {
+					t.Errorf("groups = %v, want %v", authService.groups, tt.wantGroups)
+				}
+			}
+			if authService.rollbackCtxErr != nil {
+				t.Errorf("rollback ran on a cancelled context: %v", authService.rollbackCtxErr)
+			}
+			if admins.created != tt.wantRowCreated {
+				t.Errorf("admin row created = %v, want %v", admins.created, tt.wantRowCreated)
+			}
+			if admins.deleted != tt.wantRowDeleted {
+				t.Errorf("admin row deleted = %v, want %v", admins.deleted, tt.wantRowDeleted)
+			}
+		})
+	}
+}
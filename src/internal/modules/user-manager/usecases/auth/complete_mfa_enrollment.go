@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+)
+
+type CompleteMFAEnrollmentUseCase struct {
+	auth     auth.AuthService
+	sessions session.SessionService
+	limit    *sessionLimitPolicy
+	audit    audit.AuditService
+	logger   logger.Logger
+}
+
+type CompleteMFAEnrollmentInput struct {
+	Session string
+	Code    string
+}
+
+func (input *CompleteMFAEnrollmentInput) Validate() error {
+	if len(input.Session) == 0 {
+		return auth.NewValidationError("Session")
+	}
+	if len(input.Code) == 0 {
+		return auth.NewValidationError("Code")
+	}
+	return nil
+}
+
+func NewCompleteMFAEnrollmentUseCase(auth auth.AuthService, sessions session.SessionService, limit *sessionLimitPolicy, audit audit.AuditService, logger logger.Logger) *CompleteMFAEnrollmentUseCase {
+	return &CompleteMFAEnrollmentUseCase{
+		auth:     auth,
+		sessions: sessions,
+		limit:    limit,
+		audit:    audit,
+		logger:   logger,
+	}
+}
+
+// Execute verifies the authenticator set up during an MFA_ENROLLMENT_REQUIRED
+// login and releases the tokens held back by it.
+func (uc *CompleteMFAEnrollmentUseCase) Execute(ctx context.Context, input CompleteMFAEnrollmentInput) (*auth.LoginOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess, err := uc.sessions.Get(ctx, session.GetInput{
+		Token:   input.Session,
+		Purpose: session.PurposeMFAEnrollment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.auth.ActivateMFA(ctx, auth.ActivateMFAInput{
+		AccessToken: sess.AccessToken,
+		Code:        input.Code,
+	}); err != nil {
+		return nil, err
+	}
+	recordMFAChange(ctx, uc.audit, uc.logger, sess.Username, sess.Username, auth.AuditActionMFAEnabled)
+
+	if err := uc.sessions.Delete(ctx, session.DeleteInput{Token: input.Session}); err != nil {
+		uc.logger.Warning("Error deleting MFA enrollment session: %v", err)
+	}
+
+	output := &auth.LoginOutput{
+		AccessToken:  &sess.AccessToken,
+		IdToken:      &sess.IdToken,
+		RefreshToken: &sess.RefreshToken,
+	}
+	if err := uc.limit.track(ctx, sess.Username, output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
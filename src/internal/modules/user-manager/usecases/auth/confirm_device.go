@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+)
+
+type ConfirmDeviceUseCase struct {
+	auth auth.AuthService
+}
+
+type ConfirmDeviceInput struct {
+	auth.ConfirmDeviceInput
+}
+
+func NewConfirmDeviceUseCase(auth auth.AuthService) *ConfirmDeviceUseCase {
+	return &ConfirmDeviceUseCase{
+		auth: auth,
+	}
+}
+
+func (uc *ConfirmDeviceUseCase) Execute(ctx context.Context, input ConfirmDeviceInput) (*auth.ConfirmDeviceOutput, error) {
+	if err := input.ConfirmDeviceInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	return uc.auth.ConfirmDevice(ctx, input.ConfirmDeviceInput)
+}
@@ -2,21 +2,43 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
 	"context"
+	"strings"
 )
 
 type ConfirmSignUpUseCase struct {
-	auth auth.AuthService
+	auth      auth.AuthService
+	logger    logger.Logger
+	autoLogin bool
+	// assignGroup adds the signup group again once the user is confirmed,
+	// for pools where the one added at signup doesn't stick. Adding a user to
+	// a group they're already in is a no-op, so it never duplicates.
+	assignGroup bool
+	invites     auth.InviteTokens
+	login       *LoginUseCase
 }
 
 type ConfirmSignUpInput struct {
 	Username string
 	Code     string
+	Password *string
+	// InviteToken is the one the account signed up with, if any. Its role
+	// is the group assigned on confirmation instead of GroupUser.
+	InviteToken string
+	// IP and UserAgent are only used to audit the automatic login.
+	IP        string
+	UserAgent string
 }
 
-func NewConfirmSignUpUseCase(auth auth.AuthService) *ConfirmSignUpUseCase {
+func NewConfirmSignUpUseCase(auth auth.AuthService, logger logger.Logger, autoLogin bool, assignGroup bool, invites auth.InviteTokens, login *LoginUseCase) *ConfirmSignUpUseCase {
 	return &ConfirmSignUpUseCase{
-		auth: auth,
+		auth:        auth,
+		logger:      logger,
+		autoLogin:   autoLogin,
+		assignGroup: assignGroup,
+		invites:     invites,
+		login:       login,
 	}
 }
 
@@ -44,6 +66,11 @@ func (uc *ConfirmSignUpUseCase) Execute(ctx context.Context, input ConfirmSignUp
 		return nil, err
 	}
 
+	group, err := uc.signupGroup(input.InviteToken, confirmSignUpInput.Username)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := uc.auth.VerifyCode(ctx, verifyCodeInput); err != nil {
 		return nil, err
 	}
@@ -53,9 +80,56 @@ func (uc *ConfirmSignUpUseCase) Execute(ctx context.Context, input ConfirmSignUp
 		return nil, err
 	}
 
+	if uc.assignGroup {
+		if err := uc.auth.AddGroup(ctx, auth.AddGroupInput{
+			Username:  confirmSignUpInput.Username,
+			GroupName: group,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := uc.auth.VerifyEmail(ctx, verifyEmailInput); err != nil {
 		return nil, err
 	}
 
+	if !uc.autoLogin || input.Password == nil || *input.Password == "" {
+		return out, nil
+	}
+
+	// The account is confirmed at this point, so a failed login must not fail
+	// the request; the client can still sign in on its own. It goes through
+	// the regular login, so an invited admin gets the MFA enrollment
+	// challenge instead of tokens.
+	loginOut, err := uc.login.executeAfterConfirmation(ctx, LoginInput{
+		LoginInput: auth.LoginInput{
+			Username: input.Username,
+			Password: *input.Password,
+		},
+		IP:        input.IP,
+		UserAgent: input.UserAgent,
+	})
+	if err != nil {
+		uc.logger.Warning("Auto login after confirmation failed: %v", err)
+		return out, nil
+	}
+	out.Tokens = loginOut
+
 	return out, nil
 }
+
+// signupGroup is the invited role when an invite token for username is given,
+// GroupUser otherwise.
+func (uc *ConfirmSignUpUseCase) signupGroup(inviteToken, username string) (auth.UserGroup, error) {
+	if inviteToken == "" {
+		return auth.GroupUser, nil
+	}
+	invite, err := uc.invites.Verify(inviteToken)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(invite.Email, username) {
+		return "", auth.ErrInviteEmailMismatch
+	}
+	return invite.Role, nil
+}
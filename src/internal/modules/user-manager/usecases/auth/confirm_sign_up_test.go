@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+	"time"
+)
+
+// confirmAuth confirms any code and signs in with tokens for groups.
+type confirmAuth struct {
+	auth.AuthService
+	groups []string
+	logins int
+}
+
+func (a *confirmAuth) VerifyCode(context.Context, auth.VerifyCodeInput) error   { return nil }
+func (a *confirmAuth) VerifyEmail(context.Context, auth.VerifyEmailInput) error { return nil }
+
+func (a *confirmAuth) ConfirmSignUp(context.Context, auth.ConfirmSignUpInput) (*auth.ConfirmSignUpOutput, error) {
+	return &auth.ConfirmSignUpOutput{}, nil
+}
+
+func (a *confirmAuth) Login(context.Context, auth.LoginInput) (*auth.LoginOutput, error) {
+	a.logins++
+	accessToken, idToken, refreshToken := "access", "id", "refresh"
+	return &auth.LoginOutput{AccessToken: &accessToken, IdToken: &idToken, RefreshToken: &refreshToken}, nil
+}
+
+func (a *confirmAuth) ValidateToken(context.Context, string) (*auth.Claims, error) {
+	return &auth.Claims{UserGroups: a.groups}, nil
+}
+
+func (a *confirmAuth) AddMFA(context.Context, auth.AddMFAInput) (*auth.AddMFAOutput, error) {
+	return &auth.AddMFAOutput{SecretCode: "SECRET"}, nil
+}
+
+type enrollmentSessions struct {
+	session.SessionService
+}
+
+func (enrollmentSessions) Create(context.Context, session.CreateInput) (string, *session.Session, error) {
+	return "enrollment-session", &session.Session{}, nil
+}
+
+type recordingDispatcher struct {
+	events []events.Event
+}
+
+func (d *recordingDispatcher) Register(events.EventType, events.EventHandler) {}
+func (d *recordingDispatcher) Dispatch(event events.Event) error {
+	d.events = append(d.events, event)
+	return nil
+}
+
+func TestConfirmSignUpAutoLogin(t *testing.T) {
+	tests := []struct {
+		name         string
+		groups       []string
+		locked       bool
+		wantTokens   bool
+		wantNextStep string
+		wantLogins   int
+		wantOutcome  auth.LoginOutcome
+	}{
+		{
+			name:        "user gets tokens",
+			groups:      []string{string(auth.GroupUser)},
+			wantTokens:  true,
+			wantLogins:  1,
+			wantOutcome: auth.LoginOutcomeSuccess,
+		},
+		{
+			name:         "admin without MFA gets the enrollment challenge",
+			groups:       []string{string(auth.GroupAdmin)},
+			wantNextStep: auth.NextStepMFAEnrollmentRequired,
+			wantLogins:   1,
+			wantOutcome:  auth.LoginOutcomeChallenge,
+		},
+		{
+			name:        "locked out username is not signed in",
+			groups:      []string{string(auth.GroupUser)},
+			locked:      true,
+			wantLogins:  0,
+			wantOutcome: auth.LoginOutcomeFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			authService := &confirmAuth{groups: tt.groups}
+			store := newFakeLockoutStore()
+			if tt.locked {
+				store.states["new@example.com"] = auth.LockoutState{Failures: 5, LastFailureAt: time.Now()}
+			}
+			dispatcher := &recordingDispatcher{}
+			mfaPolicy := newAdminMFAPolicy(true, authService, enrollmentSessions{}, logger.Nop{}, time.Minute)
+			lockout := newLockoutPolicy(5, time.Hour, store, logger.Nop{})
+			// A nil challenge would panic if the confirmation path asked for it.
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger.Nop{}, false)
+			login := NewLoginUseCase(authService, dispatcher, logger.Nop{}, mfaPolicy, nil, lockout, limit)
+			uc := NewConfirmSignUpUseCase(authService, logger.Nop{}, true, false, nil, login)
+
+			password := "Str0ng!Passw0rd"
+			out, err := uc.Execute(ctx, ConfirmSignUpInput{
+				Username:  "new@example.com",
+				Code:      "123456",
+				Password:  &password,
+				IP:        "203.0.113.7",
+				UserAgent: "test",
+			})
+			if err != nil {
+				t.Fatalf("confirmation failed: %v", err)
+			}
+
+			if authService.logins != tt.wantLogins {
+				t.Errorf("logins = %d, want %d", authService.logins, tt.wantLogins)
+			}
+			switch {
+			case tt.wantTokens:
+				if out.Tokens == nil || out.Tokens.AccessToken == nil {
+					t.Fatalf("Tokens = %+v, want tokens", out.Tokens)
+				}
+			case tt.wantNextStep != "":
+				if out.Tokens == nil || out.Tokens.NextStep == nil || *out.Tokens.NextStep != tt.wantNextStep {
+					t.Fatalf("Tokens = %+v, want next step %s", out.Tokens, tt.wantNextStep)
+				}
+				if out.Tokens.AccessToken != nil {
+					t.Error("admin without MFA was handed an access token")
+				}
+			default:
+				if out.Tokens != nil {
+					t.Errorf("Tokens = %+v, want none", out.Tokens)
+				}
+			}
+
+			if len(dispatcher.events) != 1 {
+				t.Fatalf("dispatched %d events, want 1", len(dispatcher.events))
+			}
+			event, ok := dispatcher.events[0].(*auth.LoginAttemptedEvent)
+			if !ok {
+				t.Fatalf("dispatched %T, want *auth.LoginAttemptedEvent", dispatcher.events[0])
+			}
+			if event.Outcome != tt.wantOutcome || event.IP != "203.0.113.7" {
+				t.Errorf("event = %+v, want outcome %s from 203.0.113.7", event, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+// groupedAuth keeps group membership the way Cognito does: adding a user
+// to a group they're already in leaves a single membership.
+type groupedAuth struct {
+	confirmAuth
+	members map[auth.UserGroup][]string
+	adds    int
+}
+
+func (a *groupedAuth) AddGroup(ctx context.Context, input auth.AddGroupInput) error {
+	a.adds++
+	for _, member := range a.members[input.GroupName] {
+		if member == input.Username {
+			return nil
+		}
+	}
+	a.members[input.GroupName] = append(a.members[input.GroupName], input.Username)
+	return nil
+}
+
+func TestConfirmSignUpAssignsTheDefaultGroupOnce(t *testing.T) {
+	tests := []struct {
+		name          string
+		assignGroup   bool
+		addedAtSignup bool
+		wantAdds      int
+		wantMembers   int
+	}{
+		{name: "added at signup and again on confirmation", assignGroup: true, addedAtSignup: true, wantAdds: 1, wantMembers: 1},
+		{name: "group lost since signup is restored", assignGroup: true, wantAdds: 1, wantMembers: 1},
+		{name: "flag off leaves the signup assignment alone", addedAtSignup: true, wantAdds: 0, wantMembers: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &groupedAuth{members: map[auth.UserGroup][]string{}}
+			if tt.addedAtSignup {
+				authService.members[auth.GroupUser] = []string{"new@example.com"}
+			}
+			uc := NewConfirmSignUpUseCase(authService, logger.Nop{}, false, tt.assignGroup, nil, nil)
+
+			if _, err := uc.Execute(context.Background(), ConfirmSignUpInput{Username: "new@example.com", Code: "123456"}); err != nil {
+				t.Fatalf("confirmation failed: %v", err)
+			}
+
+			if authService.adds != tt.wantAdds {
+				t.Errorf("AddGroup called %d times, want %d", authService.adds, tt.wantAdds)
+			}
+			members := 0
+			for _, member := range authService.members[auth.GroupUser] {
+				if member == "new@example.com" {
+					members++
+				}
+			}
+			if members != tt.wantMembers {
+				t.Errorf("user is in %s %d times, want %d", auth.GroupUser, members, tt.wantMembers)
+			}
+		})
+	}
+}
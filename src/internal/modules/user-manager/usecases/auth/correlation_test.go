@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lineLogger keeps every line logged.
+type lineLogger struct {
+	lines []string
+}
+
+func (l *lineLogger) Info(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+func (l *lineLogger) Error(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+func (l *lineLogger) Warning(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+func (l *lineLogger) Debug(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *lineLogger) find(parts ...string) string {
+	for _, line := range l.lines {
+		matches := true
+		for _, part := range parts {
+			if !strings.Contains(line, part) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return line
+		}
+	}
+	return ""
+}
+
+// respondingAuth answers a login with an MFA challenge. The responses either
+// fail with err, ask for MFA again (nextStep) or sign the user in.
+type respondingAuth struct {
+	auth.AuthService
+	err      error
+	nextStep *string
+}
+
+func (a *respondingAuth) Login(context.Context, auth.LoginInput) (*auth.LoginOutput, error) {
+	nextStep, session := "SOFTWARE_TOKEN_MFA", "challenge-session"
+	return &auth.LoginOutput{NextStep: &nextStep, Session: &session}, nil
+}
+
+func (a *respondingAuth) respond() (*auth.LoginOutput, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	if a.nextStep != nil {
+		session := "next-session"
+		return &auth.LoginOutput{NextStep: a.nextStep, Session: &session}, nil
+	}
+	accessToken := "access"
+	return &auth.LoginOutput{AccessToken: &accessToken}, nil
+}
+
+func (a *respondingAuth) VerifyMFA(context.Context, auth.VerifyMFAInput) (*auth.LoginOutput, error) {
+	return a.respond()
+}
+
+func (a *respondingAuth) SetPassword(context.Context, auth.SetPasswordInput) (*auth.LoginOutput, error) {
+	return a.respond()
+}
+
+func TestChallengeCorrelationIdRoundTrips(t *testing.T) {
+	mfaAgain := "SOFTWARE_TOKEN_MFA"
+	codeErr := errors.New("code mismatch")
+
+	tests := []struct {
+		name     string
+		step     string
+		err      error
+		nextStep *string
+		wantLog  string
+	}{
+		{name: "verify MFA succeeds", step: "VerifyMFA", wantLog: "succeeded step=VerifyMFA"},
+		{name: "verify MFA fails", step: "VerifyMFA", err: codeErr, wantLog: "failed step=VerifyMFA"},
+		{name: "set password asks for MFA next", step: "SetPassword", nextStep: &mfaAgain, wantLog: "succeeded step=SetPassword"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			authService := &respondingAuth{err: tt.err, nextStep: tt.nextStep}
+			logger := &lineLogger{}
+			dispatcher := &recordingDispatcher{}
+			mfaPolicy := newAdminMFAPolicy(false, authService, enrollmentSessions{}, logger, time.Minute)
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger, false)
+			login := NewLoginUseCase(authService, dispatcher, logger, mfaPolicy, passChallenge{}, newLockoutPolicy(0, time.Hour, newFakeLockoutStore(), logger), limit)
+
+			challenge, err := login.Execute(ctx, LoginInput{LoginInput: auth.LoginInput{Username: "member@example.com", Password: "Str0ng!Passw0rd"}})
+			if err != nil {
+				t.Fatalf("login = %v", err)
+			}
+			correlationId := deref.String(challenge.CorrelationId)
+			if auth.ValidateCorrelationId(correlationId) != nil || correlationId == "" {
+				t.Fatalf("correlation id = %q, want a uuid", correlationId)
+			}
+			if logger.find("Login challenge issued", "correlation_id="+correlationId) == "" {
+				t.Errorf("challenge log lacks correlation_id=%s: %q", correlationId, logger.lines)
+			}
+			if len(dispatcher.events) != 1 || dispatcher.events[0].(*auth.LoginAttemptedEvent).CorrelationId != correlationId {
+				t.Errorf("login attempt event = %+v, want correlation id %s", dispatcher.events, correlationId)
+			}
+
+			var out *auth.LoginOutput
+			switch tt.step {
+			case "VerifyMFA":
+				out, err = NewVerifyMFAUseCase(authService, limit, logger).Execute(ctx, VerifyMFAInput{
+					VerifyMFAInput: auth.VerifyMFAInput{Username: "member@example.com", Code: "123456", Session: "challenge-session"},
+					CorrelationId:  correlationId,
+				})
+			case "SetPassword":
+				out, err = NewSetPasswordUseCase(authService, logger, mfaPolicy, limit).Execute(ctx, SetPasswordInput{
+					SetPasswordInput: auth.SetPasswordInput{Username: "member@example.com", Password: "N3w!Passw0rdX", Session: "challenge-session"},
+					CorrelationId:    correlationId,
+				})
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("%s = %v, want %v", tt.step, err, tt.err)
+			}
+			if logger.find(tt.wantLog, "correlation_id="+correlationId) == "" {
+				t.Errorf("no %q log with correlation_id=%s: %q", tt.wantLog, correlationId, logger.lines)
+			}
+			if tt.nextStep != nil && deref.String(out.CorrelationId) != correlationId {
+				t.Errorf("follow-up challenge correlation id = %q, want %s", deref.String(out.CorrelationId), correlationId)
+			}
+		})
+	}
+}
+
+func TestChallengeResponseRejectsMalformedCorrelationId(t *testing.T) {
+	authService := &respondingAuth{}
+	limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger.Nop{}, false)
+
+	_, err := NewVerifyMFAUseCase(authService, limit, logger.Nop{}).Execute(context.Background(), VerifyMFAInput{
+		VerifyMFAInput: auth.VerifyMFAInput{Username: "member@example.com", Code: "123456", Session: "challenge-session"},
+		CorrelationId:  "not-a-uuid\nforged log line",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Field: CorrelationId") {
+		t.Errorf("VerifyMFA = %v, want a CorrelationId validation error", err)
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/validator"
+	"context"
+	"time"
+)
+
+type CreateSessionUseCase struct {
+	auth      auth.AuthService
+	events    events.EventDispatcher
+	logger    logger.Logger
+	mfaPolicy *adminMFAPolicy
+	limit     *sessionLimitPolicy
+	challenge auth.PreAuthChallenge
+	lockout   *lockoutPolicy
+}
+
+// CreateSessionInput takes either a password or, to finish an MFA challenge
+// started by a previous call, the challenge session and the MFA code.
+type CreateSessionInput struct {
+	Username         string
+	Password         string
+	ChallengeSession string
+	Code             string
+	IP               string
+	UserAgent        string
+	ChallengeToken   string
+}
+
+type CreateSessionOutput struct {
+	Token               string            `json:"-"`
+	ExpiresAt           *time.Time        `json:"expiresAt,omitempty"`
+	NextStep            *string           `json:"nextStep,omitempty"`
+	ChallengeSession    *string           `json:"session,omitempty"`
+	ChallengeParameters map[string]string `json:"challengeParameters,omitempty"`
+}
+
+func NewCreateSessionUseCase(auth auth.AuthService, events events.EventDispatcher, logger logger.Logger, mfaPolicy *adminMFAPolicy, limit *sessionLimitPolicy, challenge auth.PreAuthChallenge, lockout *lockoutPolicy) *CreateSessionUseCase {
+	return &CreateSessionUseCase{
+		auth:      auth,
+		events:    events,
+		logger:    logger,
+		mfaPolicy: mfaPolicy,
+		limit:     limit,
+		challenge: challenge,
+		lockout:   lockout,
+	}
+}
+
+func (uc *CreateSessionUseCase) Execute(ctx context.Context, input CreateSessionInput) (*CreateSessionOutput, error) {
+	loginOut, err := uc.authenticate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// A pending challenge is handed back so the client can complete it; no
+	// session exists until Cognito issues tokens.
+	if loginOut.NextStep != nil || loginOut.AccessToken == nil {
+		return &CreateSessionOutput{
+			NextStep:            loginOut.NextStep,
+			ChallengeSession:    loginOut.Session,
+			ChallengeParameters: loginOut.ChallengeParameters,
+		}, nil
+	}
+
+	accessToken := deref.String(loginOut.AccessToken)
+	token, sess, err := uc.limit.open(ctx, session.CreateInput{
+		Username:             validator.NormalizeEmail(input.Username, uc.limit.caseSensitive),
+		AccessToken:          accessToken,
+		IdToken:              deref.String(loginOut.IdToken),
+		RefreshToken:         deref.String(loginOut.RefreshToken),
+		AccessTokenExpiresAt: uc.limit.accessTokenExpiresAt(accessToken),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateSessionOutput{
+		Token:     token,
+		ExpiresAt: &sess.ExpiresAt,
+	}, nil
+}
+
+func (uc *CreateSessionUseCase) authenticate(ctx context.Context, input CreateSessionInput) (*auth.LoginOutput, error) {
+	if input.ChallengeSession != "" {
+		verifyMFAInput := auth.VerifyMFAInput{
+			Code:     input.Code,
+			Username: input.Username,
+			Session:  input.ChallengeSession,
+		}
+		if err := verifyMFAInput.Validate(); err != nil {
+			return nil, err
+		}
+		out, err := uc.auth.VerifyMFA(ctx, verifyMFAInput)
+		if err != nil {
+			return nil, err
+		}
+		return out, uc.refuseMFAEnrollment(ctx, out)
+	}
+
+	loginInput := auth.LoginInput{
+		Username: input.Username,
+		Password: input.Password,
+	}
+	if err := loginInput.Validate(); err != nil {
+		return nil, err
+	}
+	out, err := uc.passwordLogin(ctx, loginInput, input)
+	if err == nil {
+		if err = uc.refuseMFAEnrollment(ctx, out); err != nil {
+			out = nil
+		}
+	}
+	dispatchLoginAttempt(uc.events, uc.logger, LoginInput{
+		LoginInput: loginInput,
+		IP:         input.IP,
+		UserAgent:  input.UserAgent,
+	}, out, err)
+	return out, err
+}
+
+func (uc *CreateSessionUseCase) passwordLogin(ctx context.Context, loginInput auth.LoginInput, input CreateSessionInput) (*auth.LoginOutput, error) {
+	if err := uc.lockout.check(ctx, loginInput.Username); err != nil {
+		return nil, err
+	}
+	if err := uc.challenge.Verify(ctx, auth.PreAuthChallengeInput{
+		Token:  input.ChallengeToken,
+		IP:     input.IP,
+		Action: auth.PreAuthActionLogin,
+	}); err != nil {
+		return nil, err
+	}
+	out, err := uc.auth.Login(ctx, loginInput)
+	uc.lockout.record(ctx, loginInput.Username, err)
+	return out, err
+}
+
+// refuseMFAEnrollment keeps cookie sessions from admins without MFA.
+// Enrollment happens through /auth/login.
+func (uc *CreateSessionUseCase) refuseMFAEnrollment(ctx context.Context, out *auth.LoginOutput) error {
+	required, err := uc.mfaPolicy.requiresEnrollment(ctx, out)
+	if err != nil {
+		return err
+	}
+	if required {
+		return auth.ErrMFAEnrollmentRequired
+	}
+	return nil
+}
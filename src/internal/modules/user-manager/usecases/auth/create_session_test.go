@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionAuth adds the MFA challenge answer to confirmAuth.
+type sessionAuth struct {
+	*confirmAuth
+}
+
+func (a sessionAuth) VerifyMFA(ctx context.Context, input auth.VerifyMFAInput) (*auth.LoginOutput, error) {
+	return a.Login(ctx, auth.LoginInput{})
+}
+
+// challengeAuth answers every password with an MFA challenge.
+type challengeAuth struct {
+	sessionAuth
+}
+
+func (challengeAuth) Login(context.Context, auth.LoginInput) (*auth.LoginOutput, error) {
+	nextStep, session := "SOFTWARE_TOKEN_MFA", "challenge-session"
+	return &auth.LoginOutput{NextStep: &nextStep, Session: &session}, nil
+}
+
+type passChallenge struct{}
+
+func (passChallenge) Verify(context.Context, auth.PreAuthChallengeInput) error { return nil }
+
+func TestCreateSessionDispatchesLoginAttempts(t *testing.T) {
+	tests := []struct {
+		name             string
+		groups           []string
+		locked           bool
+		challengeSession string
+		wantErr          bool
+		// wantOutcome empty means no event is expected.
+		wantOutcome auth.LoginOutcome
+	}{
+		{
+			name:        "password sign in",
+			groups:      []string{string(auth.GroupUser)},
+			wantOutcome: auth.LoginOutcomeSuccess,
+		},
+		{
+			name:        "locked out username",
+			groups:      []string{string(auth.GroupUser)},
+			locked:      true,
+			wantErr:     true,
+			wantOutcome: auth.LoginOutcomeFailure,
+		},
+		{
+			name:        "admin without MFA is refused",
+			groups:      []string{string(auth.GroupAdmin)},
+			wantErr:     true,
+			wantOutcome: auth.LoginOutcomeFailure,
+		},
+		{
+			name:             "MFA answer is not a password attempt",
+			groups:           []string{string(auth.GroupUser)},
+			challengeSession: "challenge-session",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := sessionAuth{&confirmAuth{groups: tt.groups}}
+			store := newFakeLockoutStore()
+			if tt.locked {
+				store.states["member@example.com"] = auth.LockoutState{Failures: 5, LastFailureAt: time.Now()}
+			}
+			dispatcher := &recordingDispatcher{}
+			mfaPolicy := newAdminMFAPolicy(true, authService, enrollmentSessions{}, logger.Nop{}, time.Minute)
+			lockout := newLockoutPolicy(5, time.Hour, store, logger.Nop{})
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger.Nop{}, false)
+			uc := NewCreateSessionUseCase(authService, dispatcher, logger.Nop{}, mfaPolicy, limit, passChallenge{}, lockout)
+
+			_, err := uc.Execute(context.Background(), CreateSessionInput{
+				Username:         "member@example.com",
+				Password:         "Str0ng!Passw0rd",
+				ChallengeSession: tt.challengeSession,
+				Code:             "123456",
+				IP:               "203.0.113.7",
+				UserAgent:        "test",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantOutcome == "" {
+				if len(dispatcher.events) != 0 {
+					t.Fatalf("dispatched %d events, want none", len(dispatcher.events))
+				}
+				return
+			}
+			if len(dispatcher.events) != 1 {
+				t.Fatalf("dispatched %d events, want 1", len(dispatcher.events))
+			}
+			event, ok := dispatcher.events[0].(*auth.LoginAttemptedEvent)
+			if !ok {
+				t.Fatalf("dispatched %T, want *auth.LoginAttemptedEvent", dispatcher.events[0])
+			}
+			if event.Outcome != tt.wantOutcome || event.IP != "203.0.113.7" || event.UserAgent != "test" {
+				t.Errorf("event = %+v, want outcome %s from 203.0.113.7", event, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestCreateSessionOutputOmitsExpiryOfChallenges(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          auth.AuthService
+		wantExpiresAt bool
+	}{
+		{
+			name:          "session issued",
+			auth:          sessionAuth{&confirmAuth{groups: []string{string(auth.GroupUser)}}},
+			wantExpiresAt: true,
+		},
+		{
+			name: "challenge pending",
+			auth: challengeAuth{sessionAuth{&confirmAuth{groups: []string{string(auth.GroupUser)}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mfaPolicy := newAdminMFAPolicy(true, tt.auth, enrollmentSessions{}, logger.Nop{}, time.Minute)
+			lockout := newLockoutPolicy(5, time.Hour, newFakeLockoutStore(), logger.Nop{})
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, tt.auth, &memorySessions{}, logger.Nop{}, false)
+			uc := NewCreateSessionUseCase(tt.auth, &recordingDispatcher{}, logger.Nop{}, mfaPolicy, limit, passChallenge{}, lockout)
+
+			out, err := uc.Execute(context.Background(), CreateSessionInput{
+				Username: "member@example.com",
+				Password: "Str0ng!Passw0rd",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := json.Marshal(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(body), `"expiresAt"`); got != tt.wantExpiresAt {
+				t.Errorf("body = %s, want expiresAt %v", body, tt.wantExpiresAt)
+			}
+		})
+	}
+}
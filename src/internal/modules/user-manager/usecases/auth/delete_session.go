@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+)
+
+type DeleteSessionUseCase struct {
+	auth     auth.AuthService
+	sessions session.SessionService
+	logger   logger.Logger
+}
+
+type DeleteSessionInput struct {
+	Token string
+}
+
+func NewDeleteSessionUseCase(auth auth.AuthService, sessions session.SessionService, logger logger.Logger) *DeleteSessionUseCase {
+	return &DeleteSessionUseCase{
+		auth:     auth,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+func (uc *DeleteSessionUseCase) Execute(ctx context.Context, input DeleteSessionInput) error {
+	getInput := session.GetInput{
+		Token: input.Token,
+	}
+	if err := getInput.Validate(); err != nil {
+		return err
+	}
+
+	sess, err := uc.sessions.Get(ctx, getInput)
+	if err != nil {
+		return err
+	}
+
+	// Only this sign in ends: revoking its refresh token also invalidates the
+	// access tokens issued from it, leaving the user's other devices alone.
+	// The local session goes away even if Cognito refuses the revocation.
+	revokeInput := auth.RevokeTokenInput{
+		RefreshToken: sess.RefreshToken,
+	}
+	if err := uc.auth.RevokeToken(ctx, revokeInput); err != nil {
+		uc.logger.Warning("Cognito token revocation failed while deleting session: %v", err)
+	}
+
+	// The session is the sign in counted against the session limit, so
+	// deleting it also releases its slot.
+	return uc.sessions.Delete(ctx, session.DeleteInput{
+		Token: input.Token,
+	})
+}
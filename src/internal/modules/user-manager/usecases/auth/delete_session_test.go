@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+)
+
+// tokenSessions keys sessions by their cookie token.
+type tokenSessions struct {
+	session.SessionService
+	byToken map[string]*session.Session
+}
+
+func (s *tokenSessions) Get(ctx context.Context, input session.GetInput) (*session.Session, error) {
+	sess, ok := s.byToken[input.Token]
+	if !ok {
+		return nil, session.ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func (s *tokenSessions) Delete(ctx context.Context, input session.DeleteInput) error {
+	delete(s.byToken, input.Token)
+	return nil
+}
+
+// signOutAuth records global sign outs on top of revokingAuth.
+type signOutAuth struct {
+	revokingAuth
+	signedOut []string
+}
+
+func (a *signOutAuth) Logout(ctx context.Context, input auth.LogoutInput) error {
+	a.signedOut = append(a.signedOut, input.AccessToken)
+	return nil
+}
+
+func TestDeleteSessionEndsOnlyThatSignIn(t *testing.T) {
+	authService := &signOutAuth{}
+	sessions := &tokenSessions{byToken: map[string]*session.Session{
+		"laptop": {Username: "member@example.com", AccessToken: "laptop-access", RefreshToken: "laptop-refresh"},
+		"phone":  {Username: "member@example.com", AccessToken: "phone-access", RefreshToken: "phone-refresh"},
+	}}
+	uc := NewDeleteSessionUseCase(authService, sessions, logger.Nop{})
+
+	if err := uc.Execute(context.Background(), DeleteSessionInput{Token: "laptop"}); err != nil {
+		t.Fatalf("Execute = %v, want nil", err)
+	}
+
+	if len(authService.revoked) != 1 || authService.revoked[0] != "laptop-refresh" {
+		t.Errorf("revoked = %v, want [laptop-refresh]", authService.revoked)
+	}
+	if len(authService.signedOut) != 0 {
+		t.Errorf("signed out globally with %v, want no global sign out", authService.signedOut)
+	}
+	if _, ok := sessions.byToken["laptop"]; ok {
+		t.Error("laptop session still stored")
+	}
+	if _, ok := sessions.byToken["phone"]; !ok {
+		t.Error("phone session was deleted")
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	auditActionChangeEmail = "CHANGE_EMAIL"
+
+	emailChangeCodeIdentifier = "EMAIL_CHANGE_CODE"
+)
+
+type RequestEmailChangeUseCase struct {
+	auth    auth.AuthService
+	changes auth.EmailChangeStore
+	logger  logger.Logger
+}
+
+type RequestEmailChangeOutput struct {
+	auth.EmailChange
+	CodeDelivery auth.CodeDeliveryDetails `json:"codeDelivery"`
+}
+
+func NewRequestEmailChangeUseCase(auth auth.AuthService, changes auth.EmailChangeStore, logger logger.Logger) *RequestEmailChangeUseCase {
+	return &RequestEmailChangeUseCase{
+		auth:    auth,
+		changes: changes,
+		logger:  logger,
+	}
+}
+
+// Execute moves the user to the new email right away, unverified, and sends
+// it a code. Requesting again while pending replaces the pending change.
+func (uc *RequestEmailChangeUseCase) Execute(ctx context.Context, input auth.RequestEmailChangeInput) (*RequestEmailChangeOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	previousEmail := input.CurrentEmail
+	if current, err := uc.changes.Get(ctx, input.UserId); err != nil {
+		return nil, err
+	} else if current.IsPending() {
+		// The token may still carry the unverified email; keep the one the
+		// user had before the whole change started.
+		previousEmail = current.PreviousEmail
+	}
+
+	emailVerified := false
+	if err := uc.auth.UpdateUserAttributes(ctx, auth.UpdateUserAttributesInput{
+		Id:            input.UserId,
+		Email:         &input.NewEmail,
+		EmailVerified: &emailVerified,
+	}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change := &auth.EmailChange{
+		UserId:        input.UserId,
+		PreviousEmail: previousEmail,
+		NewEmail:      input.NewEmail,
+		Status:        auth.EmailChangePending,
+		RequestedAt:   &now,
+	}
+	if err := uc.changes.Save(ctx, change); err != nil {
+		return nil, err
+	}
+
+	generateOut, err := uc.auth.GenerateAndSendCode(ctx, auth.GenerateAndSendCodeInput{
+		Username:       input.NewEmail,
+		Identifier:     emailChangeCodeIdentifier,
+		Subject:        "Please confirm your new email",
+		Body:           "Your confirmation code is: %s",
+		DeliveryMedium: auth.DeliveryMediumEmail,
+	})
+	if err != nil {
+		// The change stays pending; requesting it again sends a new code.
+		uc.logger.Error("failed to send email change code: %v", err)
+		return nil, err
+	}
+
+	return &RequestEmailChangeOutput{
+		EmailChange:  *change,
+		CodeDelivery: generateOut.CodeDelivery,
+	}, nil
+}
+
+type VerifyEmailChangeUseCase struct {
+	auth    auth.AuthService
+	changes auth.EmailChangeStore
+	audit   audit.AuditService
+	logger  logger.Logger
+}
+
+func NewVerifyEmailChangeUseCase(auth auth.AuthService, changes auth.EmailChangeStore, audit audit.AuditService, logger logger.Logger) *VerifyEmailChangeUseCase {
+	return &VerifyEmailChangeUseCase{
+		auth:    auth,
+		changes: changes,
+		audit:   audit,
+		logger:  logger,
+	}
+}
+
+func (uc *VerifyEmailChangeUseCase) Execute(ctx context.Context, input auth.VerifyEmailChangeInput) (*auth.EmailChange, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	change, err := uc.changes.Get(ctx, input.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !change.IsPending() {
+		return nil, auth.ErrNoEmailChangePending
+	}
+
+	if err := uc.auth.VerifyCode(ctx, auth.VerifyCodeInput{
+		Username:   change.NewEmail,
+		Identifier: emailChangeCodeIdentifier,
+		Code:       input.Code,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := uc.auth.VerifyEmail(ctx, auth.VerifyEmailInput{Username: change.NewEmail}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	change.Status = auth.EmailChangeVerified
+	change.VerifiedAt = &now
+	if err := uc.changes.Save(ctx, change); err != nil {
+		return nil, err
+	}
+
+	if err := uc.audit.Record(ctx, audit.RecordInput{
+		Actor:   input.UserId,
+		Action:  auditActionChangeEmail,
+		Details: fmt.Sprintf("from=%s to=%s", change.PreviousEmail, change.NewEmail),
+	}); err != nil {
+		uc.logger.Error("Error recording change email audit entry: %s", err)
+	}
+	return change, nil
+}
+
+type GetEmailChangeUseCase struct {
+	changes auth.EmailChangeStore
+}
+
+func NewGetEmailChangeUseCase(changes auth.EmailChangeStore) *GetEmailChangeUseCase {
+	return &GetEmailChangeUseCase{
+		changes: changes,
+	}
+}
+
+// Execute reports NONE for users that never changed their email.
+func (uc *GetEmailChangeUseCase) Execute(ctx context.Context, userId string) (*auth.EmailChange, error) {
+	change, err := uc.changes.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return &auth.EmailChange{UserId: userId, Status: auth.EmailChangeNone}, nil
+	}
+	return change, nil
+}
+
+// requireVerifiedEmail fails with ErrEmailChangePending while the user behind
+// accessToken hasn't verified a requested email change yet.
+func requireVerifiedEmail(ctx context.Context, authService auth.AuthService, changes auth.EmailChangeStore, accessToken string) error {
+	me, err := authService.GetMe(ctx, auth.GetMeInput{AccessToken: accessToken})
+	if err != nil {
+		return err
+	}
+	change, err := changes.Get(ctx, me.Id)
+	if err != nil {
+		return err
+	}
+	if change.IsPending() {
+		return auth.ErrEmailChangePending
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+)
+
+const emailChangeSub = "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"
+
+var errWrongCode = app_error.NewApiError(400, "Invalid code")
+
+// memoryEmailChanges is an EmailChangeStore kept in a map.
+type memoryEmailChanges map[string]auth.EmailChange
+
+func (m memoryEmailChanges) Get(ctx context.Context, userId string) (*auth.EmailChange, error) {
+	change, ok := m[userId]
+	if !ok {
+		return nil, nil
+	}
+	return &change, nil
+}
+
+func (m memoryEmailChanges) Save(ctx context.Context, change *auth.EmailChange) error {
+	m[change.UserId] = *change
+	return nil
+}
+
+// mailboxAuth is a user pool holding one user, whose email codes are always
+// "123456".
+type mailboxAuth struct {
+	auth.AuthService
+	email         string
+	emailVerified bool
+	codesSentTo   []string
+	passwords     int
+}
+
+func (a *mailboxAuth) UpdateUserAttributes(ctx context.Context, input auth.UpdateUserAttributesInput) error {
+	a.email = *input.Email
+	a.emailVerified = *input.EmailVerified
+	return nil
+}
+
+func (a *mailboxAuth) GenerateAndSendCode(ctx context.Context, input auth.GenerateAndSendCodeInput) (*auth.GenerateAndSendCodeOutput, error) {
+	a.codesSentTo = append(a.codesSentTo, input.Username)
+	return &auth.GenerateAndSendCodeOutput{}, nil
+}
+
+func (a *mailboxAuth) VerifyCode(ctx context.Context, input auth.VerifyCodeInput) error {
+	if input.Code != "123456" || input.Username != a.email {
+		return errWrongCode
+	}
+	return nil
+}
+
+func (a *mailboxAuth) VerifyEmail(ctx context.Context, input auth.VerifyEmailInput) error {
+	a.emailVerified = true
+	return nil
+}
+
+func (a *mailboxAuth) GetMe(ctx context.Context, input auth.GetMeInput) (*auth.GetMeOutput, error) {
+	return &auth.GetMeOutput{Id: emailChangeSub, Username: a.email}, nil
+}
+
+func (a *mailboxAuth) ChangePassword(ctx context.Context, input auth.ChangePasswordInput) error {
+	a.passwords++
+	return nil
+}
+
+func TestEmailChangeStateTransitions(t *testing.T) {
+	ctx := context.Background()
+	authService := &mailboxAuth{email: "old@example.com", emailVerified: true}
+	changes := memoryEmailChanges{}
+	audits := &auditLog{}
+	request := NewRequestEmailChangeUseCase(authService, changes, logger.Nop{})
+	verify := NewVerifyEmailChangeUseCase(authService, changes, audits, logger.Nop{})
+	status := NewGetEmailChangeUseCase(changes)
+	changePassword := NewChangePasswordUseCase(authService, changes)
+	passwordInput := ChangePasswordInput{AccessToken: "access", OldPassword: "Old-Passw0rd", NewPassword: "New-Passw0rd"}
+
+	wantStatus := func(want auth.EmailChangeStatus) {
+		t.Helper()
+		got, err := status.Execute(ctx, emailChangeSub)
+		if err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		if got.Status != want {
+			t.Fatalf("status = %s, want %s", got.Status, want)
+		}
+	}
+
+	wantStatus(auth.EmailChangeNone)
+	if _, err := verify.Execute(ctx, auth.VerifyEmailChangeInput{UserId: emailChangeSub, Code: "123456"}); err != auth.ErrNoEmailChangePending {
+		t.Errorf("verify with nothing requested = %v, want %v", err, auth.ErrNoEmailChangePending)
+	}
+	if _, err := request.Execute(ctx, auth.RequestEmailChangeInput{UserId: emailChangeSub, CurrentEmail: "old@example.com", NewEmail: "old@example.com"}); err != auth.ErrEmailUnchanged {
+		t.Errorf("request for the current email = %v, want %v", err, auth.ErrEmailUnchanged)
+	}
+
+	// Requested: the pool has the new, unverified email and a code was sent
+	// to it.
+	out, err := request.Execute(ctx, auth.RequestEmailChangeInput{UserId: emailChangeSub, CurrentEmail: "old@example.com", NewEmail: "typo@example.com"})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if out.Status != auth.EmailChangePending || out.PreviousEmail != "old@example.com" || out.RequestedAt == nil {
+		t.Errorf("request output = %+v, want pending from old@example.com", out.EmailChange)
+	}
+	if authService.email != "typo@example.com" || authService.emailVerified {
+		t.Errorf("pool email = %s verified=%v, want the new one unverified", authService.email, authService.emailVerified)
+	}
+	wantStatus(auth.EmailChangePending)
+
+	// Pending: sensitive actions wait for the verification.
+	if err := changePassword.Execute(ctx, passwordInput); err != auth.ErrEmailChangePending {
+		t.Errorf("change password while pending = %v, want %v", err, auth.ErrEmailChangePending)
+	}
+
+	// Requesting again replaces the pending change but keeps the email the
+	// user started from, even though the token now has the unverified one.
+	out, err = request.Execute(ctx, auth.RequestEmailChangeInput{UserId: emailChangeSub, CurrentEmail: "typo@example.com", NewEmail: "new@example.com"})
+	if err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	if out.NewEmail != "new@example.com" || out.PreviousEmail != "old@example.com" {
+		t.Errorf("second request = %+v, want old@example.com -> new@example.com", out.EmailChange)
+	}
+	if len(authService.codesSentTo) != 2 || authService.codesSentTo[1] != "new@example.com" {
+		t.Errorf("codes sent to %v, want one per request", authService.codesSentTo)
+	}
+
+	// A wrong code leaves the change pending.
+	if _, err := verify.Execute(ctx, auth.VerifyEmailChangeInput{UserId: emailChangeSub, Code: "000000"}); err != errWrongCode {
+		t.Errorf("verify with a wrong code = %v, want %v", err, errWrongCode)
+	}
+	wantStatus(auth.EmailChangePending)
+
+	// Verified.
+	verified, err := verify.Execute(ctx, auth.VerifyEmailChangeInput{UserId: emailChangeSub, Code: "123456"})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if verified.Status != auth.EmailChangeVerified || verified.VerifiedAt == nil {
+		t.Errorf("verify output = %+v, want verified", verified)
+	}
+	if !authService.emailVerified {
+		t.Error("pool email left unverified")
+	}
+	wantStatus(auth.EmailChangeVerified)
+	if len(audits.entries) != 1 || audits.entries[0].Details != "from=old@example.com to=new@example.com" {
+		t.Errorf("audit entries = %+v, want the change recorded once", audits.entries)
+	}
+
+	if err := changePassword.Execute(ctx, passwordInput); err != nil || authService.passwords != 1 {
+		t.Errorf("change password once verified = %v, changed %d times", err, authService.passwords)
+	}
+	if _, err := verify.Execute(ctx, auth.VerifyEmailChangeInput{UserId: emailChangeSub, Code: "123456"}); err != auth.ErrNoEmailChangePending {
+		t.Errorf("verifying twice = %v, want %v", err, auth.ErrNoEmailChangePending)
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"context"
+)
+
+type GetSessionUseCase struct {
+	auth          auth.AuthService
+	sessions      session.SessionService
+	sessionLength *sessionLengthPolicy
+}
+
+type GetSessionInput struct {
+	Token string
+}
+
+func NewGetSessionUseCase(auth auth.AuthService, sessions session.SessionService, sessionLength *sessionLengthPolicy) *GetSessionUseCase {
+	return &GetSessionUseCase{
+		auth:          auth,
+		sessions:      sessions,
+		sessionLength: sessionLength,
+	}
+}
+
+func (uc *GetSessionUseCase) Execute(ctx context.Context, input GetSessionInput) (*auth.GetMeOutput, error) {
+	getInput := session.GetInput{
+		Token: input.Token,
+	}
+	if err := getInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess, err := uc.sessions.Get(ctx, getInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if sess.AccessTokenExpired() {
+		refreshInput := auth.RefreshTokenInput{
+			RefreshToken: sess.RefreshToken,
+		}
+		if err := refreshInput.Validate(); err != nil {
+			return nil, err
+		}
+
+		refreshOut, err := uc.auth.RefreshToken(ctx, refreshInput)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.sessionLength.check(ctx, sess.RefreshToken, refreshOut); err != nil {
+			if delErr := uc.sessions.DeleteByID(ctx, session.DeleteByIDInput{ID: sess.ID}); delErr != nil {
+				return nil, delErr
+			}
+			return nil, err
+		}
+
+		sess, err = uc.sessions.UpdateTokens(ctx, session.UpdateTokensInput{
+			Token:                input.Token,
+			AccessToken:          refreshOut.AccessToken,
+			IdToken:              refreshOut.IdToken,
+			AccessTokenExpiresAt: *refreshOut.AccessTokenExpiresAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	getMeInput := auth.GetMeInput{
+		AccessToken: sess.AccessToken,
+	}
+	if err := getMeInput.Validate(); err != nil {
+		return nil, err
+	}
+	return uc.auth.GetMe(ctx, getMeInput)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+const maxStatsWindow = 90 * 24 * time.Hour
+
+type GetStatsUseCase struct {
+	audit         audit.AuditService
+	logger        logger.Logger
+	defaultWindow time.Duration
+}
+
+type GetStatsInput struct {
+	// Window overrides the configured window when set.
+	Window time.Duration
+}
+
+func (input *GetStatsInput) Validate() error {
+	if input.Window < 0 || input.Window > maxStatsWindow {
+		return auth.NewValidationError("Window")
+	}
+	return nil
+}
+
+func NewGetStatsUseCase(audit audit.AuditService, logger logger.Logger, defaultWindow time.Duration) *GetStatsUseCase {
+	return &GetStatsUseCase{
+		audit:         audit,
+		logger:        logger,
+		defaultWindow: defaultWindow,
+	}
+}
+
+func (uc *GetStatsUseCase) Execute(ctx context.Context, input GetStatsInput) (*auth.StatsOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	window := input.Window
+	if window == 0 {
+		window = uc.defaultWindow
+	}
+	to := time.Now().UTC()
+	from := to.Add(-window)
+
+	counts, err := uc.audit.CountByAction(ctx, audit.CountByActionInput{
+		Actions: []string{
+			auth.AuditActionLoginSuccess,
+			auth.AuditActionLoginChallenge,
+			auth.AuditActionLoginFailure,
+			user.AuditActionSignup,
+		},
+		Since: from,
+	})
+	if err != nil {
+		uc.logger.Error("Error counting auth audit entries: %s", err)
+		return nil, err
+	}
+
+	activeMFAUsers, err := uc.audit.CountLatestState(ctx, audit.CountLatestStateInput{
+		Actions: []string{auth.AuditActionMFAEnabled, auth.AuditActionMFADisabled},
+		State:   auth.AuditActionMFAEnabled,
+	})
+	if err != nil {
+		uc.logger.Error("Error counting active MFA users: %s", err)
+		return nil, err
+	}
+
+	return aggregateStats(from, to, counts, activeMFAUsers), nil
+}
+
+// aggregateStats counts challenged logins as attempts but not as logins; the
+// challenge response isn't a new attempt.
+func aggregateStats(from, to time.Time, counts map[string]int64, activeMFAUsers int64) *auth.StatsOutput {
+	logins := counts[auth.AuditActionLoginSuccess]
+	failed := counts[auth.AuditActionLoginFailure]
+	attempts := logins + failed + counts[auth.AuditActionLoginChallenge]
+
+	var failedRate float64
+	if attempts > 0 {
+		failedRate = float64(failed) / float64(attempts)
+	}
+
+	return &auth.StatsOutput{
+		From:            from,
+		To:              to,
+		Logins:          logins,
+		FailedLogins:    failed,
+		Signups:         counts[user.AuditActionSignup],
+		ActiveMFAUsers:  activeMFAUsers,
+		FailedLoginRate: failedRate,
+	}
+}
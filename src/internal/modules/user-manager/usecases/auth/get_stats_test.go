@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type auditEntry struct {
+	action  string
+	details string
+	at      time.Time
+}
+
+// entriesAudit aggregates a fixed set of entries the way the audit table
+// does.
+type entriesAudit struct {
+	audit.AuditService
+	entries []auditEntry
+	err     error
+}
+
+func (a *entriesAudit) CountByAction(ctx context.Context, input audit.CountByActionInput) (map[string]int64, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	counts := map[string]int64{}
+	for _, entry := range a.entries {
+		for _, action := range input.Actions {
+			if entry.action == action && !entry.at.Before(input.Since) {
+				counts[action]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (a *entriesAudit) CountLatestState(ctx context.Context, input audit.CountLatestStateInput) (int64, error) {
+	latest := map[string]auditEntry{}
+	for _, entry := range a.entries {
+		for _, action := range input.Actions {
+			if entry.action == action && entry.at.After(latest[entry.details].at) {
+				latest[entry.details] = entry
+			}
+		}
+	}
+	var count int64
+	for _, entry := range latest {
+		if entry.action == input.State {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestGetStats(t *testing.T) {
+	now := time.Now()
+	hoursAgo := func(h int) time.Time { return now.Add(-time.Duration(h) * time.Hour) }
+
+	entries := []auditEntry{
+		{action: auth.AuditActionLoginSuccess, at: hoursAgo(1)},
+		{action: auth.AuditActionLoginSuccess, at: hoursAgo(2)},
+		{action: auth.AuditActionLoginSuccess, at: hoursAgo(30)},
+		{action: auth.AuditActionLoginChallenge, at: hoursAgo(1)},
+		{action: auth.AuditActionLoginFailure, at: hoursAgo(3)},
+		{action: auth.AuditActionLoginFailure, at: hoursAgo(40)},
+		{action: auth.AuditActionLoginFailure, at: hoursAgo(41)},
+		{action: user.AuditActionSignup, at: hoursAgo(5)},
+		{action: user.AuditActionSignup, at: hoursAgo(50)},
+		// a enabled MFA, b enabled then disabled it, c disabled then
+		// enabled again; MFA state isn't bound to the window.
+		{action: auth.AuditActionMFAEnabled, details: "a@example.com", at: hoursAgo(100)},
+		{action: auth.AuditActionMFAEnabled, details: "b@example.com", at: hoursAgo(10)},
+		{action: auth.AuditActionMFADisabled, details: "b@example.com", at: hoursAgo(9)},
+		{action: auth.AuditActionMFADisabled, details: "c@example.com", at: hoursAgo(8)},
+		{action: auth.AuditActionMFAEnabled, details: "c@example.com", at: hoursAgo(7)},
+	}
+
+	tests := []struct {
+		name       string
+		entries    []auditEntry
+		window     time.Duration
+		wantWindow time.Duration
+		want       auth.StatsOutput
+	}{
+		{
+			name:       "configured window",
+			entries:    entries,
+			wantWindow: 24 * time.Hour,
+			want:       auth.StatsOutput{Logins: 2, FailedLogins: 1, Signups: 1, ActiveMFAUsers: 2, FailedLoginRate: 0.25},
+		},
+		{
+			name:       "window from the request",
+			entries:    entries,
+			window:     72 * time.Hour,
+			wantWindow: 72 * time.Hour,
+			want:       auth.StatsOutput{Logins: 3, FailedLogins: 3, Signups: 2, ActiveMFAUsers: 2, FailedLoginRate: 3.0 / 7},
+		},
+		{
+			name:       "no attempts",
+			wantWindow: 24 * time.Hour,
+			want:       auth.StatsOutput{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewGetStatsUseCase(&entriesAudit{entries: tt.entries}, logger.Nop{}, 24*time.Hour)
+
+			got, err := uc.Execute(context.Background(), GetStatsInput{Window: tt.window})
+			if err != nil {
+				t.Fatalf("Execute = %v", err)
+			}
+			if got.To.Sub(got.From) != tt.wantWindow {
+				t.Errorf("window = %s, want %s", got.To.Sub(got.From), tt.wantWindow)
+			}
+			got.From, got.To = time.Time{}, time.Time{}
+			if *got != tt.want {
+				t.Errorf("stats = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStatsErrors(t *testing.T) {
+	auditErr := errors.New("audit unavailable")
+
+	tests := []struct {
+		name      string
+		window    time.Duration
+		auditErr  error
+		wantErr   error
+		wantField string
+	}{
+		{name: "negative window", window: -time.Hour, wantField: "Window"},
+		{name: "window past the limit", window: maxStatsWindow + time.Hour, wantField: "Window"},
+		{name: "audit store down", auditErr: auditErr, wantErr: auditErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewGetStatsUseCase(&entriesAudit{err: tt.auditErr}, logger.Nop{}, 24*time.Hour)
+			_, err := uc.Execute(context.Background(), GetStatsInput{Window: tt.window})
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantField != "" && !isFieldError(err, tt.wantField) {
+				t.Errorf("Execute = %v, want a 400 on %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+)
+
+type ListDevicesUseCase struct {
+	auth auth.AuthService
+}
+
+type ListDevicesInput struct {
+	auth.ListDevicesInput
+}
+
+func NewListDevicesUseCase(auth auth.AuthService) *ListDevicesUseCase {
+	return &ListDevicesUseCase{
+		auth: auth,
+	}
+}
+
+func (uc *ListDevicesUseCase) Execute(ctx context.Context, input ListDevicesInput) (*auth.ListDevicesOutput, error) {
+	if err := input.ListDevicesInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	return uc.auth.ListDevices(ctx, input.ListDevicesInput)
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"time"
+)
+
+// lockoutPolicy blocks password sign in for a username after threshold wrong
+// passwords within window. The lock lifts on its own once window has passed
+// since the last failure, or when an admin unlocks the user.
+type lockoutPolicy struct {
+	// threshold of zero disables the lockout.
+	threshold int
+	window    time.Duration
+	store     auth.LockoutStore
+	logger    logger.Logger
+}
+
+func newLockoutPolicy(threshold int, window time.Duration, store auth.LockoutStore, logger logger.Logger) *lockoutPolicy {
+	return &lockoutPolicy{
+		threshold: threshold,
+		window:    window,
+		store:     store,
+		logger:    logger,
+	}
+}
+
+func (p *lockoutPolicy) enabled() bool {
+	return p.threshold > 0 && p.store != nil
+}
+
+// lockedUntil returns when the lock on state lifts, and false when state
+// isn't locked at now.
+func (p *lockoutPolicy) lockedUntil(state auth.LockoutState, now time.Time) (time.Time, bool) {
+	if state.Failures < p.threshold {
+		return time.Time{}, false
+	}
+	until := state.LastFailureAt.Add(p.window)
+	return until, now.Before(until)
+}
+
+// check runs before the password is sent to Cognito. A store failure lets
+// the attempt through; Cognito's own throttling still applies.
+func (p *lockoutPolicy) check(ctx context.Context, username string) error {
+	if !p.enabled() {
+		return nil
+	}
+	state, err := p.store.Get(ctx, username)
+	if err != nil {
+		p.logger.Error("Error reading lockout state: %v", err)
+		return nil
+	}
+	if _, locked := p.lockedUntil(state, time.Now()); locked {
+		return auth.ErrAccountLocked
+	}
+	return nil
+}
+
+// record counts a wrong password and clears the counter on success. Other
+// failures, like an unconfirmed user, don't count.
+func (p *lockoutPolicy) record(ctx context.Context, username string, err error) {
+	if !p.enabled() {
+		return
+	}
+	switch {
+	case err == nil:
+		if resetErr := p.store.Reset(ctx, username); resetErr != nil {
+			p.logger.Error("Error resetting lockout state: %v", resetErr)
+		}
+	case errors.Is(err, auth.ErrInvalidUsernameOrPassword):
+		state, recordErr := p.store.RecordFailure(ctx, username, time.Now(), p.window)
+		if recordErr != nil {
+			p.logger.Error("Error recording failed sign in: %v", recordErr)
+			return
+		}
+		if state.Failures == p.threshold {
+			p.logger.Warning("Username locked out after %d failed sign ins", state.Failures)
+		}
+	}
+}
+
+func (p *lockoutPolicy) status(ctx context.Context, username string) (*auth.LockoutStatusOutput, error) {
+	out := &auth.LockoutStatusOutput{
+		Username: username,
+		Enabled:  p.enabled(),
+	}
+	if !out.Enabled {
+		return out, nil
+	}
+	state, err := p.store.Get(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	out.Failures = state.Failures
+	if until, locked := p.lockedUntil(state, time.Now()); locked {
+		out.Locked = true
+		out.LockedUntil = &until
+	}
+	return out, nil
+}
+
+func (p *lockoutPolicy) unlock(ctx context.Context, username string) error {
+	if !p.enabled() {
+		return nil
+	}
+	return p.store.Reset(ctx, username)
+}
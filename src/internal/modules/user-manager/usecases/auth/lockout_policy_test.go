@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLockoutStore keeps states in a map and fails every call once err is set.
+type fakeLockoutStore struct {
+	states map[string]auth.LockoutState
+	err    error
+}
+
+func newFakeLockoutStore() *fakeLockoutStore {
+	return &fakeLockoutStore{states: map[string]auth.LockoutState{}}
+}
+
+func (s *fakeLockoutStore) Get(ctx context.Context, username string) (auth.LockoutState, error) {
+	return s.states[username], s.err
+}
+
+func (s *fakeLockoutStore) RecordFailure(ctx context.Context, username string, now time.Time, window time.Duration) (auth.LockoutState, error) {
+	if s.err != nil {
+		return auth.LockoutState{}, s.err
+	}
+	state := s.states[username]
+	if state.Failures == 0 || now.Sub(state.FirstFailureAt) > window {
+		state = auth.LockoutState{FirstFailureAt: now}
+	}
+	state.Failures++
+	state.LastFailureAt = now
+	s.states[username] = state
+	return state, nil
+}
+
+func (s *fakeLockoutStore) Reset(ctx context.Context, username string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.states, username)
+	return nil
+}
+
+func TestLockoutPolicyLockedUntil(t *testing.T) {
+	policy := newLockoutPolicy(3, 15*time.Minute, newFakeLockoutStore(), logger.Nop{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		state      auth.LockoutState
+		wantLocked bool
+		wantUntil  time.Time
+	}{
+		{
+			name:  "no failures",
+			state: auth.LockoutState{},
+		},
+		{
+			name:  "below threshold",
+			state: auth.LockoutState{Failures: 2, LastFailureAt: now},
+		},
+		{
+			name:       "at threshold",
+			state:      auth.LockoutState{Failures: 3, LastFailureAt: now.Add(-time.Minute)},
+			wantLocked: true,
+			wantUntil:  now.Add(14 * time.Minute),
+		},
+		{
+			name:       "window counts from the last failure",
+			state:      auth.LockoutState{Failures: 5, FirstFailureAt: now.Add(-time.Hour), LastFailureAt: now.Add(-10 * time.Minute)},
+			wantLocked: true,
+			wantUntil:  now.Add(5 * time.Minute),
+		},
+		{
+			name:  "window passed",
+			state: auth.LockoutState{Failures: 3, LastFailureAt: now.Add(-15 * time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, locked := policy.lockedUntil(tt.state, now)
+			if locked != tt.wantLocked {
+				t.Fatalf("locked = %v, want %v", locked, tt.wantLocked)
+			}
+			if locked && !until.Equal(tt.wantUntil) {
+				t.Errorf("until = %v, want %v", until, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicy(t *testing.T) {
+	wrongPassword := auth.ErrInvalidUsernameOrPassword
+	otherFailure := auth.ErrUserNotConfirmed
+	storeDown := errors.New("store down")
+
+	tests := []struct {
+		name      string
+		threshold int
+		attempts  []error
+		storeErr  error
+		wantCheck error
+		// wantFailures is what status reports after the attempts.
+		wantFailures int
+	}{
+		{
+			name:         "locks at the threshold",
+			threshold:    3,
+			attempts:     []error{wrongPassword, wrongPassword, wrongPassword},
+			wantCheck:    auth.ErrAccountLocked,
+			wantFailures: 3,
+		},
+		{
+			name:         "below the threshold",
+			threshold:    3,
+			attempts:     []error{wrongPassword, wrongPassword},
+			wantFailures: 2,
+		},
+		{
+			name:         "a success clears the counter",
+			threshold:    3,
+			attempts:     []error{wrongPassword, wrongPassword, nil, wrongPassword},
+			wantFailures: 1,
+		},
+		{
+			name:      "only wrong passwords count",
+			threshold: 2,
+			attempts:  []error{otherFailure, otherFailure, otherFailure},
+		},
+		{
+			name:      "zero threshold disables it",
+			threshold: 0,
+			attempts:  []error{wrongPassword, wrongPassword, wrongPassword},
+		},
+		{
+			name:      "a failing store lets sign in through",
+			threshold: 1,
+			attempts:  []error{wrongPassword},
+			storeErr:  storeDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newFakeLockoutStore()
+			store.err = tt.storeErr
+			policy := newLockoutPolicy(tt.threshold, time.Hour, store, logger.Nop{})
+
+			for _, err := range tt.attempts {
+				policy.record(ctx, "user@example.com", err)
+			}
+
+			if err := policy.check(ctx, "user@example.com"); !errors.Is(err, tt.wantCheck) {
+				t.Fatalf("check = %v, want %v", err, tt.wantCheck)
+			}
+			if err := policy.check(ctx, "other@example.com"); err != nil {
+				t.Errorf("check for another username = %v, want nil", err)
+			}
+
+			status, err := policy.status(ctx, "user@example.com")
+			if tt.storeErr != nil {
+				if !errors.Is(err, tt.storeErr) {
+					t.Errorf("status err = %v, want %v", err, tt.storeErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Enabled != (tt.threshold > 0) {
+				t.Errorf("Enabled = %v, want %v", status.Enabled, tt.threshold > 0)
+			}
+			if status.Failures != tt.wantFailures {
+				t.Errorf("Failures = %d, want %d", status.Failures, tt.wantFailures)
+			}
+			wantLocked := tt.wantCheck != nil
+			if status.Locked != wantLocked || (status.LockedUntil != nil) != wantLocked {
+				t.Errorf("Locked = %v, LockedUntil = %v, want locked %v", status.Locked, status.LockedUntil, wantLocked)
+			}
+		})
+	}
+}
+
+func TestLockoutPolicyUnlock(t *testing.T) {
+	ctx := context.Background()
+	policy := newLockoutPolicy(1, time.Hour, newFakeLockoutStore(), logger.Nop{})
+
+	policy.record(ctx, "user@example.com", auth.ErrInvalidUsernameOrPassword)
+	if err := policy.check(ctx, "user@example.com"); !errors.Is(err, auth.ErrAccountLocked) {
+		t.Fatalf("check before unlock = %v, want ErrAccountLocked", err)
+	}
+	if err := policy.unlock(ctx, "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.check(ctx, "user@example.com"); err != nil {
+		t.Errorf("check after unlock = %v, want nil", err)
+	}
+}
@@ -1,28 +1,128 @@
 package auth
 
 import (
+	"auth-api/src/internal/events"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/app_error"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/logger"
 	"context"
+	"errors"
+	"time"
 )
 
 type LoginUseCase struct {
-	auth auth.AuthService
+	auth      auth.AuthService
+	events    events.EventDispatcher
+	logger    logger.Logger
+	mfaPolicy *adminMFAPolicy
+	challenge auth.PreAuthChallenge
+	lockout   *lockoutPolicy
+	limit     *sessionLimitPolicy
 }
 
 type LoginInput struct {
 	auth.LoginInput
+	IP             string
+	UserAgent      string
+	ChallengeToken string
 }
 
-func NewLoginUseCase(auth auth.AuthService) *LoginUseCase {
+func NewLoginUseCase(auth auth.AuthService, events events.EventDispatcher, logger logger.Logger, mfaPolicy *adminMFAPolicy, challenge auth.PreAuthChallenge, lockout *lockoutPolicy, limit *sessionLimitPolicy) *LoginUseCase {
 	return &LoginUseCase{
-		auth: auth,
+		auth:      auth,
+		events:    events,
+		logger:    logger,
+		mfaPolicy: mfaPolicy,
+		challenge: challenge,
+		lockout:   lockout,
+		limit:     limit,
 	}
 }
 
 func (uc *LoginUseCase) Execute(ctx context.Context, input LoginInput) (*auth.LoginOutput, error) {
+	return uc.login(ctx, input, true)
+}
+
+// executeAfterConfirmation signs in a user who has just confirmed their
+// account with a code sent to them, which stands in for the bot challenge.
+// The lockout, the admin MFA policy and the audit event apply as they do to
+// any other login.
+func (uc *LoginUseCase) executeAfterConfirmation(ctx context.Context, input LoginInput) (*auth.LoginOutput, error) {
+	return uc.login(ctx, input, false)
+}
+
+func (uc *LoginUseCase) login(ctx context.Context, input LoginInput, verifyChallenge bool) (*auth.LoginOutput, error) {
 	if err := input.LoginInput.Validate(); err != nil {
 		return nil, err
 	}
 
-	return uc.auth.Login(ctx, input.LoginInput)
+	if err := uc.lockout.check(ctx, input.Username); err != nil {
+		uc.dispatchAttempt(input, nil, err)
+		return nil, err
+	}
+
+	if verifyChallenge {
+		err := uc.challenge.Verify(ctx, auth.PreAuthChallengeInput{
+			Token:  input.ChallengeToken,
+			IP:     input.IP,
+			Action: auth.PreAuthActionLogin,
+		})
+		if err != nil {
+			uc.dispatchAttempt(input, nil, err)
+			return nil, err
+		}
+	}
+
+	output, err := uc.auth.Login(ctx, input.LoginInput)
+	uc.lockout.record(ctx, input.Username, err)
+	if err == nil {
+		output, err = uc.mfaPolicy.apply(ctx, input.Username, output)
+	}
+	if err == nil {
+		if err = uc.limit.track(ctx, input.Username, output); err != nil {
+			output = nil
+		}
+	}
+	if err == nil && output != nil && output.NextStep != nil {
+		correlationId := auth.NewCorrelationId()
+		output.CorrelationId = &correlationId
+		uc.logger.Info("Login challenge issued challenge=%s correlation_id=%s", *output.NextStep, correlationId)
+	}
+	uc.dispatchAttempt(input, output, err)
+	return output, err
+}
+
+func (uc *LoginUseCase) dispatchAttempt(input LoginInput, output *auth.LoginOutput, err error) {
+	dispatchLoginAttempt(uc.events, uc.logger, input, output, err)
+}
+
+// dispatchLoginAttempt only hands the attempt to the dispatcher, which
+// delivers it in the background so a slow SIEM never delays the login
+// response. Every password sign in path reports through it.
+func dispatchLoginAttempt(dispatcher events.EventDispatcher, logger logger.Logger, input LoginInput, output *auth.LoginOutput, err error) {
+	event := &auth.LoginAttemptedEvent{
+		Username:   input.Username,
+		IP:         input.IP,
+		UserAgent:  input.UserAgent,
+		Outcome:    auth.LoginOutcomeSuccess,
+		OccurredAt: time.Now().UTC(),
+	}
+	switch {
+	case err != nil:
+		event.Outcome = auth.LoginOutcomeFailure
+		event.Reason = "ERROR"
+		var apiErr *app_error.ApiError
+		if errors.As(err, &apiErr) {
+			event.Reason = apiErr.Code()
+		}
+	case output != nil && output.NextStep != nil:
+		event.Outcome = auth.LoginOutcomeChallenge
+		event.Reason = *output.NextStep
+		event.CorrelationId = deref.String(output.CorrelationId)
+	}
+
+	if dispatchErr := dispatcher.Dispatch(event); dispatchErr != nil {
+		logger.Debug("Login attempt event not dispatched: %v", dispatchErr)
+	}
 }
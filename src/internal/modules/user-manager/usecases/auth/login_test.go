@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoginPreAuthChallenge(t *testing.T) {
+	tests := []struct {
+		name       string
+		verifyErr  error
+		wantErr    error
+		wantLogins int
+	}{
+		{name: "challenge passed", wantLogins: 1},
+		{name: "challenge failed", verifyErr: auth.ErrChallengeFailed, wantErr: auth.ErrChallengeFailed},
+		{name: "provider unavailable", verifyErr: auth.ErrChallengeUnavailable, wantErr: auth.ErrChallengeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified []auth.PreAuthChallengeInput
+			challenge := auth.PreAuthChallengeFunc(func(ctx context.Context, input auth.PreAuthChallengeInput) error {
+				verified = append(verified, input)
+				return tt.verifyErr
+			})
+			authService := &confirmAuth{groups: []string{string(auth.GroupUser)}}
+			dispatcher := &recordingDispatcher{}
+			mfaPolicy := newAdminMFAPolicy(false, authService, enrollmentSessions{}, logger.Nop{}, time.Minute)
+			lockout := newLockoutPolicy(5, time.Hour, newFakeLockoutStore(), logger.Nop{})
+			limit := newSessionLimitPolicy(0, SessionLimitModeReject, authService, &memorySessions{}, logger.Nop{}, false)
+			uc := NewLoginUseCase(authService, dispatcher, logger.Nop{}, mfaPolicy, challenge, lockout, limit)
+
+			out, err := uc.Execute(context.Background(), LoginInput{
+				LoginInput:     auth.LoginInput{Username: "someone@example.com", Password: "Str0ng!Passw0rd"},
+				ChallengeToken: "captcha-token",
+				IP:             "203.0.113.7",
+			})
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if authService.logins != tt.wantLogins {
+				t.Errorf("Cognito logins = %d, want %d", authService.logins, tt.wantLogins)
+			}
+			if tt.wantErr != nil && out != nil {
+				t.Errorf("output = %+v, want no tokens", out)
+			}
+			if len(verified) != 1 || verified[0] != (auth.PreAuthChallengeInput{Token: "captcha-token", IP: "203.0.113.7", Action: auth.PreAuthActionLogin}) {
+				t.Errorf("verified %+v, want the login token once", verified)
+			}
+			if len(dispatcher.events) != 1 {
+				t.Errorf("dispatched %d login attempts, want 1", len(dispatcher.events))
+			}
+		})
+	}
+}
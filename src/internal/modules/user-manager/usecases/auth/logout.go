@@ -6,23 +6,40 @@ import (
 )
 
 type LogoutUseCase struct {
-	auth auth.AuthService
+	auth  auth.AuthService
+	limit *sessionLimitPolicy
 }
 
 type LogoutInput struct {
 	auth.LogoutInput
 }
 
-func NewLogoutUseCase(auth auth.AuthService) *LogoutUseCase {
+func NewLogoutUseCase(auth auth.AuthService, limit *sessionLimitPolicy) *LogoutUseCase {
 	return &LogoutUseCase{
-		auth: auth,
+		auth:  auth,
+		limit: limit,
 	}
 }
 
+// Execute signs the user out everywhere, which also ends the sign ins
+// tracked against the session limit.
 func (uc *LogoutUseCase) Execute(ctx context.Context, input LogoutInput) error {
 	if err := input.LogoutInput.Validate(); err != nil {
 		return err
 	}
 
-	return uc.auth.Logout(ctx, input.LogoutInput)
+	var username string
+	if uc.limit.maxSessions > 0 {
+		if me, err := uc.auth.GetMe(ctx, auth.GetMeInput{AccessToken: input.AccessToken}); err == nil {
+			username = me.Username
+		}
+	}
+
+	if err := uc.auth.Logout(ctx, input.LogoutInput); err != nil {
+		return err
+	}
+	if username != "" {
+		uc.limit.release(ctx, username)
+	}
+	return nil
 }
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+)
+
+// recordMFAChange keeps the MFA_ENABLED/MFA_DISABLED trail the admin stats are
+// built from. The change already happened in Cognito, so a failure here is
+// only logged.
+func recordMFAChange(ctx context.Context, auditService audit.AuditService, logger logger.Logger, actor, username, action string) {
+	if err := auditService.Record(ctx, audit.RecordInput{
+		Actor:   actor,
+		Action:  action,
+		Details: username,
+	}); err != nil {
+		logger.Error("Error recording %s audit entry: %s", action, err)
+	}
+}
@@ -2,20 +2,28 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/jwt_verify"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type RefreshTokenUseCase struct {
-	auth auth.AuthService
+	auth          auth.AuthService
+	sessionLength *sessionLengthPolicy
+	logger        logger.Logger
 }
 
 type RefreshTokenInput struct {
 	auth.RefreshTokenInput
+	// IncludeClaims decodes the refreshed id token into the output.
+	IncludeClaims bool
 }
 
-func NewRefreshTokenUseCase(auth auth.AuthService) *RefreshTokenUseCase {
+func NewRefreshTokenUseCase(auth auth.AuthService, sessionLength *sessionLengthPolicy, logger logger.Logger) *RefreshTokenUseCase {
 	return &RefreshTokenUseCase{
-		auth: auth,
+		auth:          auth,
+		sessionLength: sessionLength,
+		logger:        logger,
 	}
 }
 
@@ -24,5 +32,37 @@ func (uc *RefreshTokenUseCase) Execute(ctx context.Context, input RefreshTokenIn
 		return nil, err
 	}
 
-	return uc.auth.RefreshToken(ctx, input.RefreshTokenInput)
+	out, err := uc.auth.RefreshToken(ctx, input.RefreshTokenInput)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sessionLength.check(ctx, input.RefreshToken, out); err != nil {
+		return nil, err
+	}
+	if input.IncludeClaims {
+		out.Claims = uc.refreshedClaims(out.IdToken)
+	}
+	return out, nil
+}
+
+// refreshedClaims reads the id token Cognito just issued, so it isn't
+// verified again. A token that can't be decoded only costs the client the
+// claims, not the refresh.
+func (uc *RefreshTokenUseCase) refreshedClaims(idToken string) *auth.RefreshedClaims {
+	claims, err := jwt_verify.ParseUnverifiedClaims(idToken)
+	if err != nil {
+		uc.logger.Warning("Could not decode refreshed id token: %v", err)
+		return nil
+	}
+	groups := claims.UserGroups
+	if groups == nil {
+		groups = []string{}
+	}
+	return &auth.RefreshedClaims{
+		Id:            claims.Sub,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Groups:        groups,
+	}
 }
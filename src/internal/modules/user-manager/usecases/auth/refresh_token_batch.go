@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"sync"
+)
+
+const (
+	maxRefreshTokenBatchSize    = 100
+	refreshTokenBatchMaxWorkers = 10
+)
+
+// RefreshTokenBatchUseCase refreshes many sessions in one call for services
+// that hold tokens on behalf of their users. Every token goes through the
+// regular refresh, so the session length policy still applies to each one.
+type RefreshTokenBatchUseCase struct {
+	refreshToken *RefreshTokenUseCase
+	logger       logger.Logger
+}
+
+type RefreshTokenBatchInput struct {
+	RefreshTokens []string
+}
+
+func (input *RefreshTokenBatchInput) Validate() error {
+	if len(input.RefreshTokens) == 0 || len(input.RefreshTokens) > maxRefreshTokenBatchSize {
+		return auth.NewValidationError("RefreshTokens")
+	}
+	return nil
+}
+
+// RefreshTokenBatchResult is the outcome of one token, at the same index it
+// had in the input. Exactly one of Tokens and Err is set.
+type RefreshTokenBatchResult struct {
+	Tokens *auth.RefreshTokenOutput
+	Err    error
+}
+
+type RefreshTokenBatchOutput struct {
+	Results []RefreshTokenBatchResult
+}
+
+func NewRefreshTokenBatchUseCase(refreshToken *RefreshTokenUseCase, logger logger.Logger) *RefreshTokenBatchUseCase {
+	return &RefreshTokenBatchUseCase{
+		refreshToken: refreshToken,
+		logger:       logger,
+	}
+}
+
+// Execute refreshes the tokens with at most refreshTokenBatchMaxWorkers
+// requests in flight. A token that fails only fails its own result; the
+// rest of the batch carries on.
+func (uc *RefreshTokenBatchUseCase) Execute(ctx context.Context, input RefreshTokenBatchInput) (*RefreshTokenBatchOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]RefreshTokenBatchResult, len(input.RefreshTokens))
+	slots := make(chan struct{}, refreshTokenBatchMaxWorkers)
+	var wg sync.WaitGroup
+	for i, token := range input.RefreshTokens {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			out, err := uc.refreshToken.Execute(ctx, RefreshTokenInput{
+				RefreshTokenInput: auth.RefreshTokenInput{RefreshToken: token},
+			})
+			results[i] = RefreshTokenBatchResult{Tokens: out, Err: err}
+		}(i, token)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		uc.logger.Info("Refresh token batch: %d of %d tokens failed", failed, len(results))
+	}
+	return &RefreshTokenBatchOutput{Results: results}, nil
+}
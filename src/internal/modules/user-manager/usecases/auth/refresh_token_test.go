@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// reissuingAuth refreshes into the id token it was given, the way Cognito
+// issues one with the user's current groups and attributes.
+type reissuingAuth struct {
+	auth.AuthService
+	idToken string
+}
+
+func (a *reissuingAuth) RefreshToken(ctx context.Context, input auth.RefreshTokenInput) (*auth.RefreshTokenOutput, error) {
+	return &auth.RefreshTokenOutput{AccessToken: "access", IdToken: a.idToken}, nil
+}
+
+func idTokenFor(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("not checked"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRefreshTokenIncludeClaims(t *testing.T) {
+	before := idTokenFor(t, jwt.MapClaims{
+		"sub": "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", "email": "member@example.com", "email_verified": false,
+		"name": "Member", "cognito:groups": []string{"user"},
+	})
+	after := idTokenFor(t, jwt.MapClaims{
+		"sub": "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", "email": "member@example.com", "email_verified": true,
+		"name": "Renamed Member", "cognito:groups": []string{"user", "admin"},
+	})
+	noGroups := idTokenFor(t, jwt.MapClaims{"sub": "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", "email": "member@example.com"})
+
+	tests := []struct {
+		name          string
+		idToken       string
+		includeClaims bool
+		want          *auth.RefreshedClaims
+	}{
+		{name: "claims not asked for", idToken: after},
+		{
+			name:          "claims before the change",
+			idToken:       before,
+			includeClaims: true,
+			want:          &auth.RefreshedClaims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", Email: "member@example.com", Name: "Member", Groups: []string{"user"}},
+		},
+		{
+			name:          "changed groups and attributes surfaced",
+			idToken:       after,
+			includeClaims: true,
+			want:          &auth.RefreshedClaims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", Email: "member@example.com", EmailVerified: true, Name: "Renamed Member", Groups: []string{"user", "admin"}},
+		},
+		{
+			name:          "no groups is an empty list",
+			idToken:       noGroups,
+			includeClaims: true,
+			want:          &auth.RefreshedClaims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", Email: "member@example.com", Groups: []string{}},
+		},
+		{name: "undecodable id token only costs the claims", idToken: "not-a-jwt", includeClaims: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &reissuingAuth{idToken: tt.idToken}
+			uc := NewRefreshTokenUseCase(authService, newSessionLengthPolicy(0, authService, logger.Nop{}), logger.Nop{})
+
+			out, err := uc.Execute(context.Background(), RefreshTokenInput{
+				RefreshTokenInput: auth.RefreshTokenInput{RefreshToken: "refresh"},
+				IncludeClaims:     tt.includeClaims,
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if out.AccessToken != "access" || out.IdToken != tt.idToken {
+				t.Errorf("tokens = %q %q, want them passed on", out.AccessToken, out.IdToken)
+			}
+			if tt.want == nil {
+				if out.Claims != nil {
+					t.Errorf("Claims = %+v, want none", out.Claims)
+				}
+				return
+			}
+			if out.Claims == nil {
+				t.Fatal("Claims = nil")
+			}
+			got := *out.Claims
+			if got.Id != tt.want.Id || got.Email != tt.want.Email || got.EmailVerified != tt.want.EmailVerified || got.Name != tt.want.Name {
+				t.Errorf("Claims = %+v, want %+v", got, *tt.want)
+			}
+			if got.Groups == nil || len(got.Groups) != len(tt.want.Groups) {
+				t.Fatalf("Groups = %#v, want %#v", got.Groups, tt.want.Groups)
+			}
+			for i := range got.Groups {
+				if got.Groups[i] != tt.want.Groups[i] {
+					t.Errorf("Groups = %v, want %v", got.Groups, tt.want.Groups)
+				}
+			}
+		})
+	}
+}
@@ -2,20 +2,26 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type RemoveMFAUseCase struct {
-	auth auth.AuthService
+	auth   auth.AuthService
+	audit  audit.AuditService
+	logger logger.Logger
 }
 
 type RemoveMFAInput struct {
 	auth.RemoveMFAInput
 }
 
-func NewRemoveMFAUseCase(auth auth.AuthService) *RemoveMFAUseCase {
+func NewRemoveMFAUseCase(auth auth.AuthService, audit audit.AuditService, logger logger.Logger) *RemoveMFAUseCase {
 	return &RemoveMFAUseCase{
-		auth: auth,
+		auth:   auth,
+		audit:  audit,
+		logger: logger,
 	}
 }
 
@@ -24,5 +30,15 @@ func (uc *RemoveMFAUseCase) Execute(ctx context.Context, input RemoveMFAInput) e
 		return err
 	}
 
-	return uc.auth.RemoveMFA(ctx, input.RemoveMFAInput)
+	if err := uc.auth.RemoveMFA(ctx, input.RemoveMFAInput); err != nil {
+		return err
+	}
+
+	me, err := uc.auth.GetMe(ctx, auth.GetMeInput{AccessToken: input.AccessToken})
+	if err != nil {
+		uc.logger.Error("Error resolving user for MFA audit entry: %s", err)
+		return nil
+	}
+	recordMFAChange(ctx, uc.audit, uc.logger, me.Username, me.Username, auth.AuditActionMFADisabled)
+	return nil
 }
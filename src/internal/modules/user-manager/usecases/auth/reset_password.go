@@ -13,6 +13,8 @@ type ResetPasswordInput struct {
 	Username    string
 	Code        string
 	NewPassword string
+	// PasswordConfirmation is optional; see auth.ChangeForgotPasswordInput.
+	PasswordConfirmation *string
 }
 
 func NewResetPasswordUseCase(auth auth.AuthService) *ResetPasswordUseCase {
@@ -32,8 +34,9 @@ func (uc *ResetPasswordUseCase) Execute(ctx context.Context, input ResetPassword
 	}
 
 	changeForgotInput := auth.ChangeForgotPasswordInput{
-		Username:    input.Username,
-		NewPassword: input.NewPassword,
+		Username:             input.Username,
+		NewPassword:          input.NewPassword,
+		PasswordConfirmation: input.PasswordConfirmation,
 	}
 	if err := changeForgotInput.Validate(); err != nil {
 		return err
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"testing"
+)
+
+// resettingAuth accepts any code and records the passwords changed.
+type resettingAuth struct {
+	auth.AuthService
+	verified int
+	changed  []auth.ChangeForgotPasswordInput
+}
+
+func (a *resettingAuth) VerifyCode(context.Context, auth.VerifyCodeInput) error {
+	a.verified++
+	return nil
+}
+
+func (a *resettingAuth) ChangeForgotPassword(ctx context.Context, input auth.ChangeForgotPasswordInput) error {
+	a.changed = append(a.changed, input)
+	return nil
+}
+
+func TestResetPasswordConfirmation(t *testing.T) {
+	same, other := "Str0ng!Passw0rd", "Str0ng!Passw0rd2"
+
+	tests := []struct {
+		name         string
+		confirmation *string
+		wantErr      error
+	}{
+		{name: "matching", confirmation: &same},
+		{name: "omitted"},
+		{name: "mismatching", confirmation: &other, wantErr: auth.ErrPasswordMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &resettingAuth{}
+			uc := NewResetPasswordUseCase(authService)
+
+			err := uc.Execute(context.Background(), ResetPasswordInput{
+				Username:             "someone@example.com",
+				Code:                 "123456",
+				NewPassword:          same,
+				PasswordConfirmation: tt.confirmation,
+			})
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				// The code isn't spent on a request that was never going
+				// to change the password.
+				if authService.verified != 0 || len(authService.changed) != 0 {
+					t.Errorf("verified %d codes and changed %d passwords on a mismatch", authService.verified, len(authService.changed))
+				}
+				return
+			}
+			if len(authService.changed) != 1 || authService.changed[0].NewPassword != same {
+				t.Errorf("changed %+v, want the new password once", authService.changed)
+			}
+		})
+	}
+
+	if code := auth.ErrPasswordMismatch.Code(); code != "PASSWORD_MISMATCH" || auth.ErrPasswordMismatch.StatusCode != 400 {
+		t.Errorf("ErrPasswordMismatch = %d %s, want 400 PASSWORD_MISMATCH", auth.ErrPasswordMismatch.StatusCode, code)
+	}
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/code_generator"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/logger"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const selfCheckEmailDomain = "example.com"
+
+// SelfCheckUseCase runs a whole sign in against the user pool with a throwaway
+// user, so a wrong client setting or a disabled auth flow shows up at startup
+// rather than on the first real login. It creates users in the pool and must
+// never run in production.
+type SelfCheckUseCase struct {
+	auth   auth.AuthService
+	logger logger.Logger
+}
+
+func NewSelfCheckUseCase(auth auth.AuthService, logger logger.Logger) *SelfCheckUseCase {
+	return &SelfCheckUseCase{
+		auth:   auth,
+		logger: logger,
+	}
+}
+
+// Execute creates the user without sending an invite, confirms it by setting
+// a permanent password, signs in, validates the access token and deletes the
+// user. The user is deleted even when a step fails.
+func (uc *SelfCheckUseCase) Execute(ctx context.Context) (execErr error) {
+	suffix, err := code_generator.GenerateCode(12, true)
+	if err != nil {
+		return err
+	}
+	username := fmt.Sprintf("self-check-%s@%s", strings.ToLower(suffix), selfCheckEmailDomain)
+	temporaryPassword, err := selfCheckPassword()
+	if err != nil {
+		return err
+	}
+	password, err := selfCheckPassword()
+	if err != nil {
+		return err
+	}
+
+	step := func(name string, run func() error) error {
+		started := time.Now()
+		if err := run(); err != nil {
+			uc.logger.Error("Self-check step %s failed after %s: %s", name, time.Since(started), err)
+			return fmt.Errorf("self-check %s: %w", name, err)
+		}
+		uc.logger.Info("Self-check step %s ok in %s", name, time.Since(started))
+		return nil
+	}
+
+	uc.logger.Info("Running auth self-check with throwaway user %s", username)
+
+	if err := step("create", func() error {
+		return uc.auth.AdminCreateUser(ctx, auth.AdminCreateUserInput{
+			Username:          username,
+			Name:              "Self Check",
+			TemporaryPassword: temporaryPassword,
+			SuppressInvite:    true,
+		})
+	}); err != nil {
+		return err
+	}
+	defer func() {
+		// The caller's ctx may already be done when a step timed out.
+		if err := step("delete", func() error {
+			return uc.auth.DeleteUser(context.WithoutCancel(ctx), auth.DeleteUserInput{Username: username})
+		}); err != nil && execErr == nil {
+			execErr = err
+		}
+	}()
+
+	if err := step("confirm", func() error {
+		return uc.auth.AdminSetPermanentPassword(ctx, auth.AdminSetPermanentPasswordInput{
+			Username:    username,
+			NewPassword: password,
+		})
+	}); err != nil {
+		return err
+	}
+
+	var accessToken string
+	if err := step("login", func() error {
+		out, err := uc.auth.Login(ctx, auth.LoginInput{
+			Username: username,
+			Password: password,
+		})
+		if err != nil {
+			return err
+		}
+		if out.NextStep != nil {
+			return fmt.Errorf("unexpected challenge %s", *out.NextStep)
+		}
+		accessToken = deref.String(out.AccessToken)
+		if accessToken == "" {
+			return auth.ErrAuthenticationResultNil
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := step("validate token", func() error {
+		claims, err := uc.auth.ValidateToken(ctx, accessToken)
+		if err != nil {
+			return err
+		}
+		if claims.Id == "" {
+			return fmt.Errorf("token has no subject")
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	uc.logger.Info("Auth self-check passed")
+	return nil
+}
+
+// selfCheckPassword covers every character class a pool policy can require.
+func selfCheckPassword() (string, error) {
+	code, err := code_generator.GenerateCode(20, true)
+	if err != nil {
+		return "", err
+	}
+	return "Aa1!" + code, nil
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// selfCheckAuth records the steps of a self-check and fails the ones set up
+// in errs. cancel, when set, runs as login fails, like a step timing out.
+type selfCheckAuth struct {
+	auth.AuthService
+	errs      map[string]error
+	challenge *string
+	cancel    context.CancelFunc
+	calls     []string
+	created   string
+	deleted   string
+	deleteCtx error
+}
+
+func (a *selfCheckAuth) step(name string) error {
+	a.calls = append(a.calls, name)
+	return a.errs[name]
+}
+
+func (a *selfCheckAuth) AdminCreateUser(ctx context.Context, input auth.AdminCreateUserInput) error {
+	a.created = input.Username
+	if !input.SuppressInvite {
+		return errors.New("invite sent to a throwaway user")
+	}
+	return a.step("create")
+}
+
+func (a *selfCheckAuth) AdminSetPermanentPassword(ctx context.Context, input auth.AdminSetPermanentPasswordInput) error {
+	return a.step("confirm")
+}
+
+func (a *selfCheckAuth) Login(ctx context.Context, input auth.LoginInput) (*auth.LoginOutput, error) {
+	if err := a.step("login"); err != nil {
+		if a.cancel != nil {
+			a.cancel()
+		}
+		return nil, err
+	}
+	if a.challenge != nil {
+		return &auth.LoginOutput{NextStep: a.challenge}, nil
+	}
+	accessToken := "access"
+	return &auth.LoginOutput{AccessToken: &accessToken}, nil
+}
+
+func (a *selfCheckAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	if err := a.step("validate"); err != nil {
+		return nil, err
+	}
+	return &auth.Claims{Id: "6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e"}, nil
+}
+
+func (a *selfCheckAuth) DeleteUser(ctx context.Context, input auth.DeleteUserInput) error {
+	a.deleted = input.Username
+	a.deleteCtx = ctx.Err()
+	return a.step("delete")
+}
+
+func TestSelfCheck(t *testing.T) {
+	stepErr := errors.New("step failed")
+	deleteErr := errors.New("delete failed")
+	challenge := "SOFTWARE_TOKEN_MFA"
+
+	tests := []struct {
+		name      string
+		errs      map[string]error
+		challenge *string
+		cancel    bool
+		wantCalls []string
+		wantErr   error
+		wantStep  string
+	}{
+		{
+			name:      "passes and cleans up",
+			wantCalls: []string{"create", "confirm", "login", "validate", "delete"},
+		},
+		{
+			name:      "nothing to clean up when create fails",
+			errs:      map[string]error{"create": stepErr},
+			wantCalls: []string{"create"},
+			wantErr:   stepErr,
+			wantStep:  "create",
+		},
+		{
+			name:      "confirm fails",
+			errs:      map[string]error{"confirm": stepErr},
+			wantCalls: []string{"create", "confirm", "delete"},
+			wantErr:   stepErr,
+			wantStep:  "confirm",
+		},
+		{
+			name:      "login fails",
+			errs:      map[string]error{"login": stepErr},
+			wantCalls: []string{"create", "confirm", "login", "delete"},
+			wantErr:   stepErr,
+			wantStep:  "login",
+		},
+		{
+			name:      "login asks for a challenge",
+			challenge: &challenge,
+			wantCalls: []string{"create", "confirm", "login", "delete"},
+			wantStep:  "login",
+		},
+		{
+			name:      "token is refused",
+			errs:      map[string]error{"validate": stepErr},
+			wantCalls: []string{"create", "confirm", "login", "validate", "delete"},
+			wantErr:   stepErr,
+			wantStep:  "validate token",
+		},
+		{
+			name:      "delete still runs once the context is done",
+			errs:      map[string]error{"login": context.DeadlineExceeded},
+			cancel:    true,
+			wantCalls: []string{"create", "confirm", "login", "delete"},
+			wantErr:   context.DeadlineExceeded,
+			wantStep:  "login",
+		},
+		{
+			name:      "delete failing fails a passing check",
+			errs:      map[string]error{"delete": deleteErr},
+			wantCalls: []string{"create", "confirm", "login", "validate", "delete"},
+			wantErr:   deleteErr,
+			wantStep:  "delete",
+		},
+		{
+			name:      "the first failure is kept over the delete one",
+			errs:      map[string]error{"login": stepErr, "delete": deleteErr},
+			wantCalls: []string{"create", "confirm", "login", "delete"},
+			wantErr:   stepErr,
+			wantStep:  "login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			authService := &selfCheckAuth{errs: tt.errs, challenge: tt.challenge}
+			if tt.cancel {
+				authService.cancel = cancel
+			}
+
+			err := NewSelfCheckUseCase(authService, logger.Nop{}).Execute(ctx)
+
+			if !reflect.DeepEqual(authService.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", authService.calls, tt.wantCalls)
+			}
+			if tt.wantStep == "" {
+				if err != nil {
+					t.Fatalf("Execute = %v, want nil", err)
+				}
+			} else {
+				if err == nil || !strings.HasPrefix(err.Error(), "self-check "+tt.wantStep+":") {
+					t.Errorf("Execute = %v, want a %s failure", err, tt.wantStep)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Execute = %v, want it to wrap %v", err, tt.wantErr)
+				}
+			}
+			if tt.wantCalls[len(tt.wantCalls)-1] != "delete" {
+				return
+			}
+			if authService.deleted != authService.created || !strings.HasPrefix(authService.created, "self-check-") {
+				t.Errorf("deleted %q, want the created self-check user %q", authService.deleted, authService.created)
+			}
+			if authService.deleteCtx != nil {
+				t.Errorf("delete ran with a done context: %v", authService.deleteCtx)
+			}
+		})
+	}
+}
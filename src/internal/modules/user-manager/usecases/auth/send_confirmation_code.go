@@ -19,45 +19,53 @@ func NewSendConfirmationCodeUseCase(logger logger.Logger, auth auth.AuthService)
 }
 
 type SendConfirmationCodeInput struct {
-	Username string
+	Username       string
+	DeliveryMedium auth.DeliveryMedium
 }
 
-func (sc *SendConfirmationCodeUseCase) Execute(ctx context.Context, input SendConfirmationCodeInput) error {
+type SendConfirmationCodeOutput struct {
+	CodeDelivery auth.CodeDeliveryDetails `json:"codeDelivery"`
+}
+
+func (sc *SendConfirmationCodeUseCase) Execute(ctx context.Context, input SendConfirmationCodeInput) (*SendConfirmationCodeOutput, error) {
 	getUserInput := auth.GetUserInput{
 		Username: input.Username,
 	}
 	if err := getUserInput.Validate(); err != nil {
-		return err
+		return nil, err
 	}
 
 	getUserOutput, err := sc.auth.GetUser(ctx, getUserInput)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if getUserOutput == nil {
-		return auth.ErrUserNotFound
+		return nil, auth.ErrUserNotFound
 	}
 
 	if getUserOutput.Status == auth.Confirmed {
-		return auth.ErrUserAlreadyConfirmed
+		return nil, auth.ErrUserAlreadyConfirmed
 	}
 
 	generateAndSaveInput := auth.GenerateAndSendCodeInput{
-		Username:   input.Username,
-		Identifier: "CONFIRMATION_CODE",
-		Subject:    "Please confirm your email",
-		Body:       "Your confirmation code is: %s",
+		Username:       input.Username,
+		Identifier:     "CONFIRMATION_CODE",
+		Subject:        "Please confirm your email",
+		Body:           "Your confirmation code is: %s",
+		DeliveryMedium: input.DeliveryMedium,
 	}
 
 	if err := generateAndSaveInput.Validate(); err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = sc.auth.GenerateAndSendCode(ctx, generateAndSaveInput)
+	generateOut, err := sc.auth.GenerateAndSendCode(ctx, generateAndSaveInput)
 	if err != nil {
 		sc.logger.Error("failed to generate code: %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &SendConfirmationCodeOutput{
+		CodeDelivery: generateOut.CodeDelivery,
+	}, nil
 }
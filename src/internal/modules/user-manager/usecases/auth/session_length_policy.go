@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+// sessionLengthPolicy caps how long a sign in can be kept alive through
+// refreshes, counted from auth_time, which refreshes don't move.
+type sessionLengthPolicy struct {
+	// maxLength of zero disables the cap.
+	maxLength time.Duration
+	auth      auth.AuthService
+	logger    logger.Logger
+}
+
+func newSessionLengthPolicy(maxLength time.Duration, auth auth.AuthService, logger logger.Logger) *sessionLengthPolicy {
+	return &sessionLengthPolicy{
+		maxLength: maxLength,
+		auth:      auth,
+		logger:    logger,
+	}
+}
+
+// check runs on the tokens a refresh just produced. Past the cap the refresh
+// token is revoked so the client can't keep retrying with it. A token without
+// auth_time is treated as past the cap, like the freshness checks do.
+func (p *sessionLengthPolicy) check(ctx context.Context, refreshToken string, output *auth.RefreshTokenOutput) error {
+	if p.maxLength <= 0 {
+		return nil
+	}
+
+	claims, err := p.auth.ValidateToken(ctx, output.AccessToken)
+	if err != nil {
+		return err
+	}
+	if !claims.AuthTime.IsZero() && time.Since(claims.AuthTime) <= p.maxLength {
+		return nil
+	}
+
+	if err := p.auth.RevokeToken(ctx, auth.RevokeTokenInput{RefreshToken: refreshToken}); err != nil {
+		p.logger.Warning("Error revoking refresh token past the session length cap: %v", err)
+	}
+	return auth.ErrSessionExpired
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
+	"context"
+	"testing"
+	"time"
+)
+
+// refreshingAuth refreshes any token for a sign in made at authTime and
+// records the refresh tokens it revokes.
+type refreshingAuth struct {
+	auth.AuthService
+	authTime time.Time
+	revoked  []string
+}
+
+func (a *refreshingAuth) RefreshToken(ctx context.Context, input auth.RefreshTokenInput) (*auth.RefreshTokenOutput, error) {
+	return &auth.RefreshTokenOutput{AccessToken: "access", IdToken: "id"}, nil
+}
+
+func (a *refreshingAuth) ValidateToken(ctx context.Context, token string) (*auth.Claims, error) {
+	return &auth.Claims{Id: "user", AuthTime: a.authTime}, nil
+}
+
+func (a *refreshingAuth) RevokeToken(ctx context.Context, input auth.RevokeTokenInput) error {
+	a.revoked = append(a.revoked, input.RefreshToken)
+	return nil
+}
+
+func TestRefreshTokenSessionLengthCap(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		maxLength time.Duration
+		authTime  time.Time
+		wantErr   error
+	}{
+		{name: "within the cap", maxLength: 12 * time.Hour, authTime: now.Add(-11 * time.Hour)},
+		{name: "beyond the cap", maxLength: 12 * time.Hour, authTime: now.Add(-13 * time.Hour), wantErr: auth.ErrSessionExpired},
+		{name: "no auth_time", maxLength: 12 * time.Hour, wantErr: auth.ErrSessionExpired},
+		{name: "cap disabled", authTime: now.Add(-30 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &refreshingAuth{authTime: tt.authTime}
+			uc := NewRefreshTokenUseCase(authService, newSessionLengthPolicy(tt.maxLength, authService, logger.Nop{}), logger.Nop{})
+
+			out, err := uc.Execute(context.Background(), RefreshTokenInput{RefreshTokenInput: auth.RefreshTokenInput{RefreshToken: "refresh"}})
+			if err != tt.wantErr {
+				t.Fatalf("Execute error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if out == nil || out.AccessToken != "access" {
+					t.Errorf("output = %+v, want the refreshed tokens", out)
+				}
+				if len(authService.revoked) != 0 {
+					t.Errorf("revoked %v within the cap", authService.revoked)
+				}
+				return
+			}
+			if out != nil {
+				t.Errorf("output = %+v, want none past the cap", out)
+			}
+			if len(authService.revoked) != 1 || authService.revoked[0] != "refresh" {
+				t.Errorf("revoked %v, want the refresh token", authService.revoked)
+			}
+			if code := auth.ErrSessionExpired.Code(); code != "SESSION_EXPIRED" {
+				t.Errorf("code = %s, want SESSION_EXPIRED", code)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/deref"
+	"auth-api/src/pkg/jwt_verify"
+	"auth-api/src/pkg/logger"
+	"auth-api/src/pkg/validator"
+	"context"
+	"time"
+)
+
+const (
+	SessionLimitModeReject      = "reject"
+	SessionLimitModeEvictOldest = "evict_oldest"
+)
+
+// sessionLimitPolicy caps how many sign ins a user keeps at once, whether
+// they hold a cookie session or the tokens themselves. Each sign in is
+// stored as a session and the store enforces the cap, so concurrent sign ins
+// can't get past it. In reject mode the tokens just issued are revoked so
+// they don't outlive the refused sign in; in evict mode the oldest sessions
+// have their tokens revoked instead.
+type sessionLimitPolicy struct {
+	// maxSessions of zero means no limit.
+	maxSessions int
+	mode        string
+	auth        auth.AuthService
+	sessions    session.SessionService
+	logger      logger.Logger
+	// caseSensitive follows auth.case_sensitive_usernames, so sign ins keyed
+	// by a login username and by a username read back from Cognito agree.
+	caseSensitive bool
+}
+
+func newSessionLimitPolicy(maxSessions int, mode string, auth auth.AuthService, sessions session.SessionService, logger logger.Logger, caseSensitive bool) *sessionLimitPolicy {
+	return &sessionLimitPolicy{
+		maxSessions:   maxSessions,
+		mode:          mode,
+		auth:          auth,
+		sessions:      sessions,
+		logger:        logger,
+		caseSensitive: caseSensitive,
+	}
+}
+
+// open stores the sign in as a session of input.Purpose within the limit and
+// returns the session's token.
+func (p *sessionLimitPolicy) open(ctx context.Context, input session.CreateInput) (string, *session.Session, error) {
+	out, err := p.sessions.CreateWithinLimit(ctx, session.CreateWithinLimitInput{
+		CreateInput: input,
+		Limit:       p.maxSessions,
+		EvictOldest: p.mode == SessionLimitModeEvictOldest,
+	})
+	if err == session.ErrSessionLimitReached {
+		if err := p.auth.RevokeToken(ctx, auth.RevokeTokenInput{RefreshToken: input.RefreshToken}); err != nil {
+			p.logger.Warning("Error revoking tokens of refused session: %v", err)
+		}
+		return "", nil, err
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, evicted := range out.Evicted {
+		if err := p.auth.RevokeToken(ctx, auth.RevokeTokenInput{RefreshToken: evicted.RefreshToken}); err != nil {
+			p.logger.Warning("Error revoking tokens of evicted session: %v", err)
+		}
+		p.logger.Info("Evicted oldest session of %s created at %s", evicted.Username, evicted.CreatedAt)
+	}
+	return out.Token, out.Session, nil
+}
+
+// track counts tokens handed straight to the client towards the limit. An
+// output without tokens, such as a challenge, isn't a sign in yet.
+func (p *sessionLimitPolicy) track(ctx context.Context, username string, output *auth.LoginOutput) error {
+	if p.maxSessions <= 0 || output == nil || output.NextStep != nil || output.AccessToken == nil {
+		return nil
+	}
+	_, _, err := p.open(ctx, session.CreateInput{
+		Purpose:              session.PurposeToken,
+		Username:             validator.NormalizeEmail(username, p.caseSensitive),
+		AccessToken:          deref.String(output.AccessToken),
+		IdToken:              deref.String(output.IdToken),
+		RefreshToken:         deref.String(output.RefreshToken),
+		AccessTokenExpiresAt: p.accessTokenExpiresAt(deref.String(output.AccessToken)),
+	})
+	return err
+}
+
+// release forgets the sign ins of username, tracked ones and cookie sessions
+// alike, once a global sign out, a disable or a password reset has revoked
+// their tokens. Otherwise reject mode would keep refusing the user until the
+// dead sessions expire.
+func (p *sessionLimitPolicy) release(ctx context.Context, username string) {
+	if err := p.sessions.DeleteSignIns(ctx, session.DeleteSignInsInput{
+		Username: validator.NormalizeEmail(username, p.caseSensitive),
+	}); err != nil {
+		p.logger.Warning("Error releasing tracked sessions: %v", err)
+	}
+}
+
+func (p *sessionLimitPolicy) accessTokenExpiresAt(accessToken string) time.Time {
+	claims, err := jwt_verify.ParseUnverifiedClaims(accessToken)
+	if err != nil {
+		p.logger.Warning("Could not read access token expiry: %v", err)
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
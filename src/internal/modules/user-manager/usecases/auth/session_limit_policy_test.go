@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memorySessions enforces the session limit over a slice, the way the
+// repository does inside its transaction.
+type memorySessions struct {
+	session.SessionService
+	sessions []*session.Session
+	created  int
+}
+
+func (m *memorySessions) CreateWithinLimit(ctx context.Context, input session.CreateWithinLimitInput) (*session.CreateWithinLimitOutput, error) {
+	var active []*session.Session
+	for _, s := range m.sessions {
+		if s.Username == input.Username && (s.Purpose == session.PurposeWeb || s.Purpose == session.PurposeToken) {
+			active = append(active, s)
+		}
+	}
+
+	var evicted []*session.Session
+	if excess := len(active) - input.Limit + 1; input.Limit > 0 && excess > 0 {
+		if !input.EvictOldest {
+			return nil, session.ErrSessionLimitReached
+		}
+		evicted = active[:excess]
+		m.remove(func(s *session.Session) bool {
+			for _, e := range evicted {
+				if e == s {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	m.created++
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = session.PurposeWeb
+	}
+	sess := &session.Session{
+		Purpose:      purpose,
+		Username:     input.Username,
+		RefreshToken: input.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		CreatedAt:    time.Now().Add(time.Duration(m.created) * time.Second),
+	}
+	m.sessions = append(m.sessions, sess)
+	return &session.CreateWithinLimitOutput{Token: "session-token", Session: sess, Evicted: evicted}, nil
+}
+
+func (m *memorySessions) DeleteByUsername(ctx context.Context, input session.DeleteByUsernameInput) error {
+	m.remove(func(s *session.Session) bool {
+		return s.Username == input.Username && s.Purpose == input.Purpose
+	})
+	return nil
+}
+
+func (m *memorySessions) DeleteSignIns(ctx context.Context, input session.DeleteSignInsInput) error {
+	m.remove(func(s *session.Session) bool {
+		return s.Username == input.Username && (s.Purpose == session.PurposeWeb || s.Purpose == session.PurposeToken)
+	})
+	return nil
+}
+
+func (m *memorySessions) remove(match func(*session.Session) bool) {
+	kept := m.sessions[:0]
+	for _, s := range m.sessions {
+		if !match(s) {
+			kept = append(kept, s)
+		}
+	}
+	m.sessions = kept
+}
+
+// revokingAuth records the refresh tokens revoked.
+type revokingAuth struct {
+	auth.AuthService
+	revoked []string
+}
+
+func (a *revokingAuth) RevokeToken(ctx context.Context, input auth.RevokeTokenInput) error {
+	a.revoked = append(a.revoked, input.RefreshToken)
+	return nil
+}
+
+func (a *revokingAuth) GetMe(context.Context, auth.GetMeInput) (*auth.GetMeOutput, error) {
+	return &auth.GetMeOutput{Username: "Member@Example.com"}, nil
+}
+
+func (a *revokingAuth) Logout(context.Context, auth.LogoutInput) error { return nil }
+
+func tokensFor(refreshToken string) *auth.LoginOutput {
+	accessToken, idToken := "access", "id"
+	return &auth.LoginOutput{AccessToken: &accessToken, IdToken: &idToken, RefreshToken: &refreshToken}
+}
+
+func TestSessionLimitPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSessions int
+		mode        string
+		// existing are the refresh tokens of the sign ins already tracked,
+		// oldest first; a "web:" prefix makes it a cookie session.
+		existing []string
+		// caseSensitive keeps the sign in's Member@Example.com apart from
+		// the tracked member@example.com.
+		caseSensitive bool
+		wantErr       error
+		wantRevoked   []string
+		wantTracked   int
+	}{
+		{
+			name:        "below the limit",
+			maxSessions: 2,
+			mode:        SessionLimitModeReject,
+			existing:    []string{"first"},
+			wantTracked: 2,
+		},
+		{
+			name:        "reject revokes the new tokens",
+			maxSessions: 2,
+			mode:        SessionLimitModeReject,
+			existing:    []string{"first", "web:second"},
+			wantErr:     session.ErrSessionLimitReached,
+			wantRevoked: []string{"new"},
+			wantTracked: 2,
+		},
+		{
+			name:        "evict revokes the oldest across paths",
+			maxSessions: 2,
+			mode:        SessionLimitModeEvictOldest,
+			existing:    []string{"web:first", "second"},
+			wantRevoked: []string{"web:first"},
+			wantTracked: 2,
+		},
+		{
+			name:          "case sensitive usernames are counted apart",
+			maxSessions:   2,
+			mode:          SessionLimitModeReject,
+			existing:      []string{"first", "web:second"},
+			caseSensitive: true,
+			wantTracked:   3,
+		},
+		{
+			name:        "no limit tracks nothing",
+			maxSessions: 0,
+			mode:        SessionLimitModeReject,
+			wantTracked: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			authService := &revokingAuth{}
+			sessions := &memorySessions{}
+			policy := newSessionLimitPolicy(tt.maxSessions, tt.mode, authService, sessions, logger.Nop{}, tt.caseSensitive)
+			for _, refreshToken := range tt.existing {
+				purpose := session.PurposeToken
+				if strings.HasPrefix(refreshToken, "web:") {
+					purpose = session.PurposeWeb
+				}
+				if _, err := sessions.CreateWithinLimit(ctx, session.CreateWithinLimitInput{CreateInput: session.CreateInput{
+					Purpose:      purpose,
+					Username:     "member@example.com",
+					RefreshToken: refreshToken,
+				}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := policy.track(ctx, "Member@Example.com", tokensFor("new"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("track = %v, want %v", err, tt.wantErr)
+			}
+			if len(authService.revoked) != len(tt.wantRevoked) {
+				t.Fatalf("revoked = %v, want %v", authService.revoked, tt.wantRevoked)
+			}
+			for i := range tt.wantRevoked {
+				if authService.revoked[i] != tt.wantRevoked[i] {
+					t.Errorf("revoked = %v, want %v", authService.revoked, tt.wantRevoked)
+				}
+			}
+			if len(sessions.sessions) != tt.wantTracked {
+				t.Errorf("tracked %d sessions, want %d", len(sessions.sessions), tt.wantTracked)
+			}
+		})
+	}
+}
+
+func TestSessionLimitPolicySkipsChallenges(t *testing.T) {
+	sessions := &memorySessions{}
+	policy := newSessionLimitPolicy(1, SessionLimitModeReject, &revokingAuth{}, sessions, logger.Nop{}, false)
+
+	nextStep := auth.NextStepMFAEnrollmentRequired
+	if err := policy.track(context.Background(), "member@example.com", &auth.LoginOutput{NextStep: &nextStep}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Errorf("a challenge was tracked as a sign in")
+	}
+}
+
+func TestLogoutReleasesTrackedSignIns(t *testing.T) {
+	ctx := context.Background()
+	authService := &revokingAuth{}
+	sessions := &memorySessions{}
+	policy := newSessionLimitPolicy(1, SessionLimitModeReject, authService, sessions, logger.Nop{}, false)
+
+	if err := policy.track(ctx, "member@example.com", tokensFor("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLogoutUseCase(authService, policy).Execute(ctx, LogoutInput{auth.LogoutInput{AccessToken: "access"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.track(ctx, "member@example.com", tokensFor("second")); err != nil {
+		t.Errorf("sign in after logout = %v, want nil", err)
+	}
+}
+
+func TestAdminDisableReleasesSignIns(t *testing.T) {
+	ctx := context.Background()
+	authService := &disablingAuth{}
+	sessions := &memorySessions{}
+	policy := newSessionLimitPolicy(1, SessionLimitModeReject, authService, sessions, logger.Nop{}, false)
+
+	if err := policy.track(ctx, "member@example.com", tokensFor("first")); err != nil {
+		t.Fatal(err)
+	}
+	uc := NewAdminDisableUserUseCase(authService, &failingAudit{}, policy, logger.Nop{})
+	if err := uc.Execute(ctx, AdminDisableUserInput{ActorID: "admin-1", DisableUserInput: auth.DisableUserInput{Username: "Member@Example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Errorf("%d sessions left after disabling the user, want 0", len(sessions.sessions))
+	}
+}
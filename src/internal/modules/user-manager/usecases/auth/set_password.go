@@ -2,20 +2,29 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type SetPasswordUseCase struct {
-	auth auth.AuthService
+	auth      auth.AuthService
+	logger    logger.Logger
+	mfaPolicy *adminMFAPolicy
+	limit     *sessionLimitPolicy
 }
 
 type SetPasswordInput struct {
 	auth.SetPasswordInput
+	// CorrelationId is the id handed out with the login challenge.
+	CorrelationId string
 }
 
-func NewSetPasswordUseCase(auth auth.AuthService) *SetPasswordUseCase {
+func NewSetPasswordUseCase(auth auth.AuthService, logger logger.Logger, mfaPolicy *adminMFAPolicy, limit *sessionLimitPolicy) *SetPasswordUseCase {
 	return &SetPasswordUseCase{
-		auth: auth,
+		auth:      auth,
+		logger:    logger,
+		mfaPolicy: mfaPolicy,
+		limit:     limit,
 	}
 }
 
@@ -23,6 +32,25 @@ func (uc *SetPasswordUseCase) Execute(ctx context.Context, input SetPasswordInpu
 	if err := input.SetPasswordInput.Validate(); err != nil {
 		return nil, err
 	}
+	if err := auth.ValidateCorrelationId(input.CorrelationId); err != nil {
+		return nil, err
+	}
 
-	return uc.auth.SetPassword(ctx, input.SetPasswordInput)
+	output, err := uc.auth.SetPassword(ctx, input.SetPasswordInput)
+	if err == nil {
+		output, err = uc.mfaPolicy.apply(ctx, input.Username, output)
+	}
+	if err == nil {
+		err = uc.limit.track(ctx, input.Username, output)
+	}
+	if err != nil {
+		uc.logger.Info("Login challenge response failed step=SetPassword correlation_id=%s err=%v", input.CorrelationId, err)
+		return nil, err
+	}
+	uc.logger.Info("Login challenge response succeeded step=SetPassword correlation_id=%s", input.CorrelationId)
+	// A follow-up challenge keeps the same id so the whole flow reads as one.
+	if output != nil && output.NextStep != nil && input.CorrelationId != "" {
+		output.CorrelationId = &input.CorrelationId
+	}
+	return output, nil
 }
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/otpauth"
+	"context"
+)
+
+type SetupMFAUseCase struct {
+	auth      auth.AuthService
+	mfaIssuer otpauth.Issuer
+}
+
+type SetupMFAInput struct {
+	auth.AddMFAInput
+}
+
+func NewSetupMFAUseCase(auth auth.AuthService, mfaIssuer otpauth.Issuer) *SetupMFAUseCase {
+	return &SetupMFAUseCase{
+		auth:      auth,
+		mfaIssuer: mfaIssuer,
+	}
+}
+
+func (uc *SetupMFAUseCase) Execute(ctx context.Context, input SetupMFAInput) (*auth.SetupMFAOutput, error) {
+	if err := input.AddMFAInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	getMeInput := auth.GetMeInput{
+		AccessToken: input.AddMFAInput.AccessToken,
+	}
+	if err := getMeInput.Validate(); err != nil {
+		return nil, err
+	}
+
+	me, err := uc.auth.GetMe(ctx, getMeInput)
+	if err != nil {
+		return nil, err
+	}
+
+	addMfaOutput, err := uc.auth.AddMFA(ctx, input.AddMFAInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.SetupMFAOutput{
+		SecretCode: addMfaOutput.SecretCode,
+		OtpAuthURI: otpauth.BuildTOTPURI(uc.mfaIssuer, me.Username, addMfaOutput.SecretCode),
+		Session:    addMfaOutput.Session,
+	}, nil
+}
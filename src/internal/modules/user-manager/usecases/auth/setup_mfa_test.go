@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"net/url"
+	"testing"
+)
+
+// totpAuth hands out a fresh secret on every AddMFA.
+type totpAuth struct {
+	auth.AuthService
+	secrets int
+}
+
+func (a *totpAuth) GetMe(context.Context, auth.GetMeInput) (*auth.GetMeOutput, error) {
+	return &auth.GetMeOutput{Username: "member@example.com"}, nil
+}
+
+func (a *totpAuth) AddMFA(context.Context, auth.AddMFAInput) (*auth.AddMFAOutput, error) {
+	a.secrets++
+	secrets := []string{"JBSWY3DPEHPK3PXP", "KRSXG5CTMVRXEZLU"}
+	return &auth.AddMFAOutput{SecretCode: secrets[(a.secrets-1)%len(secrets)]}, nil
+}
+
+func TestSetupMFAReturnsFreshOtpauthURI(t *testing.T) {
+	uc := NewSetupMFAUseCase(&totpAuth{}, "Monitoring")
+
+	var previous string
+	for i := 0; i < 2; i++ {
+		out, err := uc.Execute(context.Background(), SetupMFAInput{auth.AddMFAInput{AccessToken: "access"}})
+		if err != nil {
+			t.Fatalf("Execute = %v", err)
+		}
+
+		uri, err := url.Parse(out.OtpAuthURI)
+		if err != nil {
+			t.Fatalf("OtpAuthURI %q doesn't parse: %v", out.OtpAuthURI, err)
+		}
+		if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Monitoring:member@example.com" {
+			t.Errorf("OtpAuthURI = %s, want otpauth://totp/Monitoring:member@example.com", out.OtpAuthURI)
+		}
+		if got := uri.Query().Get("secret"); got != out.SecretCode {
+			t.Errorf("secret = %q, want the returned secret %q", got, out.SecretCode)
+		}
+		if out.SecretCode == previous {
+			t.Errorf("secret %q was handed out again", out.SecretCode)
+		}
+		previous = out.SecretCode
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+)
+
+// maxCandidatePasswordLength matches Cognito's own limit.
+const maxCandidatePasswordLength = 256
+
+type ValidatePasswordUseCase struct {
+	auth auth.AuthService
+}
+
+type ValidatePasswordInput struct {
+	Password string
+}
+
+func (input *ValidatePasswordInput) Validate() error {
+	if len(input.Password) > maxCandidatePasswordLength {
+		return auth.NewValidationError("Password")
+	}
+	return nil
+}
+
+type ValidatePasswordOutput struct {
+	Valid bool                      `json:"valid"`
+	Rules []auth.PasswordRuleResult `json:"rules"`
+}
+
+func NewValidatePasswordUseCase(auth auth.AuthService) *ValidatePasswordUseCase {
+	return &ValidatePasswordUseCase{
+		auth: auth,
+	}
+}
+
+// Execute runs a candidate password through the user pool policy only;
+// nothing is created or stored.
+func (uc *ValidatePasswordUseCase) Execute(ctx context.Context, input ValidatePasswordInput) (*ValidatePasswordOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	policy, err := uc.auth.GetPasswordPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ValidatePasswordOutput{
+		Valid: true,
+		Rules: policy.Check(input.Password),
+	}
+	for _, rule := range output.Rules {
+		if !rule.Passed {
+			output.Valid = false
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// policyAuth serves a fixed password policy.
+type policyAuth struct {
+	auth.AuthService
+	policy auth.PasswordPolicy
+}
+
+func (a policyAuth) GetPasswordPolicy(context.Context) (*auth.PasswordPolicy, error) {
+	return &a.policy, nil
+}
+
+func TestValidatePassword(t *testing.T) {
+	strict := auth.PasswordPolicy{MinimumLength: 8, RequireUppercase: true, RequireLowercase: true, RequireNumbers: true, RequireSymbols: true}
+
+	tests := []struct {
+		name      string
+		policy    auth.PasswordPolicy
+		password  string
+		wantValid bool
+		want      map[string]bool
+	}{
+		{
+			name:      "meets every rule",
+			policy:    strict,
+			password:  "Str0ng!Pass",
+			wantValid: true,
+			want: map[string]bool{
+				auth.PasswordRuleMinLength: true, auth.PasswordRuleUppercase: true, auth.PasswordRuleLowercase: true,
+				auth.PasswordRuleNumber: true, auth.PasswordRuleSymbol: true,
+			},
+		},
+		{
+			name:     "too short",
+			policy:   strict,
+			password: "S0!a",
+			want: map[string]bool{
+				auth.PasswordRuleMinLength: false, auth.PasswordRuleUppercase: true, auth.PasswordRuleLowercase: true,
+				auth.PasswordRuleNumber: true, auth.PasswordRuleSymbol: true,
+			},
+		},
+		{
+			name:     "lowercase only",
+			policy:   strict,
+			password: "lowercaseonly",
+			want: map[string]bool{
+				auth.PasswordRuleMinLength: true, auth.PasswordRuleUppercase: false, auth.PasswordRuleLowercase: true,
+				auth.PasswordRuleNumber: false, auth.PasswordRuleSymbol: false,
+			},
+		},
+		{
+			name:     "empty",
+			policy:   strict,
+			password: "",
+			want: map[string]bool{
+				auth.PasswordRuleMinLength: false, auth.PasswordRuleUppercase: false, auth.PasswordRuleLowercase: false,
+				auth.PasswordRuleNumber: false, auth.PasswordRuleSymbol: false,
+			},
+		},
+		{
+			name:      "only the rules the policy enables",
+			policy:    auth.PasswordPolicy{MinimumLength: 6, RequireNumbers: true},
+			password:  "abc123",
+			wantValid: true,
+			want:      map[string]bool{auth.PasswordRuleMinLength: true, auth.PasswordRuleNumber: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewValidatePasswordUseCase(policyAuth{policy: tt.policy})
+
+			out, err := uc.Execute(context.Background(), ValidatePasswordInput{Password: tt.password})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+
+			got := make(map[string]bool, len(out.Rules))
+			for _, rule := range out.Rules {
+				if rule.Message == "" {
+					t.Errorf("rule %s has no message", rule.Rule)
+				}
+				got[rule.Rule] = rule.Passed
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+			if out.Valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v", out.Valid, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordRejectsOverlongCandidates(t *testing.T) {
+	uc := NewValidatePasswordUseCase(policyAuth{})
+
+	_, err := uc.Execute(context.Background(), ValidatePasswordInput{Password: strings.Repeat("a", maxCandidatePasswordLength+1)})
+	if !isFieldError(err, "Password") {
+		t.Errorf("err = %v, want a Password validation error", err)
+	}
+}
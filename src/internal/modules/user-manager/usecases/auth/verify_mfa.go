@@ -2,20 +2,27 @@ package auth
 
 import (
 	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/pkg/logger"
 	"context"
 )
 
 type VerifyMFAUseCase struct {
-	auth auth.AuthService
+	auth   auth.AuthService
+	limit  *sessionLimitPolicy
+	logger logger.Logger
 }
 
 type VerifyMFAInput struct {
 	auth.VerifyMFAInput
+	// CorrelationId is the id handed out with the login challenge.
+	CorrelationId string
 }
 
-func NewVerifyMFAUseCase(auth auth.AuthService) *VerifyMFAUseCase {
+func NewVerifyMFAUseCase(auth auth.AuthService, limit *sessionLimitPolicy, logger logger.Logger) *VerifyMFAUseCase {
 	return &VerifyMFAUseCase{
-		auth: auth,
+		auth:   auth,
+		limit:  limit,
+		logger: logger,
 	}
 }
 
@@ -23,6 +30,22 @@ func (uc *VerifyMFAUseCase) Execute(ctx context.Context, input VerifyMFAInput) (
 	if err := input.VerifyMFAInput.Validate(); err != nil {
 		return nil, err
 	}
+	if err := auth.ValidateCorrelationId(input.CorrelationId); err != nil {
+		return nil, err
+	}
 
-	return uc.auth.VerifyMFA(ctx, input.VerifyMFAInput)
+	output, err := uc.auth.VerifyMFA(ctx, input.VerifyMFAInput)
+	if err == nil {
+		err = uc.limit.track(ctx, input.Username, output)
+	}
+	if err != nil {
+		uc.logger.Info("Login challenge response failed step=VerifyMFA correlation_id=%s err=%v", input.CorrelationId, err)
+		return nil, err
+	}
+	uc.logger.Info("Login challenge response succeeded step=VerifyMFA correlation_id=%s", input.CorrelationId)
+	// A follow-up challenge keeps the same id so the whole flow reads as one.
+	if output != nil && output.NextStep != nil && input.CorrelationId != "" {
+		output.CorrelationId = &input.CorrelationId
+	}
+	return output, nil
 }
@@ -2,50 +2,113 @@ package user
 
 import (
 	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/internal/modules/user-manager/domain/user"
 	"auth-api/src/pkg/logger"
 	"context"
+	"errors"
+	"strings"
+	"time"
 )
 
 type RegisterUserUseCase struct {
-	userService user.UserService
-	auth        auth.AuthService
-	logger      logger.Logger
-	events      events.EventDispatcher
+	userService    user.UserService
+	adminService   admin.AdminService
+	auth           auth.AuthService
+	logger         logger.Logger
+	events         events.EventDispatcher
+	signupDisabled bool
+	allowedMediums []auth.DeliveryMedium
+	domainPolicy   user.EmailDomainPolicy
+	challenge      auth.PreAuthChallenge
+	invites        auth.InviteTokens
+	enumeration    user.SignupEnumerationProtection
 }
 
 type RegisterUserInput struct {
 	auth.SignUpInput
 	user.CreateUserInput
+	IP             string
+	ChallengeToken string
+	// InviteToken is optional. A valid one lets the email sign up while
+	// public signup is disabled or its domain is not allowed, and puts the
+	// account in the invited group.
+	InviteToken string
 }
 
-func NewRegisterUserUseCase(userService user.UserService, auth auth.AuthService, logger logger.Logger, events events.EventDispatcher) *RegisterUserUseCase {
+func NewRegisterUserUseCase(deps Dependencies, opts Options) *RegisterUserUseCase {
 	return &RegisterUserUseCase{
-		userService: userService,
-		auth:        auth,
-		logger:      logger,
-		events:      events,
+		userService:    deps.User,
+		adminService:   deps.Admin,
+		auth:           deps.Auth,
+		logger:         deps.Logger,
+		events:         deps.Dispatcher,
+		signupDisabled: opts.SignupDisabled,
+		allowedMediums: opts.AllowedMediums,
+		domainPolicy:   opts.DomainPolicy,
+		challenge:      deps.Challenge,
+		invites:        deps.Invites,
+		enumeration:    opts.Enumeration,
 	}
 }
 
 func (uc *RegisterUserUseCase) Execute(ctx context.Context, input RegisterUserInput) (execErr error) {
-	if err := input.SignUpInput.Validate(); err != nil {
-		return err
+	if uc.enumeration.Enabled {
+		started := time.Now()
+		defer func() {
+			if isAccountExistsError(execErr) {
+				uc.logger.Info("Sign up for an existing account answered as a new one")
+				execErr = nil
+			}
+			uc.waitUntil(ctx, started.Add(uc.enumeration.MinDuration))
+		}()
 	}
 
-	getByEmailInput := &user.GetUserByEmailInput{
-		Email: input.CreateUserInput.Email,
+	if uc.signupDisabled && input.InviteToken == "" {
+		return user.ErrSignupDisabled
 	}
-	if err := getByEmailInput.Validate(); err != nil {
+
+	if err := input.SignUpInput.Validate(); err != nil {
 		return err
 	}
-	if exists, err := uc.userService.GetByEmail(getByEmailInput); err != nil {
-		if err != user.ErrUserNotFound {
+	// The verification code goes out after the account exists, so a medium
+	// that can't deliver it is refused before anything is created.
+	if !input.SignUpInput.DeliveryMedium.Supported() {
+		return auth.ErrDeliveryMediumUnsupported
+	}
+
+	var invite *auth.Invite
+	if input.InviteToken != "" {
+		var err error
+		if invite, err = uc.invites.Verify(input.InviteToken); err != nil {
 			return err
 		}
-	} else if exists != nil {
-		return user.ErrUserAlreadyExists
+		// Mailboxes don't tell case apart, even when the pool's usernames do.
+		if !strings.EqualFold(invite.Email, input.SignUpInput.Username) {
+			return auth.ErrInviteEmailMismatch
+		}
+		input.SignUpInput.Group = invite.Role
+	}
+
+	if err := uc.challenge.Verify(ctx, auth.PreAuthChallengeInput{
+		Token:  input.ChallengeToken,
+		IP:     input.IP,
+		Action: auth.PreAuthActionSignup,
+	}); err != nil {
+		return err
+	}
+
+	if !uc.isMediumAllowed(input.SignUpInput.DeliveryMedium) {
+		return auth.ErrDeliveryMediumNotAllowed
+	}
+
+	if invite == nil && !uc.domainPolicy.Allows(input.SignUpInput.Username) {
+		return user.ErrEmailDomainNotAllowed
+	}
+
+	if err := uc.checkRowFree(input.SignUpInput.Group, input.CreateUserInput.Email); err != nil {
+		return err
 	}
 
 	signUpOutput, err := uc.auth.SignUp(ctx, input.SignUpInput)
@@ -60,24 +123,13 @@ func (uc *RegisterUserUseCase) Execute(ctx context.Context, input RegisterUserIn
 		}
 	}()
 
-	userId, err := user.ParseUserID(signUpOutput.Id)
-	if err != nil {
-		return err
-	}
-
-	input.CreateUserInput.ID = userId
-
-	if err := input.CreateUserInput.Validate(); err != nil {
-		return err
-	}
-
-	createOut, err := uc.userService.Create(&input.CreateUserInput)
+	rollbackRow, err := uc.createRow(input.SignUpInput.Group, signUpOutput.Id, input.CreateUserInput)
 	if err != nil {
 		return err
 	}
 	defer func() {
 		if execErr != nil {
-			if err := createOut.Rollback(ctx); err != nil {
+			if err := rollbackRow(ctx); err != nil {
 				uc.logger.Error("Error rolling back create user: %s", err)
 			}
 		}
@@ -86,6 +138,7 @@ func (uc *RegisterUserUseCase) Execute(ctx context.Context, input RegisterUserIn
 	userRegisteredEvent := &user.UserRegisteredEvent{
 		Email:             input.CreateUserInput.Email,
 		NeedsVerification: true,
+		DeliveryMedium:    input.SignUpInput.DeliveryMedium,
 	}
 
 	if err := uc.events.Dispatch(userRegisteredEvent); err != nil {
@@ -94,3 +147,101 @@ func (uc *RegisterUserUseCase) Execute(ctx context.Context, input RegisterUserIn
 
 	return nil
 }
+
+func (uc *RegisterUserUseCase) isMediumAllowed(medium auth.DeliveryMedium) bool {
+	for _, allowed := range uc.allowedMediums {
+		if allowed == medium {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRowFree refuses the sign up when the email already has a row in the
+// table the group's account goes to: admins for an invited Admin, users
+// otherwise.
+func (uc *RegisterUserUseCase) checkRowFree(group auth.UserGroup, email string) error {
+	if group == auth.GroupAdmin {
+		getByEmailInput := &admin.GetAdminByEmailInput{Email: email}
+		if err := getByEmailInput.Validate(); err != nil {
+			return err
+		}
+		exists, err := uc.adminService.GetByEmail(getByEmailInput)
+		if err != nil && err != admin.ErrAdminNotFound {
+			return err
+		}
+		if exists != nil {
+			return admin.ErrAdminAlreadyExists
+		}
+		return nil
+	}
+
+	getByEmailInput := &user.GetUserByEmailInput{Email: email}
+	if err := getByEmailInput.Validate(); err != nil {
+		return err
+	}
+	exists, err := uc.userService.GetByEmail(getByEmailInput)
+	if err != nil && err != user.ErrUserNotFound {
+		return err
+	}
+	if exists != nil {
+		return user.ErrUserAlreadyExists
+	}
+	return nil
+}
+
+// createRow creates the row matching the group the account was signed up
+// in, as ChangeUserGroupUseCase does, and returns how to remove it again.
+func (uc *RegisterUserUseCase) createRow(group auth.UserGroup, id string, input user.CreateUserInput) (func(context.Context) error, error) {
+	if group == auth.GroupAdmin {
+		adminId, err := admin.ParseAdminID(id)
+		if err != nil {
+			return nil, err
+		}
+		createInput := &admin.CreateAdminInput{ID: adminId, Name: input.Name, Email: input.Email}
+		if err := createInput.Validate(); err != nil {
+			return nil, err
+		}
+		createOut, err := uc.adminService.Create(createInput)
+		if err != nil {
+			return nil, err
+		}
+		return createOut.Rollback, nil
+	}
+
+	userId, err := user.ParseUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	input.ID = userId
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	createOut, err := uc.userService.Create(&input)
+	if err != nil {
+		return nil, err
+	}
+	return createOut.Rollback, nil
+}
+
+func isAccountExistsError(err error) bool {
+	return errors.Is(err, user.ErrUserAlreadyExists) ||
+		errors.Is(err, admin.ErrAdminAlreadyExists) ||
+		errors.Is(err, auth.ErrUserAlreadyExists) ||
+		errors.Is(err, auth.ErrAliasAlreadyExists) ||
+		errors.Is(err, auth.ErrPhoneAliasAlreadyExists)
+}
+
+// waitUntil pads the response to deadline, unless the request is gone first.
+func (uc *RegisterUserUseCase) waitUntil(ctx context.Context, deadline time.Time) {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		return
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
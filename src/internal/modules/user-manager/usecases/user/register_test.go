@@ -0,0 +1,355 @@
+package user
+
+import (
+	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/admin"
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"auth-api/src/internal/modules/user-manager/domain/user"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubInvites map[string]struct {
+	invite *auth.Invite
+	err    error
+}
+
+func (s stubInvites) Issue(auth.Invite) (string, error) { return "", nil }
+
+func (s stubInvites) Verify(token string) (*auth.Invite, error) {
+	entry, ok := s[token]
+	if !ok {
+		return nil, auth.ErrInvalidInvite
+	}
+	return entry.invite, entry.err
+}
+
+type stubAuth struct {
+	auth.AuthService
+	signUps []auth.SignUpInput
+}
+
+func (s *stubAuth) SignUp(ctx context.Context, input auth.SignUpInput) (*auth.SignUpOutput, error) {
+	s.signUps = append(s.signUps, input)
+	return auth.NewSignUpOutput("6f1c1f46-3b6c-4bde-9a43-6d0d3b8f0d6e", input.Username, false, s), nil
+}
+
+// stubUsers records the users rows created.
+type stubUsers struct {
+	user.UserService
+	created []string
+}
+
+func (*stubUsers) GetByEmail(*user.GetUserByEmailInput) (*user.User, error) {
+	return nil, user.ErrUserNotFound
+}
+
+func (s *stubUsers) Create(input *user.CreateUserInput) (*user.CreateUserOutput, error) {
+	s.created = append(s.created, input.Email)
+	return user.NewCreateUserOutput(&input.ID, s), nil
+}
+
+// stubAdmins records the admins rows created; existing emails already have
+// one.
+type stubAdmins struct {
+	admin.AdminService
+	existing string
+	created  []string
+}
+
+func (s *stubAdmins) GetByEmail(input *admin.GetAdminByEmailInput) (*admin.Admin, error) {
+	if input.Email == s.existing {
+		return &admin.Admin{Email: input.Email}, nil
+	}
+	return nil, admin.ErrAdminNotFound
+}
+
+func (s *stubAdmins) Create(input *admin.CreateAdminInput) (*admin.CreateAdminOutput, error) {
+	s.created = append(s.created, input.Email)
+	return admin.NewCreateAdminOutput(&input.ID, s), nil
+}
+
+type stubDispatcher struct{}
+
+func (stubDispatcher) Register(events.EventType, events.EventHandler) {}
+func (stubDispatcher) Dispatch(events.Event) error                    { return nil }
+
+type passChallenge struct{}
+
+func (passChallenge) Verify(context.Context, auth.PreAuthChallengeInput) error { return nil }
+
+func TestRegisterWithInvite(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	invites := stubInvites{
+		"admin-invite": {invite: &auth.Invite{Email: "new@blocked.example", Role: auth.GroupAdmin, ExpiresAt: expiresAt}},
+		"user-invite":  {invite: &auth.Invite{Email: "new@blocked.example", Role: auth.GroupUser, ExpiresAt: expiresAt}},
+		"expired":      {err: auth.ErrInviteExpired},
+		"admin-taken":  {invite: &auth.Invite{Email: "taken@example.com", Role: auth.GroupAdmin, ExpiresAt: expiresAt}},
+	}
+
+	tests := []struct {
+		name           string
+		signupDisabled bool
+		email          string
+		inviteToken    string
+		deliveryMedium auth.DeliveryMedium
+		wantErr        error
+		wantGroup      auth.UserGroup
+	}{
+		{
+			name:           "public signup disabled without invite",
+			signupDisabled: true,
+			email:          "new@example.com",
+			wantErr:        user.ErrSignupDisabled,
+		},
+		{
+			name:        "invited admin who already has an admins row",
+			email:       "taken@example.com",
+			inviteToken: "admin-taken",
+			wantErr:     admin.ErrAdminAlreadyExists,
+		},
+		{
+			name:           "invite while public signup is disabled",
+			signupDisabled: true,
+			email:          "new@blocked.example",
+			inviteToken:    "admin-invite",
+			wantGroup:      auth.GroupAdmin,
+		},
+		{
+			name:        "invite email is matched case insensitively",
+			email:       "New@Blocked.Example",
+			inviteToken: "user-invite",
+			wantGroup:   auth.GroupUser,
+		},
+		{
+			name:        "invite for another email",
+			email:       "other@example.com",
+			inviteToken: "admin-invite",
+			wantErr:     auth.ErrInviteEmailMismatch,
+		},
+		{
+			name:        "expired invite",
+			email:       "new@blocked.example",
+			inviteToken: "expired",
+			wantErr:     auth.ErrInviteExpired,
+		},
+		{
+			name:        "unknown invite",
+			email:       "new@blocked.example",
+			inviteToken: "forged",
+			wantErr:     auth.ErrInvalidInvite,
+		},
+		{
+			name:    "blocked domain without invite",
+			email:   "new@blocked.example",
+			wantErr: user.ErrEmailDomainNotAllowed,
+		},
+		{
+			name:           "SMS is refused before the account exists",
+			email:          "new@example.com",
+			deliveryMedium: auth.DeliveryMediumSMS,
+			wantErr:        auth.ErrDeliveryMediumUnsupported,
+		},
+		{
+			name:      "plain signup",
+			email:     "new@example.com",
+			wantGroup: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &stubAuth{}
+			users := &stubUsers{}
+			admins := &stubAdmins{existing: "taken@example.com"}
+			uc := NewRegisterUserUseCase(Dependencies{
+				User:       users,
+				Admin:      admins,
+				Auth:       authService,
+				Logger:     logger.Nop{},
+				Dispatcher: stubDispatcher{},
+				Challenge:  passChallenge{},
+				Invites:    invites,
+			}, Options{
+				SignupDisabled: tt.signupDisabled,
+				AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail, auth.DeliveryMediumSMS},
+				DomainPolicy:   user.EmailDomainPolicy{Blocked: []string{"blocked.example"}},
+			})
+
+			phone := "+5511999999999"
+			err := uc.Execute(context.Background(), RegisterUserInput{
+				SignUpInput: auth.SignUpInput{
+					Username:       tt.email,
+					Password:       "Str0ng!Passw0rd",
+					Name:           "New User",
+					Phone:          &phone,
+					DeliveryMedium: tt.deliveryMedium,
+				},
+				CreateUserInput: user.CreateUserInput{
+					Name:  "New User",
+					Email: tt.email,
+				},
+				InviteToken: tt.inviteToken,
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(authService.signUps) != 0 || len(users.created) != 0 || len(admins.created) != 0 {
+					t.Error("account was created for a rejected signup")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(authService.signUps) != 1 {
+				t.Fatalf("got %d sign ups, want 1", len(authService.signUps))
+			}
+			if got := authService.signUps[0].Group; got != tt.wantGroup {
+				t.Errorf("Group = %q, want %q", got, tt.wantGroup)
+			}
+			// The row goes where the group's account is looked up: an
+			// invited admin has no users row.
+			wantAdmins, wantUsers := 0, 1
+			if tt.wantGroup == auth.GroupAdmin {
+				wantAdmins, wantUsers = 1, 0
+			}
+			if len(admins.created) != wantAdmins || len(users.created) != wantUsers {
+				t.Errorf("created %d admins and %d users rows, want %d and %d", len(admins.created), len(users.created), wantAdmins, wantUsers)
+			}
+		})
+	}
+}
+
+func TestRegisterPreAuthChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifyErr error
+	}{
+		{name: "challenge passed"},
+		{name: "challenge failed", verifyErr: auth.ErrChallengeFailed},
+		{name: "provider unavailable", verifyErr: auth.ErrChallengeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified []auth.PreAuthChallengeInput
+			challenge := auth.PreAuthChallengeFunc(func(ctx context.Context, input auth.PreAuthChallengeInput) error {
+				verified = append(verified, input)
+				return tt.verifyErr
+			})
+			authService := &stubAuth{}
+			users := &stubUsers{}
+			uc := NewRegisterUserUseCase(Dependencies{
+				User:       users,
+				Admin:      &stubAdmins{},
+				Auth:       authService,
+				Logger:     logger.Nop{},
+				Dispatcher: stubDispatcher{},
+				Challenge:  challenge,
+				Invites:    stubInvites{},
+			}, Options{AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail}})
+
+			err := uc.Execute(context.Background(), RegisterUserInput{
+				SignUpInput:     auth.SignUpInput{Username: "new@example.com", Password: "Str0ng!Passw0rd", Name: "New User"},
+				CreateUserInput: user.CreateUserInput{Name: "New User", Email: "new@example.com"},
+				ChallengeToken:  "captcha-token",
+				IP:              "203.0.113.7",
+			})
+			if err != tt.verifyErr {
+				t.Fatalf("err = %v, want %v", err, tt.verifyErr)
+			}
+			if len(verified) != 1 || verified[0] != (auth.PreAuthChallengeInput{Token: "captcha-token", IP: "203.0.113.7", Action: auth.PreAuthActionSignup}) {
+				t.Errorf("verified %+v, want the signup token once", verified)
+			}
+			wantSignUps := 1
+			if tt.verifyErr != nil {
+				wantSignUps = 0
+			}
+			if len(authService.signUps) != wantSignUps || len(users.created) != wantSignUps {
+				t.Errorf("%d sign ups and %d users rows, want %d", len(authService.signUps), len(users.created), wantSignUps)
+			}
+		})
+	}
+}
+
+func TestRegisterPasswordConfirmation(t *testing.T) {
+	same, other := "Str0ng!Passw0rd", "Str0ng!Passw0rd2"
+
+	tests := []struct {
+		name         string
+		confirmation *string
+		wantErr      error
+	}{
+		{name: "matching", confirmation: &same},
+		{name: "omitted"},
+		{name: "mismatching", confirmation: &other, wantErr: auth.ErrPasswordMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := &stubAuth{}
+			uc := NewRegisterUserUseCase(Dependencies{
+				User:       &stubUsers{},
+				Admin:      &stubAdmins{},
+				Auth:       authService,
+				Logger:     logger.Nop{},
+				Dispatcher: stubDispatcher{},
+				Challenge:  passChallenge{},
+				Invites:    stubInvites{},
+			}, Options{AllowedMediums: []auth.DeliveryMedium{auth.DeliveryMediumEmail}})
+
+			err := uc.Execute(context.Background(), RegisterUserInput{
+				SignUpInput: auth.SignUpInput{
+					Username:             "new@example.com",
+					Password:             same,
+					PasswordConfirmation: tt.confirmation,
+					Name:                 "New User",
+				},
+				CreateUserInput: user.CreateUserInput{Name: "New User", Email: "new@example.com"},
+			})
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			wantSignUps := 1
+			if tt.wantErr != nil {
+				wantSignUps = 0
+			}
+			if len(authService.signUps) != wantSignUps {
+				t.Errorf("%d sign ups, want %d", len(authService.signUps), wantSignUps)
+			}
+		})
+	}
+}
+
+func TestVerifyInvite(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	uc := NewVerifyInviteUseCase(stubInvites{
+		"valid":   {invite: &auth.Invite{Email: "new@example.com", Role: auth.GroupUser, ExpiresAt: expiresAt}},
+		"expired": {err: auth.ErrInviteExpired},
+	})
+
+	tests := []struct {
+		token   string
+		wantErr error
+	}{
+		{token: "valid"},
+		{token: "expired", wantErr: auth.ErrInviteExpired},
+		{token: "forged", wantErr: auth.ErrInvalidInvite},
+		{token: "", wantErr: auth.ErrInvalidInvite},
+	}
+	for _, tt := range tests {
+		out, err := uc.Execute(context.Background(), VerifyInviteInput{Token: tt.token})
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("token %q: err = %v, want %v", tt.token, err, tt.wantErr)
+		}
+		if err == nil && (out.Email != "new@example.com" || out.Role != auth.GroupUser || !out.ExpiresAt.Equal(expiresAt)) {
+			t.Errorf("token %q: output = %+v", tt.token, out)
+		}
+	}
+}
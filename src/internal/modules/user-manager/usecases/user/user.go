@@ -2,19 +2,42 @@ package user
 
 import (
 	"auth-api/src/internal/events"
+	"auth-api/src/internal/modules/user-manager/domain/admin"
 	"auth-api/src/internal/modules/user-manager/domain/auth"
 	"auth-api/src/internal/modules/user-manager/domain/user"
 	"auth-api/src/pkg/logger"
 )
 
 type UseCases struct {
-	Register *RegisterUserUseCase
-	Update   *UpdateUserUseCase
+	Register     *RegisterUserUseCase
+	Update       *UpdateUserUseCase
+	VerifyInvite *VerifyInviteUseCase
 }
 
-func NewUseCases(userService user.UserService, authService auth.AuthService, logger logger.Logger, events events.EventDispatcher) *UseCases {
+// Dependencies are the services the user use cases run on.
+type Dependencies struct {
+	User       user.UserService
+	Admin      admin.AdminService
+	Auth       auth.AuthService
+	Logger     logger.Logger
+	Dispatcher events.EventDispatcher
+	Challenge  auth.PreAuthChallenge
+	Invites    auth.InviteTokens
+}
+
+// Options tune the user use cases. Zero values leave signup open to every
+// domain, with no delivery medium allowed and no enumeration protection.
+type Options struct {
+	SignupDisabled bool
+	AllowedMediums []auth.DeliveryMedium
+	DomainPolicy   user.EmailDomainPolicy
+	Enumeration    user.SignupEnumerationProtection
+}
+
+func NewUseCases(deps Dependencies, opts Options) *UseCases {
 	return &UseCases{
-		Register: NewRegisterUserUseCase(userService, authService, logger, events),
-		Update:   NewUpdateUserUseCase(userService, logger),
+		Register:     NewRegisterUserUseCase(deps, opts),
+		Update:       NewUpdateUserUseCase(deps.User, deps.Logger),
+		VerifyInvite: NewVerifyInviteUseCase(deps.Invites),
 	}
 }
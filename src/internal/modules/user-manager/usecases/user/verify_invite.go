@@ -0,0 +1,44 @@
+package user
+
+import (
+	"auth-api/src/internal/modules/user-manager/domain/auth"
+	"context"
+	"time"
+)
+
+type VerifyInviteUseCase struct {
+	invites auth.InviteTokens
+}
+
+type VerifyInviteInput struct {
+	Token string
+}
+
+// VerifyInviteOutput holds what the signup form can be prefilled with.
+type VerifyInviteOutput struct {
+	Email     string         `json:"email"`
+	Role      auth.UserGroup `json:"role"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+func NewVerifyInviteUseCase(invites auth.InviteTokens) *VerifyInviteUseCase {
+	return &VerifyInviteUseCase{
+		invites: invites,
+	}
+}
+
+// Execute only checks the token, so it works while public signup is disabled.
+func (uc *VerifyInviteUseCase) Execute(ctx context.Context, input VerifyInviteInput) (*VerifyInviteOutput, error) {
+	if input.Token == "" {
+		return nil, auth.ErrInvalidInvite
+	}
+	invite, err := uc.invites.Verify(input.Token)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyInviteOutput{
+		Email:     invite.Email,
+		Role:      invite.Role,
+		ExpiresAt: invite.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,11 @@
+package audit
+
+import "time"
+
+type Entry struct {
+	ID        int64     `json:"id"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"createdAt"`
+}
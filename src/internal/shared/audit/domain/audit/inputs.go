@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"auth-api/src/pkg/app_error"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type RecordInput struct {
+	Actor   string
+	Action  string
+	Details string
+}
+
+func (input *RecordInput) Validate() error {
+	if len(input.Actor) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Actor is required", fmt.Sprintf("Field: %s", "Actor"))
+	}
+	if len(input.Action) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Action is required", fmt.Sprintf("Field: %s", "Action"))
+	}
+	return nil
+}
+
+type CountByActionInput struct {
+	Actions []string
+	Since   time.Time
+}
+
+func (input *CountByActionInput) Validate() error {
+	if len(input.Actions) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Actions are required", fmt.Sprintf("Field: %s", "Actions"))
+	}
+	if input.Since.IsZero() {
+		return app_error.NewApiError(http.StatusBadRequest, "Since is required", fmt.Sprintf("Field: %s", "Since"))
+	}
+	return nil
+}
+
+// CountLatestStateInput counts the subjects (stored in Details) whose most
+// recent entry among Actions is State, e.g. users whose last MFA change was an
+// activation.
+type CountLatestStateInput struct {
+	Actions []string
+	State   string
+}
+
+func (input *CountLatestStateInput) Validate() error {
+	if len(input.Actions) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Actions are required", fmt.Sprintf("Field: %s", "Actions"))
+	}
+	if len(input.State) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "State is required", fmt.Sprintf("Field: %s", "State"))
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+type AuditRepository interface {
+	Save(ctx context.Context, entry *Entry) error
+	CountByAction(ctx context.Context, actions []string, since time.Time) (map[string]int64, error)
+	CountLatestState(ctx context.Context, actions []string, state string) (int64, error)
+}
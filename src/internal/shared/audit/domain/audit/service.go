@@ -0,0 +1,9 @@
+package audit
+
+import "context"
+
+type AuditService interface {
+	Record(ctx context.Context, input RecordInput) error
+	CountByAction(ctx context.Context, input CountByActionInput) (map[string]int64, error)
+	CountLatestState(ctx context.Context, input CountLatestStateInput) (int64, error)
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type AuditRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewAuditRepository(db *sql.DB, logger logger.Logger) audit.AuditRepository {
+	return &AuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *AuditRepository) Save(ctx context.Context, entry *audit.Entry) error {
+	query := `INSERT INTO audit_logs (actor, action, details, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	if err := r.db.QueryRowContext(ctx, query, entry.Actor, entry.Action, entry.Details, entry.CreatedAt).Scan(&entry.ID); err != nil {
+		r.logger.Error("Error saving audit entry: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *AuditRepository) CountByAction(ctx context.Context, actions []string, since time.Time) (map[string]int64, error) {
+	query := `SELECT action, COUNT(*) FROM audit_logs WHERE action = ANY($1) AND created_at >= $2 GROUP BY action`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(actions), since)
+	if err != nil {
+		r.logger.Error("Error counting audit entries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64, len(actions))
+	for _, action := range actions {
+		counts[action] = 0
+	}
+	for rows.Next() {
+		var action string
+		var count int64
+		if err := rows.Scan(&action, &count); err != nil {
+			r.logger.Error("Error scanning audit count: %v", err)
+			return nil, err
+		}
+		counts[action] = count
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating audit counts: %v", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *AuditRepository) CountLatestState(ctx context.Context, actions []string, state string) (int64, error) {
+	query := `SELECT COUNT(*) FROM (
+		SELECT DISTINCT ON (details) action FROM audit_logs
+		WHERE action = ANY($1)
+		ORDER BY details, created_at DESC, id DESC
+	) latest WHERE action = $2`
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query, pq.Array(actions), state).Scan(&count); err != nil {
+		r.logger.Error("Error counting latest audit state: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"auth-api/src/internal/shared/audit/domain/audit"
+	"auth-api/src/pkg/logger"
+	"context"
+	"time"
+)
+
+type AuditServiceImpl struct {
+	repo   audit.AuditRepository
+	logger logger.Logger
+}
+
+func NewAuditServiceImpl(repo audit.AuditRepository, logger logger.Logger) audit.AuditService {
+	return &AuditServiceImpl{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *AuditServiceImpl) Record(ctx context.Context, input audit.RecordInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	entry := &audit.Entry{
+		Actor:     input.Actor,
+		Action:    input.Action,
+		Details:   input.Details,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.repo.Save(ctx, entry); err != nil {
+		return err
+	}
+
+	s.logger.Info("audit: actor=%s action=%s details=%s", entry.Actor, entry.Action, entry.Details)
+	return nil
+}
+
+func (s *AuditServiceImpl) CountByAction(ctx context.Context, input audit.CountByActionInput) (map[string]int64, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.CountByAction(ctx, input.Actions, input.Since)
+}
+
+func (s *AuditServiceImpl) CountLatestState(ctx context.Context, input audit.CountLatestStateInput) (int64, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.CountLatestState(ctx, input.Actions, input.State)
+}
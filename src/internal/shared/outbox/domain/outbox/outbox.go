@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Message is an event waiting in the outbox table until the relay has
+// published it. It is written in the same transaction as the change it
+// describes, so either both are stored or neither is.
+type Message struct {
+	ID        int64
+	Event     string
+	Payload   json.RawMessage
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+func NewMessage(event string, data any) (*Message, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Event:     event,
+		Payload:   payload,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Execer is satisfied by both *sql.DB and *sql.Tx, so repositories can add a
+// message inside the transaction they are already in.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
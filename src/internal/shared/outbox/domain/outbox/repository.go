@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+type OutboxRepository interface {
+	Add(ctx context.Context, exec Execer, message *Message) error
+	// ClaimPending locks unsent messages oldest first, leaving out those
+	// that already failed maxAttempts times. No other relay can claim them
+	// until the batch is committed or rolled back.
+	ClaimPending(ctx context.Context, limit, maxAttempts int) (Batch, error)
+}
+
+// Batch is a claim on pending messages. Marks take effect, and the claim is
+// released, on Commit; Rollback after Commit is a no-op, so it can be
+// deferred.
+type Batch interface {
+	Messages() []Message
+	MarkSent(ctx context.Context, id int64, sentAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, reason string) error
+	Commit() error
+	Rollback() error
+}
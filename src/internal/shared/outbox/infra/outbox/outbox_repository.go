@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"auth-api/src/internal/shared/outbox/domain/outbox"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type OutboxRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+func NewOutboxRepository(db *sql.DB, logger logger.Logger) outbox.OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, exec outbox.Execer, message *outbox.Message) error {
+	query := `INSERT INTO outbox_messages (event, payload, created_at) VALUES ($1, $2, $3)`
+	if _, err := exec.ExecContext(ctx, query, message.Event, []byte(message.Payload), message.CreatedAt); err != nil {
+		r.logger.Error("Error adding outbox message: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit, maxAttempts int) (outbox.Batch, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Error starting outbox transaction: %v", err)
+		return nil, err
+	}
+	batch := &outboxBatch{tx: tx, logger: r.logger}
+
+	// Relays take turns on an advisory lock, so one batch is published at a
+	// time and later messages never overtake an earlier one.
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('outbox_messages'))`).Scan(&locked); err != nil {
+		tx.Rollback()
+		r.logger.Error("Error locking outbox: %v", err)
+		return nil, err
+	}
+	if !locked {
+		// Another relay is publishing; its batch is not ours to send.
+		return batch, nil
+	}
+
+	query := `SELECT id, event, payload, attempts, last_error, created_at FROM outbox_messages WHERE sent_at IS NULL AND attempts < $1 ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED`
+	rows, err := tx.QueryContext(ctx, query, maxAttempts, limit)
+	if err != nil {
+		tx.Rollback()
+		r.logger.Error("Error claiming outbox messages: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message outbox.Message
+		var payload []byte
+		if err := rows.Scan(&message.ID, &message.Event, &payload, &message.Attempts, &message.LastError, &message.CreatedAt); err != nil {
+			tx.Rollback()
+			r.logger.Error("Error scanning outbox message: %v", err)
+			return nil, err
+		}
+		message.Payload = payload
+		batch.messages = append(batch.messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		r.logger.Error("Error iterating outbox messages: %v", err)
+		return nil, err
+	}
+	return batch, nil
+}
+
+// outboxBatch holds its messages' row locks in tx until it ends.
+type outboxBatch struct {
+	tx       *sql.Tx
+	logger   logger.Logger
+	messages []outbox.Message
+}
+
+func (b *outboxBatch) Messages() []outbox.Message {
+	return b.messages
+}
+
+func (b *outboxBatch) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	query := `UPDATE outbox_messages SET sent_at = $1 WHERE id = $2`
+	if _, err := b.tx.ExecContext(ctx, query, sentAt, id); err != nil {
+		b.logger.Error("Error marking outbox message sent: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (b *outboxBatch) MarkFailed(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE outbox_messages SET attempts = attempts + 1, last_error = $1 WHERE id = $2`
+	if _, err := b.tx.ExecContext(ctx, query, reason, id); err != nil {
+		b.logger.Error("Error marking outbox message failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (b *outboxBatch) Commit() error {
+	if err := b.tx.Commit(); err != nil {
+		b.logger.Error("Error committing outbox batch: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (b *outboxBatch) Rollback() error {
+	if err := b.tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"auth-api/src/internal/shared/outbox/domain/outbox"
+	"auth-api/src/internal/shared/webhook/domain/webhook"
+	"auth-api/src/pkg/logger"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Delivery is what the relay publishes for each message. Delivery is at least
+// once: a crash before the batch commits publishes its messages again on the
+// next run, so receivers dedupe on MessageID.
+type Delivery struct {
+	MessageID  int64           `json:"messageId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Relay publishes pending outbox messages in the order they were written. A
+// failed message stops the batch, so later events never overtake it, and is
+// retried on the next poll until it has failed maxAttempts times.
+type Relay struct {
+	repo        outbox.OutboxRepository
+	publisher   webhook.WebhookPublisher
+	logger      logger.Logger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewRelay(repo outbox.OutboxRepository, publisher webhook.WebhookPublisher, logger logger.Logger, interval time.Duration, batchSize, maxAttempts int) *Relay {
+	return &Relay{
+		repo:        repo,
+		publisher:   publisher,
+		logger:      logger,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run polls until ctx is done, starting with whatever an earlier process left
+// unsent.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Error relaying outbox messages: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch and returns how many messages were sent.
+// The batch stays claimed while it is published, so a message is never sent
+// by two relays at once.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	batch, err := r.repo.ClaimPending(ctx, r.batchSize, r.maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	defer batch.Rollback()
+
+	sent := 0
+	for _, message := range batch.Messages() {
+		delivery := Delivery{
+			MessageID:  message.ID,
+			OccurredAt: message.CreatedAt,
+			Payload:    message.Payload,
+		}
+		if err := r.publisher.Publish(ctx, message.Event, delivery); err != nil {
+			if markErr := batch.MarkFailed(ctx, message.ID, err.Error()); markErr != nil {
+				return sent, markErr
+			}
+			if message.Attempts+1 >= r.maxAttempts {
+				r.logger.Error("Outbox message %d (%s) gave up after %d attempts: %v", message.ID, message.Event, r.maxAttempts, err)
+			}
+			return sent, batch.Commit()
+		}
+		if err := batch.MarkSent(ctx, message.ID, time.Now().UTC()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, batch.Commit()
+}
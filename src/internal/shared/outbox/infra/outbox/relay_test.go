@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"auth-api/src/internal/shared/outbox/domain/outbox"
+	"auth-api/src/pkg/logger"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryOutbox claims all pending messages at once and applies the marks of
+// a batch only when it commits, as the transaction does.
+type memoryOutbox struct {
+	outbox.OutboxRepository
+	messages []outbox.Message
+	claimed  bool
+}
+
+func (m *memoryOutbox) ClaimPending(ctx context.Context, limit, maxAttempts int) (outbox.Batch, error) {
+	batch := &memoryBatch{outbox: m}
+	if m.claimed {
+		return batch, nil
+	}
+	m.claimed = true
+	for _, message := range m.messages {
+		if message.SentAt == nil && message.Attempts < maxAttempts && len(batch.messages) < limit {
+			batch.messages = append(batch.messages, message)
+		}
+	}
+	return batch, nil
+}
+
+type memoryBatch struct {
+	outbox   *memoryOutbox
+	messages []outbox.Message
+	marks    []func(*outbox.Message)
+	ids      []int64
+	done     bool
+}
+
+func (b *memoryBatch) Messages() []outbox.Message { return b.messages }
+
+func (b *memoryBatch) MarkSent(ctx context.Context, id int64, sentAt time.Time) error {
+	b.mark(id, func(m *outbox.Message) { m.SentAt = &sentAt })
+	return nil
+}
+
+func (b *memoryBatch) MarkFailed(ctx context.Context, id int64, reason string) error {
+	b.mark(id, func(m *outbox.Message) { m.Attempts++; m.LastError = reason })
+	return nil
+}
+
+func (b *memoryBatch) mark(id int64, apply func(*outbox.Message)) {
+	b.ids = append(b.ids, id)
+	b.marks = append(b.marks, apply)
+}
+
+func (b *memoryBatch) Commit() error {
+	for i, id := range b.ids {
+		for j := range b.outbox.messages {
+			if b.outbox.messages[j].ID == id {
+				b.marks[i](&b.outbox.messages[j])
+			}
+		}
+	}
+	return b.end()
+}
+
+func (b *memoryBatch) Rollback() error {
+	if b.done {
+		return nil
+	}
+	return b.end()
+}
+
+func (b *memoryBatch) end() error {
+	b.done = true
+	b.outbox.claimed = false
+	return nil
+}
+
+// failingPublisher rejects the events listed in fail.
+type failingPublisher struct {
+	fail      map[string]bool
+	published []string
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event string, data any) error {
+	if p.fail[event] {
+		return errors.New("rejected")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestRelayPublishesClaimedBatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		fail          map[string]bool
+		wantSent      int
+		wantPublished []string
+		wantAttempts  int
+	}{
+		{
+			name:          "all published",
+			wantSent:      3,
+			wantPublished: []string{"first", "second", "third"},
+		},
+		{
+			name:          "a failure stops the batch",
+			fail:          map[string]bool{"second": true},
+			wantSent:      1,
+			wantPublished: []string{"first"},
+			wantAttempts:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryOutbox{messages: []outbox.Message{
+				{ID: 1, Event: "first"},
+				{ID: 2, Event: "second"},
+				{ID: 3, Event: "third"},
+			}}
+			publisher := &failingPublisher{fail: tt.fail}
+			relay := NewRelay(repo, publisher, logger.Nop{}, time.Second, 10, 3)
+
+			sent, err := relay.RelayPending(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sent != tt.wantSent || len(publisher.published) != len(tt.wantPublished) {
+				t.Fatalf("sent %d, published %v, want %d and %v", sent, publisher.published, tt.wantSent, tt.wantPublished)
+			}
+			for i, event := range tt.wantPublished {
+				if publisher.published[i] != event {
+					t.Errorf("published %v, want %v", publisher.published, tt.wantPublished)
+				}
+			}
+			if repo.claimed {
+				t.Errorf("batch still claimed after RelayPending")
+			}
+			if repo.messages[1].Attempts != tt.wantAttempts {
+				t.Errorf("second message attempts = %d, want %d", repo.messages[1].Attempts, tt.wantAttempts)
+			}
+
+			// The committed marks keep sent messages from going out again.
+			publisher.published = nil
+			publisher.fail = nil
+			if _, err := relay.RelayPending(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if len(publisher.published) != 3-tt.wantSent {
+				t.Errorf("second run published %v, want the %d unsent", publisher.published, 3-tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRelaySkipsBatchClaimedElsewhere(t *testing.T) {
+	repo := &memoryOutbox{messages: []outbox.Message{{ID: 1, Event: "first"}}}
+	other, err := repo.ClaimPending(context.Background(), 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Rollback()
+
+	publisher := &failingPublisher{}
+	sent, err := NewRelay(repo, publisher, logger.Nop{}, time.Second, 10, 3).RelayPending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || len(publisher.published) != 0 {
+		t.Errorf("published %v while another relay held the batch", publisher.published)
+	}
+}
@@ -0,0 +1,9 @@
+package session
+
+import "auth-api/src/pkg/app_error"
+
+var (
+	ErrSessionNotFound     = app_error.NewApiError(401, "Session not found", "Session not found")
+	ErrSessionExpired      = app_error.NewApiError(401, "Stored session expired", "The session expired, please sign in again")
+	ErrSessionLimitReached = app_error.NewApiError(409, "Session limit reached", "Maximum number of active sessions reached")
+)
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"auth-api/src/pkg/app_error"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type CreateInput struct {
+	// Purpose defaults to PurposeWeb and TTL to the service's session TTL.
+	Purpose              string
+	TTL                  time.Duration
+	Username             string
+	AccessToken          string
+	IdToken              string
+	RefreshToken         string
+	AccessTokenExpiresAt time.Time
+}
+
+func (input *CreateInput) Validate() error {
+	if len(input.Username) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Username is required", fmt.Sprintf("Field: %s", "Username"))
+	}
+	if len(input.AccessToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Access token is required", fmt.Sprintf("Field: %s", "AccessToken"))
+	}
+	if len(input.RefreshToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Refresh token is required", fmt.Sprintf("Field: %s", "RefreshToken"))
+	}
+	return nil
+}
+
+type GetInput struct {
+	Token string
+	// Purpose defaults to PurposeWeb; a session with another purpose is
+	// reported as not found.
+	Purpose string
+}
+
+func (input *GetInput) Validate() error {
+	if len(input.Token) == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+type UpdateTokensInput struct {
+	Token                string
+	AccessToken          string
+	IdToken              string
+	AccessTokenExpiresAt time.Time
+}
+
+func (input *UpdateTokensInput) Validate() error {
+	if len(input.Token) == 0 {
+		return ErrSessionNotFound
+	}
+	if len(input.AccessToken) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Access token is required", fmt.Sprintf("Field: %s", "AccessToken"))
+	}
+	return nil
+}
+
+type DeleteInput struct {
+	Token string
+}
+
+func (input *DeleteInput) Validate() error {
+	if len(input.Token) == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+type ListActiveInput struct {
+	Username string
+	// Purpose defaults to PurposeWeb.
+	Purpose string
+}
+
+func (input *ListActiveInput) Validate() error {
+	if len(input.Username) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Username is required", fmt.Sprintf("Field: %s", "Username"))
+	}
+	return nil
+}
+
+type DeleteByIDInput struct {
+	ID string
+}
+
+func (input *DeleteByIDInput) Validate() error {
+	if len(input.ID) == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// CreateWithinLimitInput creates a session unless the user already has Limit
+// active sessions of the LimitedPurposes. With EvictOldest the oldest ones
+// make room instead. A Limit of zero means no limit.
+type CreateWithinLimitInput struct {
+	CreateInput
+	Limit       int
+	EvictOldest bool
+}
+
+func (input *CreateWithinLimitInput) Validate() error {
+	return input.CreateInput.Validate()
+}
+
+// DeleteSignInsInput names the user whose sign ins all ended at once, e.g.
+// through a global sign out.
+type DeleteSignInsInput struct {
+	Username string
+}
+
+func (input *DeleteSignInsInput) Validate() error {
+	if len(input.Username) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Username is required", fmt.Sprintf("Field: %s", "Username"))
+	}
+	return nil
+}
+
+type DeleteByUsernameInput struct {
+	Username string
+	Purpose  string
+}
+
+func (input *DeleteByUsernameInput) Validate() error {
+	if len(input.Username) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Username is required", fmt.Sprintf("Field: %s", "Username"))
+	}
+	if len(input.Purpose) == 0 {
+		return app_error.NewApiError(http.StatusBadRequest, "Purpose is required", fmt.Sprintf("Field: %s", "Purpose"))
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package session
+
+type CreateWithinLimitOutput struct {
+	Token   string
+	Session *Session
+	// Evicted are the sessions removed to make room. Their tokens are still
+	// live in Cognito and should be revoked.
+	Evicted []*Session
+}
@@ -0,0 +1,18 @@
+package session
+
+import "context"
+
+type SessionRepository interface {
+	Save(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, id string) (*Session, error)
+	UpdateTokens(ctx context.Context, session *Session) error
+	Delete(ctx context.Context, id string) error
+	ListByUsername(ctx context.Context, username, purpose string) ([]*Session, error)
+	// SaveWithinLimit saves session once the user's active sessions of the
+	// given purposes are below limit, evicting the oldest when evictOldest is
+	// set and failing with ErrSessionLimitReached otherwise. The count and the
+	// insert are one transaction that concurrent sign ins of the same user
+	// wait on.
+	SaveWithinLimit(ctx context.Context, session *Session, purposes []string, limit int, evictOldest bool) ([]*Session, error)
+	DeleteByUsername(ctx context.Context, username, purpose string) error
+}
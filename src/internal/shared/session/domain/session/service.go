@@ -0,0 +1,25 @@
+package session
+
+import "context"
+
+type SessionService interface {
+	// Create stores a new session and returns the opaque token to hand to the
+	// client. Only a hash of the token is persisted.
+	Create(ctx context.Context, input CreateInput) (string, *Session, error)
+	// CreateWithinLimit is Create holding the user to a maximum number of
+	// sessions, enforced atomically by the store.
+	CreateWithinLimit(ctx context.Context, input CreateWithinLimitInput) (*CreateWithinLimitOutput, error)
+	Get(ctx context.Context, input GetInput) (*Session, error)
+	UpdateTokens(ctx context.Context, input UpdateTokensInput) (*Session, error)
+	Delete(ctx context.Context, input DeleteInput) error
+	// ListActive returns the user's unexpired sessions, oldest first.
+	ListActive(ctx context.Context, input ListActiveInput) ([]*Session, error)
+	// DeleteByID removes a session by its stored ID, for when the raw token
+	// isn't at hand.
+	DeleteByID(ctx context.Context, input DeleteByIDInput) error
+	// DeleteByUsername removes all of the user's sessions of a purpose.
+	DeleteByUsername(ctx context.Context, input DeleteByUsernameInput) error
+	// DeleteSignIns removes the user's sessions of the LimitedPurposes, once
+	// their tokens were revoked together.
+	DeleteSignIns(ctx context.Context, input DeleteSignInsInput) error
+}
@@ -0,0 +1,41 @@
+package session
+
+import "time"
+
+// Access tokens are refreshed this long before they actually expire so a
+// request doesn't start with a token that dies on the way to Cognito.
+const accessTokenExpirySkew = 30 * time.Second
+
+// A session's purpose limits where its token is accepted; a session parked
+// for MFA enrollment must never work as a web session.
+const (
+	PurposeWeb           = "web"
+	PurposeMFAEnrollment = "mfa_enrollment"
+	// PurposeToken records a sign in whose tokens went straight to the
+	// client, so it counts towards the session limit. Its own token is never
+	// handed out.
+	PurposeToken = "token"
+)
+
+// LimitedPurposes are the sessions that count towards the per user limit.
+var LimitedPurposes = []string{PurposeWeb, PurposeToken}
+
+type Session struct {
+	ID                   string
+	Purpose              string
+	Username             string
+	AccessToken          string
+	IdToken              string
+	RefreshToken         string
+	AccessTokenExpiresAt time.Time
+	ExpiresAt            time.Time
+	CreatedAt            time.Time
+}
+
+func (s *Session) IsExpired() bool {
+	return s.ExpiresAt.Before(time.Now())
+}
+
+func (s *Session) AccessTokenExpired() bool {
+	return s.AccessTokenExpiresAt.Before(time.Now().Add(accessTokenExpirySkew))
+}
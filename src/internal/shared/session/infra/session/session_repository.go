@@ -0,0 +1,213 @@
+package session
+
+import (
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const sessionColumns = `id, purpose, username, access_token, id_token, refresh_token, access_token_expires_at, expires_at, created_at`
+
+// SessionRepository keeps the Cognito tokens of a session encrypted with key.
+// Rows it can't open, e.g. written under another key, read as signed out.
+type SessionRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+	sealer *tokenSealer
+}
+
+func NewSessionRepository(db *sql.DB, logger logger.Logger, key []byte) session.SessionRepository {
+	return &SessionRepository{
+		db:     db,
+		logger: logger,
+		sealer: newTokenSealer(key),
+	}
+}
+
+func (r *SessionRepository) Save(ctx context.Context, s *session.Session) error {
+	accessToken, idToken, refreshToken, err := r.seal(s)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO sessions (id, purpose, username, access_token, id_token, refresh_token, access_token_expires_at, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	if _, err := r.db.ExecContext(ctx, query, s.ID, s.Purpose, s.Username, accessToken, idToken, refreshToken, s.AccessTokenExpiresAt, s.ExpiresAt, s.CreatedAt); err != nil {
+		r.logger.Error("Error saving session: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id string) (*session.Session, error) {
+	query := `SELECT id, purpose, username, access_token, id_token, refresh_token, access_token_expires_at, expires_at, created_at FROM sessions WHERE id = $1`
+	s := &session.Session{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&s.ID, &s.Purpose, &s.Username, &s.AccessToken, &s.IdToken, &s.RefreshToken, &s.AccessTokenExpiresAt, &s.ExpiresAt, &s.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, session.ErrSessionNotFound
+		}
+		r.logger.Error("Error finding session: %v", err)
+		return nil, err
+	}
+	if err := r.open(s); err != nil {
+		r.logger.Warning("Error opening session tokens: %v", err)
+		return nil, session.ErrSessionNotFound
+	}
+	return s, nil
+}
+
+func (r *SessionRepository) UpdateTokens(ctx context.Context, s *session.Session) error {
+	accessToken, idToken, _, err := r.seal(s)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE sessions SET access_token = $1, id_token = $2, access_token_expires_at = $3 WHERE id = $4`
+	if _, err := r.db.ExecContext(ctx, query, accessToken, idToken, s.AccessTokenExpiresAt, s.ID); err != nil {
+		r.logger.Error("Error updating session tokens: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM sessions WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		r.logger.Error("Error deleting session: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) ListByUsername(ctx context.Context, username, purpose string) ([]*session.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE username = $1 AND purpose = $2 AND expires_at > NOW() ORDER BY created_at ASC`
+	rows, err := r.db.QueryContext(ctx, query, username, purpose)
+	if err != nil {
+		r.logger.Error("Error listing sessions: %v", err)
+		return nil, err
+	}
+	return r.scanSessions(rows)
+}
+
+func (r *SessionRepository) SaveWithinLimit(ctx context.Context, s *session.Session, purposes []string, limit int, evictOldest bool) ([]*session.Session, error) {
+	accessToken, idToken, refreshToken, err := r.seal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Error starting session transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Row locks can't cover a user with no sessions yet, so the user's sign
+	// ins queue on an advisory lock held until the transaction ends.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, s.Username); err != nil {
+		r.logger.Error("Error locking sessions: %v", err)
+		return nil, err
+	}
+
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE username = $1 AND purpose = ANY($2) AND expires_at > NOW() ORDER BY created_at ASC`
+	rows, err := tx.QueryContext(ctx, query, s.Username, pq.Array(purposes))
+	if err != nil {
+		r.logger.Error("Error listing sessions: %v", err)
+		return nil, err
+	}
+	active, err := r.scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var evicted []*session.Session
+	if excess := len(active) - limit + 1; excess > 0 {
+		if !evictOldest {
+			return nil, session.ErrSessionLimitReached
+		}
+		evicted = active[:excess]
+		ids := make([]string, len(evicted))
+		for i, old := range evicted {
+			ids[i] = old.ID
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+			r.logger.Error("Error evicting sessions: %v", err)
+			return nil, err
+		}
+	}
+
+	insert := `INSERT INTO sessions (` + sessionColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	if _, err := tx.ExecContext(ctx, insert, s.ID, s.Purpose, s.Username, accessToken, idToken, refreshToken, s.AccessTokenExpiresAt, s.ExpiresAt, s.CreatedAt); err != nil {
+		r.logger.Error("Error saving session: %v", err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Error committing session: %v", err)
+		return nil, err
+	}
+	return evicted, nil
+}
+
+func (r *SessionRepository) DeleteByUsername(ctx context.Context, username, purpose string) error {
+	query := `DELETE FROM sessions WHERE username = $1 AND purpose = $2`
+	if _, err := r.db.ExecContext(ctx, query, username, purpose); err != nil {
+		r.logger.Error("Error deleting sessions: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) scanSessions(rows *sql.Rows) ([]*session.Session, error) {
+	defer rows.Close()
+
+	var sessions []*session.Session
+	for rows.Next() {
+		s := &session.Session{}
+		if err := rows.Scan(&s.ID, &s.Purpose, &s.Username, &s.AccessToken, &s.IdToken, &s.RefreshToken, &s.AccessTokenExpiresAt, &s.ExpiresAt, &s.CreatedAt); err != nil {
+			r.logger.Error("Error scanning session: %v", err)
+			return nil, err
+		}
+		// Still listed, so it counts towards the limit and can be evicted;
+		// there are just no tokens left to revoke.
+		if err := r.open(s); err != nil {
+			r.logger.Warning("Error opening session tokens: %v", err)
+			s.AccessToken, s.IdToken, s.RefreshToken = "", "", ""
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating sessions: %v", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) seal(s *session.Session) (accessToken, idToken, refreshToken string, err error) {
+	if accessToken, err = r.sealer.seal(s.ID, s.AccessToken); err != nil {
+		r.logger.Error("Error sealing session tokens: %v", err)
+		return "", "", "", err
+	}
+	if idToken, err = r.sealer.seal(s.ID, s.IdToken); err != nil {
+		r.logger.Error("Error sealing session tokens: %v", err)
+		return "", "", "", err
+	}
+	if refreshToken, err = r.sealer.seal(s.ID, s.RefreshToken); err != nil {
+		r.logger.Error("Error sealing session tokens: %v", err)
+		return "", "", "", err
+	}
+	return accessToken, idToken, refreshToken, nil
+}
+
+// open decrypts the tokens of s in place.
+func (r *SessionRepository) open(s *session.Session) error {
+	var err error
+	if s.AccessToken, err = r.sealer.open(s.ID, s.AccessToken); err != nil {
+		return err
+	}
+	if s.IdToken, err = r.sealer.open(s.ID, s.IdToken); err != nil {
+		return err
+	}
+	s.RefreshToken, err = r.sealer.open(s.ID, s.RefreshToken)
+	return err
+}
@@ -0,0 +1,193 @@
+package session
+
+import (
+	"auth-api/src/internal/shared/session/domain/session"
+	"auth-api/src/pkg/logger"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+const sessionTokenBytes = 32
+
+type SessionServiceImpl struct {
+	repo   session.SessionRepository
+	logger logger.Logger
+	ttl    time.Duration
+}
+
+func NewSessionServiceImpl(repo session.SessionRepository, logger logger.Logger, ttl time.Duration) session.SessionService {
+	return &SessionServiceImpl{
+		repo:   repo,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+func (s *SessionServiceImpl) Create(ctx context.Context, input session.CreateInput) (string, *session.Session, error) {
+	if err := input.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	token, sess, err := s.newSession(input)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.repo.Save(ctx, sess); err != nil {
+		return "", nil, err
+	}
+	return token, sess, nil
+}
+
+func (s *SessionServiceImpl) CreateWithinLimit(ctx context.Context, input session.CreateWithinLimitInput) (*session.CreateWithinLimitOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, sess, err := s.newSession(input.CreateInput)
+	if err != nil {
+		return nil, err
+	}
+	if input.Limit <= 0 {
+		if err := s.repo.Save(ctx, sess); err != nil {
+			return nil, err
+		}
+		return &session.CreateWithinLimitOutput{Token: token, Session: sess}, nil
+	}
+
+	evicted, err := s.repo.SaveWithinLimit(ctx, sess, session.LimitedPurposes, input.Limit, input.EvictOldest)
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateWithinLimitOutput{Token: token, Session: sess, Evicted: evicted}, nil
+}
+
+// newSession builds the session for input along with its raw token.
+func (s *SessionServiceImpl) newSession(input session.CreateInput) (string, *session.Session, error) {
+	raw := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		s.logger.Error("Error generating session token: %v", err)
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = session.PurposeWeb
+	}
+	ttl := input.TTL
+	if ttl == 0 {
+		ttl = s.ttl
+	}
+
+	now := time.Now().UTC()
+	return token, &session.Session{
+		ID:                   hashToken(token),
+		Purpose:              purpose,
+		Username:             input.Username,
+		AccessToken:          input.AccessToken,
+		IdToken:              input.IdToken,
+		RefreshToken:         input.RefreshToken,
+		AccessTokenExpiresAt: input.AccessTokenExpiresAt,
+		ExpiresAt:            now.Add(ttl),
+		CreatedAt:            now,
+	}, nil
+}
+
+func (s *SessionServiceImpl) Get(ctx context.Context, input session.GetInput) (*session.Session, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.repo.FindByID(ctx, hashToken(input.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = session.PurposeWeb
+	}
+	if sess.Purpose != purpose {
+		return nil, session.ErrSessionNotFound
+	}
+
+	if sess.IsExpired() {
+		if err := s.repo.Delete(ctx, sess.ID); err != nil {
+			s.logger.Error("Error deleting expired session: %v", err)
+		}
+		return nil, session.ErrSessionExpired
+	}
+	return sess, nil
+}
+
+func (s *SessionServiceImpl) UpdateTokens(ctx context.Context, input session.UpdateTokensInput) (*session.Session, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	sess, err := s.Get(ctx, session.GetInput{Token: input.Token})
+	if err != nil {
+		return nil, err
+	}
+
+	sess.AccessToken = input.AccessToken
+	sess.IdToken = input.IdToken
+	sess.AccessTokenExpiresAt = input.AccessTokenExpiresAt
+	if err := s.repo.UpdateTokens(ctx, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+func (s *SessionServiceImpl) Delete(ctx context.Context, input session.DeleteInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, hashToken(input.Token))
+}
+
+func (s *SessionServiceImpl) ListActive(ctx context.Context, input session.ListActiveInput) ([]*session.Session, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = session.PurposeWeb
+	}
+	return s.repo.ListByUsername(ctx, input.Username, purpose)
+}
+
+func (s *SessionServiceImpl) DeleteByID(ctx context.Context, input session.DeleteByIDInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, input.ID)
+}
+
+func (s *SessionServiceImpl) DeleteByUsername(ctx context.Context, input session.DeleteByUsernameInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.DeleteByUsername(ctx, input.Username, input.Purpose)
+}
+
+func (s *SessionServiceImpl) DeleteSignIns(ctx context.Context, input session.DeleteSignInsInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	for _, purpose := range session.LimitedPurposes {
+		if err := s.repo.DeleteByUsername(ctx, input.Username, purpose); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+var errUnsealable = errors.New("session token can't be opened")
+
+// tokenSealer encrypts the Cognito tokens kept in the sessions table with
+// AES-GCM, so a copy of the table alone doesn't hand them out. Each token is
+// bound to its session ID, so it can't be moved to another row.
+type tokenSealer struct {
+	aead cipher.AEAD
+}
+
+// newTokenSealer derives the AES-256 key from key, so a secret of any length
+// works.
+func newTokenSealer(key []byte) *tokenSealer {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		// A 32 byte key is always valid.
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &tokenSealer{aead: aead}
+}
+
+// seal leaves an empty token empty, e.g. a session without an ID token.
+func (s *tokenSealer) seal(id, token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(token)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(token), []byte(id))
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *tokenSealer) open(id, sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return "", errUnsealable
+	}
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	token, err := s.aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", errUnsealable
+	}
+	return string(token), nil
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenSealer(t *testing.T) {
+	const id, token = "session-id", "eyJraWQiOiJyZWZyZXNoIn0.refresh-token"
+	sealer := newTokenSealer([]byte("session-key"))
+
+	sealed, err := sealer.seal(id, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, token) {
+		t.Fatalf("sealed token %q holds the plaintext", sealed)
+	}
+
+	tests := []struct {
+		name    string
+		sealer  *tokenSealer
+		id      string
+		sealed  string
+		want    string
+		wantErr bool
+	}{
+		{name: "round trip", sealer: sealer, id: id, sealed: sealed, want: token},
+		{name: "empty stays empty", sealer: sealer, id: id, sealed: ""},
+		{name: "moved to another session", sealer: sealer, id: "other-id", sealed: sealed, wantErr: true},
+		{name: "another key", sealer: newTokenSealer([]byte("other-key")), id: id, sealed: sealed, wantErr: true},
+		{name: "plaintext row", sealer: sealer, id: id, sealed: token, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sealer.open(tt.id, tt.sealed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("open error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("open = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package storage
+
+import "auth-api/src/pkg/app_error"
+
+var (
+	ErrObjectKeyEmpty       = app_error.NewApiError(400, "Object key is empty")
+	ErrObjectBodyEmpty      = app_error.NewApiError(400, "Object body is empty")
+	ErrPresignExpiryInvalid = app_error.NewApiError(400, "Presigned URL expiry must be positive")
+)
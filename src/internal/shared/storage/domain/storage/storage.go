@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"io"
+	"time"
+)
+
+type PutObjectInput struct {
+	Key         string
+	ContentType string
+	// Body is read twice, once to sign it and once to send it.
+	Body io.ReadSeeker
+	Size int64
+}
+
+func (input PutObjectInput) Validate() error {
+	if input.Key == "" {
+		return ErrObjectKeyEmpty
+	}
+	if input.Body == nil {
+		return ErrObjectBodyEmpty
+	}
+	return nil
+}
+
+type PresignGetInput struct {
+	Key    string
+	Expiry time.Duration
+	// Filename, when set, is the name browsers save the download as.
+	Filename string
+}
+
+func (input PresignGetInput) Validate() error {
+	if input.Key == "" {
+		return ErrObjectKeyEmpty
+	}
+	if input.Expiry <= 0 {
+		return ErrPresignExpiryInvalid
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package storage
+
+import "context"
+
+type StorageService interface {
+	PutObject(ctx context.Context, input PutObjectInput) error
+	// PresignGet returns a URL anyone can fetch the object with until it
+	// expires.
+	PresignGet(ctx context.Context, input PresignGetInput) (string, error)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"auth-api/src/internal/shared/storage/domain/storage"
+	"auth-api/src/pkg/logger"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestPresignGetExpiry(t *testing.T) {
+	credentials := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}, nil
+	})
+	s3 := NewS3Storage(aws.Config{Region: "us-east-1", Credentials: credentials}, "exports", logger.Nop{})
+
+	tests := []struct {
+		name    string
+		expiry  time.Duration
+		want    string
+		wantErr error
+	}{
+		{name: "minutes", expiry: 15 * time.Minute, want: "900"},
+		{name: "fraction of a second dropped", expiry: 90*time.Second + 500*time.Millisecond, want: "90"},
+		{name: "no expiry", expiry: 0, wantErr: storage.ErrPresignExpiryInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := s3.PresignGet(context.Background(), storage.PresignGetInput{Key: "users/export.csv", Expiry: tt.expiry, Filename: "users.csv"})
+			if err != tt.wantErr {
+				t.Fatalf("PresignGet error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			u, err := url.Parse(signed)
+			if err != nil {
+				t.Fatalf("presigned URL %q: %v", signed, err)
+			}
+			query := u.Query()
+			if got := query.Get("X-Amz-Expires"); got != tt.want {
+				t.Errorf("X-Amz-Expires = %q, want %q", got, tt.want)
+			}
+			if query.Get("X-Amz-Signature") == "" {
+				t.Errorf("presigned URL %q isn't signed", signed)
+			}
+			if got := query.Get("response-content-disposition"); got != "attachment; filename=users.csv" {
+				t.Errorf("response-content-disposition = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package webhook
+
+import "auth-api/src/pkg/app_error"
+
+var (
+	ErrWebhookRejected = app_error.NewApiError(502, "Webhook rejected", "Webhook endpoint rejected the delivery")
+)
@@ -0,0 +1,7 @@
+package webhook
+
+import "context"
+
+type WebhookPublisher interface {
+	Publish(ctx context.Context, event string, data any) error
+}
@@ -0,0 +1,9 @@
+package webhook
+
+import "time"
+
+type Envelope struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"auth-api/src/internal/shared/webhook/domain/webhook"
+	"auth-api/src/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type HTTPWebhookPublisher struct {
+	client *http.Client
+	url    string
+	secret string
+	logger logger.Logger
+}
+
+func NewHTTPWebhookPublisher(url, secret string, timeout time.Duration, logger logger.Logger) webhook.WebhookPublisher {
+	return &HTTPWebhookPublisher{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+		secret: secret,
+		logger: logger,
+	}
+}
+
+func (p *HTTPWebhookPublisher) Publish(ctx context.Context, event string, data any) error {
+	body, err := json.Marshal(webhook.Envelope{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	// Receivers verify the body with the shared secret before trusting it.
+	if p.secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.secret))
+		mac.Write(body)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		p.logger.Error("Error delivering webhook %s: %v", event, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		p.logger.Error("Webhook %s rejected with status %d", event, resp.StatusCode)
+		return webhook.ErrWebhookRejected
+	}
+	return nil
+}
@@ -6,6 +6,9 @@ type ApiError struct {
 	Message     string `json:"message,omitempty"`
 	Description string `json:"description,omitempty"`
 	StatusCode  int    `json:"-"`
+	// Fields is set on errors built by ValidationErrors and on conflicts
+	// about a single field.
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 func (e *ApiError) Error() string {
@@ -0,0 +1,64 @@
+package app_error
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Fields maps each invalid or conflicting field to its message.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Code returns a stable, machine readable code derived from the message,
+// e.g. "User not found" becomes "USER_NOT_FOUND".
+func (e *ApiError) Code() string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range e.Message {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if underscore && b.Len() > 0 {
+				b.WriteRune('_')
+			}
+			underscore = false
+			b.WriteRune(unicode.ToUpper(r))
+			continue
+		}
+		underscore = true
+	}
+	return b.String()
+}
+
+func (e *ApiError) ToProblem(instance string) *Problem {
+	detail := e.Message
+	if e.Description != "" {
+		detail = e.Message + ": " + e.Description
+	}
+	return &Problem{
+		Type:     "about:blank",
+		Title:    statusTitle(e.StatusCode),
+		Status:   e.StatusCode,
+		Detail:   detail,
+		Instance: instance,
+		Code:     e.Code(),
+		Fields:   e.Fields,
+	}
+}
+
+// statusTitle also names 499, which net/http doesn't know.
+func statusTitle(status int) string {
+	if status == 499 {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
@@ -0,0 +1,21 @@
+package app_error
+
+import "testing"
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{message: "User not found", want: "USER_NOT_FOUND"},
+		{message: "Concurrent modification, please try again", want: "CONCURRENT_MODIFICATION_PLEASE_TRY_AGAIN"},
+		{message: "  Leading and trailing!  ", want: "LEADING_AND_TRAILING"},
+		{message: "Rate limit: 10/min", want: "RATE_LIMIT_10_MIN"},
+		{message: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := NewApiError(400, tt.message).Code(); got != tt.want {
+			t.Errorf("Code(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
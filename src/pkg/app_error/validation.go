@@ -0,0 +1,51 @@
+package app_error
+
+import (
+	"net/http"
+	"strings"
+)
+
+const fieldPrefix = "Field: "
+
+// ValidationErrors collects the errors of every field check so a form gets
+// all of its problems in one response rather than the first one only.
+type ValidationErrors struct {
+	errs []error
+}
+
+func (v *ValidationErrors) Add(err error) {
+	if err != nil {
+		v.errs = append(v.errs, err)
+	}
+}
+
+// Err returns nil when nothing failed and a single failure as is. Several
+// field errors become one 400 whose Fields maps each field to its message.
+// An error that isn't a 400 for a field is returned on its own, since the
+// request can't be treated as a plain validation failure.
+func (v *ValidationErrors) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	if len(v.errs) == 1 {
+		return v.errs[0]
+	}
+
+	fields := make(map[string]string, len(v.errs))
+	names := make([]string, 0, len(v.errs))
+	for _, err := range v.errs {
+		apiErr, ok := err.(*ApiError)
+		if !ok || apiErr.StatusCode != http.StatusBadRequest || !strings.HasPrefix(apiErr.Description, fieldPrefix) {
+			return err
+		}
+		name := strings.TrimPrefix(apiErr.Description, fieldPrefix)
+		if _, seen := fields[name]; !seen {
+			names = append(names, name)
+			fields[name] = apiErr.Message
+		}
+	}
+
+	apiErr := NewApiError(http.StatusBadRequest, "Validation error", "Fields: "+strings.Join(names, ", "))
+	apiErr.Fields = fields
+	return apiErr
+}
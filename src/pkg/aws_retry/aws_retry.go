@@ -0,0 +1,62 @@
+package aws_retry
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// Errors caused by the caller's input. Retrying them only delays the answer,
+// and for credential checks burns through Cognito's failed attempt counter.
+var nonRetryableCognitoCodes = map[string]struct{}{
+	"NotAuthorizedException":         {},
+	"CodeMismatchException":          {},
+	"ExpiredCodeException":           {},
+	"InvalidPasswordException":       {},
+	"InvalidParameterException":      {},
+	"UserNotFoundException":          {},
+	"UserNotConfirmedException":      {},
+	"UsernameExistsException":        {},
+	"AliasExistsException":           {},
+	"PasswordResetRequiredException": {},
+}
+
+var retryableCognitoCodes = map[string]struct{}{
+	"TooManyRequestsException": {},
+	"ThrottlingException":      {},
+	"LimitExceededException":   {},
+	"InternalErrorException":   {},
+}
+
+// IsCognitoErrorRetryable classifies Cognito API errors. Anything it doesn't
+// know about is left to the standard retryer checks.
+func IsCognitoErrorRetryable(err error) aws.Ternary {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return aws.UnknownTernary
+	}
+	if _, ok := nonRetryableCognitoCodes[apiErr.ErrorCode()]; ok {
+		return aws.FalseTernary
+	}
+	if _, ok := retryableCognitoCodes[apiErr.ErrorCode()]; ok {
+		return aws.TrueTernary
+	}
+	return aws.UnknownTernary
+}
+
+// NewCognitoRetryer returns a retryer for the Cognito client that fails fast on
+// client errors and retries throttling with backoff.
+func NewCognitoRetryer(maxAttempts int, maxBackoff time.Duration) aws.Retryer {
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		if maxAttempts > 0 {
+			o.MaxAttempts = maxAttempts
+		}
+		if maxBackoff > 0 {
+			o.MaxBackoff = maxBackoff
+		}
+		o.Retryables = append([]retry.IsErrorRetryable{retry.IsErrorRetryableFunc(IsCognitoErrorRetryable)}, o.Retryables...)
+	})
+}
@@ -0,0 +1,47 @@
+package aws_retry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestCognitoRetryerIsErrorRetryable(t *testing.T) {
+	retryer := NewCognitoRetryer(3, 0)
+
+	apiError := func(code string) error {
+		return &smithy.GenericAPIError{Code: code, Message: "from cognito"}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "wrong password", err: apiError("NotAuthorizedException"), want: false},
+		{name: "wrong code", err: apiError("CodeMismatchException"), want: false},
+		{name: "unknown user", err: apiError("UserNotFoundException"), want: false},
+		{name: "throttled", err: apiError("TooManyRequestsException"), want: true},
+		{name: "throttling", err: apiError("ThrottlingException"), want: true},
+		{name: "limit exceeded", err: apiError("LimitExceededException"), want: true},
+		{name: "internal error", err: apiError("InternalErrorException"), want: true},
+		{name: "wrapped client error", err: fmt.Errorf("login: %w", apiError("NotAuthorizedException")), want: false},
+		{name: "wrapped throttle", err: fmt.Errorf("login: %w", apiError("TooManyRequestsException")), want: true},
+		{name: "unknown code left to the standard checks", err: apiError("ResourceNotFoundException"), want: false},
+		{name: "not an API error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryer.IsErrorRetryable(tt.err); got != tt.want {
+				t.Errorf("IsErrorRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if got := retryer.MaxAttempts(); got != 3 {
+		t.Errorf("MaxAttempts = %d, want 3", got)
+	}
+}
@@ -0,0 +1,63 @@
+package cursor
+
+import (
+	"auth-api/src/pkg/app_error"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+var ErrInvalidCursor = app_error.NewApiError(400, "Invalid cursor", "Cursor is invalid or was not issued for this endpoint")
+
+// Signer seals upstream pagination tokens (e.g. Cognito's) into opaque
+// cursors with AES-GCM, so clients can neither read nor tamper with them. The
+// endpoint is authenticated alongside the token, so a cursor from one list
+// can't be replayed against another.
+type Signer struct {
+	aead cipher.AEAD
+}
+
+// NewSigner derives the AES-256 key from key, so a secret of any length works.
+func NewSigner(key []byte) *Signer {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		// A 32 byte key is always valid.
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &Signer{aead: aead}
+}
+
+// Wrap returns an empty cursor for an empty token, i.e. the last page.
+func (s *Signer) Wrap(endpoint, token string) string {
+	if token == "" {
+		return ""
+	}
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(token)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(token), []byte(endpoint))
+	return base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Unwrap returns the upstream token behind cursor, or ErrInvalidCursor if it
+// was altered or sealed for another endpoint.
+func (s *Signer) Unwrap(endpoint, cursor string) (string, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", ErrInvalidCursor
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	token, err := s.aead.Open(nil, nonce, ciphertext, []byte(endpoint))
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(token), nil
+}
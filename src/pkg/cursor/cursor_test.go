@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("cursor-key"))
+	const token = "upstream+pagination/token=="
+	cursor := signer.Wrap("/admin/users", token)
+	raw, _ := base64.RawURLEncoding.DecodeString(cursor)
+	raw[len(raw)/2] ^= 1
+	flipped := base64.RawURLEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name     string
+		signer   *Signer
+		endpoint string
+		cursor   string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "round trip",
+			signer:   signer,
+			endpoint: "/admin/users",
+			cursor:   cursor,
+			want:     token,
+		},
+		{
+			name:     "other endpoint",
+			signer:   signer,
+			endpoint: "/admin/groups",
+			cursor:   cursor,
+			wantErr:  ErrInvalidCursor,
+		},
+		{
+			name:     "other key",
+			signer:   NewSigner([]byte("another-key")),
+			endpoint: "/admin/users",
+			cursor:   cursor,
+			wantErr:  ErrInvalidCursor,
+		},
+		{
+			name:     "byte flipped",
+			signer:   signer,
+			endpoint: "/admin/users",
+			cursor:   flipped,
+			wantErr:  ErrInvalidCursor,
+		},
+		{
+			name:     "truncated",
+			signer:   signer,
+			endpoint: "/admin/users",
+			cursor:   cursor[:len(cursor)-2],
+			wantErr:  ErrInvalidCursor,
+		},
+		{
+			name:     "shorter than a nonce",
+			signer:   signer,
+			endpoint: "/admin/users",
+			cursor:   cursor[:8],
+			wantErr:  ErrInvalidCursor,
+		},
+		{
+			name:     "not base64",
+			signer:   signer,
+			endpoint: "/admin/users",
+			cursor:   "!!!???",
+			wantErr:  ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.signer.Unwrap(tt.endpoint, tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unwrap = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapLastPage(t *testing.T) {
+	if got := NewSigner([]byte("k")).Wrap("/admin/users", ""); got != "" {
+		t.Errorf("Wrap of an empty token = %q, want empty", got)
+	}
+}
+
+func TestWrapHidesTheToken(t *testing.T) {
+	signer := NewSigner([]byte("cursor-key"))
+	const token = "member@example.com"
+
+	first, second := signer.Wrap("/admin/users", token), signer.Wrap("/admin/users", token)
+	if first == second {
+		t.Errorf("the same token wrapped twice gave the same cursor")
+	}
+	for _, cursor := range []string{first, second} {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(raw), token) || strings.Contains(cursor, base64.RawURLEncoding.EncodeToString([]byte(token))) {
+			t.Errorf("cursor %q carries the token in the clear", cursor)
+		}
+	}
+}
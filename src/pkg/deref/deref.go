@@ -0,0 +1,29 @@
+package deref
+
+func String(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func Bool(v *bool) bool {
+	if v == nil {
+		return false
+	}
+	return *v
+}
+
+func Int32(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func Int64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,34 @@
+package deref
+
+import "testing"
+
+func TestNilYieldsZero(t *testing.T) {
+	if got := String(nil); got != "" {
+		t.Errorf("String(nil) = %q, want empty", got)
+	}
+	if got := Bool(nil); got {
+		t.Error("Bool(nil) = true, want false")
+	}
+	if got := Int32(nil); got != 0 {
+		t.Errorf("Int32(nil) = %d, want 0", got)
+	}
+	if got := Int64(nil); got != 0 {
+		t.Errorf("Int64(nil) = %d, want 0", got)
+	}
+}
+
+func TestValueIsReturned(t *testing.T) {
+	s, b, i32, i64 := "sub", true, int32(3600), int64(1700000000)
+	if got := String(&s); got != s {
+		t.Errorf("String = %q, want %q", got, s)
+	}
+	if got := Bool(&b); !got {
+		t.Error("Bool = false, want true")
+	}
+	if got := Int32(&i32); got != i32 {
+		t.Errorf("Int32 = %d, want %d", got, i32)
+	}
+	if got := Int64(&i64); got != i64 {
+		t.Errorf("Int64 = %d, want %d", got, i64)
+	}
+}
@@ -0,0 +1,105 @@
+// Package device_srp computes the secret verifier Cognito needs to remember a
+// device, following the same steps as the AWS Amplify client.
+package device_srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+)
+
+// nHex is the 3072-bit SRP group prime used by Cognito (RFC 5054).
+const nHex = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1" +
+	"29024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+	"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245" +
+	"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+	"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D" +
+	"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+	"83655D23DCA3AD961C62F356208552BB9ED529077096966D" +
+	"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+	"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9" +
+	"DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+	"15728E5A8AAAC42DAD33170D04507A33A85521ABDF1CBA64" +
+	"ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7" +
+	"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6B" +
+	"F12FFA06D98A0864D87602733EC86A64521F2B18177B200C" +
+	"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB31" +
+	"43DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF"
+
+const (
+	saltBytes     = 16
+	passwordBytes = 40
+)
+
+// N and G are the SRP group, also used by the user password SRP flow.
+var (
+	N, _ = new(big.Int).SetString(nHex, 16)
+	G    = big.NewInt(2)
+)
+
+// Verifier is what ConfirmDevice expects, plus the random password the client
+// must keep to authenticate with the device later.
+type Verifier struct {
+	PasswordVerifier string
+	Salt             string
+	DevicePassword   string
+}
+
+// NewVerifier generates a random device password and salt and derives the
+// verifier for the given device.
+func NewVerifier(deviceGroupKey, deviceKey string) (*Verifier, error) {
+	password := make([]byte, passwordBytes)
+	if _, err := rand.Read(password); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return ComputeVerifier(deviceGroupKey, deviceKey, base64.StdEncoding.EncodeToString(password), new(big.Int).SetBytes(salt))
+}
+
+// ComputeVerifier derives the verifier from a known password and salt:
+// x = H(salt | H(deviceGroupKey | deviceKey | ":" | password)), v = g^x mod N.
+func ComputeVerifier(deviceGroupKey, deviceKey, password string, salt *big.Int) (*Verifier, error) {
+	fullPassword := sha256.Sum256([]byte(deviceGroupKey + deviceKey + ":" + password))
+
+	saltHex := PadHex(salt)
+	xInput, err := hex.DecodeString(saltHex + hex.EncodeToString(fullPassword[:]))
+	if err != nil {
+		return nil, err
+	}
+	xHash := sha256.Sum256(xInput)
+	x := new(big.Int).SetBytes(xHash[:])
+
+	verifier := new(big.Int).Exp(G, x, N)
+
+	verifierBytes, err := hex.DecodeString(PadHex(verifier))
+	if err != nil {
+		return nil, err
+	}
+	saltBytes, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{
+		PasswordVerifier: base64.StdEncoding.EncodeToString(verifierBytes),
+		Salt:             base64.StdEncoding.EncodeToString(saltBytes),
+		DevicePassword:   password,
+	}, nil
+}
+
+// PadHex encodes v as an even length hex string with a leading zero byte when
+// the high bit is set, so it's never read back as negative.
+func PadHex(v *big.Int) string {
+	s := v.Text(16)
+	if len(s)%2 == 1 {
+		s = "0" + s
+	} else if s[0] >= '8' {
+		s = "00" + s
+	}
+	return s
+}
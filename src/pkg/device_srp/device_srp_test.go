@@ -0,0 +1,120 @@
+package device_srp
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("bad hex %q", s)
+	}
+	return v
+}
+
+func TestPadHex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1", "01"},
+		{"7f", "7f"},
+		{"80", "0080"},
+		{"123", "0123"},
+		{"ff00", "00ff00"},
+		{"7fff", "7fff"},
+	}
+	for _, tt := range tests {
+		if got := PadHex(mustHex(t, tt.in)); got != tt.want {
+			t.Errorf("PadHex(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// The expected values follow AuthenticationHelper.generateHashDevice in
+// amazon-cognito-identity-js step by step, computed outside this package.
+// Each salt hits a different padHex branch: short, high bit set and odd
+// length, and the last verifier itself needs the leading zero byte.
+func TestComputeVerifier(t *testing.T) {
+	tests := []struct {
+		name           string
+		deviceGroupKey string
+		deviceKey      string
+		password       string
+		saltHex        string
+		wantVerifier   string
+		wantSalt       string
+	}{
+		{
+			name:           "single byte salt",
+			deviceGroupKey: "-abcdEFGH",
+			deviceKey:      "us-east-1_0123abcd-4567-89ab-cdef-0123456789ab",
+			password:       "password",
+			saltHex:        "01",
+			wantVerifier:   "WflvqxAYPNi0+EoVXMHMbR5DGCTH9W/ieoAF8spX+MxkgmosKHYSBqCq/qgM8w9dnW1xfmSfEuKV6CZK/TKw0XuxUxOwuijoVTriDKLWFQXq++BXleiufY5mcFRCPKolywmPCK33/gT64GgNk5btJwVaKWfclkq0jivTw68A8MF6/s4hpjrzUlDixC8rPizpn4+HqndTpK+kD/5pNnBN9OjtAPXBznCqbWs6RV6gIg6LixIKEBI8rEeEvU4se735tfR45mwCC7Ahgo3q76y7JMZEFdLABmYu3U8RTm4vafSPghAgT8VVsH3zEQ8EqHp6/fCQyxkOyvm2KvSfxPVnUEVMwSUYUTF8k5mllLZ3Jw3AShPru4/kkolNeHFj4kNzV5zTiKxDC4CPXfdtbZPORHwfQsbLSb5sZLP6Io400uVpeNta9zoHNzJPJ/gIfsrPwlXPsW8y5X2K+3YL6maYDwG75N1A57kg6fbh0cgYdEtChFMmhOEUDB8RWZTsiG9a",
+			wantSalt:       "AQ==",
+		},
+		{
+			name:           "salt with the high bit set",
+			deviceGroupKey: "group",
+			deviceKey:      "us-west-2_device",
+			password:       "bZ3+xH2Jdf1l6bQ4bq3h8DNt3g5mS0Ri5l1XxO7cQ1nYyTgq3+Vw2Q==",
+			saltHex:        "80f1e2d3c4b5a69788796a5b4c3d2e1f",
+			wantVerifier:   "R2FxfVQEkQ47WoMuv4tmHtE45jCV73IvId3fNyy3Nf6p64xk/Iy3k1edBHHUegieToRoey8ggFhD4e6JcCCu9y/NwuO29Rj4z0WSiu0aTpBFiijzM7O0sxzQFvitOkDh8hnbxt3kJxN3TX/lx3OZX/vKjAqjPAN0iLw9exWvgz5kVNzOoDY1UO6TQ9ojLJHgIIqLYQEn8IjtKij213NlXH/TrBalLuK6gSOqagX+ooqIqMF9BDoMgH5Ac+i4EHMyigyksEF2oWyYV+SYkKOaYytfRe3JQSBmXVzqKc7DWnVlc5HZctYgcz4wDFV6rWY7xJ8pw2lx/Wk3ej1ffl6rtupHilD7+p9+hZY1gjPGcsx42pUMXRZI6Wz4tL355hSfEkX14MzWYfDwDHkFtpfMk4NGpwZriE5I7E+nCBzvBvtYsSztrN0hWbegIU5xtmlJniF2Z6QGm4COoV389sJgLA5S+cFB86xLIUIVXQzU9Z305kFwqPXt3tkwjO3lGpKZ",
+			wantSalt:       "AIDx4tPEtaaXiHlqW0w9Lh8=",
+		},
+		{
+			name:           "odd length salt and padded verifier",
+			deviceGroupKey: "g",
+			deviceKey:      "d",
+			password:       "",
+			saltHex:        "123",
+			wantVerifier:   "ANM9KCRHifAyVx5ohvavftJ7Kks1NBi7UZ3mjVc0oOOQ2okWyQdkzuDRwZqqns2ufIc06XUJS8u1YPeYZ0T02a0OnMllAEyOXV+1ptbtNiixN2dCkShX02KN7Fs08bwa/sCt93Fvx8eknrGP576uFBc8jKgoc+EEMUCrB9SHhwdANy5+YRTYDQiO0i6/N9MtJebZDzbZqfPShXcixxR8DgEGeoTihCS6IjkxSLMIxrI49xhlA+NMWG1JRd3xB9S055fU5J+UwuAc2wALo9qXKib0eP58UIXKHd+1+gZumAnP1kC3c4EShcffMA2uLAHoaDN9HU5a+rE9pnTF+lmTOanuQQfRQUK2zriwTXRrtE0wtVZeyGNnAl1JtN97iXKoDZcWlw3RQTU12EWDnImmxoxNHrB9jppxIUtHeQJoUTn8wvKMd93IUTwgmZwdFvYKRzojDcVjuKVhw74SaZmvzlTeUXbB55IiMeBrWtON0pflGZ1hQKizsDY3Gn+2bV4KJw==",
+			wantSalt:       "ASM=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComputeVerifier(tt.deviceGroupKey, tt.deviceKey, tt.password, mustHex(t, tt.saltHex))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PasswordVerifier != tt.wantVerifier {
+				t.Errorf("PasswordVerifier = %s, want %s", got.PasswordVerifier, tt.wantVerifier)
+			}
+			if got.Salt != tt.wantSalt {
+				t.Errorf("Salt = %s, want %s", got.Salt, tt.wantSalt)
+			}
+			if got.DevicePassword != tt.password {
+				t.Errorf("DevicePassword = %s, want %s", got.DevicePassword, tt.password)
+			}
+		})
+	}
+}
+
+func TestNewVerifierIsReproducible(t *testing.T) {
+	v, err := NewVerifier("group", "device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := base64.StdEncoding.DecodeString(v.DevicePassword)
+	if err != nil || len(password) != passwordBytes {
+		t.Fatalf("DevicePassword is not %d base64 bytes: %q", passwordBytes, v.DevicePassword)
+	}
+	salt, err := base64.StdEncoding.DecodeString(v.Salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := ComputeVerifier("group", "device", v.DevicePassword, new(big.Int).SetBytes(salt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.PasswordVerifier != v.PasswordVerifier {
+		t.Error("verifier can't be recomputed from the returned password and salt")
+	}
+}
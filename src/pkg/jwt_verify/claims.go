@@ -1,30 +1,69 @@
 package jwt_verify
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	AccessToken     string   `json:"accessToken"`
-	Alg             string   `json:"alg"`
-	Kid             string   `json:"kid"`
-	Aud             string   `json:"aud"`
-	AuthTime        int64    `json:"auth_time"`
-	CognitoUsername string   `json:"cognito:username"`
-	UserGroups      []string `json:"cognito:groups"`
-	Email           string   `json:"email"`
-	EmailVerified   bool     `json:"email_verified"`
-	EventID         string   `json:"event_id"`
-	Exp             int64    `json:"exp"`
-	Iat             int64    `json:"iat"`
-	Iss             string   `json:"iss"`
-	Jti             string   `json:"jti"`
-	Name            string   `json:"name"`
-	OriginJti       string   `json:"origin_jti"`
-	Sub             string   `json:"sub"`
-	TokenUse        string   `json:"token_use"`
+	AccessToken string `json:"accessToken"`
+	Alg         string `json:"alg"`
+	Kid         string `json:"kid"`
+	Aud         string `json:"aud"`
+	// AuthTime is optional and may be encoded as a float, hence NumericDate.
+	AuthTime        *jwt.NumericDate `json:"auth_time,omitempty"`
+	CognitoUsername string           `json:"cognito:username"`
+	UserGroups      []string         `json:"cognito:groups"`
+	Email           string           `json:"email"`
+	EmailVerified   bool             `json:"email_verified"`
+	EventID         string           `json:"event_id"`
+	Exp             int64            `json:"exp"`
+	Iat             int64            `json:"iat"`
+	Iss             string           `json:"iss"`
+	Jti             string           `json:"jti"`
+	Name            string           `json:"name"`
+	OriginJti       string           `json:"origin_jti"`
+	Sub             string           `json:"sub"`
+	TokenUse        string           `json:"token_use"`
+	// DeviceKey is only in access tokens issued to a tracked device.
+	DeviceKey string `json:"device_key,omitempty"`
+	// Custom holds the "custom:" user pool attributes, keyed by full name.
+	Custom map[string]string `json:"-"`
+	// ClientId is the app client an access token was issued to.
+	ClientId string `json:"client_id,omitempty"`
+	// Scope is an access token's space separated OAuth scopes.
+	Scope string `json:"scope,omitempty"`
+}
+
+// UnmarshalJSON also collects the "custom:" attributes, whose names depend on
+// the pool. Non-string values are kept as their JSON text.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		if !strings.HasPrefix(key, "custom:") {
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(value, &str); err != nil {
+			str = string(value)
+		}
+		if c.Custom == nil {
+			c.Custom = make(map[string]string)
+		}
+		c.Custom[key] = str
+	}
+	return nil
 }
 
 func (c *Claims) GetExpirationTime() (*jwt.NumericDate, error) {
@@ -35,6 +74,15 @@ func (c *Claims) GetIssuedAt() (*jwt.NumericDate, error) {
 	return &jwt.NumericDate{Time: time.Unix(c.Iat, 0)}, nil
 }
 
+// GetAuthTime returns when the user last actually authenticated, and false
+// when the token doesn't carry auth_time.
+func (c *Claims) GetAuthTime() (time.Time, bool) {
+	if c.AuthTime == nil || c.AuthTime.IsZero() || c.AuthTime.Unix() <= 0 {
+		return time.Time{}, false
+	}
+	return c.AuthTime.Time, true
+}
+
 func (c *Claims) GetNotBefore() (*jwt.NumericDate, error) {
 	return nil, nil
 }
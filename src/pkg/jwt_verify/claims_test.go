@@ -0,0 +1,117 @@
+package jwt_verify
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthTime(t *testing.T) {
+	pool := newTestPool(t, "k1")
+	v := pool.verifier(t)
+	signedIn := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		authTime any
+		want     time.Time
+		wantOk   bool
+	}{
+		{name: "seconds", authTime: signedIn.Unix(), want: signedIn, wantOk: true},
+		// jwt.TimePrecision keeps whole seconds.
+		{name: "float seconds", authTime: float64(signedIn.Unix()) + 0.5, want: signedIn, wantOk: true},
+		{name: "absent"},
+		{name: "zero", authTime: 0},
+		{name: "null", authTime: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := pool.claims()
+			if tt.name != "absent" {
+				claims["auth_time"] = tt.authTime
+			}
+			token := sign(t, jwt.SigningMethodRS256, pool.kid, claims, pool.key)
+
+			_, verified, err := v.ParseJWT(token)
+			if err != nil {
+				t.Fatalf("ParseJWT: %v", err)
+			}
+			unverified, err := ParseUnverifiedClaims(token)
+			if err != nil {
+				t.Fatalf("ParseUnverifiedClaims: %v", err)
+			}
+
+			for source, c := range map[string]*Claims{"verified": verified, "unverified": unverified} {
+				got, ok := c.GetAuthTime()
+				if ok != tt.wantOk || !got.Equal(tt.want) {
+					t.Errorf("%s GetAuthTime = %s %v, want %s %v", source, got, ok, tt.want, tt.wantOk)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthTimeOfAWrongType(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := sign(t, jwt.SigningMethodRS256, "k1", jwt.MapClaims{"sub": "user-1", "auth_time": "yesterday"}, key)
+
+	if _, err := ParseUnverifiedClaims(token); err == nil {
+		t.Error("ParseUnverifiedClaims accepted a string auth_time")
+	}
+}
+
+func TestCustomAttributes(t *testing.T) {
+	pool := newTestPool(t, "k1")
+	v := pool.verifier(t)
+
+	tests := []struct {
+		name   string
+		custom map[string]any
+		want   map[string]string
+	}{
+		{name: "none"},
+		{
+			name:   "string attribute",
+			custom: map[string]any{"custom:tenantId": "t-42"},
+			want:   map[string]string{"custom:tenantId": "t-42"},
+		},
+		{
+			name:   "non-string kept as JSON",
+			custom: map[string]any{"custom:tenantId": "t-42", "custom:seats": 10, "custom:beta": true},
+			want:   map[string]string{"custom:tenantId": "t-42", "custom:seats": "10", "custom:beta": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := pool.claims()
+			for key, value := range tt.custom {
+				claims[key] = value
+			}
+			token := sign(t, jwt.SigningMethodRS256, pool.kid, claims, pool.key)
+
+			_, verified, err := v.ParseJWT(token)
+			if err != nil {
+				t.Fatalf("ParseJWT: %v", err)
+			}
+			if len(verified.Custom) != len(tt.want) {
+				t.Fatalf("Custom = %v, want %v", verified.Custom, tt.want)
+			}
+			for key, want := range tt.want {
+				if got := verified.Custom[key]; got != want {
+					t.Errorf("Custom[%s] = %q, want %q", key, got, want)
+				}
+			}
+			if verified.Sub == "" {
+				t.Error("standard claims were dropped")
+			}
+		})
+	}
+}
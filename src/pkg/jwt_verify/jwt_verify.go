@@ -6,26 +6,39 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// DefaultAllowedAlgorithms is what Cognito signs its tokens with.
+var DefaultAllowedAlgorithms = []string{"RS256"}
+
+var (
+	ErrAlgorithmNotAllowed = errors.New("signing algorithm not allowed")
+	ErrKeyNotFound         = errors.New("no JWK matches the token's kid")
+)
+
 type JWTVerify interface {
 	CacheJWK() error
 	ParseJWT(tokenString string) (*jwt.Token, *Claims, error)
 	JWK() *JWK
 	JWKURL() string
+	Issuer() string
 }
 
 type jwtVerify struct {
 	jwk               *JWK
 	jwkURL            string
+	issuer            string
 	cognitoRegion     string
 	cognitoUserPoolID string
+	allowedAlgorithms []string
 	log               logger.Logger
 }
 
@@ -39,18 +52,42 @@ type JWK struct {
 	} `json:"keys"`
 }
 
-func NewAuth(cognitoRegion, cognitoUserPoolID string, logger logger.Logger) JWTVerify {
+// NewAuth falls back to DefaultAllowedAlgorithms when no algorithm is given.
+func NewAuth(cognitoRegion, cognitoUserPoolID string, logger logger.Logger, allowedAlgorithms ...string) JWTVerify {
+	if len(allowedAlgorithms) == 0 {
+		allowedAlgorithms = DefaultAllowedAlgorithms
+	}
 	a := &jwtVerify{
 		cognitoRegion:     cognitoRegion,
 		cognitoUserPoolID: cognitoUserPoolID,
+		allowedAlgorithms: allowedAlgorithms,
 		log:               logger,
 	}
 
-	a.jwkURL = fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s/.well-known/jwks.json", a.cognitoRegion, a.cognitoUserPoolID)
+	a.issuer = fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", a.cognitoRegion, a.cognitoUserPoolID)
+	a.jwkURL = a.issuer + "/.well-known/jwks.json"
 
 	return a
 }
 
+// NewSource verifies tokens from any issuer publishing its keys at jwkURL,
+// e.g. a pool behind a custom domain. An empty jwkURL means the issuer's
+// /.well-known/jwks.json.
+func NewSource(issuer, jwkURL string, logger logger.Logger, allowedAlgorithms ...string) JWTVerify {
+	if len(allowedAlgorithms) == 0 {
+		allowedAlgorithms = DefaultAllowedAlgorithms
+	}
+	if jwkURL == "" {
+		jwkURL = strings.TrimSuffix(issuer, "/") + "/.well-known/jwks.json"
+	}
+	return &jwtVerify{
+		issuer:            issuer,
+		jwkURL:            jwkURL,
+		allowedAlgorithms: allowedAlgorithms,
+		log:               logger,
+	}
+}
+
 func (a *jwtVerify) CacheJWK() error { // Check when we need to cache the JWK
 	req, err := http.NewRequest("GET", a.jwkURL, nil)
 	if err != nil {
@@ -86,9 +123,13 @@ func (a *jwtVerify) CacheJWK() error { // Check when we need to cache the JWK
 
 func (a *jwtVerify) ParseJWT(tokenString string) (*jwt.Token, *Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		key, err := convertKey(a.jwk.Keys[0].E, a.jwk.Keys[0].N)
-		return key, err
-	})
+		// Runs before the signature is checked, so a token can't pick "none" or
+		// an HMAC algorithm and have the public key used as the secret.
+		if err := a.checkAlgorithm(token); err != nil {
+			return nil, err
+		}
+		return a.key(token)
+	}, jwt.WithIssuer(a.issuer))
 	if err != nil {
 		a.log.Error("Error parsing JWT %v", err)
 		return token, nil, err
@@ -103,6 +144,54 @@ func (a *jwtVerify) ParseJWT(tokenString string) (*jwt.Token, *Claims, error) {
 	return token, claims, nil
 }
 
+func (a *jwtVerify) checkAlgorithm(token *jwt.Token) error {
+	alg, _ := token.Header["alg"].(string)
+	allowed := false
+	for _, allowedAlg := range a.allowedAlgorithms {
+		if alg == allowedAlg {
+			allowed = true
+			break
+		}
+	}
+	// The keys come from the Cognito JWKS, which only holds RSA keys.
+	isRSA := false
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		isRSA = true
+	}
+	if !allowed || !isRSA {
+		return fmt.Errorf("%w: %q", ErrAlgorithmNotAllowed, alg)
+	}
+	return nil
+}
+
+// key picks the JWK named by the token's kid. Pools sign ID and access tokens
+// with different keys, so the first key only fits one of them. A token
+// without a kid gets the first key.
+func (a *jwtVerify) key(token *jwt.Token) (*rsa.PublicKey, error) {
+	if a.jwk == nil || len(a.jwk.Keys) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return convertKey(a.jwk.Keys[0].E, a.jwk.Keys[0].N)
+	}
+	for _, k := range a.jwk.Keys {
+		if k.Kid == kid {
+			return convertKey(k.E, k.N)
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+}
+
+func ParseUnverifiedClaims(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
 func (a *jwtVerify) JWK() *JWK {
 	return a.jwk
 }
@@ -111,6 +200,10 @@ func (a *jwtVerify) JWKURL() string {
 	return a.jwkURL
 }
 
+func (a *jwtVerify) Issuer() string {
+	return a.issuer
+}
+
 func convertKey(rawE, rawN string) (*rsa.PublicKey, error) {
 	decodedE, err := base64.RawURLEncoding.DecodeString(rawE)
 	if err != nil {
@@ -0,0 +1,240 @@
+package jwt_verify
+
+import (
+	"auth-api/src/pkg/logger"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testPool is an issuer serving one RSA key from its own JWKS endpoint.
+type testPool struct {
+	issuer string
+	kid    string
+	key    *rsa.PrivateKey
+	server *httptest.Server
+}
+
+func newTestPool(t *testing.T, kid string) *testPool {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testPool{kid: kid, key: key}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"alg": "RS256",
+				"kty": "RSA",
+				"kid": kid,
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(p.server.Close)
+	p.issuer = p.server.URL + "/pool-" + kid
+	return p
+}
+
+func (p *testPool) verifier(t *testing.T, allowedAlgorithms ...string) JWTVerify {
+	t.Helper()
+	v := NewSource(p.issuer, p.server.URL, logger.Nop{}, allowedAlgorithms...)
+	if err := v.CacheJWK(); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func (p *testPool) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":       p.issuer,
+		"sub":       "user-1",
+		"token_use": "access",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParseJWTAlgorithmAllowlist(t *testing.T) {
+	pool := newTestPool(t, "k1")
+	publicKeyBytes := pool.key.PublicKey.N.Bytes()
+
+	tests := []struct {
+		name    string
+		allowed []string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "RS256 by default",
+			token: sign(t, jwt.SigningMethodRS256, "k1", pool.claims(), pool.key),
+		},
+		{
+			name:    "RS512 outside the default allowlist",
+			token:   sign(t, jwt.SigningMethodRS512, "k1", pool.claims(), pool.key),
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+		{
+			name:    "RS512 once allowed",
+			allowed: []string{"RS256", "RS512"},
+			token:   sign(t, jwt.SigningMethodRS512, "k1", pool.claims(), pool.key),
+		},
+		{
+			name:    "PS256 once allowed",
+			allowed: []string{"PS256"},
+			token:   sign(t, jwt.SigningMethodPS256, "k1", pool.claims(), pool.key),
+		},
+		{
+			name:    "HMAC keyed with the public key even when listed",
+			allowed: []string{"RS256", "HS256"},
+			token:   sign(t, jwt.SigningMethodHS256, "k1", pool.claims(), publicKeyBytes),
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+		{
+			name:    "none",
+			allowed: []string{"none"},
+			token:   sign(t, jwt.SigningMethodNone, "k1", pool.claims(), jwt.UnsafeAllowNoneSignatureType),
+			wantErr: ErrAlgorithmNotAllowed,
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, "other", pool.claims(), pool.key),
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:  "no kid uses the first key",
+			token: sign(t, jwt.SigningMethodRS256, "", pool.claims(), pool.key),
+		},
+		{
+			name: "another issuer",
+			token: func() string {
+				claims := pool.claims()
+				claims["iss"] = "https://example.com/not-the-pool"
+				return sign(t, jwt.SigningMethodRS256, "k1", claims, pool.key)
+			}(),
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, claims, err := pool.verifier(t, tt.allowed...).ParseJWT(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Sub != "user-1" {
+				t.Errorf("Sub = %q, want user-1", claims.Sub)
+			}
+		})
+	}
+}
+
+func TestMultiIssuer(t *testing.T) {
+	poolA := newTestPool(t, "a")
+	poolB := newTestPool(t, "b")
+	untrusted := newTestPool(t, "c")
+	verifier := NewMultiIssuer(logger.Nop{}, poolA.verifier(t), poolB.verifier(t))
+
+	// Tokens naming B as issuer but signed with A's key: only B's keys may be
+	// tried, whatever kid the token picks.
+	forged := poolB.claims()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+		wantIss string
+	}{
+		{
+			name:    "primary pool",
+			token:   sign(t, jwt.SigningMethodRS256, "a", poolA.claims(), poolA.key),
+			wantIss: poolA.issuer,
+		},
+		{
+			name:    "secondary pool",
+			token:   sign(t, jwt.SigningMethodRS256, "b", poolB.claims(), poolB.key),
+			wantIss: poolB.issuer,
+		},
+		{
+			name:    "untrusted issuer",
+			token:   sign(t, jwt.SigningMethodRS256, "c", untrusted.claims(), untrusted.key),
+			wantErr: ErrUnknownIssuer,
+		},
+		{
+			name:    "trusted issuer signed with another pool's key",
+			token:   sign(t, jwt.SigningMethodRS256, "a", forged, poolA.key),
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:    "trusted issuer and kid with the wrong key",
+			token:   sign(t, jwt.SigningMethodRS256, "b", forged, poolA.key),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, claims, err := verifier.ParseJWT(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Iss != tt.wantIss {
+				t.Errorf("Iss = %q, want %q", claims.Iss, tt.wantIss)
+			}
+		})
+	}
+
+	if verifier.Issuer() != poolA.issuer || verifier.JWKURL() != poolA.server.URL {
+		t.Errorf("multi issuer reports %q %q, want the primary pool", verifier.Issuer(), verifier.JWKURL())
+	}
+}
+
+func TestNewMultiIssuerSingleVerifier(t *testing.T) {
+	pool := newTestPool(t, "a")
+	primary := pool.verifier(t)
+	if got := NewMultiIssuer(logger.Nop{}, primary); got != primary {
+		t.Errorf("NewMultiIssuer with one verifier = %T, want it returned as is", got)
+	}
+}
+
+func TestNewSourceDefaultsJWKURL(t *testing.T) {
+	v := NewSource("https://auth.example.com/", "", logger.Nop{})
+	if got, want := v.JWKURL(), "https://auth.example.com/.well-known/jwks.json"; got != want {
+		t.Errorf("JWKURL = %q, want %q", got, want)
+	}
+}
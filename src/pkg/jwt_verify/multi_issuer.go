@@ -0,0 +1,74 @@
+package jwt_verify
+
+import (
+	"auth-api/src/pkg/logger"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownIssuer = errors.New("token issuer not trusted")
+
+// multiIssuer accepts tokens from several user pools, e.g. while users are
+// migrated from one pool to another. A token is only ever checked against the
+// keys of the issuer it names.
+type multiIssuer struct {
+	primary   JWTVerify
+	verifiers map[string]JWTVerify
+	log       logger.Logger
+}
+
+// NewMultiIssuer trusts the issuers of all given verifiers. The first one is
+// the primary pool, whose JWK is reported by JWK and JWKURL. With a single
+// verifier it is returned as is.
+func NewMultiIssuer(logger logger.Logger, primary JWTVerify, others ...JWTVerify) JWTVerify {
+	if len(others) == 0 {
+		return primary
+	}
+	m := &multiIssuer{
+		primary:   primary,
+		verifiers: map[string]JWTVerify{primary.Issuer(): primary},
+		log:       logger,
+	}
+	for _, v := range others {
+		m.verifiers[v.Issuer()] = v
+	}
+	return m
+}
+
+func (m *multiIssuer) CacheJWK() error {
+	var errs []error
+	for _, v := range m.verifiers {
+		if err := v.CacheJWK(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.Issuer(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiIssuer) ParseJWT(tokenString string) (*jwt.Token, *Claims, error) {
+	claims, err := ParseUnverifiedClaims(tokenString)
+	if err != nil {
+		m.log.Error("Error parsing JWT %v", err)
+		return nil, nil, err
+	}
+	v, ok := m.verifiers[claims.Iss]
+	if !ok {
+		m.log.Error("Error parsing JWT %v: %q", ErrUnknownIssuer, claims.Iss)
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownIssuer, claims.Iss)
+	}
+	return v.ParseJWT(tokenString)
+}
+
+func (m *multiIssuer) JWK() *JWK {
+	return m.primary.JWK()
+}
+
+func (m *multiIssuer) JWKURL() string {
+	return m.primary.JWKURL()
+}
+
+func (m *multiIssuer) Issuer() string {
+	return m.primary.Issuer()
+}
@@ -61,3 +61,12 @@ func (z *ZapLogger) Warning(format string, v ...interface{}) {
 func (z *ZapLogger) Debug(format string, v ...interface{}) {
 	z.logger.Debugf(format, v...)
 }
+
+// Nop discards everything logged to it, for tests and callers that need a
+// Logger but no output.
+type Nop struct{}
+
+func (Nop) Info(string, ...interface{})    {}
+func (Nop) Error(string, ...interface{})   {}
+func (Nop) Warning(string, ...interface{}) {}
+func (Nop) Debug(string, ...interface{})   {}
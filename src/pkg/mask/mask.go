@@ -0,0 +1,59 @@
+package mask
+
+import "strings"
+
+// Destination masks where a code was sent the same way whichever Cognito API
+// reported it. medium is "EMAIL" or "SMS"; anything else is hidden entirely.
+// A destination Cognito already masked is returned as is, since what it hid
+// can't be put back.
+func Destination(medium, raw string) string {
+	if strings.Contains(raw, "*") {
+		return raw
+	}
+	switch strings.ToUpper(medium) {
+	case "EMAIL":
+		return Email(raw)
+	case "SMS":
+		return Phone(raw)
+	}
+	return "***"
+}
+
+// Email keeps the first letter of the local part and the whole domain, e.g.
+// "john.doe@example.com" becomes "j***@example.com". Short local parts get
+// the same three stars, so their length isn't given away.
+func Email(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "***"
+	}
+	return email[:1] + "***@" + email[at+1:]
+}
+
+// Phone keeps the "+" and first digit of an international number and the
+// last four digits, e.g. "+11234567890" becomes "+1******7890". Formatting
+// is dropped first. Numbers too short for that show fewer digits: two at the
+// end below eight digits, none at four or fewer.
+func Phone(phone string) string {
+	prefix := ""
+	if strings.HasPrefix(strings.TrimSpace(phone), "+") {
+		prefix = "+"
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+
+	n := len(digits)
+	switch {
+	case n <= 4:
+		return prefix + strings.Repeat("*", n)
+	case n < 8:
+		return prefix + strings.Repeat("*", n-2) + digits[n-2:]
+	case prefix != "":
+		return prefix + digits[:1] + strings.Repeat("*", n-5) + digits[n-4:]
+	}
+	return strings.Repeat("*", n-4) + digits[n-4:]
+}
@@ -0,0 +1,33 @@
+package mask
+
+import "testing"
+
+func TestDestination(t *testing.T) {
+	tests := []struct {
+		name   string
+		medium string
+		raw    string
+		want   string
+	}{
+		{name: "email", medium: "EMAIL", raw: "john.doe@example.com", want: "j***@example.com"},
+		{name: "one letter local part", medium: "EMAIL", raw: "j@example.com", want: "j***@example.com"},
+		{name: "not an email", medium: "EMAIL", raw: "john.doe", want: "***"},
+		{name: "empty domain", medium: "EMAIL", raw: "john@", want: "***"},
+		{name: "lower case medium", medium: "email", raw: "john@example.com", want: "j***@example.com"},
+		{name: "international phone", medium: "SMS", raw: "+11234567890", want: "+1******7890"},
+		{name: "formatted phone", medium: "SMS", raw: "+1 (123) 456-7890", want: "+1******7890"},
+		{name: "local phone", medium: "SMS", raw: "1234567890", want: "******7890"},
+		{name: "short phone", medium: "SMS", raw: "+123456", want: "+****56"},
+		{name: "very short phone", medium: "SMS", raw: "1234", want: "****"},
+		{name: "already masked", medium: "SMS", raw: "+*******7890", want: "+*******7890"},
+		{name: "unknown medium", medium: "VOICE", raw: "+11234567890", want: "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Destination(tt.medium, tt.raw); got != tt.want {
+				t.Errorf("Destination(%q, %q) = %q, want %q", tt.medium, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
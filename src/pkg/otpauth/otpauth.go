@@ -0,0 +1,53 @@
+package otpauth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+const maxIssuerLength = 64
+
+// Issuer is the name authenticator apps list a TOTP key under. Build one with
+// ParseIssuer so it is known to fit in an otpauth label.
+type Issuer string
+
+var ErrEmptyIssuer = errors.New("mfa issuer is empty")
+
+// ParseIssuer trims name and rejects what would break the "issuer:account"
+// label: an empty name, a colon, or control characters.
+func ParseIssuer(name string) (Issuer, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrEmptyIssuer
+	}
+	if len(name) > maxIssuerLength {
+		return "", fmt.Errorf("mfa issuer %q is longer than %d bytes", name, maxIssuerLength)
+	}
+	if strings.Contains(name, ":") {
+		return "", fmt.Errorf("mfa issuer %q contains a colon", name)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return "", fmt.Errorf("mfa issuer %q contains a non-printable character", name)
+		}
+	}
+	return Issuer(name), nil
+}
+
+// BuildTOTPURI encodes spaces as %20 rather than "+", and a literal "+" as
+// %2B, since authenticator apps disagree on what a bare "+" means.
+func BuildTOTPURI(issuer Issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", string(issuer))
+
+	label := escapeLabel(string(issuer)) + ":" + escapeLabel(account)
+	return "otpauth://totp/" + label + "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func escapeLabel(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "+", "%2B")
+}
@@ -0,0 +1,85 @@
+package otpauth
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestBuildTOTPURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuer    Issuer
+		account   string
+		wantLabel string
+		wantRaw   string
+	}{
+		{
+			name:      "plain",
+			issuer:    "Monitoring",
+			account:   "member@example.com",
+			wantLabel: "Monitoring:member@example.com",
+			wantRaw:   "otpauth://totp/Monitoring:member@example.com?issuer=Monitoring&secret=JBSWY3DPEHPK3PXP",
+		},
+		{
+			name:      "spaces and plus signs",
+			issuer:    "Acme Monitoring",
+			account:   "member+alerts@example.com",
+			wantLabel: "Acme Monitoring:member+alerts@example.com",
+			wantRaw:   "otpauth://totp/Acme%20Monitoring:member%2Balerts@example.com?issuer=Acme%20Monitoring&secret=JBSWY3DPEHPK3PXP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := BuildTOTPURI(tt.issuer, tt.account, "JBSWY3DPEHPK3PXP")
+			if raw != tt.wantRaw {
+				t.Errorf("BuildTOTPURI = %s, want %s", raw, tt.wantRaw)
+			}
+
+			uri, err := url.Parse(raw)
+			if err != nil {
+				t.Fatalf("url.Parse(%s): %v", raw, err)
+			}
+			if uri.Scheme != "otpauth" || uri.Host != "totp" {
+				t.Errorf("scheme/type = %s/%s, want otpauth/totp", uri.Scheme, uri.Host)
+			}
+			if label := uri.Path[1:]; label != tt.wantLabel {
+				t.Errorf("label = %q, want %q", label, tt.wantLabel)
+			}
+			query := uri.Query()
+			if query.Get("secret") != "JBSWY3DPEHPK3PXP" {
+				t.Errorf("secret = %q, want JBSWY3DPEHPK3PXP", query.Get("secret"))
+			}
+			if query.Get("issuer") != string(tt.issuer) {
+				t.Errorf("issuer = %q, want %q", query.Get("issuer"), tt.issuer)
+			}
+		})
+	}
+}
+
+func TestParseIssuer(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Issuer
+		wantErr bool
+	}{
+		{name: "trimmed", in: "  Monitoring ", want: "Monitoring"},
+		{name: "empty", in: "   ", wantErr: true},
+		{name: "colon", in: "Acme:Monitoring", wantErr: true},
+		{name: "control character", in: "Acme\nMonitoring", wantErr: true},
+		{name: "too long", in: string(make([]byte, maxIssuerLength+1)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIssuer(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIssuer error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseIssuer = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
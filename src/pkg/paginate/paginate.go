@@ -0,0 +1,66 @@
+package paginate
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStop can be returned by a yield callback to end pagination early without
+// reporting an error to the caller.
+var ErrStop = errors.New("stop pagination")
+
+// FetchPage fetches a single page starting at nextToken (nil for the first
+// page) and returns its items along with the token for the following page.
+type FetchPage[T any] func(ctx context.Context, nextToken *string) ([]T, *string, error)
+
+// Paginate walks every page returned by fetch and calls yield for each item.
+// It stops when there are no more pages, the context is cancelled, yield
+// returns an error or maxItems items have been yielded (0 means no limit).
+func Paginate[T any](ctx context.Context, fetch FetchPage[T], maxItems int, yield func(T) error) error {
+	var nextToken *string
+	yielded := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, next, err := fetch(ctx, nextToken)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if maxItems > 0 && yielded >= maxItems {
+				return nil
+			}
+			if err := yield(item); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
+			yielded++
+		}
+
+		if next == nil || *next == "" {
+			return nil
+		}
+		nextToken = next
+	}
+}
+
+// Collect gathers every item returned by Paginate into a slice.
+func Collect[T any](ctx context.Context, fetch FetchPage[T], maxItems int) ([]T, error) {
+	var all []T
+	err := Paginate(ctx, fetch, maxItems, func(item T) error {
+		all = append(all, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
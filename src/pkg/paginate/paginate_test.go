@@ -0,0 +1,113 @@
+package paginate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// pages serves the given pages in order, using the page index as the token,
+// and counts the fetches.
+type pages struct {
+	items   [][]int
+	fetches int
+}
+
+func (p *pages) fetch(ctx context.Context, nextToken *string) ([]int, *string, error) {
+	page := 0
+	if nextToken != nil {
+		page, _ = strconv.Atoi(*nextToken)
+	}
+	p.fetches++
+	if page+1 >= len(p.items) {
+		return p.items[page], nil, nil
+	}
+	next := strconv.Itoa(page + 1)
+	return p.items[page], &next, nil
+}
+
+func TestPaginate(t *testing.T) {
+	errYield := errors.New("yield failed")
+
+	tests := []struct {
+		name        string
+		pages       [][]int
+		maxItems    int
+		stopAt      int
+		failAt      int
+		cancelAt    int
+		want        []int
+		wantFetches int
+		wantErr     error
+	}{
+		{name: "single page", pages: [][]int{{1, 2, 3}}, want: []int{1, 2, 3}, wantFetches: 1},
+		{name: "empty page", pages: [][]int{{}}, wantFetches: 1},
+		{name: "multiple pages", pages: [][]int{{1, 2}, {3}, {4, 5}}, want: []int{1, 2, 3, 4, 5}, wantFetches: 3},
+		{name: "max items", pages: [][]int{{1, 2}, {3, 4}, {5}}, maxItems: 3, want: []int{1, 2, 3}, wantFetches: 2},
+		{name: "stopped by yield", pages: [][]int{{1, 2}, {3, 4}}, stopAt: 2, want: []int{1}, wantFetches: 1},
+		{name: "yield error", pages: [][]int{{1, 2}, {3, 4}}, failAt: 3, want: []int{1, 2}, wantFetches: 2, wantErr: errYield},
+		{name: "cancelled mid page", pages: [][]int{{1, 2}, {3, 4}}, cancelAt: 1, want: []int{1}, wantFetches: 1, wantErr: context.Canceled},
+		{name: "cancelled at the end of a page", pages: [][]int{{1, 2}, {3, 4}}, cancelAt: 2, want: []int{1, 2}, wantFetches: 1, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			source := &pages{items: tt.pages}
+
+			var got []int
+			err := Paginate(ctx, source.fetch, tt.maxItems, func(item int) error {
+				switch item {
+				case tt.stopAt:
+					return ErrStop
+				case tt.failAt:
+					return errYield
+				}
+				got = append(got, item)
+				if item == tt.cancelAt {
+					cancel()
+				}
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Paginate error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("yielded %v, want %v", got, tt.want)
+			}
+			if source.fetches != tt.wantFetches {
+				t.Errorf("fetched %d pages, want %d", source.fetches, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestPaginateDoesNotFetchOnceCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source := &pages{items: [][]int{{1}}}
+
+	err := Paginate(ctx, source.fetch, 0, func(int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Paginate error = %v, want %v", err, context.Canceled)
+	}
+	if source.fetches != 0 {
+		t.Errorf("fetched %d pages after cancellation", source.fetches)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	source := &pages{items: [][]int{{1, 2}, {3}}}
+
+	got, err := Collect(context.Background(), source.fetch, 0)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("Collect = %v, want [1 2 3]", got)
+	}
+}
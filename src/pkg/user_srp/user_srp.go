@@ -0,0 +1,142 @@
+// Package user_srp answers Cognito's USER_SRP_AUTH flow, so the password never
+// leaves the server in the InitiateAuth call. It follows the same steps as the
+// AWS Amplify client.
+package user_srp
+
+import (
+	"auth-api/src/pkg/device_srp"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	ephemeralBytes  = 128
+	derivedKeyInfo  = "Caldera Derived Key"
+	derivedKeyBytes = 16
+	// Cognito rejects zero padded days, so it's "Mon Jan 2", not "Mon Jan 02".
+	timestampLayout = "Mon Jan 2 15:04:05 UTC 2006"
+)
+
+var ErrInvalidServerValue = errors.New("invalid SRP_B from server")
+
+var k = hexHash(device_srp.PadHex(device_srp.N) + device_srp.PadHex(device_srp.G))
+
+// Client holds the ephemeral secret of one login attempt; it must not be
+// reused across attempts.
+type Client struct {
+	poolName string
+	a        *big.Int
+	bigA     *big.Int
+}
+
+// PasswordClaim is the PASSWORD_VERIFIER challenge answer.
+type PasswordClaim struct {
+	Signature string
+	Timestamp string
+}
+
+// NewClient takes the full user pool id, like "us-east-1_AbC123".
+func NewClient(userPoolID string) (*Client, error) {
+	_, poolName, ok := strings.Cut(userPoolID, "_")
+	if !ok || poolName == "" {
+		return nil, errors.New("invalid user pool id")
+	}
+	for {
+		raw := make([]byte, ephemeralBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		a := new(big.Int).Mod(new(big.Int).SetBytes(raw), device_srp.N)
+		bigA := new(big.Int).Exp(device_srp.G, a, device_srp.N)
+		if bigA.Sign() != 0 {
+			return &Client{poolName: poolName, a: a, bigA: bigA}, nil
+		}
+	}
+}
+
+// SRPA is the SRP_A auth parameter for InitiateAuth.
+func (c *Client) SRPA() string {
+	return c.bigA.Text(16)
+}
+
+// Claim answers the PASSWORD_VERIFIER challenge. userID is USER_ID_FOR_SRP
+// and the other values come straight from the challenge parameters.
+func (c *Client) Claim(userID, password, saltHex, srpBHex, secretBlock string, now time.Time) (*PasswordClaim, error) {
+	bigB, ok := new(big.Int).SetString(srpBHex, 16)
+	if !ok || new(big.Int).Mod(bigB, device_srp.N).Sign() == 0 {
+		return nil, ErrInvalidServerValue
+	}
+	salt, ok := new(big.Int).SetString(saltHex, 16)
+	if !ok {
+		return nil, ErrInvalidServerValue
+	}
+
+	u := hexHash(device_srp.PadHex(c.bigA) + device_srp.PadHex(bigB))
+	if u.Sign() == 0 {
+		return nil, ErrInvalidServerValue
+	}
+
+	userHash := sha256.Sum256([]byte(c.poolName + userID + ":" + password))
+	x := hexHash(device_srp.PadHex(salt) + hex.EncodeToString(userHash[:]))
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	gx := new(big.Int).Exp(device_srp.G, x, device_srp.N)
+	base := new(big.Int).Sub(bigB, new(big.Int).Mul(k, gx))
+	base.Mod(base, device_srp.N)
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	s := new(big.Int).Exp(base, exp, device_srp.N)
+
+	key, err := derivedKey(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := base64.StdEncoding.DecodeString(secretBlock)
+	if err != nil {
+		return nil, ErrInvalidServerValue
+	}
+	timestamp := now.UTC().Format(timestampLayout)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(c.poolName))
+	mac.Write([]byte(userID))
+	mac.Write(block)
+	mac.Write([]byte(timestamp))
+
+	return &PasswordClaim{
+		Signature: base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		Timestamp: timestamp,
+	}, nil
+}
+
+// derivedKey is a single block HKDF-SHA256 with u as the salt.
+func derivedKey(s, u *big.Int) ([]byte, error) {
+	ikm, err := hex.DecodeString(device_srp.PadHex(s))
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(device_srp.PadHex(u))
+	if err != nil {
+		return nil, err
+	}
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(derivedKeyInfo))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:derivedKeyBytes], nil
+}
+
+// hexHash hashes the bytes of a hex string and reads the digest as a number.
+func hexHash(hexValue string) *big.Int {
+	raw, _ := hex.DecodeString(hexValue)
+	sum := sha256.Sum256(raw)
+	return new(big.Int).SetBytes(sum[:])
+}
@@ -0,0 +1,191 @@
+package user_srp
+
+import (
+	"auth-api/src/pkg/device_srp"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func mustHex(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		t.Fatalf("bad hex %q", s)
+	}
+	return v
+}
+
+// newTestClient fixes the ephemeral secret so the claim is reproducible.
+func newTestClient(t *testing.T, poolName, aHex string) *Client {
+	t.Helper()
+	a := mustHex(t, aHex)
+	return &Client{poolName: poolName, a: a, bigA: new(big.Int).Exp(device_srp.G, a, device_srp.N)}
+}
+
+// srpB stands in for the server: g^b mod N.
+func srpB(t *testing.T, b string) string {
+	return new(big.Int).Exp(device_srp.G, mustHex(t, b), device_srp.N).Text(16)
+}
+
+var secretBlock = base64.StdEncoding.EncodeToString([]byte("secret-block-from-cognito"))
+
+// The expected values follow AuthenticationHelper.getPasswordAuthenticationKey
+// and the PASSWORD_VERIFIER signature in amazon-cognito-identity-js step by
+// step, computed outside this package.
+func TestDerivedKey(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *big.Int
+		u    *big.Int
+		want string
+	}{
+		{
+			name: "high bit set on a single byte",
+			s:    big.NewInt(0x80),
+			u:    big.NewInt(1),
+			want: "b9aa8141c50247f2bbf32e2a5fd53d4d",
+		},
+		{
+			name: "full size secret",
+			s:    new(big.Int).Exp(device_srp.G, big.NewInt(12345), device_srp.N),
+			u:    mustHex(t, "fedcba9876543210"),
+			want: "d762b53f347bf06d4eef4b9d1ff05849",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := derivedKey(tt.s, tt.u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("derivedKey = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaim(t *testing.T) {
+	tests := []struct {
+		name          string
+		poolName      string
+		a             string
+		userID        string
+		password      string
+		salt          string
+		b             string
+		now           time.Time
+		wantSignature string
+		wantTimestamp string
+	}{
+		{
+			name:          "single byte salt",
+			poolName:      "AbC123",
+			a:             "1234567890abcdef",
+			userID:        "4f2a7c9e-user",
+			password:      "Passw0rd!",
+			salt:          "01",
+			b:             "deadbeef",
+			now:           time.Date(2024, 3, 5, 7, 8, 9, 0, time.UTC),
+			wantSignature: "7qozXMRAS2r/39vqMfCGlwl6kSLw+Fi1EqaWiXtJK+k=",
+			wantTimestamp: "Tue Mar 5 07:08:09 UTC 2024",
+		},
+		{
+			name:          "salt with the high bit set and an empty password",
+			poolName:      "xYz",
+			a:             "8000000000000000000001",
+			userID:        "bob",
+			password:      "",
+			salt:          "80f1e2d3c4b5a697",
+			b:             "3ade68b1",
+			now:           time.Date(2025, 12, 25, 23, 59, 1, 0, time.UTC),
+			wantSignature: "Rh2lzi3yvtus+/MtXrahaqquL21VZJJO1U5/VQ+pbf0=",
+			wantTimestamp: "Thu Dec 25 23:59:01 UTC 2025",
+		},
+		{
+			name:          "local time is sent as UTC",
+			poolName:      "AbC123",
+			a:             "1234567890abcdef",
+			userID:        "4f2a7c9e-user",
+			password:      "Passw0rd!",
+			salt:          "01",
+			b:             "deadbeef",
+			now:           time.Date(2024, 3, 5, 4, 8, 9, 0, time.FixedZone("BRT", -3*60*60)),
+			wantSignature: "7qozXMRAS2r/39vqMfCGlwl6kSLw+Fi1EqaWiXtJK+k=",
+			wantTimestamp: "Tue Mar 5 07:08:09 UTC 2024",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.poolName, tt.a)
+			got, err := client.Claim(tt.userID, tt.password, tt.salt, srpB(t, tt.b), secretBlock, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Signature != tt.wantSignature {
+				t.Errorf("Signature = %s, want %s", got.Signature, tt.wantSignature)
+			}
+			if got.Timestamp != tt.wantTimestamp {
+				t.Errorf("Timestamp = %q, want %q", got.Timestamp, tt.wantTimestamp)
+			}
+		})
+	}
+}
+
+func TestClaimRejectsBadServerValues(t *testing.T) {
+	valid := func(t *testing.T) string { return srpB(t, "deadbeef") }
+
+	tests := []struct {
+		name        string
+		salt        string
+		srpB        func(t *testing.T) string
+		secretBlock string
+	}{
+		{name: "B is zero", salt: "01", srpB: func(*testing.T) string { return "0" }, secretBlock: secretBlock},
+		{name: "B is N", salt: "01", srpB: func(*testing.T) string { return device_srp.N.Text(16) }, secretBlock: secretBlock},
+		{name: "B is not hex", salt: "01", srpB: func(*testing.T) string { return "xyz" }, secretBlock: secretBlock},
+		{name: "salt is not hex", salt: "salt", srpB: valid, secretBlock: secretBlock},
+		{name: "secret block is not base64", salt: "01", srpB: valid, secretBlock: "not base64!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, "AbC123", "1234567890abcdef")
+			if _, err := client.Claim("user", "password", tt.salt, tt.srpB(t), tt.secretBlock, time.Now()); err != ErrInvalidServerValue {
+				t.Errorf("Claim error = %v, want %v", err, ErrInvalidServerValue)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		userPoolID string
+		wantPool   string
+		wantErr    bool
+	}{
+		{userPoolID: "us-east-1_AbC123", wantPool: "AbC123"},
+		{userPoolID: "AbC123", wantErr: true},
+		{userPoolID: "us-east-1_", wantErr: true},
+	}
+	for _, tt := range tests {
+		client, err := NewClient(tt.userPoolID)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("NewClient(%q) error = %v, want error %v", tt.userPoolID, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if client.poolName != tt.wantPool {
+			t.Errorf("NewClient(%q) pool = %q, want %q", tt.userPoolID, client.poolName, tt.wantPool)
+		}
+		if mustHex(t, client.SRPA()).Cmp(new(big.Int).Exp(device_srp.G, client.a, device_srp.N)) != 0 {
+			t.Errorf("SRPA isn't g^a mod N")
+		}
+	}
+}
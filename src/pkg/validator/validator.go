@@ -3,10 +3,22 @@ package validator
 import (
 	"errors"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// NormalizeEmail puts email in the form usernames are stored in: lower case,
+// unless the user pool was created with case sensitive usernames.
+func NormalizeEmail(email string, caseSensitive bool) string {
+	if caseSensitive {
+		return email
+	}
+	return strings.ToLower(email)
+}
+
 func ValidateEmail(email string) error {
-	re := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
+	re := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 	if !re.MatchString(email) {
 		return errors.New("invalid email format")
 	}
@@ -28,6 +40,20 @@ func ValidateStringLength(str string, min, max int) error {
 	return nil
 }
 
+// ValidatePrintable rejects invalid UTF-8 and control characters, which
+// Cognito only refuses with a generic error.
+func ValidatePrintable(str string) error {
+	if !utf8.ValidString(str) {
+		return errors.New("invalid UTF-8")
+	}
+	for _, r := range str {
+		if unicode.IsControl(r) {
+			return errors.New("contains control characters")
+		}
+	}
+	return nil
+}
+
 func ValidateNumeric(str string) error {
 	re := regexp.MustCompile(`^[0-9]+$`)
 	if !re.MatchString(str) {